@@ -0,0 +1,263 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+// RenderingContext is the WebGL 1.0 API shared by every backend, without
+// the methods taking or returning JavaScript values. *Context implements
+// it over a browser's WebGLRenderingContext, while other implementations,
+// such as the one in the record package, run under plain go test.
+//
+// The methods are documented on Context.
+type RenderingContext interface {
+	GetContextAttributes() ContextAttributes
+	ActiveTexture(texture Enum)
+	AttachShader(program Program, shader Shader)
+	BindAttribLocation(program Program, index int, name string)
+	BindBuffer(target Enum, buffer Buffer)
+	BindFramebuffer(target Enum, framebuffer Framebuffer)
+	BindRenderbuffer(target Enum, renderbuffer Renderbuffer)
+	BindTexture(target Enum, texture Texture)
+	BlendColor(r, g, b, a float64)
+	BlendEquation(mode Enum)
+	BlendEquationSeparate(modeRGB, modeAlpha Enum)
+	BlendFunc(sfactor, dfactor Enum)
+	BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha Enum)
+	BufferData(target Enum, data interface{}, usage Enum)
+	BufferDataBytes(target Enum, data []byte, usage Enum)
+	BufferDataInt8(target Enum, data []int8, usage Enum)
+	BufferDataInt16(target Enum, data []int16, usage Enum)
+	BufferDataUint16(target Enum, data []uint16, usage Enum)
+	BufferDataInt32(target Enum, data []int32, usage Enum)
+	BufferDataUint32(target Enum, data []uint32, usage Enum)
+	BufferDataFloat32(target Enum, data []float32, usage Enum)
+	BufferSubData(target Enum, offset int, data interface{})
+	BufferSubDataBytes(target Enum, offset int, data []byte)
+	BufferSubDataInt8(target Enum, offset int, data []int8)
+	BufferSubDataInt16(target Enum, offset int, data []int16)
+	BufferSubDataUint16(target Enum, offset int, data []uint16)
+	BufferSubDataInt32(target Enum, offset int, data []int32)
+	BufferSubDataUint32(target Enum, offset int, data []uint32)
+	BufferSubDataFloat32(target Enum, offset int, data []float32)
+	CheckFramebufferStatus(target Enum) Enum
+	Clear(mask Enum)
+	ClearColor(r, g, b, a float32)
+	ClearDepth(depth float64)
+	ClearStencil(s int)
+	ColorMask(r, g, b, a bool)
+	CompileShader(shader Shader)
+	CompressedTexImage2D(target Enum, level int, internalFormat Enum, width, height, border int, data []byte)
+	CompressedTexSubImage2D(target Enum, level, xoffset, yoffset, width, height int, format Enum, data []byte)
+	CopyTexImage2D(target Enum, level int, internal Enum, x, y, w, h, border int)
+	CopyTexSubImage2D(target Enum, level, xoffset, yoffset, x, y, w, h int)
+	CreateBuffer() Buffer
+	CreateFramebuffer() Framebuffer
+	CreateProgram() Program
+	CreateRenderbuffer() Renderbuffer
+	CreateShader(typ Enum) Shader
+	CreateTexture() Texture
+	CullFace(mode Enum)
+	DeleteBuffer(buffer Buffer)
+	DeleteFramebuffer(framebuffer Framebuffer)
+	DeleteProgram(program Program)
+	DeleteRenderbuffer(renderbuffer Renderbuffer)
+	DeleteShader(shader Shader)
+	DeleteTexture(texture Texture)
+	DrawingBufferWidth() int
+	DrawingBufferHeight() int
+	DepthFunc(fun Enum)
+	DepthMask(flag bool)
+	DepthRange(zNear, zFar float64)
+	DetachShader(program Program, shader Shader)
+	Disable(cap Enum)
+	DisableVertexAttribArray(index int)
+	DrawArrays(mode Enum, first, count int)
+	DrawElements(mode Enum, count int, typ Enum, offset int)
+	Enable(cap Enum)
+	EnableVertexAttribArray(index int)
+	Finish()
+	Flush()
+	FrameBufferRenderBuffer(target, attachment, renderbufferTarget Enum, renderbuffer Renderbuffer)
+	FramebufferTexture2D(target, attachment, textarget Enum, texture Texture, level int)
+	FrontFace(mode Enum)
+	GenerateMipmap(target Enum)
+	GetActiveAttrib(program Program, index int) ActiveInfo
+	GetActiveUniform(program Program, index int) ActiveInfo
+	GetAttachedShaders(program Program) []Shader
+	GetAttribLocation(program Program, name string) int
+	GetBufferParameteri(target, pname Enum) int
+	GetParameteri(pname Enum) int
+	GetParameterb(pname Enum) bool
+	GetParameterf(pname Enum) float32
+	GetParameterString(pname Enum) string
+	GetParameterObject(pname Enum) Object
+	GetError() Enum
+	CheckError() error
+	GetFramebufferAttachmentParameteri(target, attachment, pname Enum) int
+	GetProgramParameteri(program Program, pname Enum) int
+	GetProgramParameterb(program Program, pname Enum) bool
+	GetProgramInfoLog(program Program) string
+	GetRenderbufferParameteri(target, pname Enum) int
+	GetRenderbufferInternalFormat(target Enum) Enum
+	GetShaderParameterb(shader Shader, pname Enum) bool
+	GetShaderParameteri(shader Shader, pname Enum) int
+	GetShaderPrecisionFormat(shaderType, precisionType Enum) ShaderPrecisionFormat
+	GetShaderInfoLog(shader Shader) string
+	GetShaderSource(shader Shader) string
+	GetSupportedExtensions() []string
+	GetTexParameteri(target, pname Enum) int
+	GetUniformLocation(program Program, name string) UniformLocation
+	GetVertexAttribOffset(index int, pname Enum) int
+	Hint(target, mode Enum)
+	IsBuffer(buffer Buffer) bool
+	IsContextLost() bool
+	IsFramebuffer(framebuffer Framebuffer) bool
+	IsProgram(program Program) bool
+	IsRenderbuffer(renderbuffer Renderbuffer) bool
+	IsShader(shader Shader) bool
+	IsTexture(texture Texture) bool
+	IsEnabled(capability Enum) bool
+	LineWidth(width float64)
+	LinkProgram(program Program)
+	PixelStorei(pname Enum, param int)
+	PolygonOffset(factor, units float64)
+	ReadPixelsBytes(x, y, width, height int, format, typ Enum, pixels []byte)
+	RenderbufferStorage(target, internalFormat Enum, width, height int)
+	SampleCoverage(value float32, invert bool)
+	Scissor(x, y, width, height int)
+	ShaderSource(shader Shader, source string)
+	StencilFunc(fun Enum, ref int, mask uint32)
+	StencilFuncSeparate(face, fun Enum, ref int, mask uint32)
+	StencilMask(mask uint32)
+	StencilMaskSeparate(face Enum, mask uint32)
+	StencilOp(fail, zfail, zpass Enum)
+	StencilOpSeparate(face, fail, zfail, zpass Enum)
+	TexImage2DPixels(target Enum, level int, internalFormat Enum, width, height, border int, format, typ Enum, pixels []byte)
+	TexParameterf(target, pname Enum, param float32)
+	TexParameteri(target, pname, param Enum)
+	TexSubImage2DPixels(target Enum, level, xoffset, yoffset, width, height int, format, typ Enum, pixels []byte)
+	Uniform1f(location UniformLocation, x float32)
+	Uniform1i(location UniformLocation, x int)
+	Uniform2f(location UniformLocation, x, y float32)
+	Uniform2i(location UniformLocation, x, y int)
+	Uniform3f(location UniformLocation, x, y, z float32)
+	Uniform3i(location UniformLocation, x, y, z int)
+	Uniform4f(location UniformLocation, x, y, z, w float32)
+	Uniform4i(location UniformLocation, x, y, z, w int)
+	Uniform1fv(location UniformLocation, v []float32)
+	Uniform1iv(location UniformLocation, v []int32)
+	Uniform2fv(location UniformLocation, v []float32)
+	Uniform2iv(location UniformLocation, v []int32)
+	Uniform3fv(location UniformLocation, v []float32)
+	Uniform3iv(location UniformLocation, v []int32)
+	Uniform4fv(location UniformLocation, v []float32)
+	Uniform4iv(location UniformLocation, v []int32)
+	UniformMatrix2fv(location UniformLocation, transpose bool, value []float32)
+	UniformMatrix3fv(location UniformLocation, transpose bool, value []float32)
+	UniformMatrix4fv(location UniformLocation, transpose bool, value []float32)
+	UseProgram(program Program)
+	ValidateProgram(program Program)
+	VertexAttribPointer(index, size int, typ Enum, normal bool, stride, offset int)
+	VertexAttrib1f(index int, x float32)
+	VertexAttrib2f(index int, x, y float32)
+	VertexAttrib3f(index int, x, y, z float32)
+	VertexAttrib4f(index int, x, y, z, w float32)
+	VertexAttrib1fv(index int, values []float32)
+	VertexAttrib2fv(index int, values []float32)
+	VertexAttrib3fv(index int, values []float32)
+	VertexAttrib4fv(index int, values []float32)
+	Viewport(x, y, width, height int)
+}
+
+type ContextAttributes struct {
+	// If Alpha is true, the drawing buffer has an alpha channel for
+	// the purposes of performing OpenGL destination alpha operations
+	// and compositing with the page.
+	Alpha bool
+
+	// If Depth is true, the drawing buffer has a depth buffer of at least 16 bits.
+	Depth bool
+
+	// If Stencil is true, the drawing buffer has a stencil buffer of at least 8 bits.
+	Stencil bool
+
+	// If Antialias is true and the implementation supports antialiasing
+	// the drawing buffer will perform antialiasing using its choice of
+	// technique (multisample/supersample) and quality.
+	Antialias bool
+
+	// If PremultipliedAlpha is true the page compositor will assume the
+	// drawing buffer contains colors with premultiplied alpha.
+	// This flag is ignored if the alpha flag is false.
+	PremultipliedAlpha bool
+
+	// If the value is true the buffers will not be cleared and will preserve
+	// their values until cleared or overwritten by the author.
+	PreserveDrawingBuffer bool
+
+	// PowerPreference is a hint to the user agent indicating what
+	// configuration of GPU is suitable for this context.
+	PowerPreference PowerPreference
+
+	// If FailIfMajorPerformanceCaveat is true, context creation will fail
+	// if the implementation determines that the performance of the created
+	// context would be dramatically lower than that of a native application.
+	FailIfMajorPerformanceCaveat bool
+
+	// If Desynchronized is true, the user agent may reduce latency by
+	// desynchronizing the canvas paint cycle from the event loop.
+	Desynchronized bool
+
+	// If XRCompatible is true, the context will be created on a device
+	// that is compatible with immersive XR sessions.
+	XRCompatible bool
+}
+
+// PowerPreference is the GPU configuration hint given on context creation.
+type PowerPreference string
+
+const (
+	PowerPreferenceDefault         PowerPreference = "default"
+	PowerPreferenceLowPower        PowerPreference = "low-power"
+	PowerPreferenceHighPerformance PowerPreference = "high-performance"
+)
+
+// Returns a copy of the default WebGL context attributes.
+func DefaultAttributes() *ContextAttributes {
+	return &ContextAttributes{
+		Alpha:              true,
+		Depth:              true,
+		Stencil:            false,
+		Antialias:          true,
+		PremultipliedAlpha: true,
+		PowerPreference:    PowerPreferenceDefault,
+	}
+}
+
+// ActiveInfo describes an active attribute, uniform or transform feedback
+// varying of a program, as returned by the WebGLActiveInfo object.
+type ActiveInfo struct {
+	// Name is the name of the variable as reported by WebGL. Uniform
+	// arrays are reported with an element suffix, such as "u_weights[0]".
+	Name string
+
+	// Size is the number of array elements, or 1 if it is not an array.
+	Size int
+
+	// Type is the GLSL type, such as FLOAT_VEC4 or SAMPLER_2D.
+	Type Enum
+}
+
+// ShaderPrecisionFormat describes the range and precision for a numeric
+// format supported by the shader compiler.
+type ShaderPrecisionFormat struct {
+	// The base 2 log of the absolute value of the minimum representable value.
+	RangeMin int
+
+	// The base 2 log of the absolute value of the maximum representable value.
+	RangeMax int
+
+	// The number of bits of precision that can be represented.
+	Precision int
+}
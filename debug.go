@@ -0,0 +1,47 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+// Returns the error recorded by the WebGL error flag and clears the flag,
+// or nil if no error has occurred since the last call.
+func (c *Context) CheckError() error {
+	if e := c.GetError(); e != NO_ERROR {
+		return Error(e)
+	}
+	return nil
+}
+
+// Returns a context sharing the underlying WebGL context of c, and the
+// state c keeps for it, that calls getError after every call and passes
+// any error to report. Contexts that are not created through Debug never
+// call getError on their own.
+func (c *Context) Debug(report func(*CallError)) *Context {
+	return &Context{Value: c.Value, contextState: c.contextState, debug: report}
+}
+
+// Returns a WebGL 2 context sharing the underlying WebGL context of c that
+// calls getError after every call and passes any error to report.
+func (c *Context2) Debug(report func(*CallError)) *Context2 {
+	return &Context2{c.Context.Debug(report)}
+}
+
+// Checks the WebGL error flag after a call to method and reports every
+// pending error together with the caller's location.
+func (c *Context) check(method string, args []interface{}) {
+	if method == "getError" {
+		return
+	}
+	for {
+		e := Enum(c.Value.Call("getError").Int())
+		if e == NO_ERROR {
+			return
+		}
+		file, line := caller()
+		c.debug(&CallError{Err: Error(e), Method: method, Args: args, File: file, Line: line})
+		if Error(e) == ErrContextLost {
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Returns the object drawBuffers is called on and the suffix of its
+// name: the context itself on WebGL 2, otherwise the WEBGL_draw_buffers
+// extension. ok is false if neither is available.
+func (c *Context) drawBuffersTarget() (target js.Value, suffix string, ok bool) {
+	if c.webglVersion() == 2 {
+		return c.Value, "", true
+	}
+	if ext := c.GetExtension("WEBGL_draw_buffers"); ext.Truthy() {
+		return ext, "WEBGL", true
+	}
+	return js.Value{}, "", false
+}
+
+// Returns an implementation limit, reading it with GetParameteri the first
+// time it is asked for. Limits are not cached while the context is lost,
+// when they read as 0.
+func (c *Context) limit(pname Enum) int {
+	if n, ok := c.limits[pname]; ok {
+		return n
+	}
+	n := c.GetParameteri(pname)
+	if n > 0 {
+		c.limits[pname] = n
+	}
+	return n
+}
+
+// Returns the number of color buffers a fragment shader can write to at
+// once, which is 1 without WebGL 2 or the WEBGL_draw_buffers extension.
+func (c *Context) MaxDrawBuffers() int {
+	if _, _, ok := c.drawBuffersTarget(); !ok {
+		return 1
+	}
+	return c.limit(MAX_DRAW_BUFFERS)
+}
+
+// Returns the number of color attachments a framebuffer can have, which
+// is 1 without WebGL 2 or the WEBGL_draw_buffers extension.
+func (c *Context) MaxColorAttachments() int {
+	if _, _, ok := c.drawBuffersTarget(); !ok {
+		return 1
+	}
+	return c.limit(MAX_COLOR_ATTACHMENTS)
+}
+
+// Specifies the color buffers that fragment shader outputs are written
+// into. For a framebuffer object buffers[i] must be COLOR_ATTACHMENTi or
+// NONE, and for the default framebuffer a single BACK or NONE. It uses
+// WebGL 2 when available and the WEBGL_draw_buffers extension otherwise,
+// returning an *UnsupportedError if neither exists. Buffers beyond
+// MaxDrawBuffers or attachments beyond MaxColorAttachments are reported
+// as an error without calling WebGL.
+func (c *Context) DrawBuffers(buffers []Enum) error {
+	target, suffix, ok := c.drawBuffersTarget()
+	if !ok {
+		return &UnsupportedError{Feature: "DrawBuffers", Extension: "WEBGL_draw_buffers"}
+	}
+	if limit := c.limit(MAX_DRAW_BUFFERS); len(buffers) > limit {
+		return fmt.Errorf("webgl: %d draw buffers, the context supports %d", len(buffers), limit)
+	}
+	maxAttachments := c.limit(MAX_COLOR_ATTACHMENTS)
+	for i, buffer := range buffers {
+		switch {
+		case buffer == NONE:
+		case buffer == BACK:
+			if len(buffers) != 1 {
+				return fmt.Errorf("webgl: BACK must be the only draw buffer")
+			}
+		case buffer >= COLOR_ATTACHMENT0 && buffer <= COLOR_ATTACHMENT15:
+			n := int(buffer - COLOR_ATTACHMENT0)
+			if n >= maxAttachments {
+				return fmt.Errorf("webgl: draw buffer COLOR_ATTACHMENT%d, the context supports %d color attachments", n, maxAttachments)
+			}
+			if n != i {
+				return fmt.Errorf("webgl: draw buffer %d must be COLOR_ATTACHMENT%d or NONE, got COLOR_ATTACHMENT%d", i, i, n)
+			}
+		default:
+			return fmt.Errorf("webgl: invalid draw buffer 0x%04X", uint32(buffer))
+		}
+	}
+	c.Invoke(target, "drawBuffers"+suffix, enumArray(buffers))
+	return nil
+}
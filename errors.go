@@ -0,0 +1,111 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Error is a WebGL error code reported by getError.
+type Error Enum
+
+// The errors reported by getError.
+const (
+	ErrInvalidEnum                 Error = Error(INVALID_ENUM)
+	ErrInvalidValue                Error = Error(INVALID_VALUE)
+	ErrInvalidOperation            Error = Error(INVALID_OPERATION)
+	ErrInvalidFramebufferOperation Error = Error(INVALID_FRAMEBUFFER_OPERATION)
+	ErrOutOfMemory                 Error = Error(OUT_OF_MEMORY)
+	ErrContextLost                 Error = Error(CONTEXT_LOST_WEBGL)
+)
+
+func (e Error) Error() string {
+	switch e {
+	case ErrInvalidEnum:
+		return "webgl: invalid enum"
+	case ErrInvalidValue:
+		return "webgl: invalid value"
+	case ErrInvalidOperation:
+		return "webgl: invalid operation"
+	case ErrInvalidFramebufferOperation:
+		return "webgl: invalid framebuffer operation"
+	case ErrOutOfMemory:
+		return "webgl: out of memory"
+	case ErrContextLost:
+		return "webgl: context lost"
+	}
+	return fmt.Sprintf("webgl: error 0x%04X", uint32(e))
+}
+
+// CallError describes a WebGL error detected by a debug context
+// right after the call that caused it.
+type CallError struct {
+	// Err is the error reported by getError.
+	Err Error
+
+	// Method is the name of the WebGLRenderingContext method that failed.
+	Method string
+
+	// Args are the Go arguments the method was called with.
+	Args []interface{}
+
+	// File and Line locate the call site outside of this package.
+	File string
+	Line int
+}
+
+func (e *CallError) Error() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		switch arg := arg.(type) {
+		case Enum:
+			args[i] = fmt.Sprintf("0x%04X", uint32(arg))
+		case object:
+			args[i] = fmt.Sprintf("%T", arg)
+		default:
+			args[i] = fmt.Sprint(arg)
+		}
+	}
+	return fmt.Sprintf("%s:%d: %s(%s): %v", e.File, e.Line, e.Method, strings.Join(args, ", "), e.Err)
+}
+
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedError is returned by Context methods that need a WebGL 2
+// feature when the context is a WebGL 1 context without the extension
+// providing it.
+type UnsupportedError struct {
+	// Feature is the name of the method that was called.
+	Feature string
+
+	// Extension is the WebGL 1 extension that provides the feature.
+	Extension string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("webgl: %s needs webgl2 or the %s extension", e.Feature, e.Extension)
+}
+
+// Returns the location of the first caller outside of this package and
+// the ext package, whose extension methods call Invoke.
+func caller() (string, int) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	pkg := reflect.TypeOf(Context{}).PkgPath()
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		if !strings.HasPrefix(frame.Function, pkg+".") && !strings.HasPrefix(frame.Function, pkg+"/ext.") {
+			return frame.File, frame.Line
+		}
+	}
+	return "unknown", 0
+}
@@ -0,0 +1,69 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(js && wasm)
+
+package webgl_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/ext"
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Returns the line of the statement after the call.
+func nextLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line + 1
+}
+
+func TestCallError(t *testing.T) {
+	fake := js.NewWebGL(1)
+	fake.Extensions = []string{"ANGLE_instanced_arrays"}
+	gl, err := webgl.NewContext(js.NewCanvas(fake).Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []*webgl.CallError
+	dbg := gl.Debug(func(err *webgl.CallError) { errs = append(errs, err) })
+	buffer := gl.CreateBuffer()
+	ia, _ := ext.GetInstancedArrays(dbg)
+
+	fake.Errors = []uint32{uint32(webgl.INVALID_OPERATION)}
+	bindLine := nextLine()
+	dbg.BindBuffer(webgl.ARRAY_BUFFER, buffer)
+	fake.Errors = []uint32{uint32(webgl.INVALID_VALUE)}
+	drawLine := nextLine()
+	ia.DrawArraysInstanced(webgl.TRIANGLES, 0, 3, 2)
+
+	want := []struct {
+		line int
+		msg  string
+		err  webgl.Error
+	}{
+		{bindLine, "bindBuffer(0x8892, webgl.Buffer): webgl: invalid operation", webgl.ErrInvalidOperation},
+		{drawLine, "drawArraysInstancedANGLE(0x0004, 0, 3, 2): webgl: invalid value", webgl.ErrInvalidValue},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want %d", errs, len(want))
+	}
+	for i, w := range want {
+		e := errs[i]
+		if filepath.Base(e.File) != "errors_test.go" || e.Line != w.line {
+			t.Errorf("error %d is located at %s:%d, want errors_test.go:%d", i, e.File, e.Line, w.line)
+		}
+		if got, msg := e.Error(), fmt.Sprintf("%s:%d: %s", e.File, e.Line, w.msg); got != msg {
+			t.Errorf("got message %q, want %q", got, msg)
+		}
+		if !errors.Is(e, w.err) {
+			t.Errorf("error %d does not unwrap to %v", i, w.err)
+		}
+	}
+}
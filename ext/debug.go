@@ -0,0 +1,71 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ext
+
+import (
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/internal/js"
+)
+
+// LoseContext is the WEBGL_lose_context extension, simulating the loss
+// and restoration of the context to test how a page handles them.
+type LoseContext struct {
+	gl *webgl.Context
+	v  js.Value
+}
+
+// Returns the WEBGL_lose_context extension of gl, or false if it is not
+// supported.
+func GetLoseContext(gl *webgl.Context) (*LoseContext, bool) {
+	v, ok := get(gl, "WEBGL_lose_context")
+	if !ok {
+		return nil, false
+	}
+	return &LoseContext{gl, v}, true
+}
+
+// Loses the context, as if the GPU had been reset.
+func (e *LoseContext) LoseContext() {
+	e.gl.Invoke(e.v, "loseContext")
+}
+
+// Restores a context lost with LoseContext. The context is only restored
+// if the webglcontextlost event was prevented, as OnContextLost does.
+func (e *LoseContext) RestoreContext() {
+	e.gl.Invoke(e.v, "restoreContext")
+}
+
+// The constants of the WEBGL_debug_renderer_info extension, which may be
+// passed to GetParameterString.
+const (
+	UNMASKED_VENDOR_WEBGL   webgl.Enum = 0x9245
+	UNMASKED_RENDERER_WEBGL webgl.Enum = 0x9246
+)
+
+// DebugRendererInfo is the WEBGL_debug_renderer_info extension, naming the
+// graphics driver and GPU behind the context.
+type DebugRendererInfo struct {
+	gl *webgl.Context
+}
+
+// Returns the WEBGL_debug_renderer_info extension of gl, or false if it is
+// not supported.
+func GetDebugRendererInfo(gl *webgl.Context) (*DebugRendererInfo, bool) {
+	_, ok := get(gl, "WEBGL_debug_renderer_info")
+	if !ok {
+		return nil, false
+	}
+	return &DebugRendererInfo{gl}, true
+}
+
+// Returns the vendor of the graphics driver.
+func (e *DebugRendererInfo) Vendor() string {
+	return e.gl.GetParameterString(UNMASKED_VENDOR_WEBGL)
+}
+
+// Returns the name of the GPU.
+func (e *DebugRendererInfo) Renderer() string {
+	return e.gl.GetParameterString(UNMASKED_RENDERER_WEBGL)
+}
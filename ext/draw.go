@@ -0,0 +1,163 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ext
+
+import (
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/internal/js"
+)
+
+// VERTEX_ATTRIB_ARRAY_DIVISOR_ANGLE may be passed to GetVertexAttrib to
+// get the divisor of a vertex attribute.
+const VERTEX_ATTRIB_ARRAY_DIVISOR_ANGLE webgl.Enum = 0x88FE
+
+// InstancedArrays is the ANGLE_instanced_arrays extension, drawing many
+// instances of the same geometry in one call.
+type InstancedArrays struct {
+	gl *webgl.Context
+	v  js.Value
+}
+
+// Returns the ANGLE_instanced_arrays extension of gl, or false if it is
+// not supported.
+func GetInstancedArrays(gl *webgl.Context) (*InstancedArrays, bool) {
+	v, ok := get(gl, "ANGLE_instanced_arrays")
+	if !ok {
+		return nil, false
+	}
+	return &InstancedArrays{gl, v}, true
+}
+
+// Renders primcount instances of a range of the enabled vertex arrays.
+func (e *InstancedArrays) DrawArraysInstanced(mode webgl.Enum, first, count, primcount int) {
+	e.gl.Invoke(e.v, "drawArraysInstancedANGLE", mode, first, count, primcount)
+}
+
+// Renders primcount instances of the geometry indexed by the bound
+// element array buffer.
+func (e *InstancedArrays) DrawElementsInstanced(mode webgl.Enum, count int, typ webgl.Enum, offset, primcount int) {
+	e.gl.Invoke(e.v, "drawElementsInstancedANGLE", mode, count, typ, offset, primcount)
+}
+
+// Sets the number of instances drawn before a vertex attribute advances
+// to its next element. A divisor of 0 advances it every vertex.
+func (e *InstancedArrays) VertexAttribDivisor(index, divisor int) {
+	e.gl.Invoke(e.v, "vertexAttribDivisorANGLE", index, divisor)
+}
+
+// VERTEX_ARRAY_BINDING_OES may be passed to GetParameterObject to get the
+// bound vertex array object.
+const VERTEX_ARRAY_BINDING_OES webgl.Enum = 0x85B5
+
+// VertexArrayObject is the OES_vertex_array_object extension, storing the
+// vertex attribute state in objects that can be bound in one call.
+type VertexArrayObject struct {
+	gl *webgl.Context
+	v  js.Value
+}
+
+// Returns the OES_vertex_array_object extension of gl, or false if it is
+// not supported.
+func GetVertexArrayObject(gl *webgl.Context) (*VertexArrayObject, bool) {
+	v, ok := get(gl, "OES_vertex_array_object")
+	if !ok {
+		return nil, false
+	}
+	return &VertexArrayObject{gl, v}, true
+}
+
+// Creates a vertex array object.
+func (e *VertexArrayObject) CreateVertexArray() webgl.VertexArray {
+	return webgl.VertexArray{Object: jsObject(e.gl.Invoke(e.v, "createVertexArrayOES"))}
+}
+
+// Deletes a vertex array object.
+func (e *VertexArrayObject) DeleteVertexArray(array webgl.VertexArray) {
+	e.gl.Invoke(e.v, "deleteVertexArrayOES", array)
+}
+
+// Returns whether array is a vertex array object that has not been deleted.
+func (e *VertexArrayObject) IsVertexArray(array webgl.VertexArray) bool {
+	return e.gl.Invoke(e.v, "isVertexArrayOES", array).Bool()
+}
+
+// Binds a vertex array object, or the default one if array is the zero
+// VertexArray.
+func (e *VertexArrayObject) BindVertexArray(array webgl.VertexArray) {
+	e.gl.Invoke(e.v, "bindVertexArrayOES", array)
+}
+
+// The constants of the WEBGL_draw_buffers extension. COLOR_ATTACHMENTi_WEBGL
+// and DRAW_BUFFERi_WEBGL are COLOR_ATTACHMENT0_WEBGL+i and
+// DRAW_BUFFER0_WEBGL+i.
+const (
+	COLOR_ATTACHMENT0_WEBGL     webgl.Enum = 0x8CE0
+	DRAW_BUFFER0_WEBGL          webgl.Enum = 0x8825
+	MAX_COLOR_ATTACHMENTS_WEBGL webgl.Enum = 0x8CDF
+	MAX_DRAW_BUFFERS_WEBGL      webgl.Enum = 0x8824
+)
+
+// DrawBuffers is the WEBGL_draw_buffers extension, rendering to several
+// color attachments of a framebuffer at once.
+type DrawBuffers struct {
+	gl *webgl.Context
+	v  js.Value
+}
+
+// Returns the WEBGL_draw_buffers extension of gl, or false if it is not
+// supported.
+func GetDrawBuffers(gl *webgl.Context) (*DrawBuffers, bool) {
+	v, ok := get(gl, "WEBGL_draw_buffers")
+	if !ok {
+		return nil, false
+	}
+	return &DrawBuffers{gl, v}, true
+}
+
+// Sets the color attachments fragment shaders write gl_FragData to. Each
+// buffer is NONE or the attachment of its index, or BACK when drawing to
+// the default framebuffer.
+func (e *DrawBuffers) DrawBuffers(buffers []webgl.Enum) {
+	arr := make([]interface{}, len(buffers))
+	for i, b := range buffers {
+		arr[i] = uint32(b)
+	}
+	e.gl.Invoke(e.v, "drawBuffersWEBGL", arr)
+}
+
+// ElementIndexUint is the OES_element_index_uint extension, which allows
+// UNSIGNED_INT indices in DrawElements.
+type ElementIndexUint struct{}
+
+// Returns the OES_element_index_uint extension of gl, or false if it is
+// not supported.
+func GetElementIndexUint(gl *webgl.Context) (*ElementIndexUint, bool) {
+	_, ok := get(gl, "OES_element_index_uint")
+	if !ok {
+		return nil, false
+	}
+	return &ElementIndexUint{}, true
+}
+
+// The constants of the EXT_blend_minmax extension, which may be passed to
+// BlendEquation.
+const (
+	MIN_EXT webgl.Enum = 0x8007
+	MAX_EXT webgl.Enum = 0x8008
+)
+
+// BlendMinMax is the EXT_blend_minmax extension, adding the MIN_EXT and
+// MAX_EXT blend equations.
+type BlendMinMax struct{}
+
+// Returns the EXT_blend_minmax extension of gl, or false if it is not
+// supported.
+func GetBlendMinMax(gl *webgl.Context) (*BlendMinMax, bool) {
+	_, ok := get(gl, "EXT_blend_minmax")
+	if !ok {
+		return nil, false
+	}
+	return &BlendMinMax{}, true
+}
@@ -0,0 +1,48 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ext provides Go types for the WebGL extensions.
+//
+// Each extension has a Get function, such as GetInstancedArrays, that
+// enables it on a context and returns its type along with whether the
+// browser supports it. The extension objects are looked up with
+// Context.GetExtension, which caches them on the context, so the Get
+// functions are cheap enough to call every frame:
+//
+//	if ia, ok := ext.GetInstancedArrays(gl); ok {
+//		ia.VertexAttribDivisor(loc, 1)
+//		ia.DrawArraysInstanced(webgl.TRIANGLES, 0, 6, n)
+//	}
+//
+// The Get functions are not methods of webgl.Context, such as
+// gl.InstancedArrays(), because package ext imports package webgl to wrap
+// the context, so webgl cannot import ext to return its types.
+//
+// Extensions promoted to the core WebGL 2 API, such as
+// ANGLE_instanced_arrays and OES_vertex_array_object, are not available
+// on a WebGL 2 context.
+//
+// GPUTimer measures how long the GPU takes to draw named render passes
+// with the disjoint timer query extensions.
+package ext
+
+import (
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Looks up an extension, returning its object and whether it is supported.
+func get(gl *webgl.Context, name string) (js.Value, bool) {
+	v := gl.GetExtension(name)
+	return v, v.Truthy()
+}
+
+// Returns the Object for a JavaScript value returned by an extension, or
+// nil if the value is null.
+func jsObject(v js.Value) webgl.Object {
+	if !v.Truthy() {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,156 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(js && wasm)
+
+package ext
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Returns a WebGL 1 context on a fake supporting the given extensions.
+func newContext(t *testing.T, extensions ...string) (*webgl.Context, *js.WebGL) {
+	t.Helper()
+	fake := js.NewWebGL(1)
+	fake.Extensions = extensions
+	gl, err := webgl.NewContext(js.NewCanvas(fake).Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gl, fake
+}
+
+// Returns the arguments of the only call to method as numbers.
+func callArgs(t *testing.T, fake *js.WebGL, method string) []float64 {
+	t.Helper()
+	calls := fake.CallsTo(method)
+	if len(calls) != 1 {
+		t.Fatalf("got %d calls to %s, want 1", len(calls), method)
+	}
+	args := make([]float64, len(calls[0].Args))
+	for i, arg := range calls[0].Args {
+		args[i] = arg.Float()
+	}
+	return args
+}
+
+func TestUnsupported(t *testing.T) {
+	gl, _ := newContext(t)
+	if _, ok := GetInstancedArrays(gl); ok {
+		t.Error("got ANGLE_instanced_arrays without support")
+	}
+	if _, ok := GetVertexArrayObject(gl); ok {
+		t.Error("got OES_vertex_array_object without support")
+	}
+	if _, ok := GetDrawBuffers(gl); ok {
+		t.Error("got WEBGL_draw_buffers without support")
+	}
+	if _, ok := GetTextureFilterAnisotropic(gl); ok {
+		t.Error("got EXT_texture_filter_anisotropic without support")
+	}
+}
+
+func TestInstancedArrays(t *testing.T) {
+	gl, fake := newContext(t, "ANGLE_instanced_arrays")
+	ia, ok := GetInstancedArrays(gl)
+	if !ok {
+		t.Fatal("ANGLE_instanced_arrays is not supported")
+	}
+	ia.VertexAttribDivisor(2, 1)
+	ia.DrawArraysInstanced(webgl.TRIANGLES, 3, 6, 10)
+	ia.DrawElementsInstanced(webgl.TRIANGLES, 6, webgl.UNSIGNED_SHORT, 4, 10)
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"vertexAttribDivisorANGLE", "[2 1]"},
+		{"drawArraysInstancedANGLE", "[4 3 6 10]"},
+		{"drawElementsInstancedANGLE", "[4 6 5123 4 10]"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprint(callArgs(t, fake, test.method)); got != test.want {
+			t.Errorf("%s got arguments %s, want %s", test.method, got, test.want)
+		}
+	}
+}
+
+func TestVertexArrayObject(t *testing.T) {
+	gl, fake := newContext(t, "OES_vertex_array_object")
+	vao, ok := GetVertexArrayObject(gl)
+	if !ok {
+		t.Fatal("OES_vertex_array_object is not supported")
+	}
+	array := vao.CreateVertexArray()
+	v, ok := array.Object.(js.Value)
+	if !ok || !v.Truthy() {
+		t.Fatalf("got vertex array %v", array.Object)
+	}
+	vao.BindVertexArray(array)
+	if arg := fake.CallsTo("bindVertexArrayOES")[0].Args[0]; !arg.Equal(v) {
+		t.Errorf("bound %v, want the created vertex array", arg)
+	}
+	vao.BindVertexArray(webgl.VertexArray{})
+	if arg := fake.CallsTo("bindVertexArrayOES")[1].Args[0]; !arg.IsNull() {
+		t.Errorf("bound %v for the default vertex array, want null", arg)
+	}
+	if !vao.IsVertexArray(array) {
+		t.Error("a created vertex array is not a vertex array")
+	}
+	vao.DeleteVertexArray(array)
+	if arg := fake.CallsTo("deleteVertexArrayOES")[0].Args[0]; !arg.Equal(v) {
+		t.Errorf("deleted %v, want the created vertex array", arg)
+	}
+	if vao.IsVertexArray(array) {
+		t.Error("a deleted vertex array is still a vertex array")
+	}
+
+	// A lost context creates nothing, which is a nil Object rather than a
+	// null JavaScript value.
+	fake.Lost = true
+	if array := vao.CreateVertexArray(); array.Object != nil {
+		t.Errorf("created %v on a lost context, want nil", array.Object)
+	}
+}
+
+func TestDrawBuffers(t *testing.T) {
+	gl, fake := newContext(t, "WEBGL_draw_buffers")
+	db, ok := GetDrawBuffers(gl)
+	if !ok {
+		t.Fatal("WEBGL_draw_buffers is not supported")
+	}
+	db.DrawBuffers([]webgl.Enum{COLOR_ATTACHMENT0_WEBGL, webgl.NONE, COLOR_ATTACHMENT0_WEBGL + 2})
+	calls := fake.CallsTo("drawBuffersWEBGL")
+	if len(calls) != 1 {
+		t.Fatalf("got %d calls to drawBuffersWEBGL", len(calls))
+	}
+	arr := calls[0].Args[0]
+	var got []int
+	for i := 0; i < arr.Length(); i++ {
+		got = append(got, arr.Index(i).Int())
+	}
+	if fmt.Sprint(got) != "[36064 0 36066]" {
+		t.Errorf("got buffers %v, want [36064 0 36066]", got)
+	}
+}
+
+func TestTextureFilterAnisotropic(t *testing.T) {
+	gl, fake := newContext(t, "EXT_texture_filter_anisotropic")
+	af, ok := GetTextureFilterAnisotropic(gl)
+	if !ok {
+		t.Fatal("EXT_texture_filter_anisotropic is not supported")
+	}
+	fake.Parameters[uint32(MAX_TEXTURE_MAX_ANISOTROPY_EXT)] = 16.0
+	if got := af.MaxAnisotropy(); got != 16 {
+		t.Errorf("got maximum anisotropy %v, want 16", got)
+	}
+	af.SetAnisotropy(webgl.TEXTURE_2D, 8)
+	if got := fmt.Sprint(callArgs(t, fake, "texParameterf")); got != "[3553 34046 8]" {
+		t.Errorf("texParameterf got arguments %s, want [3553 34046 8]", got)
+	}
+}
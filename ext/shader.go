@@ -0,0 +1,43 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ext
+
+import (
+	"github.com/n2d/webgl"
+)
+
+// FRAGMENT_SHADER_DERIVATIVE_HINT_OES may be passed to Hint to choose the
+// accuracy of the derivatives of the OES_standard_derivatives extension.
+const FRAGMENT_SHADER_DERIVATIVE_HINT_OES webgl.Enum = 0x8B8B
+
+// StandardDerivatives is the OES_standard_derivatives extension, adding
+// the dFdx, dFdy and fwidth functions to fragment shaders that declare
+// "#extension GL_OES_standard_derivatives : enable".
+type StandardDerivatives struct{}
+
+// Returns the OES_standard_derivatives extension of gl, or false if it is
+// not supported.
+func GetStandardDerivatives(gl *webgl.Context) (*StandardDerivatives, bool) {
+	_, ok := get(gl, "OES_standard_derivatives")
+	if !ok {
+		return nil, false
+	}
+	return &StandardDerivatives{}, true
+}
+
+// ShaderTextureLOD is the EXT_shader_texture_lod extension, adding the
+// texture lookup functions with an explicit level of detail to fragment
+// shaders that declare "#extension GL_EXT_shader_texture_lod : enable".
+type ShaderTextureLOD struct{}
+
+// Returns the EXT_shader_texture_lod extension of gl, or false if it is
+// not supported.
+func GetShaderTextureLOD(gl *webgl.Context) (*ShaderTextureLOD, bool) {
+	_, ok := get(gl, "EXT_shader_texture_lod")
+	if !ok {
+		return nil, false
+	}
+	return &ShaderTextureLOD{}, true
+}
@@ -0,0 +1,153 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ext
+
+import (
+	"github.com/n2d/webgl"
+)
+
+// The constants of the EXT_texture_filter_anisotropic extension.
+const (
+	TEXTURE_MAX_ANISOTROPY_EXT     webgl.Enum = 0x84FE
+	MAX_TEXTURE_MAX_ANISOTROPY_EXT webgl.Enum = 0x84FF
+)
+
+// TextureFilterAnisotropic is the EXT_texture_filter_anisotropic
+// extension, improving the filtering of textures seen at steep angles.
+type TextureFilterAnisotropic struct {
+	gl *webgl.Context
+}
+
+// Returns the EXT_texture_filter_anisotropic extension of gl, or false if
+// it is not supported.
+func GetTextureFilterAnisotropic(gl *webgl.Context) (*TextureFilterAnisotropic, bool) {
+	_, ok := get(gl, "EXT_texture_filter_anisotropic")
+	if !ok {
+		return nil, false
+	}
+	return &TextureFilterAnisotropic{gl}, true
+}
+
+// Returns the largest anisotropy supported, at least 2.
+func (e *TextureFilterAnisotropic) MaxAnisotropy() float32 {
+	return e.gl.GetParameterf(MAX_TEXTURE_MAX_ANISOTROPY_EXT)
+}
+
+// Sets the anisotropy of the texture bound to target, from 1 for none up
+// to MaxAnisotropy.
+func (e *TextureFilterAnisotropic) SetAnisotropy(target webgl.Enum, anisotropy float32) {
+	e.gl.TexParameterf(target, TEXTURE_MAX_ANISOTROPY_EXT, anisotropy)
+}
+
+// UNSIGNED_INT_24_8_WEBGL is the type of DEPTH_STENCIL texture images of
+// the WEBGL_depth_texture extension.
+const UNSIGNED_INT_24_8_WEBGL webgl.Enum = 0x84FA
+
+// DepthTexture is the WEBGL_depth_texture extension, allowing
+// DEPTH_COMPONENT and DEPTH_STENCIL textures to be attached to
+// framebuffers.
+type DepthTexture struct{}
+
+// Returns the WEBGL_depth_texture extension of gl, or false if it is not
+// supported.
+func GetDepthTexture(gl *webgl.Context) (*DepthTexture, bool) {
+	_, ok := get(gl, "WEBGL_depth_texture")
+	if !ok {
+		return nil, false
+	}
+	return &DepthTexture{}, true
+}
+
+// TextureFloat is the OES_texture_float extension, allowing textures of
+// type FLOAT.
+type TextureFloat struct{}
+
+// Returns the OES_texture_float extension of gl, or false if it is not
+// supported.
+func GetTextureFloat(gl *webgl.Context) (*TextureFloat, bool) {
+	_, ok := get(gl, "OES_texture_float")
+	if !ok {
+		return nil, false
+	}
+	return &TextureFloat{}, true
+}
+
+// TextureFloatLinear is the OES_texture_float_linear extension, allowing
+// linear filtering of FLOAT textures.
+type TextureFloatLinear struct{}
+
+// Returns the OES_texture_float_linear extension of gl, or false if it is
+// not supported.
+func GetTextureFloatLinear(gl *webgl.Context) (*TextureFloatLinear, bool) {
+	_, ok := get(gl, "OES_texture_float_linear")
+	if !ok {
+		return nil, false
+	}
+	return &TextureFloatLinear{}, true
+}
+
+// HALF_FLOAT_OES is the type of the half float textures of the
+// OES_texture_half_float extension. It differs from the WebGL 2
+// HALF_FLOAT.
+const HALF_FLOAT_OES webgl.Enum = 0x8D61
+
+// TextureHalfFloat is the OES_texture_half_float extension, allowing
+// textures of type HALF_FLOAT_OES.
+type TextureHalfFloat struct{}
+
+// Returns the OES_texture_half_float extension of gl, or false if it is
+// not supported.
+func GetTextureHalfFloat(gl *webgl.Context) (*TextureHalfFloat, bool) {
+	_, ok := get(gl, "OES_texture_half_float")
+	if !ok {
+		return nil, false
+	}
+	return &TextureHalfFloat{}, true
+}
+
+// RGBA32F_EXT is the renderbuffer format of the WEBGL_color_buffer_float
+// extension.
+const RGBA32F_EXT webgl.Enum = 0x8814
+
+// ColorBufferFloat is the WEBGL_color_buffer_float extension, or
+// EXT_color_buffer_float on WebGL 2, allowing rendering to float color
+// attachments.
+type ColorBufferFloat struct{}
+
+// Returns the float color buffer extension of gl, or false if it is not
+// supported.
+func GetColorBufferFloat(gl *webgl.Context) (*ColorBufferFloat, bool) {
+	if _, ok := get(gl, "WEBGL_color_buffer_float"); ok {
+		return &ColorBufferFloat{}, true
+	}
+	_, ok := get(gl, "EXT_color_buffer_float")
+	if !ok {
+		return nil, false
+	}
+	return &ColorBufferFloat{}, true
+}
+
+// The formats of the WEBGL_compressed_texture_s3tc extension, which may be
+// passed to CompressedTexImage2D.
+const (
+	COMPRESSED_RGB_S3TC_DXT1_EXT  webgl.Enum = 0x83F0
+	COMPRESSED_RGBA_S3TC_DXT1_EXT webgl.Enum = 0x83F1
+	COMPRESSED_RGBA_S3TC_DXT3_EXT webgl.Enum = 0x83F2
+	COMPRESSED_RGBA_S3TC_DXT5_EXT webgl.Enum = 0x83F3
+)
+
+// CompressedTextureS3TC is the WEBGL_compressed_texture_s3tc extension,
+// allowing DXT compressed textures.
+type CompressedTextureS3TC struct{}
+
+// Returns the WEBGL_compressed_texture_s3tc extension of gl, or false if
+// it is not supported.
+func GetCompressedTextureS3TC(gl *webgl.Context) (*CompressedTextureS3TC, bool) {
+	_, ok := get(gl, "WEBGL_compressed_texture_s3tc")
+	if !ok {
+		return nil, false
+	}
+	return &CompressedTextureS3TC{}, true
+}
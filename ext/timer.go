@@ -0,0 +1,270 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ext
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/internal/js"
+)
+
+// The constants of the EXT_disjoint_timer_query and
+// EXT_disjoint_timer_query_webgl2 extensions.
+const (
+	QUERY_COUNTER_BITS_EXT     webgl.Enum = 0x8864
+	CURRENT_QUERY_EXT          webgl.Enum = 0x8865
+	QUERY_RESULT_EXT           webgl.Enum = 0x8866
+	QUERY_RESULT_AVAILABLE_EXT webgl.Enum = 0x8867
+	TIME_ELAPSED_EXT           webgl.Enum = 0x88BF
+	TIMESTAMP_EXT              webgl.Enum = 0x8E28
+	GPU_DISJOINT_EXT           webgl.Enum = 0x8FBB
+)
+
+// GPUTimer measures the time the GPU spends on named render passes with
+// the EXT_disjoint_timer_query extension, or
+// EXT_disjoint_timer_query_webgl2 on a WebGL 2 context:
+//
+//	timer.Poll()
+//	timer.Begin("shadows")
+//	drawShadows()
+//	timer.Begin("scene")
+//	drawScene()
+//	timer.End()
+//
+// The GPU finishes a pass some frames after it was drawn, so results are
+// collected by calling Poll once per frame, which never waits for the
+// GPU. Results of passes that overlap a disjoint event, such as a GPU
+// reset or a change of clock speed, are discarded.
+type GPUTimer struct {
+	gl      *webgl.Context
+	name    string
+	ext     js.Value
+	target  js.Value
+	suffix  string
+	result  string
+	size    int
+	running *timerQuery
+	pending []timerQuery
+	free    []js.Value
+	passes  map[string]*timerPass
+	order   []*timerPass
+}
+
+type timerQuery struct {
+	pass  *timerPass
+	query js.Value
+}
+
+// timerPass keeps the most recent samples of a pass in a ring.
+type timerPass struct {
+	name    string
+	samples []time.Duration
+	next    int
+	last    time.Duration
+}
+
+// GPUTiming holds the statistics of a pass over its most recent samples.
+type GPUTiming struct {
+	// Name is the name passed to Begin.
+	Name string
+
+	// Samples is the number of results the statistics are computed from.
+	Samples int
+
+	// Last is the most recent result.
+	Last time.Duration
+
+	// Average, Median, P95, P99 and Max summarize the results.
+	Average time.Duration
+	Median  time.Duration
+	P95     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// Returns a timer for gl that keeps the given number of most recent
+// results of each pass, or false if the context supports neither timer
+// query extension.
+func NewGPUTimer(gl *webgl.Context, samples int) (*GPUTimer, bool) {
+	t := &GPUTimer{gl: gl, size: max(samples, 1), passes: make(map[string]*timerPass)}
+	if _, ok := get(gl, "EXT_disjoint_timer_query_webgl2"); ok {
+		t.name, t.result = "EXT_disjoint_timer_query_webgl2", "getQueryParameter"
+	} else if _, ok := get(gl, "EXT_disjoint_timer_query"); ok {
+		t.name, t.suffix, t.result = "EXT_disjoint_timer_query", "EXT", "getQueryObjectEXT"
+	} else {
+		return nil, false
+	}
+	t.sync()
+	return t, true
+}
+
+// Looks up the extension again and, if the context was restored since
+// the last call, drops the queries made before, which are no longer
+// valid. Returns whether the extension is available.
+func (t *GPUTimer) sync() bool {
+	v, ok := get(t.gl, t.name)
+	if ok && v.Equal(t.ext) {
+		return true
+	}
+	t.ext = v
+	t.target = v
+	if t.suffix == "" {
+		t.target = t.gl.Value
+	}
+	t.running, t.pending, t.free = nil, nil, nil
+	return ok
+}
+
+// Starts timing the named pass, ending the pass being timed, if any, as
+// passes cannot nest.
+func (t *GPUTimer) Begin(name string) {
+	t.End()
+	if !t.sync() {
+		return
+	}
+	pass := t.passes[name]
+	if pass == nil {
+		pass = &timerPass{name: name, samples: make([]time.Duration, 0, t.size)}
+		t.passes[name] = pass
+		t.order = append(t.order, pass)
+	}
+	var q js.Value
+	if n := len(t.free); n > 0 {
+		q, t.free = t.free[n-1], t.free[:n-1]
+	} else if q = t.gl.Invoke(t.target, "createQuery"+t.suffix); !q.Truthy() {
+		return
+	}
+	t.gl.Invoke(t.target, "beginQuery"+t.suffix, TIME_ELAPSED_EXT, q)
+	t.running = &timerQuery{pass, q}
+}
+
+// Ends timing the current pass.
+func (t *GPUTimer) End() {
+	if t.running == nil {
+		return
+	}
+	t.gl.Invoke(t.target, "endQuery"+t.suffix, TIME_ELAPSED_EXT)
+	t.pending = append(t.pending, *t.running)
+	t.running = nil
+}
+
+// Collects the results of the passes the GPU has finished since the last
+// call, without waiting for the others. If the GPU reported a disjoint
+// event, all results still pending are discarded instead, along with the
+// pass being timed.
+func (t *GPUTimer) Poll() {
+	if !t.sync() || len(t.pending) == 0 && t.running == nil {
+		return
+	}
+	available := 0
+	for _, p := range t.pending {
+		if !t.gl.Invoke(t.target, t.result, p.query, QUERY_RESULT_AVAILABLE_EXT).Truthy() {
+			break
+		}
+		available++
+	}
+	if t.gl.GetParameter(GPU_DISJOINT_EXT).Truthy() {
+		t.End()
+		for _, p := range t.pending {
+			t.free = append(t.free, p.query)
+		}
+		t.pending = t.pending[:0]
+		return
+	}
+	for _, p := range t.pending[:available] {
+		p.pass.add(time.Duration(t.gl.Invoke(t.target, t.result, p.query, QUERY_RESULT_EXT).Float()))
+		t.free = append(t.free, p.query)
+	}
+	t.pending = append(t.pending[:0], t.pending[available:]...)
+}
+
+// Deletes the queries of the timer. It can still be used afterwards.
+func (t *GPUTimer) Delete() {
+	t.End()
+	for _, p := range t.pending {
+		t.gl.Invoke(t.target, "deleteQuery"+t.suffix, p.query)
+	}
+	for _, q := range t.free {
+		t.gl.Invoke(t.target, "deleteQuery"+t.suffix, q)
+	}
+	t.pending, t.free = nil, nil
+}
+
+// Returns the statistics of the named pass, or false if it has no
+// results yet.
+func (t *GPUTimer) Timing(name string) (GPUTiming, bool) {
+	pass := t.passes[name]
+	if pass == nil || len(pass.samples) == 0 {
+		return GPUTiming{}, false
+	}
+	return pass.timing(), true
+}
+
+// Returns the statistics of the passes with results, in the order they
+// were first begun.
+func (t *GPUTimer) Timings() []GPUTiming {
+	var timings []GPUTiming
+	for _, pass := range t.order {
+		if len(pass.samples) > 0 {
+			timings = append(timings, pass.timing())
+		}
+	}
+	return timings
+}
+
+// Returns the p-th percentile, from 0 to 100, of the recent results of
+// the named pass, or 0 if it has none.
+func (t *GPUTimer) Percentile(name string, p float64) time.Duration {
+	pass := t.passes[name]
+	if pass == nil || len(pass.samples) == 0 {
+		return 0
+	}
+	return percentile(pass.sorted(), p)
+}
+
+// Adds a result, replacing the oldest one once the ring is full.
+func (p *timerPass) add(d time.Duration) {
+	p.last = d
+	if len(p.samples) < cap(p.samples) {
+		p.samples = append(p.samples, d)
+		return
+	}
+	p.samples[p.next] = d
+	p.next = (p.next + 1) % len(p.samples)
+}
+
+// Returns a sorted copy of the samples.
+func (p *timerPass) sorted() []time.Duration {
+	s := append([]time.Duration(nil), p.samples...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	return s
+}
+
+func (p *timerPass) timing() GPUTiming {
+	s := p.sorted()
+	var sum time.Duration
+	for _, d := range s {
+		sum += d
+	}
+	return GPUTiming{
+		Name:    p.name,
+		Samples: len(s),
+		Last:    p.last,
+		Average: sum / time.Duration(len(s)),
+		Median:  percentile(s, 50),
+		P95:     percentile(s, 95),
+		P99:     percentile(s, 99),
+		Max:     s[len(s)-1],
+	}
+}
+
+// Returns the p-th percentile of sorted samples by the nearest rank.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
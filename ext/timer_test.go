@@ -0,0 +1,117 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(js && wasm)
+
+package ext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/internal/js"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 10; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 1},
+		{10, 1},
+		{11, 2},
+		{50, 5},
+		{51, 6},
+		{95, 10},
+		{100, 10},
+	}
+	for _, test := range tests {
+		if got := percentile(sorted, test.p); got != test.want {
+			t.Errorf("percentile %v is %v, want %v", test.p, got, test.want)
+		}
+	}
+	if got := percentile([]time.Duration{7}, 99); got != 7 {
+		t.Errorf("percentile 99 of one sample is %v, want 7", got)
+	}
+}
+
+func TestTimerPassRing(t *testing.T) {
+	p := &timerPass{samples: make([]time.Duration, 0, 3)}
+	for d := time.Duration(1); d <= 5; d++ {
+		p.add(d)
+	}
+	want := []time.Duration{4, 5, 3}
+	for i, d := range want {
+		if p.samples[i] != d {
+			t.Fatalf("got samples %v, want %v", p.samples, want)
+		}
+	}
+	p.add(6)
+	if got := p.sorted(); got[0] != 4 || got[2] != 6 || p.last != 6 {
+		t.Errorf("got sorted samples %v and last %v after wrapping around", got, p.last)
+	}
+}
+
+func TestTiming(t *testing.T) {
+	p := &timerPass{name: "scene", samples: make([]time.Duration, 0, 8)}
+	for _, d := range []time.Duration{10, 1, 3, 2} {
+		p.add(d)
+	}
+	got := p.timing()
+	want := GPUTiming{Name: "scene", Samples: 4, Last: 2, Average: 4, Median: 2, P95: 10, P99: 10, Max: 10}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestGPUTimerDisjoint(t *testing.T) {
+	fake := js.NewWebGL(1)
+	fake.Extensions = []string{"EXT_disjoint_timer_query"}
+	gl, err := webgl.NewContext(js.NewCanvas(fake).Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timer, ok := NewGPUTimer(gl, 4)
+	if !ok {
+		t.Fatal("timer queries are not supported")
+	}
+	available := false
+	fake.Results["getQueryObjectEXT"] = func(args []js.Value) interface{} {
+		if webgl.Enum(args[1].Int()) == QUERY_RESULT_AVAILABLE_EXT {
+			return available
+		}
+		return 2e6
+	}
+
+	timer.Begin("shadows")
+	timer.Begin("scene")
+	fake.Parameters[uint32(GPU_DISJOINT_EXT)] = true
+	timer.Poll()
+	if n := len(fake.CallsTo("endQueryEXT")); n != 2 {
+		t.Errorf("ended %d queries, want the pending and running ones", n)
+	}
+	if n := len(fake.CallsTo("deleteQueryEXT")); n != 0 {
+		t.Errorf("deleted %d queries discarded by a disjoint event", n)
+	}
+
+	fake.Parameters[uint32(GPU_DISJOINT_EXT)] = false
+	available = true
+	timer.Begin("shadows")
+	timer.End()
+	timer.Poll()
+	if n := len(fake.CallsTo("createQueryEXT")); n != 2 {
+		t.Errorf("created %d queries, want the discarded ones reused", n)
+	}
+	if timing, ok := timer.Timing("shadows"); !ok || timing.Samples != 1 || timing.Last != 2*time.Millisecond {
+		t.Errorf("got timing %+v for the pass after the disjoint event", timing)
+	}
+	if _, ok := timer.Timing("scene"); ok {
+		t.Error("kept the result of the pass running during the disjoint event")
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+// qualifier is the storage qualifier of a variable.
+type qualifier int
+
+const (
+	qualNone qualifier = iota
+	qualConst
+	qualAttribute
+	qualUniform
+	qualVarying
+	qualIn
+	qualConstIn
+	qualOut
+	qualInOut
+
+	// qualInput and qualOutput are the built-in shader inputs and
+	// outputs, such as gl_FragCoord and gl_Position.
+	qualInput
+	qualOutput
+)
+
+// symbolKind is the kind of a name.
+type symbolKind int
+
+const (
+	symVar symbolKind = iota
+	symStruct
+	symFunc
+)
+
+// symbol is a declared name.
+type symbol struct {
+	name string
+	kind symbolKind
+	line int
+
+	// typ is the type of a variable or the structure type of a struct name.
+	typ  typ
+	qual qualifier
+
+	// value is the value of a constant variable.
+	value []float32
+
+	// funcs are the overloads of a function name.
+	funcs []*function
+
+	// used is set once a variable is referenced.
+	used bool
+
+	// loopIndex is set for the index of a for loop while its body is
+	// checked.
+	loopIndex bool
+
+	builtin bool
+}
+
+// function is a user defined function.
+type function struct {
+	name   string
+	line   int
+	ret    typ
+	params []*symbol
+	body   *block
+
+	// calls are the user functions the body calls.
+	calls []*function
+}
+
+// Returns whether two functions have the same parameter types.
+func (f *function) sameParams(g *function) bool {
+	if len(f.params) != len(g.params) {
+		return false
+	}
+	for i := range f.params {
+		if f.params[i].typ != g.params[i].typ {
+			return false
+		}
+	}
+	return true
+}
+
+// expr is a checked expression.
+type expr interface {
+	info() *exprInfo
+}
+
+// exprInfo holds what is known about any expression.
+type exprInfo struct {
+	line int
+	typ  typ
+
+	// value is the value of a constant expression, and nil otherwise.
+	value []float32
+}
+
+func (e *exprInfo) info() *exprInfo {
+	return e
+}
+
+type (
+	// constExpr is a literal or a folded constant expression.
+	constExpr struct {
+		exprInfo
+	}
+
+	varRef struct {
+		exprInfo
+		sym *symbol
+	}
+
+	unaryExpr struct {
+		exprInfo
+		op      string
+		x       expr
+		postfix bool
+	}
+
+	binaryExpr struct {
+		exprInfo
+		op   string
+		x, y expr
+	}
+
+	assignExpr struct {
+		exprInfo
+		op   string
+		l, r expr
+	}
+
+	condExpr struct {
+		exprInfo
+		c, t, f expr
+	}
+
+	callExpr struct {
+		exprInfo
+		fn   *function
+		args []expr
+	}
+
+	builtinCall struct {
+		exprInfo
+		fn   *builtin
+		args []expr
+	}
+
+	// ctorExpr constructs a value of its type.
+	ctorExpr struct {
+		exprInfo
+		args []expr
+	}
+
+	indexExpr struct {
+		exprInfo
+		x, i expr
+	}
+
+	fieldExpr struct {
+		exprInfo
+		x     expr
+		field int
+	}
+
+	swizzleExpr struct {
+		exprInfo
+		x     expr
+		comps []int
+	}
+)
+
+// stmt is a checked statement.
+type stmt interface{}
+
+type (
+	block struct {
+		stmts []stmt
+	}
+
+	// varDecl declares a variable, with an optional initializer.
+	varDecl struct {
+		sym  *symbol
+		init expr
+	}
+
+	declStmt struct {
+		vars []*varDecl
+	}
+
+	exprStmt struct {
+		x expr
+	}
+
+	ifStmt struct {
+		cond expr
+		then stmt
+		els  stmt
+	}
+
+	forStmt struct {
+		init stmt
+		cond expr
+		post expr
+		body stmt
+	}
+
+	// jumpStmt is a break, continue, return or discard statement.
+	jumpStmt struct {
+		kind string
+		x    expr
+	}
+)
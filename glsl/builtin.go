@@ -0,0 +1,486 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"math"
+
+	"github.com/n2d/webgl"
+)
+
+// lookup is the kind of a texture lookup function.
+type lookup int
+
+const (
+	lookupNone lookup = iota
+	lookup2D
+	lookup2DProj3
+	lookup2DProj4
+	lookupCube
+)
+
+// builtin is an overload of a built-in function.
+type builtin struct {
+	name   string
+	params []typ
+	ret    typ
+
+	// stage restricts the function to one type of shader if it is set.
+	stage webgl.Enum
+
+	// eval computes the function from its arguments. It is nil for
+	// texture lookups, which are run by the interpreter.
+	eval func(out []float32, args [][]float32)
+
+	lookup lookup
+
+	// lod is set for lookups with an explicit level of detail.
+	lod bool
+}
+
+// builtins are the built-in functions by name.
+var builtins = map[string][]*builtin{}
+
+// genTypes are the float types of the generic functions.
+var genTypes = []typ{floatType, vecType(tFloat, 2), vecType(tFloat, 3), vecType(tFloat, 4)}
+
+func addBuiltin(name string, ret typ, eval func(out []float32, args [][]float32), params ...typ) *builtin {
+	b := &builtin{name: name, params: params, ret: ret, eval: eval}
+	builtins[name] = append(builtins[name], b)
+	return b
+}
+
+// Returns the i-th component of an argument, repeating scalars.
+func at(a []float32, i int) float32 {
+	if len(a) == 1 {
+		return a[0]
+	}
+	return a[i]
+}
+
+func map1(f func(x float64) float64) func(out []float32, args [][]float32) {
+	return func(out []float32, args [][]float32) {
+		for i := range out {
+			out[i] = float32(f(float64(args[0][i])))
+		}
+	}
+}
+
+func map2(f func(x, y float64) float64) func(out []float32, args [][]float32) {
+	return func(out []float32, args [][]float32) {
+		for i := range out {
+			out[i] = float32(f(float64(at(args[0], i)), float64(at(args[1], i))))
+		}
+	}
+}
+
+func map3(f func(x, y, z float64) float64) func(out []float32, args [][]float32) {
+	return func(out []float32, args [][]float32) {
+		for i := range out {
+			out[i] = float32(f(float64(at(args[0], i)), float64(at(args[1], i)), float64(at(args[2], i))))
+		}
+	}
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func b2f(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func init() {
+	unary := map[string]func(float64) float64{
+		"radians":     func(x float64) float64 { return x * math.Pi / 180 },
+		"degrees":     func(x float64) float64 { return x * 180 / math.Pi },
+		"sin":         math.Sin,
+		"cos":         math.Cos,
+		"tan":         math.Tan,
+		"asin":        math.Asin,
+		"acos":        math.Acos,
+		"atan":        math.Atan,
+		"exp":         math.Exp,
+		"log":         math.Log,
+		"exp2":        math.Exp2,
+		"log2":        math.Log2,
+		"sqrt":        math.Sqrt,
+		"inversesqrt": func(x float64) float64 { return 1 / math.Sqrt(x) },
+		"abs":         math.Abs,
+		"floor":       math.Floor,
+		"ceil":        math.Ceil,
+		"fract":       func(x float64) float64 { return x - math.Floor(x) },
+		"sign": func(x float64) float64 {
+			switch {
+			case x > 0:
+				return 1
+			case x < 0:
+				return -1
+			}
+			return 0
+		},
+	}
+	mod := func(x, y float64) float64 { return x - y*math.Floor(x/y) }
+	step := func(edge, x float64) float64 {
+		if x < edge {
+			return 0
+		}
+		return 1
+	}
+	smoothstep := func(e0, e1, x float64) float64 {
+		t := math.Min(math.Max((x-e0)/(e1-e0), 0), 1)
+		return t * t * (3 - 2*t)
+	}
+	clamp := func(x, lo, hi float64) float64 { return math.Min(math.Max(x, lo), hi) }
+	mix := func(x, y, a float64) float64 { return x*(1-a) + y*a }
+
+	for _, t := range genTypes {
+		for name, f := range unary {
+			addBuiltin(name, t, map1(f), t)
+		}
+		addBuiltin("atan", t, map2(math.Atan2), t, t)
+		addBuiltin("pow", t, map2(math.Pow), t, t)
+		addBuiltin("mod", t, map2(mod), t, t)
+		addBuiltin("min", t, map2(math.Min), t, t)
+		addBuiltin("max", t, map2(math.Max), t, t)
+		addBuiltin("step", t, map2(step), t, t)
+		addBuiltin("clamp", t, map3(clamp), t, t, t)
+		addBuiltin("mix", t, map3(mix), t, t, t)
+		addBuiltin("smoothstep", t, map3(smoothstep), t, t, t)
+		if t.size > 1 {
+			addBuiltin("mod", t, map2(mod), t, floatType)
+			addBuiltin("min", t, map2(math.Min), t, floatType)
+			addBuiltin("max", t, map2(math.Max), t, floatType)
+			addBuiltin("step", t, map2(step), floatType, t)
+			addBuiltin("clamp", t, map3(clamp), t, floatType, floatType)
+			addBuiltin("mix", t, map3(mix), t, t, floatType)
+			addBuiltin("smoothstep", t, map3(smoothstep), floatType, floatType, t)
+		}
+
+		addBuiltin("length", floatType, func(out []float32, a [][]float32) {
+			out[0] = float32(math.Sqrt(float64(dot(a[0], a[0]))))
+		}, t)
+		addBuiltin("distance", floatType, func(out []float32, a [][]float32) {
+			var sum float64
+			for i := range a[0] {
+				d := float64(a[0][i] - a[1][i])
+				sum += d * d
+			}
+			out[0] = float32(math.Sqrt(sum))
+		}, t, t)
+		addBuiltin("dot", floatType, func(out []float32, a [][]float32) {
+			out[0] = dot(a[0], a[1])
+		}, t, t)
+		addBuiltin("normalize", t, func(out []float32, a [][]float32) {
+			l := float32(math.Sqrt(float64(dot(a[0], a[0]))))
+			for i := range out {
+				out[i] = a[0][i] / l
+			}
+		}, t)
+		addBuiltin("faceforward", t, func(out []float32, a [][]float32) {
+			s := float32(1)
+			if dot(a[2], a[1]) >= 0 {
+				s = -1
+			}
+			for i := range out {
+				out[i] = s * a[0][i]
+			}
+		}, t, t, t)
+		addBuiltin("reflect", t, func(out []float32, a [][]float32) {
+			d := 2 * dot(a[1], a[0])
+			for i := range out {
+				out[i] = a[0][i] - d*a[1][i]
+			}
+		}, t, t)
+		addBuiltin("refract", t, func(out []float32, a [][]float32) {
+			in, n, eta := a[0], a[1], a[2][0]
+			d := dot(n, in)
+			k := 1 - eta*eta*(1-d*d)
+			for i := range out {
+				if k < 0 {
+					out[i] = 0
+				} else {
+					out[i] = eta*in[i] - (eta*d+float32(math.Sqrt(float64(k))))*n[i]
+				}
+			}
+		}, t, t, floatType)
+	}
+	vec3 := vecType(tFloat, 3)
+	addBuiltin("cross", vec3, func(out []float32, a [][]float32) {
+		x, y := a[0], a[1]
+		out[0], out[1], out[2] = x[1]*y[2]-y[1]*x[2], x[2]*y[0]-y[2]*x[0], x[0]*y[1]-y[0]*x[1]
+	}, vec3, vec3)
+
+	for n := 2; n <= 4; n++ {
+		m := matType(n)
+		addBuiltin("matrixCompMult", m, func(out []float32, a [][]float32) {
+			for i := range out {
+				out[i] = a[0][i] * a[1][i]
+			}
+		}, m, m)
+	}
+
+	compare := map[string]func(x, y float32) bool{
+		"lessThan":         func(x, y float32) bool { return x < y },
+		"lessThanEqual":    func(x, y float32) bool { return x <= y },
+		"greaterThan":      func(x, y float32) bool { return x > y },
+		"greaterThanEqual": func(x, y float32) bool { return x >= y },
+		"equal":            func(x, y float32) bool { return x == y },
+		"notEqual":         func(x, y float32) bool { return x != y },
+	}
+	for n := 2; n <= 4; n++ {
+		bvec := vecType(tBool, n)
+		for name, f := range compare {
+			f := f
+			eval := func(out []float32, a [][]float32) {
+				for i := range out {
+					out[i] = b2f(f(a[0][i], a[1][i]))
+				}
+			}
+			addBuiltin(name, bvec, eval, vecType(tFloat, n), vecType(tFloat, n))
+			addBuiltin(name, bvec, eval, vecType(tInt, n), vecType(tInt, n))
+			if name == "equal" || name == "notEqual" {
+				addBuiltin(name, bvec, eval, bvec, bvec)
+			}
+		}
+		addBuiltin("any", boolType, func(out []float32, a [][]float32) {
+			out[0] = 0
+			for _, v := range a[0] {
+				if v != 0 {
+					out[0] = 1
+				}
+			}
+		}, bvec)
+		addBuiltin("all", boolType, func(out []float32, a [][]float32) {
+			out[0] = 1
+			for _, v := range a[0] {
+				if v == 0 {
+					out[0] = 0
+				}
+			}
+		}, bvec)
+		addBuiltin("not", bvec, func(out []float32, a [][]float32) {
+			for i := range out {
+				out[i] = b2f(a[0][i] == 0)
+			}
+		}, bvec)
+	}
+
+	vec4 := vecType(tFloat, 4)
+	lookups := []struct {
+		name   string
+		lookup lookup
+		sample typ
+		coord  typ
+	}{
+		{"texture2D", lookup2D, sampler2DType, vecType(tFloat, 2)},
+		{"texture2DProj", lookup2DProj3, sampler2DType, vec3},
+		{"texture2DProj", lookup2DProj4, sampler2DType, vec4},
+		{"textureCube", lookupCube, samplerCubeType, vec3},
+	}
+	for _, l := range lookups {
+		addBuiltin(l.name, vec4, nil, l.sample, l.coord).lookup = l.lookup
+		b := addBuiltin(l.name, vec4, nil, l.sample, l.coord, floatType)
+		b.lookup, b.stage = l.lookup, webgl.FRAGMENT_SHADER
+		b = addBuiltin(l.name+"Lod", vec4, nil, l.sample, l.coord, floatType)
+		b.lookup, b.stage, b.lod = l.lookup, webgl.VERTEX_SHADER, true
+	}
+}
+
+// Returns the overload of a built-in function for argument types and a
+// shader type, or nil if there is none.
+func findBuiltin(name string, args []typ, stage webgl.Enum) *builtin {
+	for _, b := range builtins[name] {
+		if (b.stage == 0 || b.stage == stage) && sameTypes(b.params, args) {
+			return b
+		}
+	}
+	return nil
+}
+
+func sameTypes(a, b []typ) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns a function computing a binary operator for operand types that
+// have been checked.
+func binaryFunc(op string, xt, yt typ) func(out, x, y []float32) {
+	integer := xt.basic == tInt
+	switch {
+	case op == "*" && xt.isMatrix() && yt.isMatrix():
+		return func(out, x, y []float32) {
+			n := xt.size
+			for c := 0; c < n; c++ {
+				for r := 0; r < n; r++ {
+					var sum float32
+					for k := 0; k < n; k++ {
+						sum += x[k*n+r] * y[c*n+k]
+					}
+					out[c*n+r] = sum
+				}
+			}
+		}
+	case op == "*" && xt.isMatrix() && yt.isVector():
+		return func(out, x, y []float32) {
+			n := yt.size
+			for r := 0; r < n; r++ {
+				var sum float32
+				for k := 0; k < n; k++ {
+					sum += x[k*n+r] * y[k]
+				}
+				out[r] = sum
+			}
+		}
+	case op == "*" && xt.isVector() && yt.isMatrix():
+		return func(out, x, y []float32) {
+			n := xt.size
+			for c := 0; c < n; c++ {
+				out[c] = dot(x, y[c*n:c*n+n])
+			}
+		}
+	}
+	switch op {
+	case "+":
+		return componentwise(func(a, b float32) float32 { return a + b })
+	case "-":
+		return componentwise(func(a, b float32) float32 { return a - b })
+	case "*":
+		return componentwise(func(a, b float32) float32 { return a * b })
+	case "/":
+		if integer {
+			return componentwise(func(a, b float32) float32 {
+				if b == 0 {
+					return 0
+				}
+				return float32(math.Trunc(float64(a / b)))
+			})
+		}
+		return componentwise(func(a, b float32) float32 { return a / b })
+	case "<":
+		return func(out, x, y []float32) { out[0] = b2f(x[0] < y[0]) }
+	case ">":
+		return func(out, x, y []float32) { out[0] = b2f(x[0] > y[0]) }
+	case "<=":
+		return func(out, x, y []float32) { out[0] = b2f(x[0] <= y[0]) }
+	case ">=":
+		return func(out, x, y []float32) { out[0] = b2f(x[0] >= y[0]) }
+	case "==", "!=":
+		eq := op == "=="
+		return func(out, x, y []float32) {
+			same := true
+			for i := range x {
+				if x[i] != y[i] {
+					same = false
+					break
+				}
+			}
+			out[0] = b2f(same == eq)
+		}
+	case "&&":
+		return func(out, x, y []float32) { out[0] = b2f(x[0] != 0 && y[0] != 0) }
+	case "||":
+		return func(out, x, y []float32) { out[0] = b2f(x[0] != 0 || y[0] != 0) }
+	case "^^":
+		return func(out, x, y []float32) { out[0] = b2f((x[0] != 0) != (y[0] != 0)) }
+	}
+	panic("glsl: unknown operator " + op)
+}
+
+// Returns a function applying f to the components of two operands, either
+// of which may be a scalar.
+func componentwise(f func(a, b float32) float32) func(out, x, y []float32) {
+	return func(out, x, y []float32) {
+		for i := range out {
+			out[i] = f(at(x, i), at(y, i))
+		}
+	}
+}
+
+// Converts a component to a basic type.
+func convert(v float32, from, to basic) float32 {
+	switch {
+	case to == tBool:
+		return b2f(v != 0)
+	case to == tInt && from == tFloat:
+		return float32(math.Trunc(float64(v)))
+	}
+	return v
+}
+
+// Returns a function computing a constructor of type t from arguments of
+// the given types, which have been checked.
+func constructor(t typ, args []typ) func(out []float32, args [][]float32) {
+	switch {
+	case t.basic == tStruct:
+		return func(out []float32, a [][]float32) {
+			n := 0
+			for _, v := range a {
+				n += copy(out[n:], v)
+			}
+		}
+	case len(args) == 1 && args[0].isScalar() && t.isMatrix():
+		from := args[0].basic
+		return func(out []float32, a [][]float32) {
+			v := convert(a[0][0], from, tFloat)
+			for i := range out {
+				out[i] = 0
+			}
+			for i := 0; i < t.size; i++ {
+				out[i*t.size+i] = v
+			}
+		}
+	case len(args) == 1 && args[0].isMatrix() && t.isMatrix():
+		n := args[0].size
+		return func(out []float32, a [][]float32) {
+			for c := 0; c < t.size; c++ {
+				for r := 0; r < t.size; r++ {
+					switch {
+					case c < n && r < n:
+						out[c*t.size+r] = a[0][c*n+r]
+					case c == r:
+						out[c*t.size+r] = 1
+					default:
+						out[c*t.size+r] = 0
+					}
+				}
+			}
+		}
+	case len(args) == 1 && args[0].isScalar():
+		from := args[0].basic
+		return func(out []float32, a [][]float32) {
+			v := convert(a[0][0], from, t.basic)
+			for i := range out {
+				out[i] = v
+			}
+		}
+	}
+	return func(out []float32, a [][]float32) {
+		i := 0
+		for k, v := range a {
+			for _, x := range v {
+				if i == len(out) {
+					return
+				}
+				out[i] = convert(x, args[k].basic, t.basic)
+				i++
+			}
+		}
+	}
+}
@@ -0,0 +1,695 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/n2d/webgl"
+)
+
+// binaryLevels are the binary operators by precedence, lowest first.
+var binaryLevels = [][]string{
+	{"||"}, {"^^"}, {"&&"}, {"|"}, {"^"}, {"&"}, {"==", "!="}, {"<", ">", "<=", ">="},
+	{"<<", ">>"}, {"+", "-"}, {"*", "/", "%"},
+}
+
+// assignOps are the assignment operators.
+var assignOps = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true,
+	"%=": true, "<<=": true, ">>=": true, "&=": true, "^=": true, "|=": true,
+}
+
+// Returns an expression that failed to check.
+func errorExpr(line int) expr {
+	return &constExpr{exprInfo{line: line, typ: errorType}}
+}
+
+// Returns whether any of the expressions failed to check.
+func failed(es ...expr) bool {
+	for _, e := range es {
+		if e.info().typ.basic == tError {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the values of constant expressions, or nil if any of them is
+// not constant.
+func values(es []expr) [][]float32 {
+	vs := make([][]float32, len(es))
+	for i, e := range es {
+		if vs[i] = e.info().value; vs[i] == nil {
+			return nil
+		}
+	}
+	return vs
+}
+
+// Parses an expression, including the comma operator.
+func (p *parser) expression() expr {
+	x := p.assignment()
+	for p.peek().text == "," {
+		t := p.next()
+		x = p.binary(",", x, p.assignment(), t.line)
+	}
+	return x
+}
+
+func (p *parser) assignment() expr {
+	x := p.conditional()
+	t := p.peek()
+	if !assignOps[t.text] {
+		return x
+	}
+	p.next()
+	return p.assign(t, x, p.assignment())
+}
+
+func (p *parser) conditional() expr {
+	c := p.binaryLevel(0)
+	t := p.peek()
+	if !p.accept("?") {
+		return c
+	}
+	x := p.expression()
+	p.expect(":")
+	return p.cond(c, x, p.assignment(), t.line)
+}
+
+func (p *parser) binaryLevel(level int) expr {
+	if level == len(binaryLevels) {
+		return p.unary()
+	}
+	x := p.binaryLevel(level + 1)
+	for {
+		t := p.peek()
+		if t.kind != tokPunct || indexOf(binaryLevels[level], t.text) < 0 {
+			return x
+		}
+		p.next()
+		x = p.binary(t.text, x, p.binaryLevel(level+1), t.line)
+	}
+}
+
+func (p *parser) unary() expr {
+	t := p.peek()
+	switch t.text {
+	case "++", "--", "+", "-", "!", "~":
+		p.next()
+		return p.unaryOp(t.text, p.unary(), t.line, false)
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() expr {
+	x := p.primary()
+	for {
+		t := p.peek()
+		switch t.text {
+		case "[":
+			p.next()
+			i := p.expression()
+			p.expect("]")
+			x = p.index(x, i, t.line)
+		case ".":
+			p.next()
+			x = p.field(x, p.ident(), t.line)
+		case "++", "--":
+			p.next()
+			x = p.unaryOp(t.text, x, t.line, true)
+		default:
+			return x
+		}
+	}
+}
+
+func (p *parser) primary() expr {
+	t := p.next()
+	switch t.kind {
+	case tokInt:
+		v, err := parseInt(t.text)
+		if err != nil || v > 1<<31-1 {
+			p.errorf(t.line, t.text, "Integer overflow")
+		}
+		return &constExpr{exprInfo{line: t.line, typ: intType, value: []float32{float32(v)}}}
+	case tokFloat:
+		if strings.HasSuffix(t.text, "f") || strings.HasSuffix(t.text, "F") {
+			p.errorf(t.line, "f", "Floating-point suffix unsupported prior to GLSL ES 3.00")
+			return errorExpr(t.line)
+		}
+		v, err := strconv.ParseFloat(t.text, 32)
+		if err != nil && !strings.Contains(err.Error(), "range") {
+			p.syntaxError(t)
+		}
+		return &constExpr{exprInfo{line: t.line, typ: floatType, value: []float32{float32(v)}}}
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &constExpr{exprInfo{line: t.line, typ: boolType, value: []float32{b2f(t.text == "true")}}}
+		}
+		if ty, ok := typeNames[t.text]; ok && p.peek().text == "(" {
+			return p.construct(ty, t.text, p.arguments(), t.line)
+		}
+		sym := p.lookup(t.text)
+		if sym != nil && sym.kind == symStruct && p.peek().text == "(" {
+			return p.construct(sym.typ, t.text, p.arguments(), t.line)
+		}
+		if _, isType := typeNames[t.text]; keywords[t.text] || isType {
+			p.syntaxError(t)
+		}
+		if reserved[t.text] {
+			p.errorf(t.line, t.text, "Illegal use of reserved word")
+			return errorExpr(t.line)
+		}
+		if p.peek().text == "(" {
+			return p.call(t, sym, p.arguments())
+		}
+		switch {
+		case sym == nil:
+			p.errorf(t.line, t.text, "undeclared identifier")
+			return errorExpr(t.line)
+		case sym.kind != symVar:
+			p.errorf(t.line, t.text, "variable expected")
+			return errorExpr(t.line)
+		}
+		sym.used = true
+		return &varRef{exprInfo{line: t.line, typ: sym.typ, value: sym.value}, sym}
+	}
+	if t.text == "(" {
+		x := p.expression()
+		p.expect(")")
+		return x
+	}
+	p.syntaxError(t)
+	return nil
+}
+
+// Parses the arguments of a call.
+func (p *parser) arguments() []expr {
+	p.expect("(")
+	if p.peek().text == "void" && p.peekAt(1).text == ")" {
+		p.next()
+	}
+	var args []expr
+	if p.accept(")") {
+		return args
+	}
+	for {
+		args = append(args, p.assignment())
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")")
+	return args
+}
+
+// Checks a call of a user defined or built-in function.
+func (p *parser) call(name token, sym *symbol, args []expr) expr {
+	if failed(args...) {
+		return errorExpr(name.line)
+	}
+	types := make([]typ, len(args))
+	for i, a := range args {
+		types[i] = a.info().typ
+	}
+	if sym != nil && sym.kind == symVar {
+		p.errorf(name.line, name.text, "not a function")
+		return errorExpr(name.line)
+	}
+	if sym != nil && sym.kind == symFunc {
+		for _, fn := range sym.funcs {
+			params := make([]typ, len(fn.params))
+			for i, param := range fn.params {
+				params[i] = param.typ
+			}
+			if !sameTypes(params, types) {
+				continue
+			}
+			for i, param := range fn.params {
+				if param.qual == qualOut || param.qual == qualInOut {
+					p.checkOutArgument(args[i], name.text)
+				}
+			}
+			if p.fn != nil {
+				p.fn.calls = append(p.fn.calls, fn)
+			}
+			if _, ok := p.called[fn]; !ok {
+				p.called[fn] = name.line
+			}
+			return &callExpr{exprInfo{line: name.line, typ: fn.ret}, fn, args}
+		}
+	}
+	b := findBuiltin(name.text, types, p.stage)
+	if b == nil {
+		p.errorf(name.line, name.text, "no matching overloaded function found")
+		return errorExpr(name.line)
+	}
+	e := &builtinCall{exprInfo{line: name.line, typ: b.ret}, b, args}
+	if vs := values(args); vs != nil && b.eval != nil {
+		e.value = make([]float32, b.ret.slots())
+		b.eval(e.value, vs)
+	}
+	return e
+}
+
+// Checks an argument of an out or inout parameter.
+func (p *parser) checkOutArgument(arg expr, fn string) {
+	if r, ok := arg.(*varRef); ok && r.sym.loopIndex {
+		p.errorf(arg.info().line, r.sym.name, "Loop index cannot be used as argument to a function out or inout parameter")
+		return
+	}
+	if arg.info().value != nil {
+		p.errorf(arg.info().line, fn, "Constant value cannot be passed for 'out' or 'inout' parameters.")
+		return
+	}
+	p.checkLValue(arg, fn)
+}
+
+// Checks a constructor call.
+func (p *parser) construct(t typ, name string, args []expr, line int) expr {
+	if failed(args...) {
+		return errorExpr(line)
+	}
+	types := make([]typ, len(args))
+	for i, a := range args {
+		types[i] = a.info().typ
+	}
+	if !p.checkConstructor(t, name, types, line) {
+		return errorExpr(line)
+	}
+	e := &ctorExpr{exprInfo{line: line, typ: t}, args}
+	if vs := values(args); vs != nil {
+		e.value = make([]float32, t.slots())
+		constructor(t, types)(e.value, vs)
+	}
+	return e
+}
+
+// Checks the argument types of a constructor.
+func (p *parser) checkConstructor(t typ, name string, args []typ, line int) bool {
+	switch {
+	case len(args) == 0:
+		p.errorf(line, name, "constructor does not have any arguments")
+		return false
+	case t.basic == tStruct:
+		if len(args) != len(t.strct.fields) {
+			p.errorf(line, name, "Number of constructor parameters does not match the number of structure fields")
+			return false
+		}
+		for i, f := range t.strct.fields {
+			if args[i] != f.typ {
+				p.errorf(line, name, "Structure constructor arguments do not match structure fields")
+				return false
+			}
+		}
+		return true
+	case !t.isPlain():
+		p.errorf(line, name, "cannot construct this type")
+		return false
+	}
+	n := 0
+	for i, a := range args {
+		switch {
+		case !a.isPlain():
+			p.errorf(line, "constructor", "cannot convert a %s", describe(a))
+			return false
+		case t.isMatrix() && a.isMatrix() && len(args) > 1:
+			p.errorf(line, "constructor", "constructing matrix from matrix can only take one argument")
+			return false
+		case n >= t.comps() && i > 0:
+			p.errorf(line, "constructor", "too many arguments")
+			return false
+		}
+		n += a.comps()
+	}
+	if len(args) == 1 && (args[0].isScalar() || args[0].isMatrix() && t.isMatrix()) {
+		return true
+	}
+	if n < t.comps() {
+		p.errorf(line, "constructor", "not enough data provided for construction")
+		return false
+	}
+	return true
+}
+
+// Returns the kind of a type that cannot be converted, for messages.
+func describe(t typ) string {
+	switch {
+	case t.isArray():
+		return "array"
+	case t.basic == tStruct:
+		return "structure"
+	case t.basic == tVoid:
+		return "void"
+	}
+	return "sampler"
+}
+
+// Checks an indexing expression.
+func (p *parser) index(x, i expr, line int) expr {
+	if failed(x, i) {
+		return errorExpr(line)
+	}
+	xt, it := x.info().typ, i.info().typ
+	var n int
+	switch {
+	case xt.isArray():
+		n = xt.array
+	case xt.isMatrix():
+		n = xt.cols
+	case xt.isVector():
+		n = xt.size
+	default:
+		p.errorf(line, "[", "left of '[' is not of type array, matrix, or vector")
+		return errorExpr(line)
+	}
+	if it != intType {
+		p.errorf(line, "[]", "integer expression required")
+		return errorExpr(line)
+	}
+	iv := i.info().value
+	if iv != nil && (iv[0] < 0 || int(iv[0]) >= n) {
+		p.errorf(line, "[]", "index out of range '%d'", int(iv[0]))
+		return errorExpr(line)
+	}
+	if xt.hasSampler() && !constantIndex(i) {
+		p.errorf(line, "[]", "Index expression must be constant")
+		return errorExpr(line)
+	}
+	e := &indexExpr{exprInfo{line: line, typ: xt.elem()}, x, i}
+	if xv := x.info().value; xv != nil && iv != nil {
+		size := e.typ.slots()
+		k := int(iv[0])
+		e.value = xv[k*size : (k+1)*size]
+	}
+	return e
+}
+
+// Returns whether an expression is a constant-index-expression: one
+// made of constants and loop indices.
+func constantIndex(e expr) bool {
+	if e.info().value != nil {
+		return true
+	}
+	all := func(es ...expr) bool {
+		for _, e := range es {
+			if !constantIndex(e) {
+				return false
+			}
+		}
+		return true
+	}
+	switch e := e.(type) {
+	case *varRef:
+		return e.sym.loopIndex
+	case *unaryExpr:
+		return e.op != "++" && e.op != "--" && all(e.x)
+	case *binaryExpr:
+		return all(e.x, e.y)
+	case *ctorExpr:
+		return all(e.args...)
+	case *builtinCall:
+		return e.fn.eval != nil && all(e.args...)
+	}
+	return false
+}
+
+// swizzleSets are the sets of names of vector components.
+var swizzleSets = []string{"xyzw", "rgba", "stpq"}
+
+// Checks the selection of a structure field or of vector components.
+func (p *parser) field(x expr, name token, line int) expr {
+	if failed(x) {
+		return errorExpr(line)
+	}
+	xt := x.info().typ
+	xv := x.info().value
+	if xt.isStruct() {
+		offset := 0
+		for i, f := range xt.strct.fields {
+			if f.name == name.text {
+				e := &fieldExpr{exprInfo{line: line, typ: f.typ}, x, i}
+				if xv != nil {
+					e.value = xv[offset : offset+f.typ.slots()]
+				}
+				return e
+			}
+			offset += f.typ.slots()
+		}
+		p.errorf(name.line, name.text, "no such field in structure")
+		return errorExpr(line)
+	}
+	if !xt.isVector() {
+		p.errorf(name.line, name.text, "field selection requires structure or vector on left hand side")
+		return errorExpr(line)
+	}
+	set := ""
+	for _, s := range swizzleSets {
+		if strings.IndexByte(s, name.text[0]) >= 0 {
+			set = s
+		}
+	}
+	if len(name.text) > 4 || set == "" {
+		p.errorf(name.line, name.text, "illegal vector field selection")
+		return errorExpr(line)
+	}
+	comps := make([]int, len(name.text))
+	for i := range comps {
+		comps[i] = strings.IndexByte(set, name.text[i])
+		if comps[i] < 0 {
+			p.errorf(name.line, name.text, "illegal vector field selection")
+			return errorExpr(line)
+		}
+		if comps[i] >= xt.size {
+			p.errorf(name.line, name.text, "vector field selection out of range")
+			return errorExpr(line)
+		}
+	}
+	e := &swizzleExpr{exprInfo{line: line, typ: vecType(xt.basic, len(comps))}, x, comps}
+	if xv != nil {
+		e.value = make([]float32, len(comps))
+		for i, c := range comps {
+			e.value[i] = xv[c]
+		}
+	}
+	return e
+}
+
+// Checks a unary operator.
+func (p *parser) unaryOp(op string, x expr, line int, postfix bool) expr {
+	if failed(x) {
+		return errorExpr(line)
+	}
+	xt := x.info().typ
+	ok := false
+	switch op {
+	case "~":
+		p.errorf(line, op, "bit-wise operator supported in GLSL ES 3.00 and above only")
+		return errorExpr(line)
+	case "!":
+		ok = xt == boolType
+	default:
+		ok = xt.isNumeric()
+	}
+	if !ok {
+		p.errorf(line, op, "wrong operand type - no operation '%s' exists that takes an operand of type %s (or there is no acceptable conversion)", op, xt)
+		return errorExpr(line)
+	}
+	if (op == "++" || op == "--") && !p.checkLValue(x, op) {
+		return errorExpr(line)
+	}
+	e := &unaryExpr{exprInfo{line: line, typ: xt}, op, x, postfix}
+	if xv := x.info().value; xv != nil && op != "++" && op != "--" {
+		e.value = make([]float32, len(xv))
+		unaryFunc(op)(e.value, xv)
+	}
+	return e
+}
+
+// Returns a function computing the unary operator +, - or !.
+func unaryFunc(op string) func(out, x []float32) {
+	switch op {
+	case "-":
+		return func(out, x []float32) {
+			for i := range out {
+				out[i] = -x[i]
+			}
+		}
+	case "!":
+		return func(out, x []float32) { out[0] = 1 - x[0] }
+	}
+	return func(out, x []float32) { copy(out, x) }
+}
+
+// Returns the result type of a binary operator, and whether the operand
+// types are valid.
+func binaryType(op string, xt, yt typ) (typ, bool) {
+	switch op {
+	case ",":
+		return yt, true
+	case "&&", "||", "^^":
+		return boolType, xt == boolType && yt == boolType
+	case "==", "!=":
+		return boolType, xt == yt && xt.basic != tVoid && !xt.hasArray() && !xt.hasSampler()
+	case "<", ">", "<=", ">=":
+		return boolType, xt == yt && xt.isScalar() && xt.isNumeric()
+	}
+	if !xt.isNumeric() || !yt.isNumeric() || xt.basic != yt.basic {
+		return errorType, false
+	}
+	switch {
+	case xt == yt:
+		return xt, true
+	case xt.isScalar():
+		return yt, true
+	case yt.isScalar():
+		return xt, true
+	case op == "*" && xt.isVector() && yt.isMatrix() && xt.size == yt.size:
+		return xt, true
+	case op == "*" && xt.isMatrix() && yt.isVector() && xt.size == yt.size:
+		return yt, true
+	}
+	return errorType, false
+}
+
+// Checks a binary operator.
+func (p *parser) binary(op string, x, y expr, line int) expr {
+	switch op {
+	case "%":
+		p.errorf(line, op, "integer modulus operator supported in GLSL ES 3.00 and above only")
+		return errorExpr(line)
+	case "<<", ">>", "&", "|", "^":
+		p.errorf(line, op, "bit-wise operator supported in GLSL ES 3.00 and above only")
+		return errorExpr(line)
+	}
+	if failed(x, y) {
+		return errorExpr(line)
+	}
+	xt, yt := x.info().typ, y.info().typ
+	t, ok := binaryType(op, xt, yt)
+	if !ok {
+		p.errorf(line, op, "wrong operand types - no operation '%s' exists that takes a left-hand operand of type '%s' and a right operand of type '%s' (or there is no acceptable conversion)", op, xt, yt)
+		return errorExpr(line)
+	}
+	e := &binaryExpr{exprInfo{line: line, typ: t}, op, x, y}
+	xv, yv := x.info().value, y.info().value
+	if xv != nil && yv != nil && op != "," {
+		e.value = make([]float32, t.slots())
+		binaryFunc(op, xt, yt)(e.value, xv, yv)
+	}
+	return e
+}
+
+// Checks a conditional expression.
+func (p *parser) cond(c, x, y expr, line int) expr {
+	if failed(c, x, y) {
+		return errorExpr(line)
+	}
+	if c.info().typ != boolType {
+		p.errorf(line, "?:", "boolean expression expected")
+		return errorExpr(line)
+	}
+	xt, yt := x.info().typ, y.info().typ
+	if xt != yt || xt.isArray() {
+		p.errorf(line, "?:", "wrong operand types - no operation '?:' exists that takes a left-hand operand of type '%s' and a right operand of type '%s' (or there is no acceptable conversion)", xt, yt)
+		return errorExpr(line)
+	}
+	e := &condExpr{exprInfo{line: line, typ: xt}, c, x, y}
+	if cv, xv, yv := c.info().value, x.info().value, y.info().value; cv != nil && xv != nil && yv != nil {
+		e.value = yv
+		if cv[0] != 0 {
+			e.value = xv
+		}
+	}
+	return e
+}
+
+// Checks an assignment.
+func (p *parser) assign(op token, l, r expr) expr {
+	line := op.line
+	switch op.text {
+	case "%=":
+		p.errorf(line, op.text, "integer modulus operator supported in GLSL ES 3.00 and above only")
+		return errorExpr(line)
+	case "<<=", ">>=", "&=", "^=", "|=":
+		p.errorf(line, op.text, "bit-wise operator supported in GLSL ES 3.00 and above only")
+		return errorExpr(line)
+	}
+	if failed(l, r) {
+		return errorExpr(line)
+	}
+	lt, rt := l.info().typ, r.info().typ
+	name := op.text
+	if name == "=" {
+		name = "assign"
+		if lt != rt {
+			p.errorf(line, name, "cannot convert from '%s' to '%s'", rt, lt)
+			return errorExpr(line)
+		}
+		if lt.hasArray() {
+			p.errorf(line, name, "l-value required (can't modify an array)")
+			return errorExpr(line)
+		}
+	} else if t, ok := binaryType(op.text[:1], lt, rt); !ok || t != lt {
+		p.errorf(line, op.text, "wrong operand types - no operation '%s' exists that takes a left-hand operand of type '%s' and a right operand of type '%s' (or there is no acceptable conversion)", op.text, lt, rt)
+		return errorExpr(line)
+	}
+	if !p.checkLValue(l, name) {
+		return errorExpr(line)
+	}
+	return &assignExpr{exprInfo{line: line, typ: lt}, op.text, l, r}
+}
+
+// Checks that an expression can be assigned to, reporting an error for
+// the operation op if it cannot.
+func (p *parser) checkLValue(e expr, op string) bool {
+	line := e.info().line
+	switch e := e.(type) {
+	case *varRef:
+		s := e.sym
+		var reason string
+		switch {
+		case s.loopIndex:
+			p.errorf(line, s.name, "Loop index cannot be statically assigned to within the body of the loop")
+			return false
+		case s.qual == qualConst || s.qual == qualConstIn:
+			reason = "can't modify a const"
+		case s.qual == qualUniform:
+			reason = "can't modify a uniform"
+		case s.qual == qualAttribute:
+			reason = "can't modify an attribute"
+		case s.qual == qualVarying && p.stage != webgl.VERTEX_SHADER:
+			reason = "can't modify a varying"
+		case s.qual == qualInput:
+			reason = "can't modify an input"
+		case s.typ.hasSampler():
+			reason = "can't modify a sampler"
+		default:
+			return true
+		}
+		p.errorf(line, op, "l-value required (%s \"%s\")", reason, s.name)
+		return false
+	case *indexExpr:
+		return p.checkLValue(e.x, op)
+	case *fieldExpr:
+		return p.checkLValue(e.x, op)
+	case *swizzleExpr:
+		for i, c := range e.comps {
+			for _, d := range e.comps[:i] {
+				if c == d {
+					p.errorf(line, op, "l-value of swizzle cannot have duplicate components")
+					return false
+				}
+			}
+		}
+		return p.checkLValue(e.x, op)
+	}
+	p.errorf(line, op, "l-value required")
+	return false
+}
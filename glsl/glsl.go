@@ -0,0 +1,94 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package glsl implements the OpenGL ES Shading Language 1.00 used by
+// WebGL 1. Compile runs the preprocessor, parser and type checker over a
+// shader source and reports errors in the format of a WebGL info log, and
+// Link checks a vertex and a fragment shader against each other and
+// returns a Program that interprets them.
+//
+// The checks include the restrictions WebGL places on shaders, such as
+// the default float precision of fragment shaders and the loop forms of
+// appendix A of the specification. No extensions are supported.
+package glsl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n2d/webgl"
+)
+
+// Implementation limits, matching those of package record.
+const (
+	maxVertexAttribs          = 16
+	maxVertexUniformVectors   = 256
+	maxVaryingVectors         = 15
+	maxVertexTextureUnits     = 16
+	maxCombinedTextureUnits   = 32
+	maxTextureUnits           = 16
+	maxFragmentUniformVectors = 224
+	maxDrawBuffers            = 1
+)
+
+// Error is a failure to compile or link. Its text is the info log a WebGL
+// implementation would return.
+type Error struct {
+	Log string
+}
+
+func (e *Error) Error() string {
+	return e.Log
+}
+
+// diagnostics collects the messages of a compilation.
+type diagnostics struct {
+	errors []string
+}
+
+// Records an error at a line of the source, naming the token it is about.
+func (d *diagnostics) errorf(line int, token, format string, args ...interface{}) {
+	d.errors = append(d.errors, fmt.Sprintf("ERROR: 0:%d: '%s' : %s", line, token, fmt.Sprintf(format, args...)))
+}
+
+// Returns the recorded errors as an *Error, or nil if there are none.
+func (d *diagnostics) err() error {
+	if len(d.errors) == 0 {
+		return nil
+	}
+	return &Error{Log: strings.Join(d.errors, "\n") + "\n"}
+}
+
+// Shader is a compiled shader.
+type Shader struct {
+	// Type is VERTEX_SHADER or FRAGMENT_SHADER.
+	Type webgl.Enum
+
+	globals   []*symbol
+	functions []*function
+	main      *function
+
+	// inits are the global declarations with initializers, in order.
+	inits []*varDecl
+
+	// builtins are the built-in variables by name.
+	builtins map[string]*symbol
+}
+
+// Compiles the source of a shader of the given type. Errors are returned
+// as an *Error.
+func Compile(typ webgl.Enum, src string) (*Shader, error) {
+	if typ != webgl.VERTEX_SHADER && typ != webgl.FRAGMENT_SHADER {
+		return nil, fmt.Errorf("glsl: invalid shader type 0x%04X", uint32(typ))
+	}
+	var d diagnostics
+	toks := preprocess(&d, src)
+	s := &Shader{Type: typ}
+	p := newParser(&d, s, toks)
+	p.parse()
+	if err := d.err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
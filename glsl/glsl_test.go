@@ -0,0 +1,291 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n2d/webgl"
+)
+
+func TestPreprocess(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"object macro", "#define N 4\nint a[N];", "int a [ 4 ] ;"},
+		{"function macro", "#define ADD(a, b) ((a) + (b))\nADD(x, 1)", "( ( x ) + ( 1 ) )"},
+		{"arguments across lines", "#define ADD(a, b) a + b\nADD(x,\ny)", "x + y"},
+		{"nested macros", "#define A B\n#define B 2\nA", "2"},
+		{"recursive macro", "#define A A + 1\nA", "A + 1"},
+		{"undef", "#define A 1\n#undef A\nA", "A"},
+		{"if", "#if 1 + 1 == 2\nyes\n#else\nno\n#endif", "yes"},
+		{"if false", "#if 0\nyes\n#else\nno\n#endif", "no"},
+		{"elif", "#if 0\na\n#elif 2 > 1\nb\n#else\nc\n#endif", "b"},
+		{"defined", "#define X\n#if defined(X) && !defined Y\nyes\n#endif", "yes"},
+		{"ifdef", "#ifdef GL_ES\nes\n#endif\n#ifndef GL_ES\ndesktop\n#endif", "es"},
+		{"nested if", "#if 0\n#if 1\na\n#endif\n#else\nb\n#endif", "b"},
+		{"skipped garbage", "#if 0\n@ $\n#endif\nok", "ok"},
+		{"predefined", "__VERSION__ GL_ES __LINE__", "100 1 1"},
+		{"line", "#line 10\n__LINE__", "10"},
+		{"comments", "a /* b\nc */ d // e\nf", "a d f"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var d diagnostics
+			toks := preprocess(&d, test.src)
+			if err := d.err(); err != nil {
+				t.Fatal(err)
+			}
+			var texts []string
+			for _, tok := range toks[:len(toks)-1] {
+				texts = append(texts, tok.text)
+			}
+			if got := strings.Join(texts, " "); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  webgl.Enum
+		src  string
+		want string
+	}{{
+		name: "missing precision",
+		typ:  webgl.FRAGMENT_SHADER,
+		src:  "void main() {\n\tfloat x = 1.0;\n\tgl_FragColor = vec4(x);\n}",
+		want: "ERROR: 0:2: '' : No precision specified for (float)",
+	}, {
+		name: "missing semicolon",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "void main() {\n\tgl_Position = vec4(1.0)\n}",
+		want: "ERROR: 0:3: '}' : syntax error",
+	}, {
+		name: "assignment type",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "void main() {\n\n\tgl_Position = vec3(1.0);\n}",
+		want: "ERROR: 0:3: 'assign' : cannot convert from 'vec3' to 'vec4'",
+	}, {
+		name: "initializer type",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "void main() {\n\tint x = 1.0;\n}",
+		want: "ERROR: 0:2: '=' : cannot convert from 'float' to 'int'",
+	}, {
+		name: "undeclared",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "void main() {\n\tfoo = 1;\n}",
+		want: "ERROR: 0:2: 'foo' : undeclared identifier",
+	}, {
+		name: "loop index written",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "void main() {\n\tfor (int i = 0; i < 3; i++) {\n\t\ti = 2;\n\t}\n}",
+		want: "ERROR: 0:3: 'i' : Loop index cannot be statically assigned to within the body of the loop",
+	}, {
+		name: "line directive",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "#line 20\nvoid main() {\n\tfoo = 1;\n}",
+		want: "ERROR: 0:21: 'foo' : undeclared identifier",
+	}, {
+		name: "error directive",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "void main() {}\n#if 1\n#error too old\n#endif",
+		want: "ERROR: 0:3: '#error' : too old",
+	}, {
+		name: "unterminated if",
+		typ:  webgl.VERTEX_SHADER,
+		src:  "#if 1\nvoid main() {}",
+		want: "ERROR: 0:2: '#if' : unexpected end of file found in conditional block",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Compile(test.typ, test.src)
+			if err == nil {
+				t.Fatal("compiled without errors")
+			}
+			if _, ok := err.(*Error); !ok {
+				t.Fatalf("got %T, want *Error", err)
+			}
+			if got := strings.SplitN(err.Error(), "\n", 2)[0]; got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// checker is a texture of 2x2 checks of red and blue that returns the
+// bias in alpha.
+type checker struct{}
+
+func (checker) Texture2D(unit int, s, t, bias float32, lod bool) [4]float32 {
+	if (int(s*2)+int(t*2))%2 == 0 {
+		return [4]float32{1, 0, 0, bias}
+	}
+	return [4]float32{0, 0, 1, bias}
+}
+
+func (checker) TextureCube(unit int, x, y, z, bias float32, lod bool) [4]float32 {
+	return [4]float32{x, y, z, bias}
+}
+
+const (
+	testVertex = `
+attribute vec3 a_pos;
+attribute vec2 a_uv;
+uniform mat4 u_mvp;
+uniform float u_weights[3];
+struct Light { vec3 dir; float intensity; };
+uniform Light u_light;
+varying vec2 v_uv;
+varying float v_shade;
+
+float square(float x) { return x * x; }
+
+void main() {
+	v_uv = a_uv;
+	float sum = 0.0;
+	for (int i = 0; i < 3; i++) {
+		sum += u_weights[i];
+	}
+	v_shade = square(sum) + u_light.intensity * dot(u_light.dir, vec3(1.0));
+	gl_Position = u_mvp * vec4(a_pos, 1.0);
+	gl_PointSize = 4.0;
+}`
+
+	testFragment = `
+precision mediump float;
+varying vec2 v_uv;
+varying float v_shade;
+uniform sampler2D u_tex;
+
+void main() {
+	if (v_uv.x < 0.0) {
+		discard;
+	}
+	vec4 c = texture2D(u_tex, v_uv);
+	gl_FragColor = vec4(mix(c.rgb, vec3(1.0), 0.5), v_shade);
+}`
+)
+
+func TestRun(t *testing.T) {
+	vs, err := Compile(webgl.VERTEX_SHADER, testVertex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := Compile(webgl.FRAGMENT_SHADER, testFragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Link(vs, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.LoadUniforms(func(name string) interface{} {
+		switch name {
+		case "u_mvp":
+			return []float32{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 1, 1, 1, 1}
+		case "u_weights":
+			return []float32{0.25, 0.5, 0.25}
+		case "u_light.dir":
+			return []float32{1, 0, 0}
+		case "u_light.intensity":
+			return []float32{0.5}
+		}
+		return nil
+	})
+	if p.Varyings != 3 {
+		t.Errorf("got %d varying components, want 3", p.Varyings)
+	}
+
+	varyings := make([]float32, p.Varyings)
+	tests := []struct {
+		name      string
+		attribs   [][4]float32
+		position  [4]float32
+		color     [4]float32
+		discarded bool
+	}{{
+		name:     "red check",
+		attribs:  [][4]float32{{1, 2, 3, 1}, {0.25, 0.25, 0, 1}},
+		position: [4]float32{3, 5, 7, 1},
+		color:    [4]float32{1, 0.5, 0.5, 1.5},
+	}, {
+		name:     "blue check",
+		attribs:  [][4]float32{{0, 0, 0, 1}, {0.75, 0.25, 0, 1}},
+		position: [4]float32{1, 1, 1, 1},
+		color:    [4]float32{0.5, 0.5, 1, 1.5},
+	}, {
+		name:      "discarded",
+		attribs:   [][4]float32{{0, 0, 0, 1}, {-1, 0, 0, 1}},
+		position:  [4]float32{1, 1, 1, 1},
+		discarded: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attribs := make([][4]float32, len(p.Attribs))
+			for i, a := range p.Attribs {
+				switch a.Name {
+				case "a_pos":
+					attribs[i] = test.attribs[0]
+				case "a_uv":
+					attribs[i] = test.attribs[1]
+				}
+			}
+			position, pointSize := p.RunVertex(attribs, varyings, checker{})
+			if position != test.position || pointSize != 4 {
+				t.Errorf("got position %v and point size %v, want %v and 4", position, pointSize, test.position)
+			}
+			color, ok := p.RunFragment([4]float32{}, true, [2]float32{}, varyings, checker{})
+			if ok == test.discarded {
+				t.Fatalf("got kept %v, want %v", ok, !test.discarded)
+			}
+			if ok && color != test.color {
+				t.Errorf("got color %v, want %v", color, test.color)
+			}
+		})
+	}
+}
+
+func TestLinkErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		vs, fs string
+		want   string
+	}{{
+		name: "varying not written",
+		vs:   "void main() { gl_Position = vec4(0.0); }",
+		fs:   "precision mediump float;\nvarying float v;\nvoid main() { gl_FragColor = vec4(v); }",
+		want: "Varying `v` has static-use in the frag shader, but is undeclared in the vert shader.",
+	}, {
+		name: "varying type",
+		vs:   "varying vec2 v;\nvoid main() { v = vec2(0.0); gl_Position = vec4(0.0); }",
+		fs:   "precision mediump float;\nvarying float v;\nvoid main() { gl_FragColor = vec4(v); }",
+		want: "Types of varying `v` differ between VERTEX and FRAGMENT shaders.",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vs, err := Compile(webgl.VERTEX_SHADER, test.vs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fs, err := Compile(webgl.FRAGMENT_SHADER, test.fs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Link(vs, fs)
+			if err == nil {
+				t.Fatal("linked without errors")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %q, want it to contain %q", err, test.want)
+			}
+		})
+	}
+}
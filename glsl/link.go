@@ -0,0 +1,285 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n2d/webgl"
+)
+
+// Program is a linked vertex and fragment shader pair that can be run.
+// Its methods are not safe for concurrent use.
+type Program struct {
+	// Attribs and Uniforms are the active attributes and uniforms, as
+	// GetActiveAttrib and GetActiveUniform report them.
+	Attribs  []webgl.ActiveInfo
+	Uniforms []webgl.ActiveInfo
+
+	// Varyings is the number of varying components passed from the vertex
+	// shader to the fragment shader.
+	Varyings int
+
+	vertex   *machine
+	fragment *machine
+
+	attribs  [][]float32
+	varyings []varying
+	uniforms []uniform
+
+	depthRange [][]float32
+
+	position    []float32
+	pointSize   []float32
+	writesSize  bool
+	fragCoord   []float32
+	frontFacing []float32
+	pointCoord  []float32
+	fragColor   []float32
+}
+
+// varying is the storage of a varying in both shaders.
+type varying struct {
+	offset int
+	vertex []float32
+	frag   []float32
+}
+
+// uniform is the storage of an active uniform in the shaders using it.
+type uniform struct {
+	name  string
+	basic basic
+	mems  [][]float32
+}
+
+// Returns whether two types are the same, comparing structures by their
+// fields.
+func sameType(a, b typ) bool {
+	if a.basic != tStruct || b.basic != tStruct {
+		return a == b
+	}
+	if a.array != b.array || a.strct.name != b.strct.name || len(a.strct.fields) != len(b.strct.fields) {
+		return false
+	}
+	for i, f := range a.strct.fields {
+		g := b.strct.fields[i]
+		if f.name != g.name || !sameType(f.typ, g.typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the number of vectors a type takes in the uniform or attribute
+// storage.
+func vectors(t typ) int {
+	if t.basic == tStruct {
+		n := 0
+		for _, f := range t.strct.fields {
+			n += vectors(f.typ)
+		}
+		return n * max(t.array, 1)
+	}
+	return max(t.cols, 1) * max(t.array, 1)
+}
+
+// Calls f for each active uniform in a variable of type t, with its name
+// and storage.
+func expandUniform(name string, t typ, mem []float32, f func(name string, t typ, mem []float32)) {
+	switch {
+	case t.basic == tStruct && t.array > 0:
+		size := t.slots() / t.array
+		for i := 0; i < t.array; i++ {
+			expandUniform(fmt.Sprintf("%s[%d]", name, i), t.elem(), mem[i*size:(i+1)*size], f)
+		}
+	case t.basic == tStruct:
+		offset := 0
+		for _, fl := range t.strct.fields {
+			n := fl.typ.slots()
+			expandUniform(name+"."+fl.name, fl.typ, mem[offset:offset+n], f)
+			offset += n
+		}
+	default:
+		f(name, t, mem)
+	}
+}
+
+// Links a vertex and a fragment shader into a program. Errors are
+// returned as an *Error.
+func Link(vs, fs *Shader) (*Program, error) {
+	if vs == nil || fs == nil || vs.Type != webgl.VERTEX_SHADER || fs.Type != webgl.FRAGMENT_SHADER {
+		return nil, &Error{Log: "Missing vertex or fragment shader.\n"}
+	}
+	var errs []string
+	errorf := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	p := &Program{vertex: newMachine(vs), fragment: newMachine(fs)}
+
+	declared := make(map[string]*symbol)
+	for _, sym := range vs.globals {
+		declared[sym.name] = sym
+	}
+	for _, sym := range fs.globals {
+		v := declared[sym.name]
+		switch sym.qual {
+		case qualVarying:
+			switch {
+			case v == nil || v.qual != qualVarying:
+				if sym.used {
+					errorf("Varying `%s` has static-use in the frag shader, but is undeclared in the vert shader.", sym.name)
+				}
+				continue
+			case !sameType(v.typ, sym.typ):
+				errorf("Types of varying `%s` differ between VERTEX and FRAGMENT shaders.", sym.name)
+				continue
+			}
+			p.varyings = append(p.varyings, varying{p.Varyings, p.vertex.storage[v], p.fragment.storage[sym]})
+			p.Varyings += sym.typ.slots()
+		case qualUniform:
+			if v != nil && v.qual == qualUniform && !sameType(v.typ, sym.typ) {
+				errorf("Types of uniform `%s` differ between VERTEX and FRAGMENT shaders.", sym.name)
+			}
+		}
+	}
+	if p.Varyings > maxVaryingVectors*4 {
+		errorf("Varyings over maximum register limit")
+	}
+
+	locations := 0
+	for _, sym := range vs.globals {
+		if sym.qual != qualAttribute || !sym.used {
+			continue
+		}
+		p.Attribs = append(p.Attribs, webgl.ActiveInfo{Name: sym.name, Type: sym.typ.enum(), Size: 1})
+		p.attribs = append(p.attribs, p.vertex.storage[sym])
+		locations += vectors(sym.typ)
+	}
+	if locations > maxVertexAttribs {
+		errorf("Too many attributes")
+	}
+
+	index := make(map[string]int)
+	for _, s := range []*Shader{vs, fs} {
+		m := p.vertex
+		limit, stage := maxVertexUniformVectors, "Vertex"
+		if s == fs {
+			m = p.fragment
+			limit, stage = maxFragmentUniformVectors, "Fragment"
+		}
+		n := 0
+		for _, sym := range s.globals {
+			if sym.qual != qualUniform || !sym.used {
+				continue
+			}
+			n += vectors(sym.typ)
+			expandUniform(sym.name, sym.typ, m.storage[sym], func(name string, t typ, mem []float32) {
+				if i, ok := index[name]; ok {
+					p.uniforms[i].mems = append(p.uniforms[i].mems, mem)
+					return
+				}
+				info := webgl.ActiveInfo{Name: name, Type: t.elem().enum(), Size: 1}
+				if !t.isArray() {
+					info.Type = t.enum()
+				} else {
+					info.Name += "[0]"
+					info.Size = t.array
+				}
+				index[name] = len(p.uniforms)
+				p.Uniforms = append(p.Uniforms, info)
+				p.uniforms = append(p.uniforms, uniform{name, t.basic, [][]float32{mem}})
+			})
+		}
+		if n > limit {
+			errorf("%s shader active uniforms exceed GL_MAX_%s_UNIFORM_VECTORS (%d)", stage, strings.ToUpper(stage), limit)
+		}
+		if dr := s.builtins["gl_DepthRange"]; dr.used {
+			p.depthRange = append(p.depthRange, m.storage[dr])
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &Error{Log: strings.Join(errs, "\n") + "\n"}
+	}
+
+	vb, fb := vs.builtins, fs.builtins
+	p.position = p.vertex.storage[vb["gl_Position"]]
+	p.pointSize = p.vertex.storage[vb["gl_PointSize"]]
+	p.writesSize = vb["gl_PointSize"].used
+	p.fragCoord = p.fragment.storage[fb["gl_FragCoord"]]
+	p.frontFacing = p.fragment.storage[fb["gl_FrontFacing"]]
+	p.pointCoord = p.fragment.storage[fb["gl_PointCoord"]]
+	p.fragColor = p.fragment.storage[fb["gl_FragColor"]]
+	if fb["gl_FragData"].used {
+		p.fragColor = p.fragment.storage[fb["gl_FragData"]][:4]
+	}
+	return p, nil
+}
+
+// Loads the values of the active uniforms for the next invocations.
+// value returns the values set for an active uniform, given its name
+// without a trailing "[0]", as []float32 or []int32 holding every element
+// of an array, or nil if none were set.
+func (p *Program) LoadUniforms(value func(name string) interface{}) {
+	for _, u := range p.uniforms {
+		for _, mem := range u.mems {
+			clear(mem)
+			switch v := value(u.name).(type) {
+			case []float32:
+				for i := range mem[:min(len(mem), len(v))] {
+					mem[i] = convert(v[i], tFloat, u.basic)
+				}
+			case []int32:
+				for i := range mem[:min(len(mem), len(v))] {
+					mem[i] = convert(float32(v[i]), tInt, u.basic)
+				}
+			}
+		}
+	}
+}
+
+// Sets the depth range read through gl_DepthRange.
+func (p *Program) SetDepthRange(near, far float32) {
+	for _, mem := range p.depthRange {
+		mem[0], mem[1], mem[2] = near, far, far-near
+	}
+}
+
+// Runs the vertex shader for the values of the active attributes, in the
+// order of Attribs. It stores the varyings in varyings, which must hold
+// Varyings components, and returns the position and point size, which is
+// 1 unless the shader writes gl_PointSize.
+func (p *Program) RunVertex(attribs [][4]float32, varyings []float32, tex Textures) (position [4]float32, pointSize float32) {
+	for i, mem := range p.attribs {
+		copy(mem, attribs[i][:])
+	}
+	p.vertex.run(tex)
+	for _, v := range p.varyings {
+		copy(varyings[v.offset:], v.vertex)
+	}
+	copy(position[:], p.position)
+	if !p.writesSize {
+		return position, 1
+	}
+	return position, p.pointSize[0]
+}
+
+// Runs the fragment shader for a fragment with the given window
+// coordinates, facing, point coordinates and interpolated varyings. It
+// returns the color of the fragment, or false if it is discarded.
+func (p *Program) RunFragment(coord [4]float32, frontFacing bool, pointCoord [2]float32, varyings []float32, tex Textures) ([4]float32, bool) {
+	copy(p.fragCoord, coord[:])
+	p.frontFacing[0] = b2f(frontFacing)
+	copy(p.pointCoord, pointCoord[:])
+	for _, v := range p.varyings {
+		copy(v.frag, varyings[v.offset:])
+	}
+	var color [4]float32
+	if !p.fragment.run(tex) {
+		return color, false
+	}
+	copy(color[:], p.fragColor)
+	return color, true
+}
@@ -0,0 +1,645 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"github.com/n2d/webgl"
+)
+
+// Textures samples the textures bound to texture units for a running
+// shader.
+type Textures interface {
+	// Texture2D samples the 2D texture of a unit at texture coordinates.
+	// bias is added to the level of detail selected from the derivatives
+	// of the coordinates, or is the level of detail if lod is set.
+	Texture2D(unit int, s, t, bias float32, lod bool) [4]float32
+
+	// TextureCube samples the cube map of a unit in a direction, with a
+	// bias or level of detail as for Texture2D.
+	TextureCube(unit int, x, y, z, bias float32, lod bool) [4]float32
+}
+
+// flow is the way control leaves a statement.
+type flow int
+
+const (
+	flowNext flow = iota
+	flowBreak
+	flowContinue
+	flowReturn
+	flowDiscard
+)
+
+// discarded is raised when a function other than main discards the
+// fragment.
+type discarded struct{}
+
+// arena allocates the storage of a machine.
+type arena struct {
+	chunks [][]float32
+	free   []float32
+}
+
+func (a *arena) alloc(n int) []float32 {
+	if n > len(a.free) {
+		a.free = make([]float32, max(n, 1024))
+		a.chunks = append(a.chunks, a.free)
+	}
+	s := a.free[:n:n]
+	a.free = a.free[n:]
+	return s
+}
+
+func (a *arena) clear() {
+	for _, c := range a.chunks {
+		clear(c)
+	}
+}
+
+// machine is a shader compiled to Go closures. Every variable and every
+// intermediate value has its own storage, which is possible because GLSL
+// ES does not allow recursion.
+type machine struct {
+	stage webgl.Enum
+
+	// fixed holds the constants and the inputs, vars the other variables,
+	// which are cleared at each invocation, and temps the intermediate
+	// values.
+	fixed arena
+	vars  arena
+	temps arena
+
+	storage map[*symbol][]float32
+	codes   map[*function]*code
+
+	textures Textures
+
+	inits []func()
+	main  *code
+}
+
+// code is a compiled function.
+type code struct {
+	params [][]float32
+	ret    []float32
+	body   func() flow
+}
+
+// value is a compiled expression: eval, if set, computes it into mem.
+type value struct {
+	eval func()
+	mem  []float32
+}
+
+// Compiles a shader.
+func newMachine(s *Shader) *machine {
+	m := &machine{
+		stage:   s.Type,
+		storage: make(map[*symbol][]float32),
+		codes:   make(map[*function]*code),
+	}
+	for _, sym := range s.builtins {
+		if sym.qual == qualOutput {
+			m.storage[sym] = m.vars.alloc(sym.typ.slots())
+		} else if sym.value == nil {
+			m.storage[sym] = m.fixed.alloc(sym.typ.slots())
+		}
+	}
+	for _, sym := range s.globals {
+		switch {
+		case sym.value != nil:
+		case sym.qual == qualUniform || sym.qual == qualAttribute || sym.qual == qualVarying && s.Type == webgl.FRAGMENT_SHADER:
+			m.storage[sym] = m.fixed.alloc(sym.typ.slots())
+		default:
+			m.storage[sym] = m.vars.alloc(sym.typ.slots())
+		}
+	}
+	for _, fn := range s.functions {
+		m.code(fn)
+	}
+	for _, v := range s.inits {
+		m.inits = append(m.inits, m.initializer(v))
+	}
+	for _, fn := range s.functions {
+		m.codes[fn].body = m.stmt(fn.body, m.codes[fn])
+	}
+	m.main = m.codes[s.main]
+	return m
+}
+
+// Returns the compiled form of a function, allocating its parameters and
+// result.
+func (m *machine) code(fn *function) *code {
+	if c := m.codes[fn]; c != nil {
+		return c
+	}
+	c := &code{ret: m.vars.alloc(fn.ret.slots())}
+	for _, p := range fn.params {
+		mem := m.vars.alloc(p.typ.slots())
+		c.params = append(c.params, mem)
+		m.storage[p] = mem
+	}
+	m.codes[fn] = c
+	return c
+}
+
+// Runs the shader, returning false if it discarded the fragment.
+func (m *machine) run(tex Textures) (ok bool) {
+	m.textures = tex
+	m.vars.clear()
+	if m.stage == webgl.FRAGMENT_SHADER {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, d := r.(discarded); !d {
+					panic(r)
+				}
+				ok = false
+			}
+		}()
+	}
+	for _, init := range m.inits {
+		init()
+	}
+	return m.main.body() != flowDiscard
+}
+
+// Returns a function running the evaluations that are set.
+func sequence(evals ...func()) func() {
+	var fs []func()
+	for _, f := range evals {
+		if f != nil {
+			fs = append(fs, f)
+		}
+	}
+	switch len(fs) {
+	case 0:
+		return nil
+	case 1:
+		return fs[0]
+	case 2:
+		a, b := fs[0], fs[1]
+		return func() {
+			a()
+			b()
+		}
+	}
+	return func() {
+		for _, f := range fs {
+			f()
+		}
+	}
+}
+
+// Returns the values of a list of expressions, and a function evaluating
+// them in order.
+func (m *machine) exprs(es []expr) ([][]float32, func()) {
+	mems := make([][]float32, len(es))
+	evals := make([]func(), len(es))
+	for i, e := range es {
+		v := m.expr(e)
+		mems[i], evals[i] = v.mem, v.eval
+	}
+	return mems, sequence(evals...)
+}
+
+// Compiles an expression.
+func (m *machine) expr(e expr) value {
+	info := e.info()
+	if info.value != nil {
+		mem := m.fixed.alloc(len(info.value))
+		copy(mem, info.value)
+		return value{mem: mem}
+	}
+	switch e := e.(type) {
+	case *varRef:
+		return value{mem: m.storage[e.sym]}
+	case *fieldExpr:
+		x := m.expr(e.x)
+		offset := 0
+		for _, f := range e.x.info().typ.strct.fields[:e.field] {
+			offset += f.typ.slots()
+		}
+		return value{x.eval, x.mem[offset : offset+info.typ.slots()]}
+	case *swizzleExpr:
+		x := m.expr(e.x)
+		out := m.temps.alloc(len(e.comps))
+		comps := e.comps
+		return value{sequence(x.eval, func() {
+			for i, c := range comps {
+				out[i] = x.mem[c]
+			}
+		}), out}
+	case *indexExpr:
+		x, i := m.expr(e.x), m.expr(e.i)
+		size := info.typ.slots()
+		n := len(x.mem) / size
+		out := m.temps.alloc(size)
+		return value{sequence(x.eval, i.eval, func() {
+			k := min(max(int(i.mem[0]), 0), n-1)
+			copy(out, x.mem[k*size:])
+		}), out}
+	case *unaryExpr:
+		if e.op == "++" || e.op == "--" {
+			return m.increment(e)
+		}
+		x := m.expr(e.x)
+		out := m.temps.alloc(len(x.mem))
+		f := unaryFunc(e.op)
+		return value{sequence(x.eval, func() { f(out, x.mem) }), out}
+	case *binaryExpr:
+		return m.binary(e)
+	case *assignExpr:
+		return m.assign(e)
+	case *condExpr:
+		c, x, y := m.expr(e.c), m.expr(e.t), m.expr(e.f)
+		out := m.temps.alloc(len(x.mem))
+		return value{sequence(c.eval, func() {
+			if c.mem[0] != 0 {
+				if x.eval != nil {
+					x.eval()
+				}
+				copy(out, x.mem)
+			} else {
+				if y.eval != nil {
+					y.eval()
+				}
+				copy(out, y.mem)
+			}
+		}), out}
+	case *ctorExpr:
+		mems, eval := m.exprs(e.args)
+		types := make([]typ, len(e.args))
+		for i, a := range e.args {
+			types[i] = a.info().typ
+		}
+		out := m.temps.alloc(info.typ.slots())
+		f := constructor(info.typ, types)
+		return value{sequence(eval, func() { f(out, mems) }), out}
+	case *builtinCall:
+		mems, eval := m.exprs(e.args)
+		out := m.temps.alloc(info.typ.slots())
+		if e.fn.lookup != lookupNone {
+			return value{sequence(eval, m.lookup(e.fn, out, mems)), out}
+		}
+		f := e.fn.eval
+		return value{sequence(eval, func() { f(out, mems) }), out}
+	case *callExpr:
+		return m.call(e)
+	}
+	panic("glsl: unexpected expression")
+}
+
+// Compiles a binary operator.
+func (m *machine) binary(e *binaryExpr) value {
+	x, y := m.expr(e.x), m.expr(e.y)
+	switch e.op {
+	case ",":
+		return value{sequence(x.eval, y.eval), y.mem}
+	case "&&", "||":
+		out := m.temps.alloc(1)
+		and := e.op == "&&"
+		return value{sequence(x.eval, func() {
+			out[0] = x.mem[0]
+			if (out[0] != 0) == and {
+				if y.eval != nil {
+					y.eval()
+				}
+				out[0] = y.mem[0]
+			}
+		}), out}
+	}
+	out := m.temps.alloc(e.typ.slots())
+	f := binaryFunc(e.op, e.x.info().typ, e.y.info().typ)
+	return value{sequence(x.eval, y.eval, func() { f(out, x.mem, y.mem) }), out}
+}
+
+// lvalue is a compiled expression that can be assigned to: indices
+// returns the offsets of its components in mem.
+type lvalue struct {
+	mem     []float32
+	indices func() []int
+}
+
+func (l lvalue) load(dst []float32, indices []int) {
+	for i, j := range indices {
+		dst[i] = l.mem[j]
+	}
+}
+
+func (l lvalue) store(src []float32, indices []int) {
+	for i, j := range indices {
+		l.mem[j] = src[i]
+	}
+}
+
+// Returns n offsets from start.
+func offsets(start, n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = start + i
+	}
+	return s
+}
+
+// Compiles an expression that is assigned to.
+func (m *machine) lvalue(e expr) lvalue {
+	switch e := e.(type) {
+	case *varRef:
+		mem := m.storage[e.sym]
+		indices := offsets(0, len(mem))
+		return lvalue{mem, func() []int { return indices }}
+	case *fieldExpr:
+		x := m.lvalue(e.x)
+		offset := 0
+		for _, f := range e.x.info().typ.strct.fields[:e.field] {
+			offset += f.typ.slots()
+		}
+		n := e.typ.slots()
+		return lvalue{x.mem, func() []int { return x.indices()[offset : offset+n] }}
+	case *swizzleExpr:
+		x := m.lvalue(e.x)
+		comps := e.comps
+		buf := make([]int, len(comps))
+		return lvalue{x.mem, func() []int {
+			base := x.indices()
+			for i, c := range comps {
+				buf[i] = base[c]
+			}
+			return buf
+		}}
+	case *indexExpr:
+		x, i := m.lvalue(e.x), m.expr(e.i)
+		size := e.typ.slots()
+		eval := i.eval
+		return lvalue{x.mem, func() []int {
+			if eval != nil {
+				eval()
+			}
+			base := x.indices()
+			k := min(max(int(i.mem[0]), 0), len(base)/size-1)
+			return base[k*size : (k+1)*size]
+		}}
+	}
+	panic("glsl: unexpected l-value")
+}
+
+// Compiles an assignment.
+func (m *machine) assign(e *assignExpr) value {
+	l, r := m.lvalue(e.l), m.expr(e.r)
+	out := m.temps.alloc(e.typ.slots())
+	if e.op == "=" {
+		return value{func() {
+			if r.eval != nil {
+				r.eval()
+			}
+			copy(out, r.mem)
+			l.store(out, l.indices())
+		}, out}
+	}
+	cur := m.temps.alloc(len(out))
+	f := binaryFunc(e.op[:1], e.l.info().typ, e.r.info().typ)
+	return value{func() {
+		if r.eval != nil {
+			r.eval()
+		}
+		indices := l.indices()
+		l.load(cur, indices)
+		f(out, cur, r.mem)
+		l.store(out, indices)
+	}, out}
+}
+
+// Compiles an increment or decrement.
+func (m *machine) increment(e *unaryExpr) value {
+	l := m.lvalue(e.x)
+	out := m.temps.alloc(e.typ.slots())
+	delta := float32(1)
+	if e.op == "--" {
+		delta = -1
+	}
+	postfix := e.postfix
+	return value{func() {
+		indices := l.indices()
+		for i, j := range indices {
+			old := l.mem[j]
+			l.mem[j] = old + delta
+			if postfix {
+				out[i] = old
+			} else {
+				out[i] = old + delta
+			}
+		}
+	}, out}
+}
+
+// Compiles a call of a user defined function.
+func (m *machine) call(e *callExpr) value {
+	c := m.code(e.fn)
+	out := m.temps.alloc(len(c.ret))
+	type arg struct {
+		in      value
+		out     *lvalue
+		inout   bool
+		tmp     []float32
+		indices []int
+	}
+	args := make([]*arg, len(e.args))
+	for i, param := range e.fn.params {
+		a := &arg{tmp: m.temps.alloc(len(c.params[i]))}
+		switch param.qual {
+		case qualOut, qualInOut:
+			l := m.lvalue(e.args[i])
+			a.out, a.inout = &l, param.qual == qualInOut
+		default:
+			a.in = m.expr(e.args[i])
+		}
+		args[i] = a
+	}
+	return value{func() {
+		for _, a := range args {
+			if a.out != nil {
+				a.indices = a.out.indices()
+				if a.inout {
+					a.out.load(a.tmp, a.indices)
+				}
+				continue
+			}
+			if a.in.eval != nil {
+				a.in.eval()
+			}
+			copy(a.tmp, a.in.mem)
+		}
+		for i, a := range args {
+			copy(c.params[i], a.tmp)
+		}
+		if c.body() == flowDiscard {
+			panic(discarded{})
+		}
+		copy(out, c.ret)
+		for i, a := range args {
+			if a.out != nil {
+				a.out.store(c.params[i], a.indices)
+			}
+		}
+	}, out}
+}
+
+// Returns a function running a texture lookup.
+func (m *machine) lookup(b *builtin, out []float32, args [][]float32) func() {
+	explicit := b.lod || m.stage == webgl.VERTEX_SHADER
+	store := func(c [4]float32) {
+		copy(out, c[:])
+	}
+	bias := func() float32 {
+		if len(args) == 3 {
+			return args[2][0]
+		}
+		return 0
+	}
+	switch b.lookup {
+	case lookupCube:
+		return func() {
+			d := args[1]
+			store(m.textures.TextureCube(int(args[0][0]), d[0], d[1], d[2], bias(), explicit))
+		}
+	case lookup2DProj3, lookup2DProj4:
+		q := 2
+		if b.lookup == lookup2DProj4 {
+			q = 3
+		}
+		return func() {
+			c := args[1]
+			store(m.textures.Texture2D(int(args[0][0]), c[0]/c[q], c[1]/c[q], bias(), explicit))
+		}
+	}
+	return func() {
+		c := args[1]
+		store(m.textures.Texture2D(int(args[0][0]), c[0], c[1], bias(), explicit))
+	}
+}
+
+// Compiles the initializer of a global variable.
+func (m *machine) initializer(v *varDecl) func() {
+	mem := m.storage[v.sym]
+	x := m.expr(v.init)
+	return func() {
+		if x.eval != nil {
+			x.eval()
+		}
+		copy(mem, x.mem)
+	}
+}
+
+// Compiles a statement of the body of a function.
+func (m *machine) stmt(s stmt, fn *code) func() flow {
+	switch s := s.(type) {
+	case *block:
+		var stmts []func() flow
+		for _, t := range s.stmts {
+			stmts = append(stmts, m.stmt(t, fn))
+		}
+		return func() flow {
+			for _, f := range stmts {
+				if r := f(); r != flowNext {
+					return r
+				}
+			}
+			return flowNext
+		}
+	case *declStmt:
+		var evals []func()
+		for _, v := range s.vars {
+			if v.sym.value != nil {
+				continue
+			}
+			m.storage[v.sym] = m.vars.alloc(v.sym.typ.slots())
+			if v.init != nil {
+				evals = append(evals, m.initializer(v))
+			}
+		}
+		return statement(sequence(evals...))
+	case *exprStmt:
+		return statement(m.expr(s.x).eval)
+	case *ifStmt:
+		c := m.expr(s.cond)
+		then, els := m.stmt(s.then, fn), m.stmt(s.els, fn)
+		return func() flow {
+			if c.eval != nil {
+				c.eval()
+			}
+			if c.mem[0] != 0 {
+				return then()
+			}
+			return els()
+		}
+	case *forStmt:
+		init := m.stmt(s.init, fn)
+		var cond value
+		if s.cond != nil {
+			cond = m.expr(s.cond)
+		}
+		var post func()
+		if s.post != nil {
+			post = m.expr(s.post).eval
+		}
+		body := m.stmt(s.body, fn)
+		return func() flow {
+			init()
+			for {
+				if cond.mem != nil {
+					if cond.eval != nil {
+						cond.eval()
+					}
+					if cond.mem[0] == 0 {
+						return flowNext
+					}
+				}
+				switch body() {
+				case flowBreak:
+					return flowNext
+				case flowReturn:
+					return flowReturn
+				case flowDiscard:
+					return flowDiscard
+				}
+				if post != nil {
+					post()
+				}
+			}
+		}
+	case *jumpStmt:
+		switch s.kind {
+		case "break":
+			return func() flow { return flowBreak }
+		case "continue":
+			return func() flow { return flowContinue }
+		case "discard":
+			return func() flow { return flowDiscard }
+		}
+		if s.x == nil {
+			return func() flow { return flowReturn }
+		}
+		x := m.expr(s.x)
+		return func() flow {
+			if x.eval != nil {
+				x.eval()
+			}
+			copy(fn.ret, x.mem)
+			return flowReturn
+		}
+	}
+	return func() flow { return flowNext }
+}
+
+// Returns a statement running an evaluation, which may be nil.
+func statement(eval func()) func() flow {
+	if eval == nil {
+		return func() flow { return flowNext }
+	}
+	return func() flow {
+		eval()
+		return flowNext
+	}
+}
@@ -0,0 +1,938 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"strings"
+
+	"github.com/n2d/webgl"
+)
+
+// keywords are the words that cannot be used as names.
+var keywords = map[string]bool{
+	"attribute": true, "const": true, "uniform": true, "varying": true,
+	"break": true, "continue": true, "do": true, "for": true, "while": true,
+	"if": true, "else": true, "in": true, "out": true, "inout": true,
+	"true": true, "false": true, "lowp": true, "mediump": true, "highp": true,
+	"precision": true, "invariant": true, "discard": true, "return": true,
+	"struct": true,
+}
+
+// reserved are the words reserved for future use.
+var reserved = map[string]bool{
+	"asm": true, "class": true, "union": true, "enum": true, "typedef": true,
+	"template": true, "this": true, "packed": true, "goto": true, "switch": true,
+	"default": true, "inline": true, "noinline": true, "volatile": true,
+	"public": true, "static": true, "extern": true, "external": true,
+	"interface": true, "flat": true, "long": true, "short": true, "double": true,
+	"half": true, "fixed": true, "unsigned": true, "superp": true, "input": true,
+	"output": true, "hvec2": true, "hvec3": true, "hvec4": true, "dvec2": true,
+	"dvec3": true, "dvec4": true, "fvec2": true, "fvec3": true, "fvec4": true,
+	"sampler1D": true, "sampler3D": true, "sampler1DShadow": true,
+	"sampler2DShadow": true, "sampler2DRect": true, "sampler3DRect": true,
+	"sampler2DRectShadow": true, "sizeof": true, "cast": true,
+	"namespace": true, "using": true,
+}
+
+// bailout is raised to stop parsing at a syntax error.
+type bailout struct{}
+
+// scope holds the names declared in a block.
+type scope struct {
+	syms map[string]*symbol
+
+	// precisions are the basic types given a default precision in the
+	// scope.
+	precisions map[basic]bool
+}
+
+// parser parses and checks a preprocessed shader.
+type parser struct {
+	d     *diagnostics
+	s     *Shader
+	toks  []token
+	pos   int
+	stage webgl.Enum
+
+	scopes []*scope
+
+	// fn is the function being parsed, and loops the depth of loops in
+	// it.
+	fn    *function
+	loops int
+
+	// called holds the line of the first call of each user function.
+	called map[*function]int
+}
+
+func newParser(d *diagnostics, s *Shader, toks []token) *parser {
+	p := &parser{
+		d:      d,
+		s:      s,
+		toks:   toks,
+		stage:  s.Type,
+		called: make(map[*function]int),
+	}
+	p.push()
+	p.declareBuiltins()
+	p.push()
+	return p
+}
+
+// Declares the built-in variables and constants in the outermost scope.
+func (p *parser) declareBuiltins() {
+	sc := p.scopes[0]
+	sc.precisions[tInt] = true
+	sc.precisions[tSampler2D] = true
+	sc.precisions[tSamplerCube] = true
+	if p.stage == webgl.VERTEX_SHADER {
+		sc.precisions[tFloat] = true
+	}
+	p.s.builtins = make(map[string]*symbol)
+	add := func(name string, t typ, q qualifier) *symbol {
+		sym := &symbol{name: name, kind: symVar, typ: t, qual: q, builtin: true}
+		sc.syms[name] = sym
+		p.s.builtins[name] = sym
+		return sym
+	}
+	for name, v := range map[string]int{
+		"gl_MaxVertexAttribs":             maxVertexAttribs,
+		"gl_MaxVertexUniformVectors":      maxVertexUniformVectors,
+		"gl_MaxVaryingVectors":            maxVaryingVectors,
+		"gl_MaxVertexTextureImageUnits":   maxVertexTextureUnits,
+		"gl_MaxCombinedTextureImageUnits": maxCombinedTextureUnits,
+		"gl_MaxTextureImageUnits":         maxTextureUnits,
+		"gl_MaxFragmentUniformVectors":    maxFragmentUniformVectors,
+		"gl_MaxDrawBuffers":               maxDrawBuffers,
+	} {
+		add(name, intType, qualConst).value = []float32{float32(v)}
+	}
+	depthRange := &structType{name: "gl_DepthRangeParameters", fields: []field{
+		{"near", floatType}, {"far", floatType}, {"diff", floatType},
+	}}
+	sc.syms[depthRange.name] = &symbol{name: depthRange.name, kind: symStruct, typ: typ{basic: tStruct, size: 1, strct: depthRange}, builtin: true}
+	add("gl_DepthRange", sc.syms[depthRange.name].typ, qualUniform)
+	vec4 := vecType(tFloat, 4)
+	if p.stage == webgl.VERTEX_SHADER {
+		add("gl_Position", vec4, qualOutput)
+		add("gl_PointSize", floatType, qualOutput)
+		return
+	}
+	add("gl_FragCoord", vec4, qualInput)
+	add("gl_FrontFacing", boolType, qualInput)
+	add("gl_PointCoord", vecType(tFloat, 2), qualInput)
+	add("gl_FragColor", vec4, qualOutput)
+	fragData := vec4
+	fragData.array = maxDrawBuffers
+	add("gl_FragData", fragData, qualOutput)
+}
+
+func (p *parser) push() {
+	p.scopes = append(p.scopes, &scope{syms: make(map[string]*symbol), precisions: make(map[basic]bool)})
+}
+
+func (p *parser) pop() {
+	p.scopes = p.scopes[:len(p.scopes)-1]
+}
+
+// Returns the innermost declaration of a name, or nil.
+func (p *parser) lookup(name string) *symbol {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if s := p.scopes[i].syms[name]; s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// Declares a variable or structure name in the innermost scope.
+func (p *parser) declare(sym *symbol) {
+	sc := p.scopes[len(p.scopes)-1]
+	if sc.syms[sym.name] != nil {
+		p.errorf(sym.line, sym.name, "redefinition")
+		return
+	}
+	sc.syms[sym.name] = sym
+}
+
+// Reports an error if a declared name is reserved.
+func (p *parser) checkName(name string, line int) {
+	switch {
+	case strings.HasPrefix(name, "gl_"), strings.HasPrefix(name, "webgl_"), strings.HasPrefix(name, "_webgl_"):
+		p.errorf(line, name, "reserved built-in name")
+	case len(name) > 256:
+		p.errorf(line, name, "Identifier name is too long")
+	}
+}
+
+// Returns whether a basic type has a default precision in scope.
+func (p *parser) hasPrecision(b basic) bool {
+	for _, sc := range p.scopes {
+		if sc.precisions[b] {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports an error if a float type is declared without a precision where
+// there is no default one.
+func (p *parser) checkPrecision(t typ, precision string, line int) {
+	if precision == "" && t.basic == tFloat && !p.hasPrecision(tFloat) {
+		p.errorf(line, "", "No precision specified for (float)")
+	}
+}
+
+func (p *parser) errorf(line int, tok, format string, args ...interface{}) {
+	p.d.errorf(line, tok, format, args...)
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) peekAt(k int) token {
+	if p.pos+k >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.pos+k]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// Consumes the next token if its text is s.
+func (p *parser) accept(s string) bool {
+	if t := p.peek(); t.text == s && t.kind != tokEOF {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) token {
+	t := p.next()
+	if t.text != s || t.kind == tokEOF {
+		p.syntaxError(t)
+	}
+	return t
+}
+
+// Reports a syntax error and stops parsing.
+func (p *parser) syntaxError(t token) {
+	p.errorf(t.line, t.text, "syntax error")
+	panic(bailout{})
+}
+
+// Reads a name.
+func (p *parser) ident() token {
+	t := p.next()
+	if _, ok := typeNames[t.text]; t.kind != tokIdent || keywords[t.text] || ok {
+		p.syntaxError(t)
+	}
+	if reserved[t.text] {
+		p.errorf(t.line, t.text, "Illegal use of reserved word")
+	}
+	return t
+}
+
+// Parses the shader.
+func (p *parser) parse() {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+		}
+	}()
+	for p.peek().kind != tokEOF {
+		p.external()
+	}
+	p.finish()
+}
+
+// Checks the shader as a whole once it has been parsed.
+func (p *parser) finish() {
+	last := p.peek().line
+	if sym := p.scopes[1].syms["main"]; sym != nil && sym.kind == symFunc {
+		for _, fn := range sym.funcs {
+			if fn.body != nil && len(fn.params) == 0 {
+				p.s.main = fn
+			}
+		}
+	}
+	if p.s.main == nil {
+		p.errorf(last, "", "Missing main()")
+	}
+	for fn, line := range p.called {
+		if fn.body == nil {
+			p.errorf(line, fn.name, "Function is called but not defined")
+		}
+	}
+	// Recursion is not allowed.
+	state := make(map[*function]int)
+	var visit func(fn *function, path []string) bool
+	visit = func(fn *function, path []string) bool {
+		path = append(path, fn.name)
+		switch state[fn] {
+		case 1:
+			p.errorf(fn.line, fn.name, "Recursive function call in the following call chain: %s", strings.Join(path, " -> "))
+			return true
+		case 2:
+			return false
+		}
+		state[fn] = 1
+		for _, g := range fn.calls {
+			if visit(g, path) {
+				return true
+			}
+		}
+		state[fn] = 2
+		return false
+	}
+	for _, fn := range p.s.functions {
+		if visit(fn, nil) {
+			break
+		}
+	}
+	if p.stage == webgl.FRAGMENT_SHADER && p.s.builtins["gl_FragColor"].used && p.s.builtins["gl_FragData"].used {
+		p.errorf(last, "", "cannot use both gl_FragData and gl_FragColor")
+	}
+}
+
+// declType is the qualified type starting a declaration.
+type declType struct {
+	line      int
+	qual      qualifier
+	invariant bool
+	precision string
+	typ       typ
+}
+
+// Parses a global declaration or function definition.
+func (p *parser) external() {
+	switch t := p.peek(); {
+	case t.text == ";":
+		p.next()
+		return
+	case t.text == "precision":
+		p.precisionStatement()
+		return
+	case t.text == "invariant" && p.peekAt(1).text != "varying":
+		p.invariant()
+		return
+	}
+	d := p.declType()
+	if p.accept(";") {
+		return
+	}
+	name := p.ident()
+	if p.peek().text == "(" {
+		p.function(d, name)
+		return
+	}
+	for _, v := range p.declarators(d, name, true) {
+		p.s.globals = append(p.s.globals, v.sym)
+		if v.init != nil && v.sym.value == nil {
+			p.s.inits = append(p.s.inits, v)
+		}
+	}
+	p.expect(";")
+}
+
+// Parses a precision statement.
+func (p *parser) precisionStatement() {
+	line := p.expect("precision").line
+	if p.precisionQualifier() == "" {
+		p.syntaxError(p.peek())
+	}
+	t := p.typeSpecifier()
+	switch t {
+	case floatType, intType, sampler2DType, samplerCubeType:
+		p.scopes[len(p.scopes)-1].precisions[t.basic] = true
+	default:
+		p.errorf(line, "precision", "illegal type argument for default precision qualifier")
+	}
+	p.expect(";")
+}
+
+// Parses an invariant redeclaration of outputs.
+func (p *parser) invariant() {
+	p.expect("invariant")
+	for {
+		t := p.ident()
+		sym := p.lookup(t.text)
+		switch {
+		case sym == nil:
+			p.errorf(t.line, t.text, "undeclared identifier")
+		case sym.qual != qualVarying && sym.qual != qualOutput && sym.qual != qualInput:
+			p.errorf(t.line, t.text, "can only declare a varying or an output as invariant")
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(";")
+}
+
+// Parses an optional precision qualifier.
+func (p *parser) precisionQualifier() string {
+	switch t := p.peek().text; t {
+	case "highp", "mediump", "lowp":
+		p.next()
+		return t
+	}
+	return ""
+}
+
+// Parses the qualifiers and the type of a declaration.
+func (p *parser) declType() declType {
+	d := declType{line: p.peek().line}
+	d.invariant = p.accept("invariant")
+	switch p.peek().text {
+	case "const":
+		d.qual = qualConst
+	case "attribute":
+		d.qual = qualAttribute
+	case "uniform":
+		d.qual = qualUniform
+	case "varying":
+		d.qual = qualVarying
+	}
+	if d.qual != qualNone {
+		p.next()
+	}
+	d.precision = p.precisionQualifier()
+	d.typ = p.typeSpecifier()
+	return d
+}
+
+// Parses a type name or a structure definition.
+func (p *parser) typeSpecifier() typ {
+	t := p.peek()
+	if t.text == "struct" {
+		return p.structSpecifier()
+	}
+	if ty, ok := typeNames[t.text]; ok {
+		p.next()
+		return ty
+	}
+	if sym := p.lookup(t.text); sym != nil && sym.kind == symStruct {
+		p.next()
+		return sym.typ
+	}
+	p.syntaxError(t)
+	return errorType
+}
+
+// Returns whether the next tokens start a declaration rather than an
+// expression.
+func (p *parser) isDeclaration() bool {
+	t := p.peek()
+	switch t.text {
+	case "const", "precision", "invariant", "highp", "mediump", "lowp", "struct", "attribute", "uniform", "varying":
+		return true
+	}
+	if _, ok := typeNames[t.text]; !ok {
+		if sym := p.lookup(t.text); sym == nil || sym.kind != symStruct {
+			return false
+		}
+	}
+	return p.peekAt(1).kind == tokIdent
+}
+
+// Parses a structure definition.
+func (p *parser) structSpecifier() typ {
+	line := p.expect("struct").line
+	st := &structType{}
+	if p.peek().text != "{" {
+		st.name = p.ident().text
+	}
+	p.expect("{")
+	for !p.accept("}") {
+		precision := p.precisionQualifier()
+		ft := p.typeSpecifier()
+		p.checkPrecision(ft, precision, p.peek().line)
+		for {
+			name := p.ident()
+			t := ft
+			if p.accept("[") {
+				t.array = p.arraySize()
+			}
+			if t.basic == tVoid {
+				p.errorf(name.line, name.text, "illegal use of type 'void'")
+			}
+			for _, f := range st.fields {
+				if f.name == name.text {
+					p.errorf(name.line, name.text, "duplicate field name in structure")
+				}
+			}
+			st.fields = append(st.fields, field{name.text, t})
+			if !p.accept(",") {
+				break
+			}
+		}
+		p.expect(";")
+	}
+	t := typ{basic: tStruct, size: 1, strct: st}
+	if len(st.fields) == 0 {
+		p.syntaxError(p.toks[p.pos-1])
+	}
+	if st.name != "" {
+		p.checkName(st.name, line)
+		p.declare(&symbol{name: st.name, kind: symStruct, line: line, typ: t})
+	}
+	return t
+}
+
+// Parses the size of an array after its opening bracket.
+func (p *parser) arraySize() int {
+	e := p.conditional()
+	p.expect("]")
+	v := e.info()
+	if v.typ.basic == tError {
+		return 1
+	}
+	if v.value == nil || v.typ != intType {
+		p.errorf(v.line, "", "array size must be a constant integer expression")
+		return 1
+	}
+	if n := int(v.value[0]); n > 0 {
+		return n
+	}
+	p.errorf(v.line, "", "array size must be a positive integer")
+	return 1
+}
+
+// Parses the declarators of a variable declaration after its first name.
+func (p *parser) declarators(d declType, name token, global bool) []*varDecl {
+	var decls []*varDecl
+	for {
+		t := d.typ
+		if p.accept("[") {
+			t.array = p.arraySize()
+		}
+		sym := &symbol{name: name.text, kind: symVar, line: name.line, typ: t, qual: d.qual}
+		p.checkVariable(d, sym, global)
+		v := &varDecl{sym: sym}
+		if tok := p.peek(); p.accept("=") {
+			v.init = p.assignment()
+			p.checkInit(sym, v.init, tok.line)
+		} else if d.qual == qualConst {
+			p.errorf(name.line, name.text, "variables with qualifier 'const' must be initialized")
+		}
+		p.declare(sym)
+		decls = append(decls, v)
+		if !p.accept(",") {
+			return decls
+		}
+		name = p.ident()
+	}
+}
+
+// Checks the type and qualifiers of a declared variable.
+func (p *parser) checkVariable(d declType, sym *symbol, global bool) {
+	p.checkName(sym.name, sym.line)
+	t := sym.typ
+	if t.basic == tVoid {
+		p.errorf(sym.line, sym.name, "illegal use of type 'void'")
+		return
+	}
+	var name string
+	switch d.qual {
+	case qualAttribute:
+		name = "attribute"
+	case qualUniform:
+		name = "uniform"
+	case qualVarying:
+		name = "varying"
+	}
+	switch {
+	case name != "" && !global:
+		p.errorf(sym.line, name, "only allowed at global scope")
+	case d.qual == qualAttribute && p.stage != webgl.VERTEX_SHADER:
+		p.errorf(sym.line, name, "supported in vertex shaders only")
+	case d.qual == qualAttribute && t.isArray():
+		p.errorf(sym.line, name, "cannot declare arrays of this qualifier")
+	case (d.qual == qualAttribute || d.qual == qualVarying) && t.basic == tStruct:
+		p.errorf(sym.line, name, "cannot be used with a structure")
+	case (d.qual == qualAttribute || d.qual == qualVarying) && t.basic != tFloat:
+		p.errorf(sym.line, name, "cannot be bool or int")
+	case d.qual != qualUniform && t.hasSampler():
+		p.errorf(sym.line, sym.name, "samplers must be uniform")
+	case d.invariant && d.qual != qualVarying:
+		p.errorf(sym.line, "invariant", "can only declare a varying as invariant")
+	}
+	p.checkPrecision(t, d.precision, sym.line)
+}
+
+// Checks the initializer of a variable.
+func (p *parser) checkInit(sym *symbol, init expr, line int) {
+	v := init.info()
+	switch {
+	case v.typ.basic == tError:
+	case sym.qual == qualAttribute || sym.qual == qualUniform || sym.qual == qualVarying:
+		p.errorf(line, "=", "cannot initialize this type of qualifier")
+	case sym.typ.isArray():
+		p.errorf(line, "=", "cannot initialize an array")
+	case v.typ != sym.typ:
+		p.errorf(line, "=", "cannot convert from '%s' to '%s'", v.typ, sym.typ)
+	case sym.qual == qualConst && v.value == nil:
+		p.errorf(line, "=", "assigning non-constant to 'const %s'", sym.typ)
+	case sym.qual == qualConst:
+		sym.value = v.value
+	}
+}
+
+// Parses a function prototype or definition after its name.
+func (p *parser) function(d declType, name token) {
+	if d.qual != qualNone || d.invariant {
+		p.errorf(name.line, name.text, "no qualifiers allowed for function return")
+	}
+	if d.typ.basic != tVoid {
+		p.checkPrecision(d.typ, d.precision, name.line)
+	}
+	fn := &function{name: name.text, line: name.line, ret: d.typ}
+	p.expect("(")
+	p.push()
+	defer p.pop()
+	if p.peek().text == "void" && p.peekAt(1).text == ")" {
+		p.next()
+	}
+	for p.peek().text != ")" {
+		fn.params = append(fn.params, p.parameter())
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")")
+	if len(builtins[fn.name]) > 0 {
+		p.errorf(name.line, fn.name, "Name of a built-in function cannot be redeclared as function")
+	}
+	if fn.name == "main" {
+		if len(fn.params) > 0 {
+			p.errorf(name.line, fn.name, "function cannot take any parameter(s)")
+		}
+		if fn.ret != voidType {
+			p.errorf(name.line, fn.name, "main function cannot return a value")
+		}
+	}
+	decl := p.declareFunction(fn)
+	if p.accept(";") {
+		return
+	}
+	if decl.body != nil {
+		p.errorf(name.line, fn.name, "function already has a body")
+	}
+	decl.params = fn.params
+	p.fn = decl
+	decl.body = &block{}
+	decl.body.stmts = p.statements()
+	p.fn = nil
+	p.s.functions = append(p.s.functions, decl)
+}
+
+// Parses a function parameter.
+func (p *parser) parameter() *symbol {
+	isConst := p.accept("const")
+	q := qualIn
+	switch p.peek().text {
+	case "in":
+		p.next()
+	case "out":
+		p.next()
+		q = qualOut
+	case "inout":
+		p.next()
+		q = qualInOut
+	}
+	line := p.peek().line
+	if isConst {
+		if q != qualIn {
+			p.errorf(line, "const", "qualifier not allowed with out or inout")
+		}
+		q = qualConstIn
+	}
+	precision := p.precisionQualifier()
+	t := p.typeSpecifier()
+	sym := &symbol{kind: symVar, line: line, typ: t, qual: q}
+	if t.basic == tVoid {
+		p.errorf(line, "void", "illegal use of type 'void'")
+	}
+	p.checkPrecision(t, precision, line)
+	if p.peek().kind == tokIdent {
+		name := p.ident()
+		sym.name = name.text
+		if p.accept("[") {
+			sym.typ.array = p.arraySize()
+		}
+		p.checkName(sym.name, line)
+		p.declare(sym)
+	}
+	if (q == qualOut || q == qualInOut) && sym.typ.hasSampler() {
+		p.errorf(line, sym.name, "samplers cannot be output parameters")
+	}
+	return sym
+}
+
+// Declares a function in the global scope, returning its earlier
+// declaration with the same parameters if there is one.
+func (p *parser) declareFunction(fn *function) *function {
+	sc := p.scopes[1]
+	sym := sc.syms[fn.name]
+	if sym == nil {
+		sym = &symbol{name: fn.name, kind: symFunc, line: fn.line}
+		sc.syms[fn.name] = sym
+	}
+	if sym.kind != symFunc {
+		p.errorf(fn.line, fn.name, "redefinition")
+		return fn
+	}
+	for _, g := range sym.funcs {
+		if !g.sameParams(fn) {
+			continue
+		}
+		if g.ret != fn.ret {
+			p.errorf(fn.line, fn.name, "overloaded functions must have the same return type")
+		}
+		for i := range g.params {
+			if g.params[i].qual != fn.params[i].qual {
+				p.errorf(fn.line, fn.name, "function must have the same parameter qualifiers in re-declaration")
+				break
+			}
+		}
+		return g
+	}
+	sym.funcs = append(sym.funcs, fn)
+	return fn
+}
+
+// Parses statements up to a closing brace.
+func (p *parser) statements() []stmt {
+	p.expect("{")
+	var stmts []stmt
+	for !p.accept("}") {
+		if p.peek().kind == tokEOF {
+			p.syntaxError(p.peek())
+		}
+		if s := p.statement(); s != nil {
+			stmts = append(stmts, s)
+		}
+	}
+	return stmts
+}
+
+// Parses a statement in a new scope.
+func (p *parser) scoped() stmt {
+	p.push()
+	defer p.pop()
+	return p.statement()
+}
+
+// Checks that a condition is a boolean.
+func (p *parser) checkCondition(c expr, tok string) {
+	if t := c.info().typ; t.basic != tError && t != boolType {
+		p.errorf(c.info().line, tok, "boolean expression expected")
+	}
+}
+
+func (p *parser) statement() stmt {
+	t := p.peek()
+	switch t.text {
+	case "{":
+		p.push()
+		defer p.pop()
+		return &block{stmts: p.statements()}
+	case ";":
+		p.next()
+		return nil
+	case "if":
+		p.next()
+		p.expect("(")
+		s := &ifStmt{cond: p.expression()}
+		p.expect(")")
+		p.checkCondition(s.cond, "if")
+		s.then = p.scoped()
+		if p.accept("else") {
+			s.els = p.scoped()
+		}
+		return s
+	case "for":
+		return p.forStatement()
+	case "while", "do":
+		p.errorf(t.line, t.text, "This type of loop is not allowed")
+		p.next()
+		p.loops++
+		if t.text == "do" {
+			p.scoped()
+			p.expect("while")
+		}
+		p.expect("(")
+		p.expression()
+		p.expect(")")
+		if t.text == "while" {
+			p.scoped()
+		} else {
+			p.expect(";")
+		}
+		p.loops--
+		return nil
+	case "break", "continue":
+		p.next()
+		p.expect(";")
+		if p.loops == 0 {
+			p.errorf(t.line, t.text, "%s statement only allowed in loops", t.text)
+		}
+		return &jumpStmt{kind: t.text}
+	case "discard":
+		p.next()
+		p.expect(";")
+		if p.stage != webgl.FRAGMENT_SHADER {
+			p.errorf(t.line, t.text, "discard supported in fragment shaders only")
+		}
+		return &jumpStmt{kind: t.text}
+	case "return":
+		p.next()
+		s := &jumpStmt{kind: t.text}
+		if !p.accept(";") {
+			s.x = p.expression()
+			p.expect(";")
+		}
+		switch xt := exprType(s.x); {
+		case s.x == nil && p.fn.ret != voidType:
+			p.errorf(t.line, t.text, "non-void function must return a value")
+		case s.x != nil && p.fn.ret == voidType:
+			p.errorf(t.line, t.text, "void function cannot return a value")
+		case s.x != nil && xt.basic != tError && xt != p.fn.ret:
+			p.errorf(t.line, t.text, "function return is not matching type:")
+		}
+		return s
+	}
+	if p.isDeclaration() {
+		return p.declStatement()
+	}
+	x := p.expression()
+	p.expect(";")
+	return &exprStmt{x: x}
+}
+
+// Returns the type of an expression that may be nil.
+func exprType(e expr) typ {
+	if e == nil {
+		return voidType
+	}
+	return e.info().typ
+}
+
+// Parses a declaration statement.
+func (p *parser) declStatement() stmt {
+	if p.peek().text == "precision" {
+		p.precisionStatement()
+		return nil
+	}
+	d := p.declType()
+	if p.accept(";") {
+		return nil
+	}
+	s := &declStmt{vars: p.declarators(d, p.ident(), false)}
+	p.expect(";")
+	return s
+}
+
+// Parses a for loop, checking it has the form required by appendix A of
+// the specification.
+func (p *parser) forStatement() stmt {
+	line := p.expect("for").line
+	p.expect("(")
+	p.push()
+	defer p.pop()
+	s := &forStmt{}
+	switch {
+	case p.accept(";"):
+	case p.isDeclaration():
+		s.init = p.declStatement()
+	default:
+		s.init = &exprStmt{x: p.expression()}
+		p.expect(";")
+	}
+	if !p.accept(";") {
+		s.cond = p.expression()
+		p.expect(";")
+		p.checkCondition(s.cond, "for")
+	}
+	if p.peek().text != ")" {
+		s.post = p.expression()
+	}
+	p.expect(")")
+	index := p.checkLoop(s, line)
+	if index != nil {
+		index.loopIndex = true
+	}
+	p.loops++
+	s.body = p.scoped()
+	p.loops--
+	if index != nil {
+		index.loopIndex = false
+	}
+	return s
+}
+
+// relational are the comparison operators.
+var relational = map[string]bool{"<": true, ">": true, "<=": true, ">=": true, "==": true, "!=": true}
+
+// Checks the header of a for loop, returning its loop index.
+func (p *parser) checkLoop(s *forStmt, line int) *symbol {
+	decl, ok := s.init.(*declStmt)
+	switch {
+	case s.init == nil:
+		p.errorf(line, "for", "Missing init declaration")
+		return nil
+	case !ok || len(decl.vars) != 1 || decl.vars[0].init == nil:
+		p.errorf(line, "for", "Invalid init declaration")
+		return nil
+	}
+	v := decl.vars[0]
+	index := v.sym
+	if index.typ != intType && index.typ != floatType {
+		p.errorf(line, index.name, "Invalid type for loop index")
+		return nil
+	}
+	if v.init.info().value == nil {
+		p.errorf(line, index.name, "Loop index cannot be initialized with non-constant expression")
+		return nil
+	}
+	isIndex := func(e expr) bool {
+		r, ok := e.(*varRef)
+		return ok && r.sym == index
+	}
+	c, ok := s.cond.(*binaryExpr)
+	switch {
+	case s.cond == nil:
+		p.errorf(line, "for", "Missing condition")
+		return nil
+	case !ok || !isIndex(c.x) || c.y.info().value == nil || !relational[c.op]:
+		p.errorf(line, "for", "Invalid condition")
+		return nil
+	}
+	valid := false
+	switch e := s.post.(type) {
+	case *unaryExpr:
+		valid = (e.op == "++" || e.op == "--") && isIndex(e.x)
+	case *assignExpr:
+		valid = (e.op == "+=" || e.op == "-=") && isIndex(e.l) && e.r.info().value != nil
+	}
+	switch {
+	case s.post == nil:
+		p.errorf(line, "for", "Missing expression")
+	case !valid:
+		p.errorf(line, "for", "Invalid expression")
+	}
+	return index
+}
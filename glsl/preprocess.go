@@ -0,0 +1,687 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"strconv"
+	"strings"
+)
+
+// tokenKind is the lexical class of a token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokFloat
+	tokPunct
+)
+
+// token is a preprocessing token.
+type token struct {
+	kind tokenKind
+	text string
+	line int
+
+	// space is set if the token follows white space on its line.
+	space bool
+}
+
+// puncts are the operators and punctuators, longest first.
+var puncts = []string{
+	"<<=", ">>=",
+	"++", "--", "<=", ">=", "==", "!=", "&&", "||", "^^", "+=", "-=", "*=", "/=",
+	"%=", "&=", "|=", "^=", "<<", ">>",
+	"+", "-", "*", "/", "%", "<", ">", "=", "!", "~", "&", "|", "^", "?", ":",
+	";", ",", ".", "(", ")", "[", "]", "{", "}", "#",
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Removes the comments of a source, keeping its line breaks.
+func stripComments(d *diagnostics, src string) string {
+	var b strings.Builder
+	line := 1
+	for i := 0; i < len(src); i++ {
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			i--
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				d.errorf(line, "", "unexpected end of file found in comment")
+				return b.String()
+			}
+			comment := src[i : i+2+end+2]
+			n := strings.Count(comment, "\n")
+			line += n
+			b.WriteByte(' ')
+			b.WriteString(strings.Repeat("\n", n))
+			i += len(comment) - 1
+		default:
+			if src[i] == '\n' {
+				line++
+			}
+			b.WriteByte(src[i])
+		}
+	}
+	return b.String()
+}
+
+// Splits a line of source into tokens.
+func tokenize(d *diagnostics, text string, line int) []token {
+	var toks []token
+	space := false
+	for i := 0; i < len(text); {
+		c := text[i]
+		if c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f' {
+			space = true
+			i++
+			continue
+		}
+		t := token{line: line, space: space}
+		space = false
+		switch {
+		case isLetter(c):
+			j := i
+			for j < len(text) && (isLetter(text[j]) || isDigit(text[j])) {
+				j++
+			}
+			t.kind, t.text = tokIdent, text[i:j]
+		case isDigit(c) || c == '.' && i+1 < len(text) && isDigit(text[i+1]):
+			j := i
+			for j < len(text) && (isLetter(text[j]) || isDigit(text[j]) || text[j] == '.' ||
+				(text[j] == '+' || text[j] == '-') && (text[j-1] == 'e' || text[j-1] == 'E') && !isHex(text[i:j])) {
+				j++
+			}
+			t.text = text[i:j]
+			t.kind = tokInt
+			if !isHex(t.text) && strings.ContainsAny(t.text, ".eE") {
+				t.kind = tokFloat
+			}
+		default:
+			for _, p := range puncts {
+				if strings.HasPrefix(text[i:], p) {
+					t.kind, t.text = tokPunct, p
+					break
+				}
+			}
+			if t.kind == tokEOF {
+				d.errorf(line, text[i:i+1], "invalid character")
+				i++
+				continue
+			}
+		}
+		toks = append(toks, t)
+		i += len(t.text)
+	}
+	return toks
+}
+
+func isHex(s string) bool {
+	return strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+}
+
+// Returns the value of an integer literal.
+func parseInt(s string) (int64, error) {
+	return strconv.ParseInt(s, 0, 64)
+}
+
+// macro is a preprocessor macro.
+type macro struct {
+	function   bool
+	params     []string
+	body       []token
+	predefined bool
+}
+
+// conditional is an open #if, #ifdef or #ifndef block.
+type conditional struct {
+	// active is set while the lines of the current branch are used, taken
+	// once any branch has been, sawElse after #else, and outer if the
+	// enclosing lines are used.
+	active  bool
+	taken   bool
+	sawElse bool
+	outer   bool
+}
+
+// preprocessor expands the directives and macros of a source.
+type preprocessor struct {
+	d      *diagnostics
+	macros map[string]*macro
+	conds  []conditional
+
+	// pending holds the tokens of the text lines since the last
+	// directive, which are expanded together so macro arguments may span
+	// lines.
+	pending []token
+	out     []token
+
+	// seen is set once anything but white space and comments has been
+	// read, for the placement rule of #version.
+	seen bool
+}
+
+// Preprocesses a source into a token sequence ending with an EOF token.
+func preprocess(d *diagnostics, src string) []token {
+	p := &preprocessor{
+		d: d,
+		macros: map[string]*macro{
+			"GL_ES":                      {body: []token{{kind: tokInt, text: "1"}}, predefined: true},
+			"GL_FRAGMENT_PRECISION_HIGH": {body: []token{{kind: tokInt, text: "1"}}, predefined: true},
+			"__VERSION__":                {body: []token{{kind: tokInt, text: "100"}}, predefined: true},
+			"__LINE__":                   {predefined: true},
+			"__FILE__":                   {body: []token{{kind: tokInt, text: "0"}}, predefined: true},
+		},
+	}
+	lines := strings.Split(stripComments(d, src), "\n")
+	delta := 0
+	for i, text := range lines {
+		line := i + 1 + delta
+		// Skipped lines are not diagnosed.
+		td := d
+		if !p.active() {
+			td = &diagnostics{}
+		}
+		toks := tokenize(td, text, line)
+		if len(toks) > 0 && toks[0].text == "#" {
+			p.flush()
+			if next, ok := p.directive(toks[1:], line); ok {
+				delta = next - (i + 2)
+			}
+			p.seen = true
+			continue
+		}
+		if len(toks) > 0 {
+			p.seen = true
+		}
+		if p.active() {
+			p.pending = append(p.pending, toks...)
+		}
+	}
+	p.flush()
+	last := len(lines) + delta
+	if len(p.conds) > 0 {
+		d.errorf(last, "#if", "unexpected end of file found in conditional block")
+	}
+	return append(p.out, token{kind: tokEOF, line: last})
+}
+
+// Returns whether the current lines are used.
+func (p *preprocessor) active() bool {
+	return len(p.conds) == 0 || p.conds[len(p.conds)-1].active
+}
+
+// Expands the pending text lines into the output.
+func (p *preprocessor) flush() {
+	p.out = append(p.out, p.expand(p.pending, nil)...)
+	p.pending = nil
+}
+
+// Handles a directive line, returning the number of the next line if the
+// directive is #line.
+func (p *preprocessor) directive(toks []token, line int) (next int, ok bool) {
+	if len(toks) == 0 {
+		return 0, false
+	}
+	name := toks[0].text
+	args := toks[1:]
+	switch name {
+	case "if", "ifdef", "ifndef":
+		c := conditional{outer: p.active()}
+		if c.outer {
+			switch name {
+			case "if":
+				c.active = p.evaluate(args, line) != 0
+			case "ifdef", "ifndef":
+				if len(args) == 0 || args[0].kind != tokIdent {
+					p.d.errorf(line, "#"+name, "invalid macro name")
+				} else {
+					_, defined := p.macros[args[0].text]
+					c.active = defined == (name == "ifdef")
+				}
+			}
+			c.taken = c.active
+		}
+		p.conds = append(p.conds, c)
+		return 0, false
+	case "elif", "else", "endif":
+		if len(p.conds) == 0 {
+			p.d.errorf(line, "#"+name, "unexpected #%s", name)
+			return 0, false
+		}
+		c := &p.conds[len(p.conds)-1]
+		if c.sawElse && name != "endif" {
+			p.d.errorf(line, "#"+name, "unexpected #%s found after #else", name)
+			return 0, false
+		}
+		switch name {
+		case "elif":
+			c.active = c.outer && !c.taken && p.evaluate(args, line) != 0
+			c.taken = c.taken || c.active
+		case "else":
+			c.sawElse = true
+			c.active = c.outer && !c.taken
+			c.taken = true
+		case "endif":
+			p.conds = p.conds[:len(p.conds)-1]
+		}
+		return 0, false
+	}
+	if !p.active() {
+		return 0, false
+	}
+	switch name {
+	case "define":
+		p.define(args, line)
+	case "undef":
+		if len(args) == 0 || args[0].kind != tokIdent {
+			p.d.errorf(line, "#undef", "invalid macro name")
+		} else if m := p.macros[args[0].text]; m != nil && m.predefined {
+			p.d.errorf(line, args[0].text, "predefined macro undefined")
+		} else {
+			delete(p.macros, args[0].text)
+		}
+	case "error":
+		var msg []string
+		for _, t := range args {
+			msg = append(msg, t.text)
+		}
+		p.d.errorf(line, "#error", "%s", strings.Join(msg, " "))
+	case "pragma":
+	case "extension":
+		p.extension(args, line)
+	case "version":
+		switch {
+		case p.seen:
+			p.d.errorf(line, "#version", "#version directive must occur before anything else, except for comments and white space")
+		case len(args) == 0 || args[0].kind != tokInt:
+			p.d.errorf(line, "#version", "invalid version number")
+		case args[0].text != "100":
+			p.d.errorf(line, args[0].text, "version number not supported")
+		}
+	case "line":
+		args = p.expand(args, nil)
+		if len(args) == 0 || len(args) > 2 || args[0].kind != tokInt {
+			p.d.errorf(line, "#line", "invalid line directive")
+			return 0, false
+		}
+		n, err := parseInt(args[0].text)
+		if err != nil {
+			p.d.errorf(line, args[0].text, "invalid line number")
+			return 0, false
+		}
+		return int(n), true
+	default:
+		p.d.errorf(line, "#"+name, "invalid directive name")
+	}
+	return 0, false
+}
+
+// Handles a #define directive.
+func (p *preprocessor) define(args []token, line int) {
+	if len(args) == 0 || args[0].kind != tokIdent {
+		p.d.errorf(line, "#define", "invalid macro name")
+		return
+	}
+	name := args[0].text
+	if old := p.macros[name]; old != nil && old.predefined {
+		p.d.errorf(line, name, "predefined macro redefined")
+		return
+	}
+	if strings.HasPrefix(name, "GL_") {
+		p.d.errorf(line, name, "macro names beginning with \"GL_\" are reserved")
+		return
+	}
+	m := &macro{}
+	body := args[1:]
+	if len(body) > 0 && body[0].text == "(" && !body[0].space {
+		m.function = true
+		i := 1
+		for ; i < len(body) && body[i].text != ")"; i++ {
+			t := body[i]
+			if t.text == "," {
+				continue
+			}
+			if t.kind != tokIdent {
+				p.d.errorf(line, t.text, "invalid macro parameter")
+				return
+			}
+			m.params = append(m.params, t.text)
+		}
+		if i == len(body) {
+			p.d.errorf(line, name, "missing ')' in macro parameter list")
+			return
+		}
+		body = body[i+1:]
+	}
+	for _, t := range body {
+		if t.text == "#" {
+			p.d.errorf(line, "#", "unexpected token in macro definition")
+			return
+		}
+	}
+	m.body = body
+	if old := p.macros[name]; old != nil && !sameMacro(old, m) {
+		p.d.errorf(line, name, "macro redefined")
+		return
+	}
+	p.macros[name] = m
+}
+
+// Returns whether two macro definitions are the same.
+func sameMacro(a, b *macro) bool {
+	if a.function != b.function || len(a.params) != len(b.params) || len(a.body) != len(b.body) {
+		return false
+	}
+	for i := range a.params {
+		if a.params[i] != b.params[i] {
+			return false
+		}
+	}
+	for i := range a.body {
+		if a.body[i].text != b.body[i].text {
+			return false
+		}
+	}
+	return true
+}
+
+// Handles an #extension directive. No extension is supported, so
+// requiring one is an error.
+func (p *preprocessor) extension(args []token, line int) {
+	if len(args) != 3 || args[0].kind != tokIdent || args[1].text != ":" || args[2].kind != tokIdent {
+		p.d.errorf(line, "#extension", "invalid extension directive")
+		return
+	}
+	name, behavior := args[0].text, args[2].text
+	switch behavior {
+	case "require", "enable":
+		if name == "all" {
+			p.d.errorf(line, name, "extension 'all' cannot have 'require' or 'enable' behavior")
+		} else if behavior == "require" {
+			p.d.errorf(line, name, "extension is not supported")
+		}
+	case "warn", "disable":
+	default:
+		p.d.errorf(line, behavior, "invalid extension behavior")
+	}
+}
+
+// Expands the macros of a token sequence, not expanding those in hidden.
+func (p *preprocessor) expand(toks []token, hidden map[string]bool) []token {
+	var out []token
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		m := p.macros[t.text]
+		if t.kind != tokIdent || m == nil || hidden[t.text] {
+			out = append(out, t)
+			continue
+		}
+		if t.text == "__LINE__" {
+			out = append(out, token{kind: tokInt, text: strconv.Itoa(t.line), line: t.line, space: t.space})
+			continue
+		}
+		inner := map[string]bool{t.text: true}
+		for name := range hidden {
+			inner[name] = true
+		}
+		body := m.body
+		if m.function {
+			if i+1 == len(toks) || toks[i+1].text != "(" {
+				out = append(out, t)
+				continue
+			}
+			args, end, ok := p.macroArgs(toks, i+2, t)
+			if !ok {
+				return out
+			}
+			i = end
+			if len(args) == 1 && len(args[0]) == 0 && len(m.params) == 0 {
+				args = nil
+			}
+			if len(args) != len(m.params) {
+				if len(args) < len(m.params) {
+					p.d.errorf(t.line, t.text, "macro has too few arguments")
+				} else {
+					p.d.errorf(t.line, t.text, "macro has too many arguments")
+				}
+				continue
+			}
+			body = nil
+			for _, b := range m.body {
+				if k := indexOf(m.params, b.text); k >= 0 && b.kind == tokIdent {
+					body = append(body, p.expand(args[k], hidden)...)
+				} else {
+					body = append(body, b)
+				}
+			}
+		}
+		expanded := make([]token, len(body))
+		for k, b := range body {
+			b.line = t.line
+			expanded[k] = b
+		}
+		out = append(out, p.expand(expanded, inner)...)
+	}
+	return out
+}
+
+// Collects the arguments of a function-like macro invocation starting
+// after its opening parenthesis, returning them and the index of the
+// closing parenthesis.
+func (p *preprocessor) macroArgs(toks []token, start int, name token) (args [][]token, end int, ok bool) {
+	depth := 0
+	var arg []token
+	for i := start; i < len(toks); i++ {
+		t := toks[i]
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			if depth == 0 {
+				return append(args, arg), i, true
+			}
+			depth--
+		case ",":
+			if depth == 0 {
+				args = append(args, arg)
+				arg = nil
+				continue
+			}
+		}
+		arg = append(arg, t)
+	}
+	p.d.errorf(name.line, name.text, "unexpected end of file found in macro invocation")
+	return nil, 0, false
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Evaluates the expression of an #if or #elif directive.
+func (p *preprocessor) evaluate(toks []token, line int) int64 {
+	// defined is resolved before macros are expanded.
+	var resolved []token
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if t.text != "defined" {
+			resolved = append(resolved, t)
+			continue
+		}
+		j := i + 1
+		paren := j < len(toks) && toks[j].text == "("
+		if paren {
+			j++
+		}
+		if j >= len(toks) || toks[j].kind != tokIdent || paren && (j+1 >= len(toks) || toks[j+1].text != ")") {
+			p.d.errorf(line, "defined", "invalid use of defined")
+			return 0
+		}
+		v := "0"
+		if _, ok := p.macros[toks[j].text]; ok {
+			v = "1"
+		}
+		resolved = append(resolved, token{kind: tokInt, text: v, line: line})
+		i = j
+		if paren {
+			i++
+		}
+	}
+	e := &ppExpr{p: p, toks: p.expand(resolved, nil), line: line, ok: true}
+	if len(e.toks) == 0 {
+		p.d.errorf(line, "#if", "no expression in conditional directive")
+		return 0
+	}
+	v := e.binary(0)
+	if e.ok && e.pos < len(e.toks) {
+		e.fail(e.toks[e.pos].text, "unexpected token after conditional expression")
+	}
+	return v
+}
+
+// ppExpr evaluates a preprocessor conditional expression.
+type ppExpr struct {
+	p    *preprocessor
+	toks []token
+	pos  int
+	line int
+	ok   bool
+}
+
+// ppOps are the binary operators of conditional expressions by
+// precedence, lowest first.
+var ppOps = [][]string{
+	{"||"}, {"&&"}, {"|"}, {"^"}, {"&"}, {"==", "!="}, {"<", ">", "<=", ">="},
+	{"<<", ">>"}, {"+", "-"}, {"*", "/", "%"},
+}
+
+func (e *ppExpr) fail(tok, msg string) {
+	if e.ok {
+		e.p.d.errorf(e.line, tok, "%s", msg)
+		e.ok = false
+	}
+}
+
+func (e *ppExpr) binary(level int) int64 {
+	if level == len(ppOps) {
+		return e.unary()
+	}
+	v := e.binary(level + 1)
+	for e.pos < len(e.toks) && indexOf(ppOps[level], e.toks[e.pos].text) >= 0 {
+		op := e.toks[e.pos].text
+		e.pos++
+		w := e.binary(level + 1)
+		switch op {
+		case "||":
+			v = b2i(v != 0 || w != 0)
+		case "&&":
+			v = b2i(v != 0 && w != 0)
+		case "|":
+			v |= w
+		case "^":
+			v ^= w
+		case "&":
+			v &= w
+		case "==":
+			v = b2i(v == w)
+		case "!=":
+			v = b2i(v != w)
+		case "<":
+			v = b2i(v < w)
+		case ">":
+			v = b2i(v > w)
+		case "<=":
+			v = b2i(v <= w)
+		case ">=":
+			v = b2i(v >= w)
+		case "<<":
+			v <<= uint(w & 63)
+		case ">>":
+			v >>= uint(w & 63)
+		case "+":
+			v += w
+		case "-":
+			v -= w
+		case "*":
+			v *= w
+		case "/", "%":
+			if w == 0 {
+				e.fail(op, "division by zero")
+				return 0
+			}
+			if op == "/" {
+				v /= w
+			} else {
+				v %= w
+			}
+		}
+	}
+	return v
+}
+
+func (e *ppExpr) unary() int64 {
+	if e.pos == len(e.toks) {
+		e.fail("#if", "unexpected end of conditional expression")
+		return 0
+	}
+	t := e.toks[e.pos]
+	e.pos++
+	switch t.text {
+	case "+":
+		return e.unary()
+	case "-":
+		return -e.unary()
+	case "!":
+		return b2i(e.unary() == 0)
+	case "~":
+		return ^e.unary()
+	case "(":
+		v := e.binary(0)
+		if e.pos == len(e.toks) || e.toks[e.pos].text != ")" {
+			e.fail(t.text, "missing ')' in conditional expression")
+			return 0
+		}
+		e.pos++
+		return v
+	}
+	if t.kind == tokInt {
+		v, err := parseInt(t.text)
+		if err != nil {
+			e.fail(t.text, "invalid integer constant")
+		}
+		return v
+	}
+	if t.kind == tokIdent {
+		e.fail(t.text, "undefined identifier in conditional expression")
+	} else {
+		e.fail(t.text, "invalid token in conditional expression")
+	}
+	return 0
+}
+
+func b2i(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,232 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glsl
+
+import (
+	"fmt"
+
+	"github.com/n2d/webgl"
+)
+
+// basic is the kind of the components of a type.
+type basic int
+
+const (
+	tVoid basic = iota
+	tBool
+	tInt
+	tFloat
+	tSampler2D
+	tSamplerCube
+	tStruct
+
+	// tError is the type of an expression that failed to check.
+	tError basic = -1
+)
+
+// typ is a GLSL type. Values of every type are stored as float32
+// components: matrices column by column, structures field by field and
+// arrays element by element.
+type typ struct {
+	basic basic
+
+	// size is the number of components of a vector or the number of rows
+	// of a matrix, and 1 for scalars.
+	size int
+
+	// cols is the number of columns of a matrix, and 0 for other types.
+	cols int
+
+	strct *structType
+
+	// array is the length of an array type, and 0 for other types.
+	array int
+}
+
+// structType is a structure type.
+type structType struct {
+	name   string
+	fields []field
+}
+
+// field is a member of a structure.
+type field struct {
+	name string
+	typ  typ
+}
+
+var (
+	voidType        = typ{basic: tVoid}
+	boolType        = typ{basic: tBool, size: 1}
+	intType         = typ{basic: tInt, size: 1}
+	floatType       = typ{basic: tFloat, size: 1}
+	sampler2DType   = typ{basic: tSampler2D, size: 1}
+	samplerCubeType = typ{basic: tSamplerCube, size: 1}
+	errorType       = typ{basic: tError, size: 1}
+)
+
+// Returns the vector type of n components of a basic type, or the scalar
+// type if n is 1.
+func vecType(b basic, n int) typ {
+	return typ{basic: b, size: n}
+}
+
+// Returns the n by n float matrix type.
+func matType(n int) typ {
+	return typ{basic: tFloat, size: n, cols: n}
+}
+
+// typeNames are the keywords naming basic types.
+var typeNames = map[string]typ{
+	"void":        voidType,
+	"bool":        boolType,
+	"int":         intType,
+	"float":       floatType,
+	"vec2":        vecType(tFloat, 2),
+	"vec3":        vecType(tFloat, 3),
+	"vec4":        vecType(tFloat, 4),
+	"bvec2":       vecType(tBool, 2),
+	"bvec3":       vecType(tBool, 3),
+	"bvec4":       vecType(tBool, 4),
+	"ivec2":       vecType(tInt, 2),
+	"ivec3":       vecType(tInt, 3),
+	"ivec4":       vecType(tInt, 4),
+	"mat2":        matType(2),
+	"mat3":        matType(3),
+	"mat4":        matType(4),
+	"sampler2D":   sampler2DType,
+	"samplerCube": samplerCubeType,
+}
+
+func (t typ) isArray() bool {
+	return t.array > 0
+}
+
+func (t typ) isStruct() bool {
+	return t.basic == tStruct && t.array == 0
+}
+
+func (t typ) isSampler() bool {
+	return (t.basic == tSampler2D || t.basic == tSamplerCube) && t.array == 0
+}
+
+func (t typ) isScalar() bool {
+	return t.basic >= tBool && t.basic <= tFloat && t.size == 1 && t.cols == 0 && t.array == 0
+}
+
+func (t typ) isVector() bool {
+	return t.basic >= tBool && t.basic <= tFloat && t.size > 1 && t.cols == 0 && t.array == 0
+}
+
+func (t typ) isMatrix() bool {
+	return t.cols > 0 && t.array == 0
+}
+
+// Returns whether t is a scalar, vector or matrix of int or float.
+func (t typ) isNumeric() bool {
+	return (t.basic == tInt || t.basic == tFloat) && t.array == 0
+}
+
+// Returns whether t is a scalar, vector or matrix.
+func (t typ) isPlain() bool {
+	return t.basic >= tBool && t.basic <= tFloat && t.array == 0
+}
+
+// Returns the number of components of a scalar, vector or matrix.
+func (t typ) comps() int {
+	return t.size * max(t.cols, 1)
+}
+
+// Returns the number of float32 values storing a value of the type.
+func (t typ) slots() int {
+	n := t.comps()
+	if t.basic == tStruct {
+		n = 0
+		for _, f := range t.strct.fields {
+			n += f.typ.slots()
+		}
+	}
+	return n * max(t.array, 1)
+}
+
+// Returns the type of the elements of an array, the columns of a matrix
+// or the components of a vector.
+func (t typ) elem() typ {
+	switch {
+	case t.array > 0:
+		t.array = 0
+	case t.cols > 0:
+		t.cols = 0
+	default:
+		t.size = 1
+	}
+	return t
+}
+
+// Returns whether a type is or contains a type that matches f.
+func (t typ) contains(f func(typ) bool) bool {
+	if f(t) {
+		return true
+	}
+	if t.basic == tStruct {
+		for _, fl := range t.strct.fields {
+			if fl.typ.contains(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns whether a type contains a sampler.
+func (t typ) hasSampler() bool {
+	return t.contains(func(t typ) bool { return t.basic == tSampler2D || t.basic == tSamplerCube })
+}
+
+// Returns whether a type is or contains an array.
+func (t typ) hasArray() bool {
+	return t.contains(typ.isArray)
+}
+
+func (t typ) String() string {
+	var s string
+	switch {
+	case t.basic == tStruct:
+		s = t.strct.name
+	case t.cols > 0:
+		s = fmt.Sprintf("mat%d", t.cols)
+	default:
+		for name, u := range typeNames {
+			if u == (typ{basic: t.basic, size: t.size}) {
+				s = name
+			}
+		}
+	}
+	if t.array > 0 {
+		s += fmt.Sprintf("[%d]", t.array)
+	}
+	return s
+}
+
+// Returns the WebGL type enum of a type, as reported for active
+// attributes and uniforms.
+func (t typ) enum() webgl.Enum {
+	if t.cols > 0 {
+		return []webgl.Enum{webgl.FLOAT_MAT2, webgl.FLOAT_MAT3, webgl.FLOAT_MAT4}[t.cols-2]
+	}
+	switch t.basic {
+	case tBool:
+		return []webgl.Enum{webgl.BOOL, webgl.BOOL_VEC2, webgl.BOOL_VEC3, webgl.BOOL_VEC4}[t.size-1]
+	case tInt:
+		return []webgl.Enum{webgl.INT, webgl.INT_VEC2, webgl.INT_VEC3, webgl.INT_VEC4}[t.size-1]
+	case tFloat:
+		return []webgl.Enum{webgl.FLOAT, webgl.FLOAT_VEC2, webgl.FLOAT_VEC3, webgl.FLOAT_VEC4}[t.size-1]
+	case tSampler2D:
+		return webgl.SAMPLER_2D
+	case tSamplerCube:
+		return webgl.SAMPLER_CUBE
+	}
+	return 0
+}
@@ -0,0 +1,169 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"image"
+	"image/color"
+)
+
+// TexImageOptions controls how TexImageFromGo and TexSubImageFromGo
+// upload a Go image.
+type TexImageOptions struct {
+	// FlipY uploads the image bottom row first, so that the top of the
+	// image ends up at t = 1 as OpenGL texture coordinates expect.
+	FlipY bool
+
+	// PremultiplyAlpha stores RGBA colors multiplied by their alpha.
+	// Otherwise colors are stored unassociated, converting from the
+	// premultiplied colors of *image.RGBA if needed.
+	PremultiplyAlpha bool
+}
+
+// Loads a Go image into a texture. *image.Gray and *image.Alpha images
+// are uploaded as LUMINANCE and ALPHA, every other image as RGBA, all
+// with UNSIGNED_BYTE components. *image.RGBA and *image.NRGBA pixels are
+// uploaded without conversion when their layout already matches opts.
+//
+// The UNPACK_ALIGNMENT, UNPACK_FLIP_Y_WEBGL and
+// UNPACK_PREMULTIPLY_ALPHA_WEBGL pixel storage parameters are set for the
+// upload and left set afterwards. opts may be nil.
+func TexImageFromGo(gl RenderingContext, target Enum, level int, img image.Image, opts *TexImageOptions) {
+	format, pixels := imagePixels(img, opts)
+	storeImageOptions(gl, opts)
+	r := img.Bounds()
+	gl.TexImage2DPixels(target, level, format, r.Dx(), r.Dy(), 0, format, UNSIGNED_BYTE, pixels)
+}
+
+// Replaces the part of a texture at xoffset, yoffset with a Go image, as
+// TexImageFromGo does. The image must match the format of the texture.
+func TexSubImageFromGo(gl RenderingContext, target Enum, level, xoffset, yoffset int, img image.Image, opts *TexImageOptions) {
+	format, pixels := imagePixels(img, opts)
+	storeImageOptions(gl, opts)
+	r := img.Bounds()
+	gl.TexSubImage2DPixels(target, level, xoffset, yoffset, r.Dx(), r.Dy(), format, UNSIGNED_BYTE, pixels)
+}
+
+// Sets the pixel storage parameters for uploading tightly packed rows.
+// Flipping is left to WebGL, while premultiplication is done by
+// imagePixels since it depends on the source image.
+func storeImageOptions(gl RenderingContext, opts *TexImageOptions) {
+	flip := 0
+	if opts != nil && opts.FlipY {
+		flip = 1
+	}
+	gl.PixelStorei(UNPACK_ALIGNMENT, 1)
+	gl.PixelStorei(UNPACK_FLIP_Y_WEBGL, flip)
+	gl.PixelStorei(UNPACK_PREMULTIPLY_ALPHA_WEBGL, 0)
+}
+
+// Returns the format and tightly packed pixel rows of an image.
+func imagePixels(img image.Image, opts *TexImageOptions) (Enum, []byte) {
+	premul := opts != nil && opts.PremultiplyAlpha
+	r := img.Bounds()
+	switch img := img.(type) {
+	case *image.Gray:
+		return LUMINANCE, packRows(img.Pix, img.PixOffset(r.Min.X, r.Min.Y), img.Stride, r.Dx(), r.Dy())
+	case *image.Alpha:
+		return ALPHA, packRows(img.Pix, img.PixOffset(r.Min.X, r.Min.Y), img.Stride, r.Dx(), r.Dy())
+	case *image.RGBA:
+		pix := packRows(img.Pix, img.PixOffset(r.Min.X, r.Min.Y), img.Stride, 4*r.Dx(), r.Dy())
+		if !premul {
+			pix = unpremultiply(pix)
+		}
+		return RGBA, pix
+	case *image.NRGBA:
+		pix := packRows(img.Pix, img.PixOffset(r.Min.X, r.Min.Y), img.Stride, 4*r.Dx(), r.Dy())
+		if premul {
+			pix = premultiply(pix)
+		}
+		return RGBA, pix
+	case *image.Paletted:
+		palette := make([][4]byte, len(img.Palette))
+		for i, c := range img.Palette {
+			palette[i] = rgbaBytes(c, premul)
+		}
+		pix := make([]byte, 0, 4*r.Dx()*r.Dy())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row := img.Pix[img.PixOffset(r.Min.X, y):]
+			for _, i := range row[:r.Dx()] {
+				var p [4]byte
+				if int(i) < len(palette) {
+					p = palette[i]
+				}
+				pix = append(pix, p[:]...)
+			}
+		}
+		return RGBA, pix
+	}
+
+	pix := make([]byte, 0, 4*r.Dx()*r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			p := rgbaBytes(img.At(x, y), premul)
+			pix = append(pix, p[:]...)
+		}
+	}
+	return RGBA, pix
+}
+
+// Returns the rows of n bytes starting at offset in pix, copying them into
+// a new slice only if they are not already contiguous.
+func packRows(pix []byte, offset, stride, n, rows int) []byte {
+	if rows == 0 || n == 0 {
+		return []byte{}
+	}
+	if stride == n {
+		return pix[offset : offset+n*rows]
+	}
+	packed := make([]byte, 0, n*rows)
+	for y := 0; y < rows; y++ {
+		packed = append(packed, pix[offset+y*stride:offset+y*stride+n]...)
+	}
+	return packed
+}
+
+// Returns the bytes of a color as 8-bit RGBA, premultiplied or not.
+func rgbaBytes(c color.Color, premul bool) [4]byte {
+	if premul {
+		p := color.RGBAModel.Convert(c).(color.RGBA)
+		return [4]byte{p.R, p.G, p.B, p.A}
+	}
+	p := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return [4]byte{p.R, p.G, p.B, p.A}
+}
+
+// Returns a copy of RGBA pixels with their colors multiplied by alpha.
+func premultiply(src []byte) []byte {
+	dst := make([]byte, len(src))
+	for i := 0; i+3 < len(src); i += 4 {
+		a := uint32(src[i+3])
+		dst[i] = byte((uint32(src[i])*a + 127) / 255)
+		dst[i+1] = byte((uint32(src[i+1])*a + 127) / 255)
+		dst[i+2] = byte((uint32(src[i+2])*a + 127) / 255)
+		dst[i+3] = byte(a)
+	}
+	return dst
+}
+
+// Returns a copy of premultiplied RGBA pixels with their colors divided
+// by alpha.
+func unpremultiply(src []byte) []byte {
+	dst := make([]byte, len(src))
+	for i := 0; i+3 < len(src); i += 4 {
+		a := uint32(src[i+3])
+		switch a {
+		case 0:
+		case 255:
+			dst[i], dst[i+1], dst[i+2] = src[i], src[i+1], src[i+2]
+		default:
+			dst[i] = byte(min((uint32(src[i])*255+a/2)/a, 255))
+			dst[i+1] = byte(min((uint32(src[i+1])*255+a/2)/a, 255))
+			dst[i+2] = byte(min((uint32(src[i+2])*255+a/2)/a, 255))
+		}
+		dst[i+3] = byte(a)
+	}
+	return dst
+}
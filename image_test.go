@@ -0,0 +1,156 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+func TestTexImageFromGo(t *testing.T) {
+	// wide is a 4x2 image whose middle 2x2 pixels are taken as a
+	// sub-image with a stride wider than its rows.
+	wide := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for i := range wide.Pix {
+		wide.Pix[i] = byte(i)
+	}
+	for i := 3; i < len(wide.Pix); i += 4 {
+		wide.Pix[i] = 255
+	}
+	gray := &image.Gray{Pix: []byte{1, 2, 3, 9, 4, 5, 6, 9}, Stride: 4, Rect: image.Rect(0, 0, 3, 2)}
+	paletted := &image.Paletted{
+		Pix:     []byte{0, 1, 7},
+		Stride:  3,
+		Rect:    image.Rect(0, 0, 3, 1),
+		Palette: color.Palette{color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 200, 128}},
+	}
+
+	tests := []struct {
+		name   string
+		img    image.Image
+		opts   *webgl.TexImageOptions
+		format webgl.Enum
+		w, h   int
+		want   []byte
+	}{{
+		name:   "RGBA",
+		img:    &image.RGBA{Pix: []byte{100, 50, 0, 128, 0, 0, 0, 0}, Stride: 8, Rect: image.Rect(0, 0, 2, 1)},
+		format: webgl.RGBA, w: 2, h: 1,
+		want: []byte{199, 100, 0, 128, 0, 0, 0, 0},
+	}, {
+		name:   "RGBA premultiplied",
+		img:    &image.RGBA{Pix: []byte{100, 50, 0, 128}, Stride: 4, Rect: image.Rect(0, 0, 1, 1)},
+		opts:   &webgl.TexImageOptions{PremultiplyAlpha: true},
+		format: webgl.RGBA, w: 1, h: 1,
+		want: []byte{100, 50, 0, 128},
+	}, {
+		name:   "NRGBA",
+		img:    &image.NRGBA{Pix: []byte{200, 100, 0, 128}, Stride: 4, Rect: image.Rect(0, 0, 1, 1)},
+		format: webgl.RGBA, w: 1, h: 1,
+		want: []byte{200, 100, 0, 128},
+	}, {
+		name:   "NRGBA premultiplied",
+		img:    &image.NRGBA{Pix: []byte{200, 100, 0, 128, 10, 20, 30, 255}, Stride: 8, Rect: image.Rect(0, 0, 2, 1)},
+		opts:   &webgl.TexImageOptions{PremultiplyAlpha: true},
+		format: webgl.RGBA, w: 2, h: 1,
+		want: []byte{100, 50, 0, 128, 10, 20, 30, 255},
+	}, {
+		name:   "Gray",
+		img:    gray,
+		format: webgl.LUMINANCE, w: 3, h: 2,
+		want: []byte{1, 2, 3, 4, 5, 6},
+	}, {
+		name:   "Alpha",
+		img:    &image.Alpha{Pix: []byte{7, 8}, Stride: 1, Rect: image.Rect(0, 0, 1, 2)},
+		format: webgl.ALPHA, w: 1, h: 2,
+		want: []byte{7, 8},
+	}, {
+		name:   "Paletted",
+		img:    paletted,
+		format: webgl.RGBA, w: 3, h: 1,
+		want: []byte{255, 0, 0, 255, 0, 0, 200, 128, 0, 0, 0, 0},
+	}, {
+		name:   "Paletted premultiplied",
+		img:    paletted,
+		opts:   &webgl.TexImageOptions{PremultiplyAlpha: true},
+		format: webgl.RGBA, w: 3, h: 1,
+		want: []byte{255, 0, 0, 255, 0, 0, 100, 128, 0, 0, 0, 0},
+	}, {
+		name:   "sub-image",
+		img:    wide.SubImage(image.Rect(1, 0, 3, 2)),
+		format: webgl.RGBA, w: 2, h: 2,
+		want: []byte{4, 5, 6, 255, 8, 9, 10, 255, 20, 21, 22, 255, 24, 25, 26, 255},
+	}, {
+		name:   "other image",
+		img:    &image.RGBA64{Pix: []byte{0x80, 0, 0x40, 0, 0, 0, 0x80, 0}, Stride: 8, Rect: image.Rect(0, 0, 1, 1)},
+		format: webgl.RGBA, w: 1, h: 1,
+		want: []byte{255, 127, 0, 128},
+	}, {
+		name:   "other image premultiplied",
+		img:    &image.RGBA64{Pix: []byte{0x80, 0, 0x40, 0, 0, 0, 0x80, 0}, Stride: 8, Rect: image.Rect(0, 0, 1, 1)},
+		opts:   &webgl.TexImageOptions{PremultiplyAlpha: true},
+		format: webgl.RGBA, w: 1, h: 1,
+		want: []byte{128, 64, 0, 128},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl := record.New(64, 64, nil)
+			gl.BindTexture(webgl.TEXTURE_2D, gl.CreateTexture())
+			webgl.TexImageFromGo(gl, webgl.TEXTURE_2D, 0, test.img, test.opts)
+			calls := gl.Filter("TexImage2DPixels")
+			if len(calls) != 1 {
+				t.Fatalf("got calls %v", gl.Calls)
+			}
+			args := calls[0].Args
+			if args[2] != test.format || args[6] != test.format || args[3] != test.w || args[4] != test.h {
+				t.Errorf("got %v, want a %dx%d image of format 0x%04X", calls[0], test.w, test.h, uint32(test.format))
+			}
+			if got := args[8].([]byte); !bytes.Equal(got, test.want) {
+				t.Errorf("uploaded %v, want %v", got, test.want)
+			}
+			if errs := gl.Errors(); len(errs) > 0 {
+				t.Errorf("got WebGL errors %v", errs)
+			}
+		})
+	}
+}
+
+func TestTexImageFromGoOptions(t *testing.T) {
+	img := &image.NRGBA{Pix: []byte{1, 2, 3, 4, 5, 6, 7, 8}, Stride: 4, Rect: image.Rect(0, 0, 1, 2)}
+	for _, flip := range []bool{false, true} {
+		gl := record.New(64, 64, nil)
+		gl.BindTexture(webgl.TEXTURE_2D, gl.CreateTexture())
+		webgl.TexImageFromGo(gl, webgl.TEXTURE_2D, 0, img, &webgl.TexImageOptions{FlipY: flip})
+		want := "[PixelStorei(0x0CF5, 1) PixelStorei(0x9240, 0) PixelStorei(0x9241, 0)]"
+		if flip {
+			want = "[PixelStorei(0x0CF5, 1) PixelStorei(0x9240, 1) PixelStorei(0x9241, 0)]"
+		}
+		if got := fmt.Sprint(gl.Filter("PixelStorei")); got != want {
+			t.Errorf("FlipY %v: got %s, want %s", flip, got, want)
+		}
+		// WebGL flips the rows, so they are passed in image order.
+		if got := gl.Filter("TexImage2DPixels")[0].Args[8].([]byte); !bytes.Equal(got, img.Pix) {
+			t.Errorf("FlipY %v: uploaded %v, want %v", flip, got, img.Pix)
+		}
+	}
+
+	gl := record.New(64, 64, nil)
+	gl.BindTexture(webgl.TEXTURE_2D, gl.CreateTexture())
+	gl.TexImage2DPixels(webgl.TEXTURE_2D, 0, webgl.RGBA, 2, 2, 0, webgl.RGBA, webgl.UNSIGNED_BYTE, nil)
+	webgl.TexSubImageFromGo(gl, webgl.TEXTURE_2D, 0, 1, 0, img, nil)
+	call := gl.Filter("TexSubImage2DPixels")[0]
+	if fmt.Sprint(call.Args[:8]) != "[3553 0 1 0 1 2 6408 5121]" || !bytes.Equal(call.Args[8].([]byte), img.Pix) {
+		t.Errorf("got %v", call)
+	}
+	if errs := gl.Errors(); len(errs) > 0 {
+		t.Errorf("got WebGL errors %v", errs)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Returns the WebGL version of the context, 1 or 2.
+func (c *Context) webglVersion() int {
+	if c.version == 0 {
+		c.version = 1
+		if t := js.Global().Get("WebGL2RenderingContext"); !t.IsUndefined() && c.Value.InstanceOf(t) {
+			c.version = 2
+		}
+	}
+	return c.version
+}
+
+// Returns the object the instanced drawing methods are called on and the
+// suffix of their names: the context itself on WebGL 2, otherwise the
+// ANGLE_instanced_arrays extension.
+func (c *Context) instanced(feature string) (js.Value, string, error) {
+	if c.webglVersion() == 2 {
+		return c.Value, "", nil
+	}
+	ext := c.GetExtension("ANGLE_instanced_arrays")
+	if !ext.Truthy() {
+		return js.Value{}, "", &UnsupportedError{Feature: feature, Extension: "ANGLE_instanced_arrays"}
+	}
+	return ext, "ANGLE", nil
+}
+
+// Renders instanceCount instances of primitives from array data. It uses
+// WebGL 2 when available and the ANGLE_instanced_arrays extension
+// otherwise, returning an *UnsupportedError if neither exists.
+func (c *Context) DrawArraysInstanced(mode Enum, first, count, instanceCount int) error {
+	target, suffix, err := c.instanced("DrawArraysInstanced")
+	if err != nil {
+		return err
+	}
+	c.Invoke(target, "drawArraysInstanced"+suffix, mode, first, count, instanceCount)
+	return nil
+}
+
+// Renders instanceCount instances of primitives indexed by element array
+// data. It uses WebGL 2 when available and the ANGLE_instanced_arrays
+// extension otherwise, returning an *UnsupportedError if neither exists.
+func (c *Context) DrawElementsInstanced(mode Enum, count int, typ Enum, offset, instanceCount int) error {
+	target, suffix, err := c.instanced("DrawElementsInstanced")
+	if err != nil {
+		return err
+	}
+	c.Invoke(target, "drawElementsInstanced"+suffix, mode, count, typ, offset, instanceCount)
+	return nil
+}
+
+// Sets the rate at which a generic vertex attribute advances when drawing
+// multiple instances. A divisor of 0 advances once per vertex. It uses
+// WebGL 2 when available and the ANGLE_instanced_arrays extension
+// otherwise, returning an *UnsupportedError if neither exists.
+func (c *Context) VertexAttribDivisor(index, divisor int) error {
+	target, suffix, err := c.instanced("VertexAttribDivisor")
+	if err != nil {
+		return err
+	}
+	c.Invoke(target, "vertexAttribDivisor"+suffix, index, divisor)
+	c.vao.vertexAttribDivisor(index, divisor)
+	return nil
+}
+
+// Uploads a slice of per-instance structs of the layout's type to the
+// buffer bound to ARRAY_BUFFER, replacing its contents, then points the
+// program's attributes at their fields and gives them a divisor of 1, so
+// they advance once per instance in DrawArraysInstanced and
+// DrawElementsInstanced:
+//
+//	type Instance struct {
+//		Offset [2]float32 `gl:"a_offset"`
+//		Color  [4]uint8   `gl:"a_color,normalized"`
+//	}
+//
+// Unlike Bind, the program may have attributes the layout does not
+// provide, which are usually bound per vertex from another buffer. The
+// divisors stay set on the attribute locations, so attributes later used
+// per vertex need a divisor of 0 again.
+func (l *VertexLayout) BindInstances(gl *Context, info *ProgramInfo, instances interface{}, usage Enum) error {
+	if _, _, err := gl.instanced("BindInstances"); err != nil {
+		return err
+	}
+	for _, attr := range l.Attributes {
+		active, ok := info.Attribute(attr.Name)
+		if !ok {
+			continue
+		}
+		switch active.Type {
+		case FLOAT, FLOAT_VEC2, FLOAT_VEC3, FLOAT_VEC4:
+		default:
+			return fmt.Errorf("webgl: attribute %s of type %s cannot be bound to an instance field",
+				active.Name, TypeName(active.Type))
+		}
+	}
+	if err := l.BufferData(gl, ARRAY_BUFFER, instances, usage); err != nil {
+		return err
+	}
+	for _, attr := range l.Attributes {
+		location := info.AttribLocation(attr.Name)
+		if location < 0 {
+			continue
+		}
+		gl.VertexAttribPointer(location, attr.Size, attr.Type, attr.Normalized, l.Stride, attr.Offset)
+		gl.EnableVertexAttribArray(location)
+		gl.VertexAttribDivisor(location, 1)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package js is the part of syscall/js used by the WebGL bindings.
+//
+// Under js/wasm its types are aliases of the syscall/js ones, so the
+// bindings take and return syscall/js values. On other platforms it is an
+// in-memory JavaScript object model with the same API, holding plain
+// objects, arrays, typed arrays, weak maps and functions, and fakes of the
+// canvas and WebGL contexts made by NewCanvas and NewWebGL. The fakes
+// reject unknown method and property names and wrong argument counts, the
+// way a browser throws a TypeError, so the bindings can be checked by go
+// test without a browser.
+package js
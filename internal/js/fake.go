@@ -0,0 +1,679 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(js && wasm)
+
+package js
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Type is the JavaScript type of a Value.
+type Type int
+
+const (
+	TypeUndefined Type = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeSymbol
+	TypeObject
+	TypeFunction
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeSymbol:
+		return "symbol"
+	case TypeObject:
+		return "object"
+	case TypeFunction:
+		return "function"
+	}
+	panic("bad type")
+}
+
+// ValueError is the panic value of a Value method called on a value of
+// the wrong type.
+type ValueError struct {
+	Method string
+	Type   Type
+}
+
+func (e *ValueError) Error() string {
+	return "syscall/js: call of " + e.Method + " on " + e.Type.String()
+}
+
+// TypeError is the panic value of the operations a browser would throw a
+// TypeError for, such as calling a method that does not exist.
+type TypeError struct {
+	Message string
+}
+
+func (e *TypeError) Error() string {
+	return "TypeError: " + e.Message
+}
+
+func throwf(format string, args ...interface{}) {
+	panic(&TypeError{fmt.Sprintf(format, args...)})
+}
+
+// Value is a JavaScript value.
+type Value struct {
+	typ Type
+	b   bool
+	n   float64
+	s   string
+	o   *object
+}
+
+// object is a JavaScript object, array, typed array or function.
+type object struct {
+	class string
+	props map[string]Value
+
+	// strict objects throw on reads and writes of properties they do not
+	// have, as the fake host objects do for misspelled names.
+	strict bool
+
+	// fn is the body of a function and ctor creates the objects of a
+	// constructor.
+	fn   func(this Value, args []Value) Value
+	ctor func(args []Value) Value
+
+	// elems are the elements of an array.
+	elems []Value
+
+	// buffer holds the bytes of an ArrayBuffer, shared by the typed
+	// arrays viewing it from offset with length elements of size bytes.
+	buffer *[]byte
+	offset int
+	length int
+	size   int
+}
+
+var (
+	undefined = Value{typ: TypeUndefined}
+	null      = Value{typ: TypeNull}
+	global    = newGlobal()
+)
+
+// Returns the global object.
+func Global() Value {
+	return global
+}
+
+func Null() Value {
+	return null
+}
+
+func Undefined() Value {
+	return undefined
+}
+
+// Returns a Value for a Go value, as syscall/js does for nil, Value, Func,
+// booleans, numbers, strings, []interface{} and map[string]interface{}.
+func ValueOf(x interface{}) Value {
+	switch x := x.(type) {
+	case Value:
+		return x
+	case Func:
+		return x.Value
+	case nil:
+		return null
+	case bool:
+		return Value{typ: TypeBoolean, b: x}
+	case int:
+		return number(float64(x))
+	case int8:
+		return number(float64(x))
+	case int16:
+		return number(float64(x))
+	case int32:
+		return number(float64(x))
+	case int64:
+		return number(float64(x))
+	case uint:
+		return number(float64(x))
+	case uint8:
+		return number(float64(x))
+	case uint16:
+		return number(float64(x))
+	case uint32:
+		return number(float64(x))
+	case uint64:
+		return number(float64(x))
+	case uintptr:
+		return number(float64(x))
+	case float32:
+		return number(float64(x))
+	case float64:
+		return number(x)
+	case string:
+		return Value{typ: TypeString, s: x}
+	case []interface{}:
+		elems := make([]Value, len(x))
+		for i, e := range x {
+			elems[i] = ValueOf(e)
+		}
+		return newObject(&object{class: "Array", elems: elems})
+	case map[string]interface{}:
+		o := &object{class: "Object", props: make(map[string]Value)}
+		for k, e := range x {
+			o.props[k] = ValueOf(e)
+		}
+		return newObject(o)
+	}
+	panic("ValueOf: invalid value")
+}
+
+func number(n float64) Value {
+	return Value{typ: TypeNumber, n: n}
+}
+
+func newObject(o *object) Value {
+	if o.props == nil {
+		o.props = make(map[string]Value)
+	}
+	if o.fn != nil || o.ctor != nil {
+		return Value{typ: TypeFunction, o: o}
+	}
+	return Value{typ: TypeObject, o: o}
+}
+
+// Returns a function object running fn.
+func function(fn func(this Value, args []Value) Value) Value {
+	return newObject(&object{class: "Function", fn: fn})
+}
+
+// Returns a constructor of objects of a class.
+func constructor(class string, ctor func(args []Value) Value) Value {
+	v := newObject(&object{class: "Function", ctor: ctor})
+	v.o.props["name"] = ValueOf(class)
+	return v
+}
+
+// Returns an object of a class holding props, which throws on unknown
+// property names if strict is set.
+func newStrict(class string, props map[string]Value) Value {
+	return newObject(&object{class: class, props: props, strict: true})
+}
+
+func (v Value) Type() Type {
+	return v.typ
+}
+
+func (v Value) object(method string) *object {
+	if v.o == nil {
+		panic(&ValueError{method, v.typ})
+	}
+	return v.o
+}
+
+// Returns the named property of an object.
+func (v Value) Get(p string) Value {
+	o := v.object("Value.Get")
+	if x, ok := o.props[p]; ok {
+		return x
+	}
+	switch {
+	case o.elems != nil && p == "length":
+		return ValueOf(len(o.elems))
+	case o.buffer != nil && p == "length" && o.size > 0:
+		return ValueOf(o.length)
+	case o.buffer != nil && p == "byteLength":
+		return ValueOf(o.length * max(o.size, 1))
+	case o.strict:
+		throwf("%s has no property %q", o.class, p)
+	}
+	return undefined
+}
+
+// Sets the named property of an object.
+func (v Value) Set(p string, x interface{}) {
+	o := v.object("Value.Set")
+	if _, ok := o.props[p]; !ok && o.strict {
+		throwf("%s has no property %q", o.class, p)
+	}
+	o.props[p] = ValueOf(x)
+}
+
+// Deletes the named property of an object.
+func (v Value) Delete(p string) {
+	delete(v.object("Value.Delete").props, p)
+}
+
+// Returns an element of an array or typed array.
+func (v Value) Index(i int) Value {
+	o := v.object("Value.Index")
+	switch {
+	case o.elems != nil:
+		if i < 0 || i >= len(o.elems) {
+			return undefined
+		}
+		return o.elems[i]
+	case o.buffer != nil && o.size > 0:
+		if i < 0 || i >= o.length {
+			return undefined
+		}
+		return number(o.load(i))
+	}
+	return v.Get(strconv.Itoa(i))
+}
+
+// Sets an element of an array or typed array.
+func (v Value) SetIndex(i int, x interface{}) {
+	o := v.object("Value.SetIndex")
+	switch {
+	case o.elems != nil:
+		for len(o.elems) <= i {
+			o.elems = append(o.elems, undefined)
+		}
+		o.elems[i] = ValueOf(x)
+	case o.buffer != nil && o.size > 0:
+		if i >= 0 && i < o.length {
+			o.store(i, ValueOf(x).Float())
+		}
+	default:
+		v.Set(strconv.Itoa(i), x)
+	}
+}
+
+// Returns the length property of an object.
+func (v Value) Length() int {
+	return v.Get("length").Int()
+}
+
+// Calls the method m of an object with the arguments converted by
+// ValueOf.
+func (v Value) Call(m string, args ...interface{}) Value {
+	o := v.object("Value.Call")
+	f := v.Get(m)
+	if f.typ != TypeFunction || f.o.fn == nil {
+		throwf("%s.%s is not a function", o.class, m)
+	}
+	return f.o.fn(v, values(args))
+}
+
+// Calls a function with the arguments converted by ValueOf.
+func (v Value) Invoke(args ...interface{}) Value {
+	if v.typ != TypeFunction || v.o.fn == nil {
+		panic(&ValueError{"Value.Invoke", v.typ})
+	}
+	return v.o.fn(undefined, values(args))
+}
+
+// Calls a constructor with the arguments converted by ValueOf.
+func (v Value) New(args ...interface{}) Value {
+	if v.typ != TypeFunction || v.o.ctor == nil {
+		panic(&ValueError{"Value.New", v.typ})
+	}
+	return v.o.ctor(values(args))
+}
+
+func values(args []interface{}) []Value {
+	vs := make([]Value, len(args))
+	for i, a := range args {
+		vs[i] = ValueOf(a)
+	}
+	return vs
+}
+
+// Returns whether v is an object made by the constructor t.
+func (v Value) InstanceOf(t Value) bool {
+	return v.o != nil && t.o != nil && t.o.ctor != nil && v.o.class == t.o.props["name"].s
+}
+
+func (v Value) Bool() bool {
+	if v.typ != TypeBoolean {
+		panic(&ValueError{"Value.Bool", v.typ})
+	}
+	return v.b
+}
+
+func (v Value) Float() float64 {
+	if v.typ != TypeNumber {
+		panic(&ValueError{"Value.Float", v.typ})
+	}
+	return v.n
+}
+
+func (v Value) Int() int {
+	if v.typ != TypeNumber {
+		panic(&ValueError{"Value.Int", v.typ})
+	}
+	return int(v.n)
+}
+
+// Returns a string, or a description of the value such as "<number: 1>"
+// for other types, as syscall/js does.
+func (v Value) String() string {
+	switch v.typ {
+	case TypeString:
+		return v.s
+	case TypeUndefined:
+		return "<undefined>"
+	case TypeNull:
+		return "<null>"
+	case TypeBoolean:
+		return "<boolean: " + strconv.FormatBool(v.b) + ">"
+	case TypeNumber:
+		return "<number: " + strconv.FormatFloat(v.n, 'g', -1, 64) + ">"
+	}
+	return "<" + v.typ.String() + ">"
+}
+
+func (v Value) Truthy() bool {
+	switch v.typ {
+	case TypeUndefined, TypeNull:
+		return false
+	case TypeBoolean:
+		return v.b
+	case TypeNumber:
+		return v.n != 0 && !math.IsNaN(v.n)
+	case TypeString:
+		return v.s != ""
+	}
+	return true
+}
+
+func (v Value) IsNull() bool {
+	return v.typ == TypeNull
+}
+
+func (v Value) IsUndefined() bool {
+	return v.typ == TypeUndefined
+}
+
+func (v Value) IsNaN() bool {
+	return v.typ == TypeNumber && math.IsNaN(v.n)
+}
+
+// Returns whether two values are the same, as JavaScript's === does.
+func (v Value) Equal(w Value) bool {
+	if v.typ != w.typ {
+		return false
+	}
+	switch v.typ {
+	case TypeBoolean:
+		return v.b == w.b
+	case TypeNumber:
+		return v.n == w.n
+	case TypeString:
+		return v.s == w.s
+	case TypeObject, TypeFunction:
+		return v.o == w.o
+	}
+	return true
+}
+
+// Func is a Go function wrapped as a JavaScript function.
+type Func struct {
+	Value
+}
+
+func FuncOf(fn func(this Value, args []Value) interface{}) Func {
+	return Func{function(func(this Value, args []Value) Value {
+		return ValueOf(fn(this, args))
+	})}
+}
+
+// Release makes the function throw when called.
+func (f Func) Release() {
+	f.o.fn = func(Value, []Value) Value {
+		throwf("call to released function")
+		return undefined
+	}
+}
+
+// Copies bytes from a Uint8Array or Uint8ClampedArray into dst, returning
+// the number of bytes copied.
+func CopyBytesToGo(dst []byte, src Value) int {
+	o := src.o
+	if o == nil || o.size != 1 || (o.class != "Uint8Array" && o.class != "Uint8ClampedArray") {
+		panic("syscall/js: CopyBytesToGo: expected src to be a Uint8Array or Uint8ClampedArray")
+	}
+	return copy(dst, (*o.buffer)[o.offset:o.offset+o.length])
+}
+
+// Copies bytes from src into a Uint8Array or Uint8ClampedArray, returning
+// the number of bytes copied.
+func CopyBytesToJS(dst Value, src []byte) int {
+	o := dst.o
+	if o == nil || o.size != 1 || (o.class != "Uint8Array" && o.class != "Uint8ClampedArray") {
+		panic("syscall/js: CopyBytesToJS: expected dst to be a Uint8Array or Uint8ClampedArray")
+	}
+	return copy((*o.buffer)[o.offset:o.offset+o.length], src)
+}
+
+// Returns a copy of the bytes viewed by a typed array or held by an
+// ArrayBuffer.
+func Bytes(v Value) []byte {
+	o := v.object("Bytes")
+	if o.buffer == nil {
+		throwf("%s is not an ArrayBuffer or typed array", o.class)
+	}
+	n := o.length * max(o.size, 1)
+	return append([]byte(nil), (*o.buffer)[o.offset:o.offset+n]...)
+}
+
+// typedArrays are the element sizes of the typed array constructors.
+var typedArrays = map[string]int{
+	"Int8Array":         1,
+	"Uint8Array":        1,
+	"Uint8ClampedArray": 1,
+	"Int16Array":        2,
+	"Uint16Array":       2,
+	"Int32Array":        4,
+	"Uint32Array":       4,
+	"Float32Array":      4,
+	"Float64Array":      8,
+}
+
+// Returns element i of a typed array.
+func (o *object) load(i int) float64 {
+	b := (*o.buffer)[o.offset+i*o.size:]
+	switch o.class {
+	case "Int8Array":
+		return float64(int8(b[0]))
+	case "Uint8Array", "Uint8ClampedArray":
+		return float64(b[0])
+	case "Int16Array":
+		return float64(int16(binary.LittleEndian.Uint16(b)))
+	case "Uint16Array":
+		return float64(binary.LittleEndian.Uint16(b))
+	case "Int32Array":
+		return float64(int32(binary.LittleEndian.Uint32(b)))
+	case "Uint32Array":
+		return float64(binary.LittleEndian.Uint32(b))
+	case "Float32Array":
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+// Stores element i of a typed array.
+func (o *object) store(i int, x float64) {
+	b := (*o.buffer)[o.offset+i*o.size:]
+	switch o.class {
+	case "Int8Array", "Uint8Array":
+		b[0] = byte(int64(x))
+	case "Uint8ClampedArray":
+		b[0] = byte(math.Max(0, math.Min(255, math.RoundToEven(x))))
+	case "Int16Array", "Uint16Array":
+		binary.LittleEndian.PutUint16(b, uint16(int64(x)))
+	case "Int32Array", "Uint32Array":
+		binary.LittleEndian.PutUint32(b, uint32(int64(x)))
+	case "Float32Array":
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(x)))
+	default:
+		binary.LittleEndian.PutUint64(b, math.Float64bits(x))
+	}
+}
+
+// Returns an ArrayBuffer of n zero bytes.
+func newArrayBuffer(n int) Value {
+	data := make([]byte, n)
+	return newObject(&object{class: "ArrayBuffer", buffer: &data, length: n})
+}
+
+// Returns a typed array viewing length elements of buffer from a byte
+// offset.
+func newTypedArray(class string, buffer Value, offset, length int) Value {
+	size := typedArrays[class]
+	v := newObject(&object{class: class, buffer: buffer.o.buffer, offset: offset, length: length, size: size})
+	v.o.props["buffer"] = buffer
+	v.o.props["byteOffset"] = ValueOf(offset)
+	v.o.props["subarray"] = function(func(this Value, args []Value) Value {
+		o := this.o
+		begin, end := 0, o.length
+		if len(args) > 0 {
+			begin = clampIndex(args[0].Int(), o.length)
+		}
+		if len(args) > 1 {
+			end = clampIndex(args[1].Int(), o.length)
+		}
+		return newTypedArray(o.class, o.props["buffer"], o.offset+begin*o.size, max(end-begin, 0))
+	})
+	return v
+}
+
+// Resolves a possibly negative subarray index.
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	return max(0, min(i, n))
+}
+
+// Returns the constructor of a typed array class, taking a length, an
+// array of numbers, or an ArrayBuffer with an optional byte offset and
+// length.
+func typedArrayConstructor(class string) Value {
+	size := typedArrays[class]
+	return constructor(class, func(args []Value) Value {
+		if len(args) == 0 {
+			return newTypedArray(class, newArrayBuffer(0), 0, 0)
+		}
+		a := args[0]
+		switch {
+		case a.typ == TypeNumber:
+			return newTypedArray(class, newArrayBuffer(a.Int()*size), 0, a.Int())
+		case a.o != nil && a.o.class == "ArrayBuffer":
+			offset := 0
+			if len(args) > 1 {
+				offset = args[1].Int()
+			}
+			if offset%size != 0 {
+				throwf("start offset of %s should be a multiple of %d", class, size)
+			}
+			length := (len(*a.o.buffer) - offset) / size
+			if len(args) > 2 {
+				length = args[2].Int()
+			}
+			if offset+length*size > len(*a.o.buffer) {
+				throwf("invalid %s length %d", class, length)
+			}
+			return newTypedArray(class, a, offset, length)
+		case a.o != nil:
+			n := a.Length()
+			v := newTypedArray(class, newArrayBuffer(n*size), 0, n)
+			for i := 0; i < n; i++ {
+				v.o.store(i, a.Index(i).Float())
+			}
+			return v
+		}
+		throwf("invalid argument to %s", class)
+		return undefined
+	})
+}
+
+// Returns a WeakMap, whose keys must be objects. Its entries are not
+// weak, which only matters for memory use.
+func newWeakMap(args []Value) Value {
+	m := make(map[*object]Value)
+	key := func(args []Value) *object {
+		if len(args) == 0 {
+			return nil
+		}
+		return args[0].o
+	}
+	return newStrict("WeakMap", map[string]Value{
+		"get": function(func(this Value, args []Value) Value {
+			if v, ok := m[key(args)]; ok {
+				return v
+			}
+			return undefined
+		}),
+		"has": function(func(this Value, args []Value) Value {
+			_, ok := m[key(args)]
+			return ValueOf(ok)
+		}),
+		"set": function(func(this Value, args []Value) Value {
+			k := key(args)
+			if k == nil {
+				throwf("Invalid value used as weak map key")
+			}
+			m[k] = undefined
+			if len(args) > 1 {
+				m[k] = args[1]
+			}
+			return this
+		}),
+		"delete": function(func(this Value, args []Value) Value {
+			k := key(args)
+			_, ok := m[k]
+			delete(m, k)
+			return ValueOf(ok)
+		}),
+	})
+}
+
+// Returns the global object with the constructors the bindings use. The
+// WebGLRenderingContext and WebGL2RenderingContext constructors exist, as
+// in a browser supporting both, and can be deleted to emulate one that
+// does not.
+func newGlobal() Value {
+	g := newObject(&object{class: "Window"})
+	g.o.props["Object"] = constructor("Object", func(args []Value) Value {
+		return newObject(&object{class: "Object"})
+	})
+	g.o.props["Array"] = constructor("Array", func(args []Value) Value {
+		elems := make([]Value, 0, len(args))
+		return newObject(&object{class: "Array", elems: append(elems, args...)})
+	})
+	g.o.props["ArrayBuffer"] = constructor("ArrayBuffer", func(args []Value) Value {
+		n := 0
+		if len(args) > 0 {
+			n = args[0].Int()
+		}
+		return newArrayBuffer(n)
+	})
+	for class := range typedArrays {
+		g.o.props[class] = typedArrayConstructor(class)
+	}
+	g.o.props["WeakMap"] = constructor("WeakMap", newWeakMap)
+	for _, class := range []string{"WebGLRenderingContext", "WebGL2RenderingContext"} {
+		g.o.props[class] = constructor(class, func(args []Value) Value {
+			throwf("Illegal constructor")
+			return undefined
+		})
+	}
+	return g
+}
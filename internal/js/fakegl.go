@@ -0,0 +1,757 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(js && wasm)
+
+package js
+
+import (
+	"strconv"
+	"strings"
+)
+
+// webgl1Methods and webgl2Methods list the methods of the WebGL 1 and
+// WebGL 2 contexts with their accepted argument counts, following the
+// WebIDL of the specifications. WebGL 2 methods with the name of a WebGL 1
+// method replace its argument counts.
+const webgl1Methods = `
+activeTexture 1
+attachShader 2
+bindAttribLocation 3
+bindBuffer 2
+bindFramebuffer 2
+bindRenderbuffer 2
+bindTexture 2
+blendColor 4
+blendEquation 1
+blendEquationSeparate 2
+blendFunc 2
+blendFuncSeparate 4
+bufferData 3
+bufferSubData 3
+checkFramebufferStatus 1
+clear 1
+clearColor 4
+clearDepth 1
+clearStencil 1
+colorMask 4
+compileShader 1
+compressedTexImage2D 7
+compressedTexSubImage2D 8
+copyTexImage2D 8
+copyTexSubImage2D 8
+createBuffer 0
+createFramebuffer 0
+createProgram 0
+createRenderbuffer 0
+createShader 1
+createTexture 0
+cullFace 1
+deleteBuffer 1
+deleteFramebuffer 1
+deleteProgram 1
+deleteRenderbuffer 1
+deleteShader 1
+deleteTexture 1
+depthFunc 1
+depthMask 1
+depthRange 2
+detachShader 2
+disable 1
+disableVertexAttribArray 1
+drawArrays 3
+drawElements 4
+enable 1
+enableVertexAttribArray 1
+finish 0
+flush 0
+framebufferRenderbuffer 4
+framebufferTexture2D 5
+frontFace 1
+generateMipmap 1
+getActiveAttrib 2
+getActiveUniform 2
+getAttachedShaders 1
+getAttribLocation 2
+getBufferParameter 2
+getContextAttributes 0
+getError 0
+getExtension 1
+getFramebufferAttachmentParameter 3
+getParameter 1
+getProgramInfoLog 1
+getProgramParameter 2
+getRenderbufferParameter 2
+getShaderInfoLog 1
+getShaderParameter 2
+getShaderPrecisionFormat 2
+getShaderSource 1
+getSupportedExtensions 0
+getTexParameter 2
+getUniform 2
+getUniformLocation 2
+getVertexAttrib 2
+getVertexAttribOffset 2
+hint 2
+isBuffer 1
+isContextLost 0
+isEnabled 1
+isFramebuffer 1
+isProgram 1
+isRenderbuffer 1
+isShader 1
+isTexture 1
+lineWidth 1
+linkProgram 1
+pixelStorei 2
+polygonOffset 2
+readPixels 7
+renderbufferStorage 4
+sampleCoverage 2
+scissor 4
+shaderSource 2
+stencilFunc 3
+stencilFuncSeparate 4
+stencilMask 1
+stencilMaskSeparate 2
+stencilOp 3
+stencilOpSeparate 4
+texImage2D 6|9
+texParameterf 3
+texParameteri 3
+texSubImage2D 7|9
+uniform1f 2
+uniform2f 3
+uniform3f 4
+uniform4f 5
+uniform1i 2
+uniform2i 3
+uniform3i 4
+uniform4i 5
+uniform1fv 2
+uniform2fv 2
+uniform3fv 2
+uniform4fv 2
+uniform1iv 2
+uniform2iv 2
+uniform3iv 2
+uniform4iv 2
+uniformMatrix2fv 3
+uniformMatrix3fv 3
+uniformMatrix4fv 3
+useProgram 1
+validateProgram 1
+vertexAttrib1f 2
+vertexAttrib2f 3
+vertexAttrib3f 4
+vertexAttrib4f 5
+vertexAttrib1fv 2
+vertexAttrib2fv 2
+vertexAttrib3fv 2
+vertexAttrib4fv 2
+vertexAttribPointer 6
+viewport 4
+`
+
+const webgl2Methods = `
+bufferData 3|4|5
+bufferSubData 3|4|5
+compressedTexImage2D 7|8|9
+compressedTexSubImage2D 8|9|10
+readPixels 7|8
+texImage2D 6|9|10
+texSubImage2D 7|9|10
+uniform1fv 2|3|4
+uniform2fv 2|3|4
+uniform3fv 2|3|4
+uniform4fv 2|3|4
+uniform1iv 2|3|4
+uniform2iv 2|3|4
+uniform3iv 2|3|4
+uniform4iv 2|3|4
+uniformMatrix2fv 3|4|5
+uniformMatrix3fv 3|4|5
+uniformMatrix4fv 3|4|5
+copyBufferSubData 5
+getBufferSubData 3|4|5
+blitFramebuffer 10
+framebufferTextureLayer 5
+invalidateFramebuffer 2
+invalidateSubFramebuffer 6
+readBuffer 1
+getInternalformatParameter 3
+renderbufferStorageMultisample 5
+texStorage2D 5
+texStorage3D 6
+texImage3D 10|11
+texSubImage3D 11|12
+copyTexSubImage3D 9
+compressedTexImage3D 8|9|10
+compressedTexSubImage3D 10|11|12
+getFragDataLocation 2
+uniform1ui 2
+uniform2ui 3
+uniform3ui 4
+uniform4ui 5
+uniform1uiv 2|3|4
+uniform2uiv 2|3|4
+uniform3uiv 2|3|4
+uniform4uiv 2|3|4
+uniformMatrix2x3fv 3|4|5
+uniformMatrix3x2fv 3|4|5
+uniformMatrix2x4fv 3|4|5
+uniformMatrix4x2fv 3|4|5
+uniformMatrix3x4fv 3|4|5
+uniformMatrix4x3fv 3|4|5
+vertexAttribI4i 5
+vertexAttribI4iv 2
+vertexAttribI4ui 5
+vertexAttribI4uiv 2
+vertexAttribIPointer 5
+vertexAttribDivisor 2
+drawArraysInstanced 4
+drawElementsInstanced 5
+drawRangeElements 6
+drawBuffers 1
+clearBufferfv 3|4
+clearBufferiv 3|4
+clearBufferuiv 3|4
+clearBufferfi 4
+createQuery 0
+deleteQuery 1
+isQuery 1
+beginQuery 2
+endQuery 1
+getQuery 2
+getQueryParameter 2
+createSampler 0
+deleteSampler 1
+isSampler 1
+bindSampler 2
+samplerParameteri 3
+samplerParameterf 3
+getSamplerParameter 2
+fenceSync 2
+isSync 1
+deleteSync 1
+clientWaitSync 3
+waitSync 3
+getSyncParameter 2
+createTransformFeedback 0
+deleteTransformFeedback 1
+isTransformFeedback 1
+bindTransformFeedback 2
+beginTransformFeedback 1
+endTransformFeedback 0
+transformFeedbackVaryings 3
+getTransformFeedbackVarying 2
+pauseTransformFeedback 0
+resumeTransformFeedback 0
+bindBufferBase 3
+bindBufferRange 5
+getIndexedParameter 2
+getUniformIndices 2
+getActiveUniforms 3
+getUniformBlockIndex 2
+getActiveUniformBlockParameter 3
+getActiveUniformBlockName 2
+uniformBlockBinding 3
+createVertexArray 0
+deleteVertexArray 1
+isVertexArray 1
+bindVertexArray 1
+`
+
+// Parses a method table into argument counts by method name.
+func parseMethods(m map[string][]int, table string) map[string][]int {
+	for _, line := range strings.Split(strings.TrimSpace(table), "\n") {
+		name, counts, _ := strings.Cut(line, " ")
+		m[name] = nil
+		for _, c := range strings.Split(counts, "|") {
+			n, err := strconv.Atoi(c)
+			if err != nil {
+				panic(err)
+			}
+			m[name] = append(m[name], n)
+		}
+	}
+	return m
+}
+
+var (
+	webgl1 = parseMethods(make(map[string][]int), webgl1Methods)
+	webgl2 = parseMethods(parseMethods(make(map[string][]int), webgl1Methods), webgl2Methods)
+)
+
+// extensionMethods lists the methods of the extension objects by
+// extension name.
+var extensionMethods = map[string]map[string][]int{
+	"ANGLE_instanced_arrays": parseMethods(make(map[string][]int), `
+drawArraysInstancedANGLE 4
+drawElementsInstancedANGLE 5
+vertexAttribDivisorANGLE 2
+`),
+	"OES_vertex_array_object": parseMethods(make(map[string][]int), `
+createVertexArrayOES 0
+deleteVertexArrayOES 1
+isVertexArrayOES 1
+bindVertexArrayOES 1
+`),
+	"WEBGL_draw_buffers": parseMethods(make(map[string][]int), `
+drawBuffersWEBGL 1
+`),
+	"WEBGL_lose_context": parseMethods(make(map[string][]int), `
+loseContext 0
+restoreContext 0
+`),
+	"EXT_disjoint_timer_query": parseMethods(make(map[string][]int), `
+createQueryEXT 0
+deleteQueryEXT 1
+isQueryEXT 1
+beginQueryEXT 2
+endQueryEXT 1
+queryCounterEXT 2
+getQueryEXT 2
+getQueryObjectEXT 2
+`),
+	"EXT_disjoint_timer_query_webgl2": parseMethods(make(map[string][]int), `
+queryCounterEXT 2
+`),
+}
+
+// created maps the methods creating objects to the classes of the objects.
+var created = map[string]string{
+	"createBuffer":            "WebGLBuffer",
+	"createFramebuffer":       "WebGLFramebuffer",
+	"createProgram":           "WebGLProgram",
+	"createRenderbuffer":      "WebGLRenderbuffer",
+	"createShader":            "WebGLShader",
+	"createTexture":           "WebGLTexture",
+	"createQuery":             "WebGLQuery",
+	"createSampler":           "WebGLSampler",
+	"createTransformFeedback": "WebGLTransformFeedback",
+	"createVertexArray":       "WebGLVertexArrayObject",
+	"createVertexArrayOES":    "WebGLVertexArrayObjectOES",
+	"createQueryEXT":          "WebGLTimerQueryEXT",
+	"fenceSync":               "WebGLSync",
+	"getUniformLocation":      "WebGLUniformLocation",
+}
+
+// Call is a call made on a fake object.
+type Call struct {
+	Method string
+	Args   []Value
+}
+
+func (c Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = a.String()
+		if a.o != nil {
+			args[i] = a.o.class
+		}
+	}
+	return c.Method + "(" + strings.Join(args, ", ") + ")"
+}
+
+// WebGL is a fake WebGLRenderingContext or WebGL2RenderingContext. Its
+// methods record their calls, throw a TypeError for unknown names or
+// argument counts, and return objects for the create methods and the
+// values configured in the fields for the queries. Other queries return
+// null unless Results says otherwise.
+type WebGL struct {
+	// Value is the context object.
+	Value Value
+
+	// Version is 1 for a WebGLRenderingContext and 2 for a
+	// WebGL2RenderingContext.
+	Version int
+
+	// Calls are the calls made on the context, oldest first.
+	Calls []Call
+
+	// Attributes are the properties of the dictionary returned by
+	// getContextAttributes.
+	Attributes map[string]interface{}
+
+	// Extensions are the names returned by getSupportedExtensions.
+	// getExtension returns an object for them, with the methods of the
+	// extension, and null for other names. The loseContext and
+	// restoreContext methods of WEBGL_lose_context lose and restore the
+	// canvas of the context.
+	Extensions []string
+
+	// Parameters are the values returned by getParameter by parameter
+	// name. isEnabled returns the boolean values of capabilities.
+	Parameters map[uint32]interface{}
+
+	// Errors are returned by getError in order, followed by NO_ERROR.
+	Errors []uint32
+
+	// Lost is returned by isContextLost. While it is set, calls create no
+	// objects and queries return null, as on a lost context.
+	Lost bool
+
+	// Results, when set for a method, returns the result of a call
+	// instead of the default one.
+	Results map[string]func(args []Value) interface{}
+
+	methods    map[string][]int
+	canvas     *Canvas
+	extensions map[string]Value
+	objects    []*object
+	deleted    map[*object]bool
+	attached   map[*object][]Value
+	sources    map[*object]string
+}
+
+// Creates a fake WebGL context of version 1 or 2 with a drawing buffer of
+// 300x150 pixels, the WebGL default context attributes and no extensions.
+func NewWebGL(version int) *WebGL {
+	gl := &WebGL{
+		Version: version,
+		Attributes: map[string]interface{}{
+			"alpha":                        true,
+			"depth":                        true,
+			"stencil":                      false,
+			"antialias":                    true,
+			"premultipliedAlpha":           true,
+			"preserveDrawingBuffer":        false,
+			"powerPreference":              "default",
+			"failIfMajorPerformanceCaveat": false,
+			"desynchronized":               false,
+			"xrCompatible":                 false,
+		},
+		Parameters: make(map[uint32]interface{}),
+		Results:    make(map[string]func(args []Value) interface{}),
+		methods:    webgl1,
+		extensions: make(map[string]Value),
+		deleted:    make(map[*object]bool),
+		attached:   make(map[*object][]Value),
+		sources:    make(map[*object]string),
+	}
+	class := "WebGLRenderingContext"
+	if version == 2 {
+		class, gl.methods = "WebGL2RenderingContext", webgl2
+	}
+	props := map[string]Value{
+		"canvas":              null,
+		"drawingBufferWidth":  ValueOf(300),
+		"drawingBufferHeight": ValueOf(150),
+	}
+	for name, counts := range gl.methods {
+		props[name] = gl.method(class, name, counts)
+	}
+	gl.Value = newStrict(class, props)
+	return gl
+}
+
+// Returns a method of the context.
+func (gl *WebGL) method(class, name string, counts []int) Value {
+	return function(func(this Value, args []Value) Value {
+		ok := false
+		for _, n := range counts {
+			ok = ok || len(args) == n
+		}
+		if !ok {
+			throwf("%s.%s: %d arguments, want %v", class, name, len(args), counts)
+		}
+		gl.Calls = append(gl.Calls, Call{name, args})
+		if gl.Lost && name != "isContextLost" && name != "getError" && name != "restoreContext" {
+			return gl.lostResult(name)
+		}
+		if result, ok := gl.Results[name]; ok {
+			return ValueOf(result(args))
+		}
+		return gl.result(name, args)
+	})
+}
+
+// Returns the default result of a call.
+func (gl *WebGL) result(name string, args []Value) Value {
+	if class, ok := created[name]; ok {
+		v := newStrict(class, nil)
+		gl.objects = append(gl.objects, v.o)
+		return v
+	}
+	arg := func(i int) *object {
+		return args[i].o
+	}
+	switch name {
+	case "getError":
+		if len(gl.Errors) == 0 {
+			return ValueOf(0)
+		}
+		e := gl.Errors[0]
+		gl.Errors = gl.Errors[1:]
+		return ValueOf(e)
+	case "isContextLost":
+		return ValueOf(gl.Lost)
+	case "getContextAttributes":
+		props := make(map[string]Value)
+		for k, v := range gl.Attributes {
+			props[k] = ValueOf(v)
+		}
+		return newStrict("Object", props)
+	case "getSupportedExtensions":
+		names := make([]interface{}, len(gl.Extensions))
+		for i, name := range gl.Extensions {
+			names[i] = name
+		}
+		return ValueOf(names)
+	case "getExtension":
+		return gl.extension(args[0].String())
+	case "getParameter":
+		if v, ok := gl.Parameters[uint32(args[0].Int())]; ok {
+			return ValueOf(v)
+		}
+		return null
+	case "getShaderPrecisionFormat":
+		return newStrict("WebGLShaderPrecisionFormat", map[string]Value{
+			"rangeMin":  ValueOf(127),
+			"rangeMax":  ValueOf(127),
+			"precision": ValueOf(23),
+		})
+	case "checkFramebufferStatus":
+		return ValueOf(0x8CD5)
+	case "getAttribLocation", "getFragDataLocation":
+		return ValueOf(-1)
+	case "getShaderInfoLog", "getProgramInfoLog":
+		return ValueOf("")
+	case "shaderSource":
+		if o := arg(0); o != nil {
+			gl.sources[o] = args[1].String()
+		}
+	case "getShaderSource":
+		if o := arg(0); o != nil {
+			return ValueOf(gl.sources[o])
+		}
+		return null
+	case "attachShader":
+		if p := arg(0); p != nil {
+			gl.attached[p] = append(gl.attached[p], args[1])
+		}
+	case "detachShader":
+		if p := arg(0); p != nil {
+			shaders := gl.attached[p][:0]
+			for _, s := range gl.attached[p] {
+				if !s.Equal(args[1]) {
+					shaders = append(shaders, s)
+				}
+			}
+			gl.attached[p] = shaders
+		}
+	case "getAttachedShaders":
+		shaders := []interface{}{}
+		if p := arg(0); p != nil {
+			for _, s := range gl.attached[p] {
+				shaders = append(shaders, s)
+			}
+		}
+		return ValueOf(shaders)
+	case "isEnabled":
+		v, _ := gl.Parameters[uint32(args[0].Int())].(bool)
+		return ValueOf(v)
+	case "loseContext":
+		if gl.canvas != nil {
+			gl.canvas.Lose()
+		} else {
+			gl.lose()
+		}
+	case "restoreContext":
+		if gl.canvas != nil {
+			gl.canvas.Restore()
+		} else {
+			gl.Lost = false
+		}
+	}
+	if strings.HasPrefix(name, "delete") && len(args) == 1 {
+		if o := arg(0); o != nil {
+			gl.deleted[o] = true
+		}
+		return undefined
+	}
+	if class, ok := strings.CutPrefix(name, "is"); ok && len(args) == 1 {
+		o := arg(0)
+		for method, c := range created {
+			if c == "WebGL"+class || method == "create"+class {
+				return ValueOf(o != nil && o.class == c && !gl.deleted[o])
+			}
+		}
+	}
+	if strings.HasPrefix(name, "get") {
+		return null
+	}
+	return undefined
+}
+
+// Returns the result of a call on a lost context, which creates nothing
+// and reports every object as invalid.
+func (gl *WebGL) lostResult(name string) Value {
+	switch {
+	case strings.HasPrefix(name, "is"):
+		return ValueOf(false)
+	case strings.HasPrefix(name, "get") || created[name] != "":
+		return null
+	case name == "checkFramebufferStatus":
+		return ValueOf(0)
+	}
+	return undefined
+}
+
+// Loses the context, invalidating every object created so far and the
+// extension objects.
+func (gl *WebGL) lose() {
+	gl.Lost = true
+	for _, o := range gl.objects {
+		gl.deleted[o] = true
+	}
+	gl.objects = nil
+	clear(gl.extensions)
+}
+
+// Returns the object of a supported extension, or null.
+func (gl *WebGL) extension(name string) Value {
+	if v, ok := gl.extensions[name]; ok {
+		return v
+	}
+	for _, n := range gl.Extensions {
+		if n == name {
+			props := make(map[string]Value)
+			for method, counts := range extensionMethods[name] {
+				props[method] = gl.method(name, method, counts)
+			}
+			v := newStrict(name, props)
+			gl.extensions[name] = v
+			return v
+		}
+	}
+	return null
+}
+
+// Returns the recorded calls of a method.
+func (gl *WebGL) CallsTo(method string) []Call {
+	var calls []Call
+	for _, c := range gl.Calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Canvas is a fake HTMLCanvasElement.
+type Canvas struct {
+	// Value is the canvas object.
+	Value Value
+
+	// Contexts are the contexts returned by getContext by context name.
+	// Other names return null.
+	Contexts map[string]*WebGL
+
+	// Attributes is the context attributes dictionary passed to the last
+	// call to getContext, or undefined.
+	Attributes Value
+
+	listeners map[string][]Value
+}
+
+// Creates a fake canvas of 300x150 pixels whose getContext returns the
+// given contexts, a version 1 one for "webgl" and "experimental-webgl"
+// and a version 2 one for "webgl2".
+func NewCanvas(contexts ...*WebGL) *Canvas {
+	c := &Canvas{
+		Contexts:   make(map[string]*WebGL),
+		Attributes: undefined,
+		listeners:  make(map[string][]Value),
+	}
+	for _, gl := range contexts {
+		switch gl.Version {
+		case 1:
+			c.Contexts["webgl"] = gl
+			c.Contexts["experimental-webgl"] = gl
+		case 2:
+			c.Contexts["webgl2"] = gl
+		}
+	}
+	c.Value = newStrict("HTMLCanvasElement", map[string]Value{
+		"width":  ValueOf(300),
+		"height": ValueOf(150),
+		"getContext": function(func(this Value, args []Value) Value {
+			if len(args) < 1 || len(args) > 2 {
+				throwf("HTMLCanvasElement.getContext: %d arguments", len(args))
+			}
+			c.Attributes = undefined
+			if len(args) == 2 {
+				c.Attributes = args[1]
+			}
+			if gl, ok := c.Contexts[args[0].String()]; ok {
+				return gl.Value
+			}
+			return null
+		}),
+		"addEventListener": function(func(this Value, args []Value) Value {
+			if len(args) < 2 || len(args) > 3 {
+				throwf("HTMLCanvasElement.addEventListener: %d arguments", len(args))
+			}
+			typ := args[0].String()
+			c.listeners[typ] = append(c.listeners[typ], args[1])
+			return undefined
+		}),
+		"removeEventListener": function(func(this Value, args []Value) Value {
+			if len(args) < 2 || len(args) > 3 {
+				throwf("HTMLCanvasElement.removeEventListener: %d arguments", len(args))
+			}
+			typ := args[0].String()
+			for i, f := range c.listeners[typ] {
+				if f.Equal(args[1]) {
+					c.listeners[typ] = append(c.listeners[typ][:i:i], c.listeners[typ][i+1:]...)
+					break
+				}
+			}
+			return undefined
+		}),
+	})
+	for _, gl := range contexts {
+		gl.Value.Set("canvas", c.Value)
+		gl.canvas = c
+	}
+	return c
+}
+
+// Dispatches an event of the given type to the listeners of the canvas,
+// and returns whether one of them called preventDefault.
+func (c *Canvas) Dispatch(typ string) (prevented bool) {
+	event := newStrict("WebGLContextEvent", map[string]Value{
+		"type":          ValueOf(typ),
+		"statusMessage": ValueOf(""),
+		"preventDefault": function(func(this Value, args []Value) Value {
+			prevented = true
+			return undefined
+		}),
+	})
+	for _, f := range append([]Value(nil), c.listeners[typ]...) {
+		f.Invoke(event)
+	}
+	return prevented
+}
+
+// Loses the contexts of the canvas and dispatches webglcontextlost,
+// returning whether a listener called preventDefault to allow the
+// contexts to be restored.
+func (c *Canvas) Lose() bool {
+	for _, gl := range c.Contexts {
+		gl.lose()
+	}
+	return c.Dispatch("webglcontextlost")
+}
+
+// Restores the contexts of the canvas and dispatches
+// webglcontextrestored. The objects created before the loss stay invalid.
+func (c *Canvas) Restore() {
+	for _, gl := range c.Contexts {
+		gl.Lost = false
+	}
+	c.Dispatch("webglcontextrestored")
+}
@@ -0,0 +1,57 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && wasm
+
+package js
+
+import (
+	"syscall/js"
+)
+
+type (
+	Value      = js.Value
+	Type       = js.Type
+	Func       = js.Func
+	ValueError = js.ValueError
+)
+
+const (
+	TypeUndefined = js.TypeUndefined
+	TypeNull      = js.TypeNull
+	TypeBoolean   = js.TypeBoolean
+	TypeNumber    = js.TypeNumber
+	TypeString    = js.TypeString
+	TypeSymbol    = js.TypeSymbol
+	TypeObject    = js.TypeObject
+	TypeFunction  = js.TypeFunction
+)
+
+func Global() Value {
+	return js.Global()
+}
+
+func Null() Value {
+	return js.Null()
+}
+
+func Undefined() Value {
+	return js.Undefined()
+}
+
+func ValueOf(x interface{}) Value {
+	return js.ValueOf(x)
+}
+
+func FuncOf(fn func(this Value, args []Value) interface{}) Func {
+	return js.FuncOf(fn)
+}
+
+func CopyBytesToGo(dst []byte, src Value) int {
+	return js.CopyBytesToGo(dst, src)
+}
+
+func CopyBytesToJS(dst Value, src []byte) int {
+	return js.CopyBytesToJS(dst, src)
+}
@@ -0,0 +1,115 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+// Object is the value a RenderingContext implementation uses to identify
+// a WebGL object, such as the JavaScript object for a Context. A nil
+// Object is the null object.
+type Object interface{}
+
+// object is implemented by the WebGL object handle types so backends can
+// get at the Object they wrap.
+type object interface {
+	object() Object
+}
+
+// Buffer is a WebGLBuffer, storing vertex or index data.
+type Buffer struct{ Object }
+
+// Valid reports whether b refers to a WebGLBuffer rather than null.
+func (b Buffer) Valid() bool { return b.Object != nil }
+
+func (b Buffer) object() Object { return b.Object }
+
+// Texture is a WebGLTexture.
+type Texture struct{ Object }
+
+// Valid reports whether t refers to a WebGLTexture rather than null.
+func (t Texture) Valid() bool { return t.Object != nil }
+
+func (t Texture) object() Object { return t.Object }
+
+// Shader is a WebGLShader, either a vertex or a fragment shader.
+type Shader struct{ Object }
+
+// Valid reports whether s refers to a WebGLShader rather than null.
+func (s Shader) Valid() bool { return s.Object != nil }
+
+func (s Shader) object() Object { return s.Object }
+
+// Program is a WebGLProgram made of a linked vertex and fragment shader.
+type Program struct{ Object }
+
+// Valid reports whether p refers to a WebGLProgram rather than null.
+func (p Program) Valid() bool { return p.Object != nil }
+
+func (p Program) object() Object { return p.Object }
+
+// Framebuffer is a WebGLFramebuffer.
+type Framebuffer struct{ Object }
+
+// Valid reports whether f refers to a WebGLFramebuffer rather than null.
+// The zero Framebuffer may be bound to select the default framebuffer.
+func (f Framebuffer) Valid() bool { return f.Object != nil }
+
+func (f Framebuffer) object() Object { return f.Object }
+
+// Renderbuffer is a WebGLRenderbuffer.
+type Renderbuffer struct{ Object }
+
+// Valid reports whether r refers to a WebGLRenderbuffer rather than null.
+func (r Renderbuffer) Valid() bool { return r.Object != nil }
+
+func (r Renderbuffer) object() Object { return r.Object }
+
+// UniformLocation is a WebGLUniformLocation within a program.
+type UniformLocation struct{ Object }
+
+// Valid reports whether l refers to a uniform location. GetUniformLocation
+// returns an invalid location for uniforms that are not active.
+func (l UniformLocation) Valid() bool { return l.Object != nil }
+
+func (l UniformLocation) object() Object { return l.Object }
+
+// Query is a WebGLQuery, used with a Context2.
+type Query struct{ Object }
+
+// Valid reports whether q refers to a WebGLQuery rather than null.
+func (q Query) Valid() bool { return q.Object != nil }
+
+func (q Query) object() Object { return q.Object }
+
+// Sampler is a WebGLSampler, used with a Context2.
+type Sampler struct{ Object }
+
+// Valid reports whether s refers to a WebGLSampler rather than null.
+func (s Sampler) Valid() bool { return s.Object != nil }
+
+func (s Sampler) object() Object { return s.Object }
+
+// Sync is a WebGLSync fence, used with a Context2.
+type Sync struct{ Object }
+
+// Valid reports whether s refers to a WebGLSync rather than null.
+func (s Sync) Valid() bool { return s.Object != nil }
+
+func (s Sync) object() Object { return s.Object }
+
+// TransformFeedback is a WebGLTransformFeedback, used with a Context2.
+type TransformFeedback struct{ Object }
+
+// Valid reports whether t refers to a WebGLTransformFeedback rather than null.
+func (t TransformFeedback) Valid() bool { return t.Object != nil }
+
+func (t TransformFeedback) object() Object { return t.Object }
+
+// VertexArray is a WebGLVertexArrayObject, a WebGLVertexArrayObjectOES or
+// a vertex array object emulated by the Context.
+type VertexArray struct{ Object }
+
+// Valid reports whether v refers to a vertex array object rather than null.
+func (v VertexArray) Valid() bool { return v.Object != nil }
+
+func (v VertexArray) object() Object { return v.Object }
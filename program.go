@@ -0,0 +1,134 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ProgramInfo lists the active attributes and uniforms of a linked program
+// and caches their locations, so variables can be looked up by name without
+// further calls into WebGL.
+type ProgramInfo struct {
+	// Program is the program the information was read from.
+	Program Program
+
+	// Attributes are the active attributes, in the order WebGL reports them.
+	Attributes []ActiveInfo
+
+	// Uniforms are the active uniforms, in the order WebGL reports them.
+	Uniforms []ActiveInfo
+
+	gl       RenderingContext
+	attribs  map[string]attribEntry
+	uniforms map[string]uniformEntry
+
+	// State used by SetUniforms.
+	plans  map[reflect.Type][]uniformField
+	sent   map[string]*sentUniform
+	links  int
+	floats []float32
+	ints   []int32
+	ends   []int
+}
+
+type attribEntry struct {
+	info     ActiveInfo
+	location int
+}
+
+type uniformEntry struct {
+	info     ActiveInfo
+	location UniformLocation
+}
+
+// Reads the active attributes and uniforms of a linked program along with
+// their locations. Uniform arrays are expanded so every element can be
+// looked up by name, both as "u_weights" for the whole array and as
+// "u_weights[2]" for a single element.
+func GetProgramInfo(gl RenderingContext, program Program) *ProgramInfo {
+	p := &ProgramInfo{
+		Program:  program,
+		gl:       gl,
+		attribs:  make(map[string]attribEntry),
+		uniforms: make(map[string]uniformEntry),
+		plans:    make(map[reflect.Type][]uniformField),
+		sent:     make(map[string]*sentUniform),
+	}
+	p.links, _ = linkCount(gl)
+
+	n := gl.GetProgramParameteri(program, ACTIVE_ATTRIBUTES)
+	for i := 0; i < n; i++ {
+		info := gl.GetActiveAttrib(program, i)
+		if info.Name == "" {
+			continue
+		}
+		p.Attributes = append(p.Attributes, info)
+		location := -1
+		if !strings.HasPrefix(info.Name, "gl_") {
+			location = gl.GetAttribLocation(program, info.Name)
+		}
+		p.attribs[info.Name] = attribEntry{info, location}
+	}
+
+	n = gl.GetProgramParameteri(program, ACTIVE_UNIFORMS)
+	for i := 0; i < n; i++ {
+		info := gl.GetActiveUniform(program, i)
+		if info.Name == "" {
+			continue
+		}
+		p.Uniforms = append(p.Uniforms, info)
+
+		base := strings.TrimSuffix(info.Name, "[0]")
+		if base == info.Name && info.Size <= 1 {
+			p.uniforms[info.Name] = uniformEntry{info, gl.GetUniformLocation(program, info.Name)}
+			continue
+		}
+		first := gl.GetUniformLocation(program, base+"[0]")
+		array := info
+		array.Name = base
+		p.uniforms[base] = uniformEntry{array, first}
+		for j := 0; j < info.Size; j++ {
+			element := ActiveInfo{Name: base + "[" + strconv.Itoa(j) + "]", Size: 1, Type: info.Type}
+			location := first
+			if j > 0 {
+				location = gl.GetUniformLocation(program, element.Name)
+			}
+			p.uniforms[element.Name] = uniformEntry{element, location}
+		}
+	}
+	return p
+}
+
+// Returns the active attribute with the given name.
+func (p *ProgramInfo) Attribute(name string) (ActiveInfo, bool) {
+	e, ok := p.attribs[name]
+	return e.info, ok
+}
+
+// Returns the location of the named attribute, or -1 if the program
+// has no such active attribute.
+func (p *ProgramInfo) AttribLocation(name string) int {
+	if e, ok := p.attribs[name]; ok {
+		return e.location
+	}
+	return -1
+}
+
+// Returns the active uniform with the given name. Array uniforms can be
+// looked up by their base name, which reports the whole array, or by
+// element, which reports a single value.
+func (p *ProgramInfo) Uniform(name string) (ActiveInfo, bool) {
+	e, ok := p.uniforms[name]
+	return e.info, ok
+}
+
+// Returns the cached location of the named uniform, which is invalid if
+// the program has no such active uniform.
+func (p *ProgramInfo) UniformLocation(name string) UniformLocation {
+	return p.uniforms[name].location
+}
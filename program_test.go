@@ -0,0 +1,99 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl_test
+
+import (
+	"testing"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/soft"
+)
+
+const (
+	lightsVertex = `
+attribute vec3 a_pos;
+attribute vec2 a_uv;
+uniform mat4 u_mvp;
+varying vec2 v_uv;
+
+void main() {
+	v_uv = a_uv;
+	gl_Position = u_mvp * vec4(a_pos, 1.0);
+}`
+
+	lightsFragment = `
+precision mediump float;
+uniform vec4 u_lights[3];
+varying vec2 v_uv;
+
+void main() {
+	gl_FragColor = u_lights[0] + u_lights[1] * v_uv.x + u_lights[2] * v_uv.y;
+}`
+)
+
+func TestGetProgramInfo(t *testing.T) {
+	gl := soft.New(4, 4, nil)
+	program, err := webgl.LinkProgram(gl, lightsVertex, lightsFragment, map[string]int{"a_uv": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := webgl.GetProgramInfo(gl, program)
+	gl.Reset()
+
+	for _, name := range []string{"a_pos", "a_uv"} {
+		if _, ok := info.Attribute(name); !ok {
+			t.Errorf("attribute %s is missing", name)
+		}
+	}
+	if got := info.AttribLocation("a_uv"); got != 0 {
+		t.Errorf("a_uv is at location %d, want the bound 0", got)
+	}
+	if got := info.AttribLocation("a_missing"); got != -1 {
+		t.Errorf("a missing attribute is at location %d, want -1", got)
+	}
+
+	uniforms := []struct {
+		name string
+		typ  webgl.Enum
+		size int
+	}{
+		{"u_mvp", webgl.FLOAT_MAT4, 1},
+		{"u_lights", webgl.FLOAT_VEC4, 3},
+		{"u_lights[0]", webgl.FLOAT_VEC4, 1},
+		{"u_lights[1]", webgl.FLOAT_VEC4, 1},
+		{"u_lights[2]", webgl.FLOAT_VEC4, 1},
+	}
+	for _, u := range uniforms {
+		got, ok := info.Uniform(u.name)
+		if !ok || got.Name != u.name || got.Type != u.typ || got.Size != u.size {
+			t.Errorf("got uniform %+v for %s, want type %s and size %d", got, u.name, webgl.TypeName(u.typ), u.size)
+		}
+		if !info.UniformLocation(u.name).Valid() {
+			t.Errorf("uniform %s has no location", u.name)
+		}
+	}
+	if _, ok := info.Uniform("u_lights[3]"); ok || info.UniformLocation("u_lights[3]").Valid() {
+		t.Error("found an element past the end of u_lights")
+	}
+	if len(gl.Calls) > 0 {
+		t.Errorf("lookups made calls %v", gl.Calls)
+	}
+
+	// The cached locations are the ones WebGL returns.
+	if got := gl.GetAttribLocation(program, "a_pos"); got != info.AttribLocation("a_pos") {
+		t.Errorf("a_pos is at location %d, cached %d", got, info.AttribLocation("a_pos"))
+	}
+	for _, name := range []string{"u_mvp", "u_lights", "u_lights[1]", "u_lights[2]"} {
+		if got := gl.GetUniformLocation(program, name); got != info.UniformLocation(name) {
+			t.Errorf("uniform %s is at %v, cached %v", name, got, info.UniformLocation(name))
+		}
+	}
+	if info.UniformLocation("u_lights") != info.UniformLocation("u_lights[0]") {
+		t.Error("u_lights and u_lights[0] have different locations")
+	}
+	if info.UniformLocation("u_lights[1]") == info.UniformLocation("u_lights[0]") {
+		t.Error("u_lights[1] shares the location of u_lights[0]")
+	}
+}
@@ -0,0 +1,1243 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"unsafe"
+
+	"github.com/n2d/webgl"
+)
+
+// textureLevel identifies an image of a texture by its target, which is
+// a cube map face for cube maps, and mipmap level.
+type textureLevel struct {
+	target webgl.Enum
+	level  int
+}
+
+// Image is a texture image or the storage of a renderbuffer. The rows of
+// Pixels are tightly packed, starting with the bottom row. Renderbuffers
+// use their internal format as Format.
+type Image struct {
+	Width  int
+	Height int
+	Format webgl.Enum
+	Type   webgl.Enum
+	Pixels []byte
+}
+
+// Creates a zeroed image with bpp bytes per pixel.
+func newImage(width, height int, format, typ webgl.Enum, bpp int) *Image {
+	return &Image{
+		Width:  width,
+		Height: height,
+		Format: format,
+		Type:   typ,
+		Pixels: make([]byte, width*height*bpp),
+	}
+}
+
+// framebufferAttachment is an image attached to a framebuffer.
+type framebufferAttachment struct {
+	object *Object
+	target webgl.Enum
+	level  int
+}
+
+// Returns the bytes of a slice of fixed size numbers.
+func sliceBytes[T int8 | uint8 | int16 | uint16 | int32 | uint32 | float32](s []T) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+// Returns the bytes of the data passed to BufferData or BufferSubData.
+func bufferBytes(data interface{}) ([]byte, bool) {
+	switch data := data.(type) {
+	case []byte:
+		return data, true
+	case []int8:
+		return sliceBytes(data), true
+	case []int16:
+		return sliceBytes(data), true
+	case []uint16:
+		return sliceBytes(data), true
+	case []int32:
+		return sliceBytes(data), true
+	case []uint32:
+		return sliceBytes(data), true
+	case []float32:
+		return sliceBytes(data), true
+	case []float64:
+		return sliceBytes(convertSlice[float32](data)), true
+	case []int:
+		return sliceBytes(convertSlice[int32](data)), true
+	case []uint:
+		return sliceBytes(convertSlice[uint32](data)), true
+	}
+	return nil, false
+}
+
+// Returns a copy of s with its elements converted to T.
+func convertSlice[T int32 | uint32 | float32, S int | uint | float64](s []S) []T {
+	t := make([]T, len(s))
+	for i, v := range s {
+		t[i] = T(v)
+	}
+	return t
+}
+
+// Returns the buffer bound to a buffer target.
+func (c *Context) boundBuffer(target webgl.Enum) (*Object, bool) {
+	var b webgl.Buffer
+	switch target {
+	case webgl.ARRAY_BUFFER:
+		b = c.state.ArrayBuffer
+	case webgl.ELEMENT_ARRAY_BUFFER:
+		b = c.state.ElementArrayBuffer
+	default:
+		c.fail(webgl.INVALID_ENUM)
+		return nil, false
+	}
+	obj, _ := b.Object.(*Object)
+	if obj == nil {
+		c.fail(webgl.INVALID_OPERATION)
+		return nil, false
+	}
+	return obj, true
+}
+
+func (c *Context) BindBuffer(target webgl.Enum, buffer webgl.Buffer) {
+	c.record("BindBuffer", target, buffer)
+	b, ok := c.lookup(buffer.Object, "Buffer", false)
+	if !ok {
+		return
+	}
+	if target != webgl.ARRAY_BUFFER && target != webgl.ELEMENT_ARRAY_BUFFER {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if b != nil {
+		if b.target != 0 && b.target != target {
+			c.fail(webgl.INVALID_OPERATION)
+			return
+		}
+		b.target = target
+	}
+	if target == webgl.ARRAY_BUFFER {
+		c.state.ArrayBuffer = buffer
+	} else {
+		c.state.ElementArrayBuffer = buffer
+	}
+}
+
+func (c *Context) BufferData(target webgl.Enum, data interface{}, usage webgl.Enum) {
+	c.record("BufferData", target, data, usage)
+	if size, ok := data.(int); ok {
+		if size < 0 {
+			c.fail(webgl.INVALID_VALUE)
+			return
+		}
+		c.bufferData(target, make([]byte, size), usage)
+		return
+	}
+	b, ok := bufferBytes(data)
+	if !ok {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.bufferData(target, append([]byte{}, b...), usage)
+}
+
+func (c *Context) BufferDataBytes(target webgl.Enum, data []byte, usage webgl.Enum) {
+	c.record("BufferDataBytes", target, data, usage)
+	c.bufferData(target, append([]byte{}, data...), usage)
+}
+
+func (c *Context) BufferDataInt8(target webgl.Enum, data []int8, usage webgl.Enum) {
+	c.record("BufferDataInt8", target, data, usage)
+	c.bufferData(target, append([]byte{}, sliceBytes(data)...), usage)
+}
+
+func (c *Context) BufferDataInt16(target webgl.Enum, data []int16, usage webgl.Enum) {
+	c.record("BufferDataInt16", target, data, usage)
+	c.bufferData(target, append([]byte{}, sliceBytes(data)...), usage)
+}
+
+func (c *Context) BufferDataUint16(target webgl.Enum, data []uint16, usage webgl.Enum) {
+	c.record("BufferDataUint16", target, data, usage)
+	c.bufferData(target, append([]byte{}, sliceBytes(data)...), usage)
+}
+
+func (c *Context) BufferDataInt32(target webgl.Enum, data []int32, usage webgl.Enum) {
+	c.record("BufferDataInt32", target, data, usage)
+	c.bufferData(target, append([]byte{}, sliceBytes(data)...), usage)
+}
+
+func (c *Context) BufferDataUint32(target webgl.Enum, data []uint32, usage webgl.Enum) {
+	c.record("BufferDataUint32", target, data, usage)
+	c.bufferData(target, append([]byte{}, sliceBytes(data)...), usage)
+}
+
+func (c *Context) BufferDataFloat32(target webgl.Enum, data []float32, usage webgl.Enum) {
+	c.record("BufferDataFloat32", target, data, usage)
+	c.bufferData(target, append([]byte{}, sliceBytes(data)...), usage)
+}
+
+func (c *Context) bufferData(target webgl.Enum, data []byte, usage webgl.Enum) {
+	switch usage {
+	case webgl.STREAM_DRAW, webgl.STATIC_DRAW, webgl.DYNAMIC_DRAW:
+	default:
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	b, ok := c.boundBuffer(target)
+	if !ok {
+		return
+	}
+	b.data, b.usage = data, usage
+}
+
+func (c *Context) BufferSubData(target webgl.Enum, offset int, data interface{}) {
+	c.record("BufferSubData", target, offset, data)
+	b, ok := bufferBytes(data)
+	if !ok {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.bufferSubData(target, offset, b)
+}
+
+func (c *Context) BufferSubDataBytes(target webgl.Enum, offset int, data []byte) {
+	c.record("BufferSubDataBytes", target, offset, data)
+	c.bufferSubData(target, offset, data)
+}
+
+func (c *Context) BufferSubDataInt8(target webgl.Enum, offset int, data []int8) {
+	c.record("BufferSubDataInt8", target, offset, data)
+	c.bufferSubData(target, offset, sliceBytes(data))
+}
+
+func (c *Context) BufferSubDataInt16(target webgl.Enum, offset int, data []int16) {
+	c.record("BufferSubDataInt16", target, offset, data)
+	c.bufferSubData(target, offset, sliceBytes(data))
+}
+
+func (c *Context) BufferSubDataUint16(target webgl.Enum, offset int, data []uint16) {
+	c.record("BufferSubDataUint16", target, offset, data)
+	c.bufferSubData(target, offset, sliceBytes(data))
+}
+
+func (c *Context) BufferSubDataInt32(target webgl.Enum, offset int, data []int32) {
+	c.record("BufferSubDataInt32", target, offset, data)
+	c.bufferSubData(target, offset, sliceBytes(data))
+}
+
+func (c *Context) BufferSubDataUint32(target webgl.Enum, offset int, data []uint32) {
+	c.record("BufferSubDataUint32", target, offset, data)
+	c.bufferSubData(target, offset, sliceBytes(data))
+}
+
+func (c *Context) BufferSubDataFloat32(target webgl.Enum, offset int, data []float32) {
+	c.record("BufferSubDataFloat32", target, offset, data)
+	c.bufferSubData(target, offset, sliceBytes(data))
+}
+
+func (c *Context) bufferSubData(target webgl.Enum, offset int, data []byte) {
+	b, ok := c.boundBuffer(target)
+	if !ok {
+		return
+	}
+	if offset < 0 || offset+len(data) > len(b.data) {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	copy(b.data[offset:], data)
+}
+
+func (c *Context) GetBufferParameteri(target, pname webgl.Enum) int {
+	c.record("GetBufferParameteri", target, pname)
+	b, ok := c.boundBuffer(target)
+	if !ok {
+		return 0
+	}
+	switch pname {
+	case webgl.BUFFER_SIZE:
+		return len(b.data)
+	case webgl.BUFFER_USAGE:
+		if b.usage == 0 {
+			return int(webgl.STATIC_DRAW)
+		}
+		return int(b.usage)
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+// Returns the contents of a buffer created by the context, or nil if the
+// handle does not refer to one.
+func (c *Context) BufferContents(buffer webgl.Buffer) []byte {
+	if b := c.object(buffer.Object, "Buffer"); b != nil {
+		return b.data
+	}
+	return nil
+}
+
+// Returns the image of a texture for a target, which is a cube map face
+// for cube maps, and mipmap level, or nil if it has not been specified.
+// The image is shared with the context, so a backend that renders may
+// write to its pixels.
+func (c *Context) TextureImage(texture webgl.Texture, target webgl.Enum, level int) *Image {
+	if t := c.object(texture.Object, "Texture"); t != nil {
+		return t.levels[textureLevel{target, level}]
+	}
+	return nil
+}
+
+// Returns the value of a parameter set by TexParameteri for a texture,
+// or 0 if the handle does not refer to a texture.
+func (c *Context) TextureParameter(texture webgl.Texture, pname webgl.Enum) int {
+	if t := c.object(texture.Object, "Texture"); t != nil {
+		return t.params[pname]
+	}
+	return 0
+}
+
+// Returns the storage of a renderbuffer, or nil if it has none. The
+// image is shared with the context like those of TextureImage.
+func (c *Context) RenderbufferImage(renderbuffer webgl.Renderbuffer) *Image {
+	if r := c.object(renderbuffer.Object, "Renderbuffer"); r != nil {
+		return r.storage
+	}
+	return nil
+}
+
+// Returns the image attached to an attachment point of a framebuffer, or
+// nil if there is none.
+func (c *Context) AttachedImage(framebuffer webgl.Framebuffer, attachment webgl.Enum) *Image {
+	if f := c.object(framebuffer.Object, "Framebuffer"); f != nil {
+		if a, ok := f.attachments[attachment]; ok {
+			return a.image()
+		}
+	}
+	return nil
+}
+
+func (c *Context) BindTexture(target webgl.Enum, texture webgl.Texture) {
+	c.record("BindTexture", target, texture)
+	t, ok := c.lookup(texture.Object, "Texture", false)
+	if !ok {
+		return
+	}
+	if target != webgl.TEXTURE_2D && target != webgl.TEXTURE_CUBE_MAP {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if t != nil {
+		if t.target != 0 && t.target != target {
+			c.fail(webgl.INVALID_OPERATION)
+			return
+		}
+		t.target = target
+	}
+	if target == webgl.TEXTURE_2D {
+		c.state.unit().Texture2D = texture
+	} else {
+		c.state.unit().TextureCubeMap = texture
+	}
+}
+
+// Returns the texture bound to the active unit for a texture target,
+// which may be a cube map face, raising errors as WebGL would.
+func (c *Context) boundTexture(target webgl.Enum, faces bool) (*Object, bool) {
+	var t webgl.Texture
+	switch {
+	case target == webgl.TEXTURE_2D:
+		t = c.state.unit().Texture2D
+	case target == webgl.TEXTURE_CUBE_MAP && !faces:
+		t = c.state.unit().TextureCubeMap
+	case target >= webgl.TEXTURE_CUBE_MAP_POSITIVE_X && target <= webgl.TEXTURE_CUBE_MAP_NEGATIVE_Z && faces:
+		t = c.state.unit().TextureCubeMap
+	default:
+		c.fail(webgl.INVALID_ENUM)
+		return nil, false
+	}
+	obj, _ := t.Object.(*Object)
+	if obj == nil {
+		c.fail(webgl.INVALID_OPERATION)
+		return nil, false
+	}
+	return obj, true
+}
+
+// Returns whether n is a power of two.
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// Returns the number of bytes per pixel of a format and type, or 0 if
+// the combination is not valid in WebGL 1.
+func pixelSize(format, typ webgl.Enum) int {
+	switch typ {
+	case webgl.UNSIGNED_BYTE:
+		switch format {
+		case webgl.ALPHA, webgl.LUMINANCE:
+			return 1
+		case webgl.LUMINANCE_ALPHA:
+			return 2
+		case webgl.RGB:
+			return 3
+		case webgl.RGBA:
+			return 4
+		}
+	case webgl.UNSIGNED_SHORT_5_6_5:
+		if format == webgl.RGB {
+			return 2
+		}
+	case webgl.UNSIGNED_SHORT_4_4_4_4, webgl.UNSIGNED_SHORT_5_5_5_1:
+		if format == webgl.RGBA {
+			return 2
+		}
+	}
+	return 0
+}
+
+// Returns the number of bytes of a block of pixels laid out with the
+// given row alignment, or -1 for invalid sizes.
+func imageSize(width, height, bpp, alignment int) int {
+	if width < 0 || height < 0 {
+		return -1
+	}
+	if width == 0 || height == 0 {
+		return 0
+	}
+	row := (width*bpp + alignment - 1) / alignment * alignment
+	return row*(height-1) + width*bpp
+}
+
+// Checks the level and size of a texture image for a target.
+func (c *Context) checkTexSize(target webgl.Enum, level, width, height int) bool {
+	max := MaxTextureSize
+	if target != webgl.TEXTURE_2D {
+		max = MaxCubeMapTextureSize
+		if width != height {
+			c.fail(webgl.INVALID_VALUE)
+			return false
+		}
+	}
+	if level < 0 || width < 0 || height < 0 || width > max>>uint(level) || height > max>>uint(level) {
+		c.fail(webgl.INVALID_VALUE)
+		return false
+	}
+	if level > 0 && (!isPowerOfTwo(width) || !isPowerOfTwo(height)) {
+		c.fail(webgl.INVALID_VALUE)
+		return false
+	}
+	return true
+}
+
+func (c *Context) TexImage2DPixels(target webgl.Enum, level int, internalFormat webgl.Enum, width, height, border int, format, typ webgl.Enum, pixels []byte) {
+	c.record("TexImage2DPixels", target, level, internalFormat, width, height, border, format, typ, pixels)
+	t, ok := c.boundTexture(target, true)
+	if !ok {
+		return
+	}
+	bpp := pixelSize(format, typ)
+	switch {
+	case bpp == 0:
+		c.fail(webgl.INVALID_ENUM)
+		return
+	case internalFormat != format:
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	case border != 0:
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	if !c.checkTexSize(target, level, width, height) {
+		return
+	}
+	img := newImage(width, height, format, typ, bpp)
+	if pixels != nil && !c.unpack(img, 0, 0, width, height, pixels) {
+		return
+	}
+	t.levels[textureLevel{target, level}] = img
+}
+
+// Copies pixel rows laid out with UNPACK_ALIGNMENT into the tightly
+// packed pixels of a texture image, raising INVALID_OPERATION if there
+// are too few.
+func (c *Context) unpack(img *Image, x, y, width, height int, pixels []byte) bool {
+	bpp := pixelSize(img.Format, img.Type)
+	align := c.state.PixelStore[webgl.UNPACK_ALIGNMENT]
+	if len(pixels) < imageSize(width, height, bpp, align) {
+		c.fail(webgl.INVALID_OPERATION)
+		return false
+	}
+	row := (width*bpp + align - 1) / align * align
+	for i := 0; i < height; i++ {
+		dst := img.Pixels[((y+i)*img.Width+x)*bpp:]
+		copy(dst[:width*bpp], pixels[i*row:])
+	}
+	return true
+}
+
+func (c *Context) TexSubImage2DPixels(target webgl.Enum, level, xoffset, yoffset, width, height int, format, typ webgl.Enum, pixels []byte) {
+	c.record("TexSubImage2DPixels", target, level, xoffset, yoffset, width, height, format, typ, pixels)
+	t, ok := c.boundTexture(target, true)
+	if !ok {
+		return
+	}
+	bpp := pixelSize(format, typ)
+	if bpp == 0 {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	img := t.levels[textureLevel{target, level}]
+	switch {
+	case img == nil || img.Format != format || img.Type != typ:
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	case xoffset < 0 || yoffset < 0 || width < 0 || height < 0 ||
+		xoffset+width > img.Width || yoffset+height > img.Height:
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.unpack(img, xoffset, yoffset, width, height, pixels)
+}
+
+func (c *Context) CompressedTexImage2D(target webgl.Enum, level int, internalFormat webgl.Enum, width, height, border int, data []byte) {
+	c.record("CompressedTexImage2D", target, level, internalFormat, width, height, border, data)
+	if _, ok := c.boundTexture(target, true); ok {
+		// No compressed texture formats are supported.
+		c.fail(webgl.INVALID_ENUM)
+	}
+}
+
+func (c *Context) CompressedTexSubImage2D(target webgl.Enum, level, xoffset, yoffset, width, height int, format webgl.Enum, data []byte) {
+	c.record("CompressedTexSubImage2D", target, level, xoffset, yoffset, width, height, format, data)
+	if _, ok := c.boundTexture(target, true); ok {
+		c.fail(webgl.INVALID_ENUM)
+	}
+}
+
+func (c *Context) CopyTexImage2D(target webgl.Enum, level int, internal webgl.Enum, x, y, w, h, border int) {
+	c.record("CopyTexImage2D", target, level, internal, x, y, w, h, border)
+	t, ok := c.boundTexture(target, true)
+	if !ok {
+		return
+	}
+	bpp := pixelSize(internal, webgl.UNSIGNED_BYTE)
+	switch {
+	case bpp == 0:
+		c.fail(webgl.INVALID_ENUM)
+		return
+	case border != 0:
+		c.fail(webgl.INVALID_VALUE)
+		return
+	case !c.checkTexSize(target, level, w, h) || !c.checkFramebuffer():
+		return
+	}
+	t.levels[textureLevel{target, level}] = newImage(w, h, internal, webgl.UNSIGNED_BYTE, bpp)
+}
+
+func (c *Context) CopyTexSubImage2D(target webgl.Enum, level, xoffset, yoffset, x, y, w, h int) {
+	c.record("CopyTexSubImage2D", target, level, xoffset, yoffset, x, y, w, h)
+	t, ok := c.boundTexture(target, true)
+	if !ok {
+		return
+	}
+	img := t.levels[textureLevel{target, level}]
+	switch {
+	case img == nil:
+		c.fail(webgl.INVALID_OPERATION)
+	case xoffset < 0 || yoffset < 0 || w < 0 || h < 0 || xoffset+w > img.Width || yoffset+h > img.Height:
+		c.fail(webgl.INVALID_VALUE)
+	default:
+		c.checkFramebuffer()
+	}
+}
+
+func (c *Context) GenerateMipmap(target webgl.Enum) {
+	c.record("GenerateMipmap", target)
+	t, ok := c.boundTexture(target, false)
+	if !ok {
+		return
+	}
+	base := webgl.TEXTURE_2D
+	if target == webgl.TEXTURE_CUBE_MAP {
+		base = webgl.TEXTURE_CUBE_MAP_POSITIVE_X
+	}
+	img := t.levels[textureLevel{base, 0}]
+	if img == nil || !isPowerOfTwo(img.Width) || !isPowerOfTwo(img.Height) {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	faces := []webgl.Enum{base}
+	if target == webgl.TEXTURE_CUBE_MAP {
+		faces = []webgl.Enum{
+			webgl.TEXTURE_CUBE_MAP_POSITIVE_X, webgl.TEXTURE_CUBE_MAP_NEGATIVE_X,
+			webgl.TEXTURE_CUBE_MAP_POSITIVE_Y, webgl.TEXTURE_CUBE_MAP_NEGATIVE_Y,
+			webgl.TEXTURE_CUBE_MAP_POSITIVE_Z, webgl.TEXTURE_CUBE_MAP_NEGATIVE_Z,
+		}
+	}
+	bpp := pixelSize(img.Format, img.Type)
+	for _, face := range faces {
+		w, h := img.Width, img.Height
+		for level := 1; w > 1 || h > 1; level++ {
+			w, h = max(w/2, 1), max(h/2, 1)
+			t.levels[textureLevel{face, level}] = newImage(w, h, img.Format, img.Type, bpp)
+		}
+	}
+}
+
+// Returns whether a texture parameter and value are valid.
+func validTexParameter(pname webgl.Enum, param int) bool {
+	p := webgl.Enum(param)
+	switch pname {
+	case webgl.TEXTURE_MIN_FILTER:
+		switch p {
+		case webgl.NEAREST, webgl.LINEAR, webgl.NEAREST_MIPMAP_NEAREST,
+			webgl.LINEAR_MIPMAP_NEAREST, webgl.NEAREST_MIPMAP_LINEAR, webgl.LINEAR_MIPMAP_LINEAR:
+			return true
+		}
+	case webgl.TEXTURE_MAG_FILTER:
+		return p == webgl.NEAREST || p == webgl.LINEAR
+	case webgl.TEXTURE_WRAP_S, webgl.TEXTURE_WRAP_T:
+		return p == webgl.REPEAT || p == webgl.CLAMP_TO_EDGE || p == webgl.MIRRORED_REPEAT
+	}
+	return false
+}
+
+func (c *Context) TexParameteri(target, pname, param webgl.Enum) {
+	c.record("TexParameteri", target, pname, param)
+	c.texParameter(target, pname, int(param))
+}
+
+func (c *Context) TexParameterf(target, pname webgl.Enum, param float32) {
+	c.record("TexParameterf", target, pname, param)
+	c.texParameter(target, pname, int(param))
+}
+
+func (c *Context) texParameter(target, pname webgl.Enum, param int) {
+	t, ok := c.boundTexture(target, false)
+	if !ok {
+		return
+	}
+	if !validTexParameter(pname, param) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	t.params[pname] = param
+}
+
+func (c *Context) GetTexParameteri(target, pname webgl.Enum) int {
+	c.record("GetTexParameteri", target, pname)
+	t, ok := c.boundTexture(target, false)
+	if !ok {
+		return 0
+	}
+	if v, ok := t.params[pname]; ok {
+		return v
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+func (c *Context) BindRenderbuffer(target webgl.Enum, renderbuffer webgl.Renderbuffer) {
+	c.record("BindRenderbuffer", target, renderbuffer)
+	r, ok := c.lookup(renderbuffer.Object, "Renderbuffer", false)
+	if !ok {
+		return
+	}
+	if target != webgl.RENDERBUFFER {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if r != nil {
+		r.target = target
+	}
+	c.state.Renderbuffer = renderbuffer
+}
+
+// Returns the bound renderbuffer, raising errors as WebGL would.
+func (c *Context) boundRenderbuffer(target webgl.Enum) (*Object, bool) {
+	if target != webgl.RENDERBUFFER {
+		c.fail(webgl.INVALID_ENUM)
+		return nil, false
+	}
+	r, _ := c.state.Renderbuffer.Object.(*Object)
+	if r == nil {
+		c.fail(webgl.INVALID_OPERATION)
+		return nil, false
+	}
+	return r, true
+}
+
+func (c *Context) RenderbufferStorage(target, internalFormat webgl.Enum, width, height int) {
+	c.record("RenderbufferStorage", target, internalFormat, width, height)
+	r, ok := c.boundRenderbuffer(target)
+	if !ok {
+		return
+	}
+	switch internalFormat {
+	case webgl.RGBA4, webgl.RGB565, webgl.RGB5_A1, webgl.DEPTH_COMPONENT16,
+		webgl.STENCIL_INDEX8, webgl.DEPTH_STENCIL:
+	default:
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if width < 0 || height < 0 || width > MaxRenderbufferSize || height > MaxRenderbufferSize {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	r.storage = newImage(width, height, internalFormat, renderbufferType(internalFormat), renderbufferSize(internalFormat))
+}
+
+func (c *Context) GetRenderbufferParameteri(target, pname webgl.Enum) int {
+	c.record("GetRenderbufferParameteri", target, pname)
+	r, ok := c.boundRenderbuffer(target)
+	if !ok {
+		return 0
+	}
+	switch pname {
+	case webgl.RENDERBUFFER_WIDTH:
+		if r.storage == nil {
+			return 0
+		}
+		return r.storage.Width
+	case webgl.RENDERBUFFER_HEIGHT:
+		if r.storage == nil {
+			return 0
+		}
+		return r.storage.Height
+	case webgl.RENDERBUFFER_INTERNAL_FORMAT:
+		if r.storage == nil {
+			return int(webgl.RGBA4)
+		}
+		return int(r.storage.Format)
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+func (c *Context) GetRenderbufferInternalFormat(target webgl.Enum) webgl.Enum {
+	c.record("GetRenderbufferInternalFormat", target)
+	r, ok := c.boundRenderbuffer(target)
+	if !ok {
+		return 0
+	}
+	if r.storage == nil {
+		return webgl.RGBA4
+	}
+	return r.storage.Format
+}
+
+// Returns the pixel type used to store a renderbuffer format.
+func renderbufferType(format webgl.Enum) webgl.Enum {
+	switch format {
+	case webgl.RGBA4:
+		return webgl.UNSIGNED_SHORT_4_4_4_4
+	case webgl.RGB565:
+		return webgl.UNSIGNED_SHORT_5_6_5
+	case webgl.RGB5_A1:
+		return webgl.UNSIGNED_SHORT_5_5_5_1
+	case webgl.DEPTH_COMPONENT16:
+		return webgl.UNSIGNED_SHORT
+	case webgl.DEPTH_STENCIL:
+		return webgl.UNSIGNED_INT_24_8
+	}
+	return webgl.UNSIGNED_BYTE
+}
+
+// Returns the number of bytes per pixel of a renderbuffer format.
+func renderbufferSize(format webgl.Enum) int {
+	switch format {
+	case webgl.STENCIL_INDEX8:
+		return 1
+	case webgl.DEPTH_STENCIL:
+		return 4
+	}
+	return 2
+}
+
+func (c *Context) BindFramebuffer(target webgl.Enum, framebuffer webgl.Framebuffer) {
+	c.record("BindFramebuffer", target, framebuffer)
+	f, ok := c.lookup(framebuffer.Object, "Framebuffer", false)
+	if !ok {
+		return
+	}
+	if target != webgl.FRAMEBUFFER {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if f != nil {
+		f.target = target
+	}
+	c.state.Framebuffer = framebuffer
+}
+
+// Returns the bound framebuffer object, raising errors as WebGL would
+// when the default framebuffer is bound.
+func (c *Context) boundFramebuffer(target webgl.Enum) (*Object, bool) {
+	if target != webgl.FRAMEBUFFER {
+		c.fail(webgl.INVALID_ENUM)
+		return nil, false
+	}
+	f, _ := c.state.Framebuffer.Object.(*Object)
+	if f == nil {
+		c.fail(webgl.INVALID_OPERATION)
+		return nil, false
+	}
+	return f, true
+}
+
+// Returns whether attachment is a WebGL 1 attachment point.
+func isAttachment(attachment webgl.Enum) bool {
+	switch attachment {
+	case webgl.COLOR_ATTACHMENT0, webgl.DEPTH_ATTACHMENT,
+		webgl.STENCIL_ATTACHMENT, webgl.DEPTH_STENCIL_ATTACHMENT:
+		return true
+	}
+	return false
+}
+
+func (c *Context) FrameBufferRenderBuffer(target, attachment, renderbufferTarget webgl.Enum, renderbuffer webgl.Renderbuffer) {
+	c.record("FrameBufferRenderBuffer", target, attachment, renderbufferTarget, renderbuffer)
+	f, ok := c.boundFramebuffer(target)
+	if !ok {
+		return
+	}
+	r, ok := c.lookup(renderbuffer.Object, "Renderbuffer", false)
+	if !ok {
+		return
+	}
+	if !isAttachment(attachment) || renderbufferTarget != webgl.RENDERBUFFER {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if r == nil {
+		delete(f.attachments, attachment)
+		return
+	}
+	f.attachments[attachment] = framebufferAttachment{object: r, target: webgl.RENDERBUFFER}
+}
+
+func (c *Context) FramebufferTexture2D(target, attachment, textarget webgl.Enum, texture webgl.Texture, level int) {
+	c.record("FramebufferTexture2D", target, attachment, textarget, texture, level)
+	f, ok := c.boundFramebuffer(target)
+	if !ok {
+		return
+	}
+	t, ok := c.lookup(texture.Object, "Texture", false)
+	if !ok {
+		return
+	}
+	if !isAttachment(attachment) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if t == nil {
+		delete(f.attachments, attachment)
+		return
+	}
+	switch {
+	case textarget == webgl.TEXTURE_2D && t.target == webgl.TEXTURE_2D:
+	case textarget >= webgl.TEXTURE_CUBE_MAP_POSITIVE_X && textarget <= webgl.TEXTURE_CUBE_MAP_NEGATIVE_Z &&
+		t.target == webgl.TEXTURE_CUBE_MAP:
+	default:
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	if level != 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	f.attachments[attachment] = framebufferAttachment{object: t, target: textarget, level: level}
+}
+
+func (c *Context) GetFramebufferAttachmentParameteri(target, attachment, pname webgl.Enum) int {
+	c.record("GetFramebufferAttachmentParameteri", target, attachment, pname)
+	f, ok := c.boundFramebuffer(target)
+	if !ok {
+		return 0
+	}
+	if !isAttachment(attachment) {
+		c.fail(webgl.INVALID_ENUM)
+		return 0
+	}
+	a, attached := f.attachments[attachment]
+	switch pname {
+	case webgl.FRAMEBUFFER_ATTACHMENT_OBJECT_TYPE:
+		switch {
+		case !attached:
+			return int(webgl.NONE)
+		case a.object.Kind == "Texture":
+			return int(webgl.TEXTURE)
+		}
+		return int(webgl.RENDERBUFFER)
+	case webgl.FRAMEBUFFER_ATTACHMENT_TEXTURE_LEVEL:
+		if attached && a.object.Kind == "Texture" {
+			return a.level
+		}
+	case webgl.FRAMEBUFFER_ATTACHMENT_TEXTURE_CUBE_MAP_FACE:
+		if attached && a.object.Kind == "Texture" {
+			if a.target == webgl.TEXTURE_2D {
+				return 0
+			}
+			return int(a.target)
+		}
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+// Returns the attached image, or nil if it has not been specified.
+func (a framebufferAttachment) image() *Image {
+	if a.object.Kind == "Renderbuffer" {
+		return a.object.storage
+	}
+	return a.object.levels[textureLevel{a.target, a.level}]
+}
+
+// Returns the completeness status of the bound framebuffer.
+func (c *Context) framebufferStatus() webgl.Enum {
+	f, _ := c.state.Framebuffer.Object.(*Object)
+	if f == nil {
+		return webgl.FRAMEBUFFER_COMPLETE
+	}
+	if len(f.attachments) == 0 {
+		return webgl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT
+	}
+	_, depth := f.attachments[webgl.DEPTH_ATTACHMENT]
+	_, stencil := f.attachments[webgl.STENCIL_ATTACHMENT]
+	_, depthStencil := f.attachments[webgl.DEPTH_STENCIL_ATTACHMENT]
+	if depthStencil && (depth || stencil) || depth && stencil {
+		return webgl.FRAMEBUFFER_UNSUPPORTED
+	}
+	width, height := -1, -1
+	for point, a := range f.attachments {
+		img := a.image()
+		if img == nil || img.Width == 0 || img.Height == 0 || !attachable(point, img.Format) {
+			return webgl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT
+		}
+		if width >= 0 && (img.Width != width || img.Height != height) {
+			return webgl.FRAMEBUFFER_INCOMPLETE_DIMENSIONS
+		}
+		width, height = img.Width, img.Height
+	}
+	return webgl.FRAMEBUFFER_COMPLETE
+}
+
+// Returns whether an image format may be attached to an attachment point.
+func attachable(point, format webgl.Enum) bool {
+	switch point {
+	case webgl.COLOR_ATTACHMENT0:
+		switch format {
+		case webgl.RGBA4, webgl.RGB565, webgl.RGB5_A1, webgl.RGBA, webgl.RGB:
+			return true
+		}
+	case webgl.DEPTH_ATTACHMENT:
+		return format == webgl.DEPTH_COMPONENT16
+	case webgl.STENCIL_ATTACHMENT:
+		return format == webgl.STENCIL_INDEX8
+	case webgl.DEPTH_STENCIL_ATTACHMENT:
+		return format == webgl.DEPTH_STENCIL
+	}
+	return false
+}
+
+func (c *Context) CheckFramebufferStatus(target webgl.Enum) webgl.Enum {
+	c.record("CheckFramebufferStatus", target)
+	if target != webgl.FRAMEBUFFER {
+		c.fail(webgl.INVALID_ENUM)
+		return 0
+	}
+	return c.framebufferStatus()
+}
+
+// Raises INVALID_FRAMEBUFFER_OPERATION if the bound framebuffer cannot
+// be drawn to or read from.
+func (c *Context) checkFramebuffer() bool {
+	if c.framebufferStatus() != webgl.FRAMEBUFFER_COMPLETE {
+		c.fail(webgl.INVALID_FRAMEBUFFER_OPERATION)
+		return false
+	}
+	return true
+}
+
+func (c *Context) Clear(mask webgl.Enum) {
+	c.record("Clear", mask)
+	c.snapshot()
+	if mask&^(webgl.COLOR_BUFFER_BIT|webgl.DEPTH_BUFFER_BIT|webgl.STENCIL_BUFFER_BIT) != 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.checkFramebuffer()
+}
+
+func (c *Context) ReadPixelsBytes(x, y, width, height int, format, typ webgl.Enum, pixels []byte) {
+	c.record("ReadPixelsBytes", x, y, width, height, format, typ)
+	if format != webgl.RGBA || typ != webgl.UNSIGNED_BYTE {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	if width < 0 || height < 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	if !c.checkFramebuffer() {
+		return
+	}
+	if len(pixels) < imageSize(width, height, 4, c.state.PixelStore[webgl.PACK_ALIGNMENT]) {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	for i := range pixels {
+		pixels[i] = 0
+	}
+}
+
+// Returns the vertex attribute at index, raising INVALID_VALUE if it is
+// out of range.
+func (c *Context) attrib(index int) *VertexAttrib {
+	if index < 0 || index >= MaxVertexAttribs {
+		c.fail(webgl.INVALID_VALUE)
+		return nil
+	}
+	return &c.state.Attribs[index]
+}
+
+func (c *Context) EnableVertexAttribArray(index int) {
+	c.record("EnableVertexAttribArray", index)
+	if a := c.attrib(index); a != nil {
+		a.Enabled = true
+	}
+}
+
+func (c *Context) DisableVertexAttribArray(index int) {
+	c.record("DisableVertexAttribArray", index)
+	if a := c.attrib(index); a != nil {
+		a.Enabled = false
+	}
+}
+
+// Returns the size in bytes of a vertex attribute component type.
+func typeSize(typ webgl.Enum) int {
+	switch typ {
+	case webgl.BYTE, webgl.UNSIGNED_BYTE:
+		return 1
+	case webgl.SHORT, webgl.UNSIGNED_SHORT:
+		return 2
+	case webgl.FLOAT:
+		return 4
+	}
+	return 0
+}
+
+func (c *Context) VertexAttribPointer(index, size int, typ webgl.Enum, normal bool, stride, offset int) {
+	c.record("VertexAttribPointer", index, size, typ, normal, stride, offset)
+	a := c.attrib(index)
+	if a == nil {
+		return
+	}
+	n := typeSize(typ)
+	switch {
+	case n == 0:
+		c.fail(webgl.INVALID_ENUM)
+		return
+	case size < 1 || size > 4 || stride < 0 || stride > 255 || offset < 0:
+		c.fail(webgl.INVALID_VALUE)
+		return
+	case stride%n != 0 || offset%n != 0:
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	case c.state.ArrayBuffer.Object == nil:
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	a.Buffer = c.state.ArrayBuffer
+	a.Size, a.Type, a.Normalized = size, typ, normal
+	a.Stride, a.Offset = stride, offset
+}
+
+func (c *Context) GetVertexAttribOffset(index int, pname webgl.Enum) int {
+	c.record("GetVertexAttribOffset", index, pname)
+	a := c.attrib(index)
+	if a == nil {
+		return 0
+	}
+	if pname != webgl.VERTEX_ATTRIB_ARRAY_POINTER {
+		c.fail(webgl.INVALID_ENUM)
+		return 0
+	}
+	return a.Offset
+}
+
+// Sets the constant value of a vertex attribute.
+func (c *Context) vertexAttrib(index int, v []float32) {
+	a := c.attrib(index)
+	if a == nil {
+		return
+	}
+	value := [4]float32{0, 0, 0, 1}
+	copy(value[:], v)
+	a.Value = value
+}
+
+func (c *Context) VertexAttrib1f(index int, x float32) {
+	c.record("VertexAttrib1f", index, x)
+	c.vertexAttrib(index, []float32{x})
+}
+
+func (c *Context) VertexAttrib2f(index int, x, y float32) {
+	c.record("VertexAttrib2f", index, x, y)
+	c.vertexAttrib(index, []float32{x, y})
+}
+
+func (c *Context) VertexAttrib3f(index int, x, y, z float32) {
+	c.record("VertexAttrib3f", index, x, y, z)
+	c.vertexAttrib(index, []float32{x, y, z})
+}
+
+func (c *Context) VertexAttrib4f(index int, x, y, z, w float32) {
+	c.record("VertexAttrib4f", index, x, y, z, w)
+	c.vertexAttrib(index, []float32{x, y, z, w})
+}
+
+// Sets the constant value of a vertex attribute from a slice that must
+// hold at least n values.
+func (c *Context) vertexAttribv(index int, values []float32, n int) {
+	if len(values) < n {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.vertexAttrib(index, values[:n])
+}
+
+func (c *Context) VertexAttrib1fv(index int, values []float32) {
+	c.record("VertexAttrib1fv", index, values)
+	c.vertexAttribv(index, values, 1)
+}
+
+func (c *Context) VertexAttrib2fv(index int, values []float32) {
+	c.record("VertexAttrib2fv", index, values)
+	c.vertexAttribv(index, values, 2)
+}
+
+func (c *Context) VertexAttrib3fv(index int, values []float32) {
+	c.record("VertexAttrib3fv", index, values)
+	c.vertexAttribv(index, values, 3)
+}
+
+func (c *Context) VertexAttrib4fv(index int, values []float32) {
+	c.record("VertexAttrib4fv", index, values)
+	c.vertexAttribv(index, values, 4)
+}
+
+// Returns whether mode is a primitive mode.
+func isDrawMode(mode webgl.Enum) bool {
+	switch mode {
+	case webgl.POINTS, webgl.LINES, webgl.LINE_LOOP, webgl.LINE_STRIP,
+		webgl.TRIANGLES, webgl.TRIANGLE_STRIP, webgl.TRIANGLE_FAN:
+		return true
+	}
+	return false
+}
+
+// Checks the state shared by DrawArrays and DrawElements, and that the
+// enabled vertex attributes hold vertices up to maxVertex.
+func (c *Context) checkDraw(maxVertex int) bool {
+	p, _ := c.state.Program.Object.(*Object)
+	if p == nil || !p.linked {
+		c.fail(webgl.INVALID_OPERATION)
+		return false
+	}
+	if !c.checkFramebuffer() {
+		return false
+	}
+	if maxVertex < 0 {
+		return true
+	}
+	for i := range c.state.Attribs {
+		a := &c.state.Attribs[i]
+		if !a.Enabled {
+			continue
+		}
+		b, _ := a.Buffer.Object.(*Object)
+		if b == nil || b.Deleted {
+			c.fail(webgl.INVALID_OPERATION)
+			return false
+		}
+		size := a.Size * typeSize(a.Type)
+		stride := a.Stride
+		if stride == 0 {
+			stride = size
+		}
+		if a.Offset+maxVertex*stride+size > len(b.data) {
+			c.fail(webgl.INVALID_OPERATION)
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Context) DrawArrays(mode webgl.Enum, first, count int) {
+	c.record("DrawArrays", mode, first, count)
+	c.snapshot()
+	if !isDrawMode(mode) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if first < 0 || count < 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.checkDraw(first + count - 1)
+}
+
+func (c *Context) DrawElements(mode webgl.Enum, count int, typ webgl.Enum, offset int) {
+	c.record("DrawElements", mode, count, typ, offset)
+	c.snapshot()
+	if !isDrawMode(mode) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	var n int
+	switch typ {
+	case webgl.UNSIGNED_BYTE:
+		n = 1
+	case webgl.UNSIGNED_SHORT:
+		n = 2
+	default:
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if count < 0 || offset < 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	if offset%n != 0 {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	b, _ := c.state.ElementArrayBuffer.Object.(*Object)
+	if b == nil || offset+count*n > len(b.data) {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	maxVertex := -1
+	for i := 0; i < count; i++ {
+		var index int
+		if n == 1 {
+			index = int(b.data[offset+i])
+		} else {
+			index = int(b.data[offset+2*i]) | int(b.data[offset+2*i+1])<<8
+		}
+		maxVertex = max(maxVertex, index)
+	}
+	c.checkDraw(maxVertex)
+}
@@ -0,0 +1,812 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/n2d/webgl"
+)
+
+// Object is the webgl.Object behind the handles created by a Context.
+type Object struct {
+	// Kind is the name of the handle type, such as "Buffer".
+	Kind string
+
+	// ID numbers the objects of a context in creation order, from 1.
+	ID int
+
+	// Deleted reports whether the object has been deleted.
+	Deleted bool
+
+	ctx *Context
+
+	// Buffers.
+	target webgl.Enum
+	data   []byte
+	usage  webgl.Enum
+
+	// Textures, using target as well.
+	levels map[textureLevel]*Image
+	params map[webgl.Enum]int
+
+	// Shaders, using log for programs as well.
+	shaderType webgl.Enum
+	source     string
+	compiled   bool
+	log        string
+
+	// Programs.
+	shaders   []*Object
+	bindings  map[string]int
+	linked    bool
+	validated bool
+	attribs   []webgl.ActiveInfo
+	uniforms  []webgl.ActiveInfo
+	locations map[string]int
+	uniformAt map[string]*location
+	values    map[string]interface{}
+
+	// Renderbuffers.
+	storage *Image
+
+	// Framebuffers.
+	attachments map[webgl.Enum]framebufferAttachment
+}
+
+func (o *Object) String() string {
+	return fmt.Sprintf("%s#%d", o.Kind, o.ID)
+}
+
+// location is the webgl.Object behind uniform locations.
+type location struct {
+	program *Object
+	name    string
+}
+
+func (l *location) String() string {
+	return fmt.Sprintf("%v[%q]", l.program, l.name)
+}
+
+// Creates an object of the given kind.
+func (c *Context) newObject(kind string) *Object {
+	c.ids++
+	return &Object{Kind: kind, ID: c.ids, ctx: c}
+}
+
+// Returns the object behind a handle of the given kind, or nil for the
+// null object. Objects of another kind or context raise INVALID_OPERATION
+// and return ok false, as do deleted objects unless allowDeleted is set.
+func (c *Context) lookup(o webgl.Object, kind string, allowDeleted bool) (obj *Object, ok bool) {
+	if o == nil {
+		return nil, true
+	}
+	obj, _ = o.(*Object)
+	if obj == nil || obj.ctx != c || obj.Kind != kind || obj.Deleted && !allowDeleted {
+		c.fail(webgl.INVALID_OPERATION)
+		return nil, false
+	}
+	return obj, true
+}
+
+// Returns whether o is a live object of this context of the given kind.
+func (c *Context) is(o webgl.Object, kind string) bool {
+	obj, _ := o.(*Object)
+	return obj != nil && obj.ctx == c && obj.Kind == kind && !obj.Deleted
+}
+
+// Returns the live object of this context of the given kind behind a
+// handle, or nil, without raising errors.
+func (c *Context) object(o webgl.Object, kind string) *Object {
+	if c.is(o, kind) {
+		return o.(*Object)
+	}
+	return nil
+}
+
+// Deletes the object behind a handle, returning it if it was live.
+func (c *Context) remove(o webgl.Object, kind string) *Object {
+	obj, ok := c.lookup(o, kind, true)
+	if !ok || obj == nil || obj.Deleted {
+		return nil
+	}
+	obj.Deleted = true
+	return obj
+}
+
+func (c *Context) CreateBuffer() webgl.Buffer {
+	c.record("CreateBuffer")
+	return webgl.Buffer{Object: c.newObject("Buffer")}
+}
+
+func (c *Context) CreateTexture() webgl.Texture {
+	c.record("CreateTexture")
+	t := c.newObject("Texture")
+	t.levels = make(map[textureLevel]*Image)
+	t.params = map[webgl.Enum]int{
+		webgl.TEXTURE_MIN_FILTER: int(webgl.NEAREST_MIPMAP_LINEAR),
+		webgl.TEXTURE_MAG_FILTER: int(webgl.LINEAR),
+		webgl.TEXTURE_WRAP_S:     int(webgl.REPEAT),
+		webgl.TEXTURE_WRAP_T:     int(webgl.REPEAT),
+	}
+	return webgl.Texture{Object: t}
+}
+
+func (c *Context) CreateFramebuffer() webgl.Framebuffer {
+	c.record("CreateFramebuffer")
+	f := c.newObject("Framebuffer")
+	f.attachments = make(map[webgl.Enum]framebufferAttachment)
+	return webgl.Framebuffer{Object: f}
+}
+
+func (c *Context) CreateRenderbuffer() webgl.Renderbuffer {
+	c.record("CreateRenderbuffer")
+	return webgl.Renderbuffer{Object: c.newObject("Renderbuffer")}
+}
+
+func (c *Context) CreateShader(typ webgl.Enum) webgl.Shader {
+	c.record("CreateShader", typ)
+	if typ != webgl.VERTEX_SHADER && typ != webgl.FRAGMENT_SHADER {
+		c.fail(webgl.INVALID_ENUM)
+		return webgl.Shader{}
+	}
+	s := c.newObject("Shader")
+	s.shaderType = typ
+	return webgl.Shader{Object: s}
+}
+
+func (c *Context) CreateProgram() webgl.Program {
+	c.record("CreateProgram")
+	p := c.newObject("Program")
+	p.bindings = make(map[string]int)
+	p.locations = make(map[string]int)
+	p.uniformAt = make(map[string]*location)
+	p.values = make(map[string]interface{})
+	return webgl.Program{Object: p}
+}
+
+func (c *Context) DeleteBuffer(buffer webgl.Buffer) {
+	c.record("DeleteBuffer", buffer)
+	b := c.remove(buffer.Object, "Buffer")
+	if b == nil {
+		return
+	}
+	s := &c.state
+	if s.ArrayBuffer.Object == b {
+		s.ArrayBuffer = webgl.Buffer{}
+	}
+	if s.ElementArrayBuffer.Object == b {
+		s.ElementArrayBuffer = webgl.Buffer{}
+	}
+}
+
+func (c *Context) DeleteTexture(texture webgl.Texture) {
+	c.record("DeleteTexture", texture)
+	t := c.remove(texture.Object, "Texture")
+	if t == nil {
+		return
+	}
+	for i := range c.state.Textures {
+		u := &c.state.Textures[i]
+		if u.Texture2D.Object == t {
+			u.Texture2D = webgl.Texture{}
+		}
+		if u.TextureCubeMap.Object == t {
+			u.TextureCubeMap = webgl.Texture{}
+		}
+	}
+	c.detach(t)
+}
+
+func (c *Context) DeleteFramebuffer(framebuffer webgl.Framebuffer) {
+	c.record("DeleteFramebuffer", framebuffer)
+	f := c.remove(framebuffer.Object, "Framebuffer")
+	if f != nil && c.state.Framebuffer.Object == f {
+		c.state.Framebuffer = webgl.Framebuffer{}
+	}
+}
+
+func (c *Context) DeleteRenderbuffer(renderbuffer webgl.Renderbuffer) {
+	c.record("DeleteRenderbuffer", renderbuffer)
+	r := c.remove(renderbuffer.Object, "Renderbuffer")
+	if r == nil {
+		return
+	}
+	if c.state.Renderbuffer.Object == r {
+		c.state.Renderbuffer = webgl.Renderbuffer{}
+	}
+	c.detach(r)
+}
+
+// Detaches a deleted texture or renderbuffer from the bound framebuffer.
+func (c *Context) detach(o *Object) {
+	f, _ := c.state.Framebuffer.Object.(*Object)
+	if f == nil {
+		return
+	}
+	for point, a := range f.attachments {
+		if a.object == o {
+			delete(f.attachments, point)
+		}
+	}
+}
+
+func (c *Context) DeleteShader(shader webgl.Shader) {
+	c.record("DeleteShader", shader)
+	c.remove(shader.Object, "Shader")
+}
+
+func (c *Context) DeleteProgram(program webgl.Program) {
+	c.record("DeleteProgram", program)
+	c.remove(program.Object, "Program")
+}
+
+func (c *Context) IsBuffer(buffer webgl.Buffer) bool {
+	c.record("IsBuffer", buffer)
+	b, _ := buffer.Object.(*Object)
+	return c.is(buffer.Object, "Buffer") && b.target != 0
+}
+
+func (c *Context) IsTexture(texture webgl.Texture) bool {
+	c.record("IsTexture", texture)
+	t, _ := texture.Object.(*Object)
+	return c.is(texture.Object, "Texture") && t.target != 0
+}
+
+func (c *Context) IsFramebuffer(framebuffer webgl.Framebuffer) bool {
+	c.record("IsFramebuffer", framebuffer)
+	f, _ := framebuffer.Object.(*Object)
+	return c.is(framebuffer.Object, "Framebuffer") && f.target != 0
+}
+
+func (c *Context) IsRenderbuffer(renderbuffer webgl.Renderbuffer) bool {
+	c.record("IsRenderbuffer", renderbuffer)
+	r, _ := renderbuffer.Object.(*Object)
+	return c.is(renderbuffer.Object, "Renderbuffer") && r.target != 0
+}
+
+func (c *Context) IsShader(shader webgl.Shader) bool {
+	c.record("IsShader", shader)
+	return c.is(shader.Object, "Shader")
+}
+
+func (c *Context) IsProgram(program webgl.Program) bool {
+	c.record("IsProgram", program)
+	return c.is(program.Object, "Program")
+}
+
+func (c *Context) ShaderSource(shader webgl.Shader, source string) {
+	c.record("ShaderSource", shader, source)
+	if s, ok := c.lookup(shader.Object, "Shader", false); ok && s != nil {
+		s.source = source
+	}
+}
+
+func (c *Context) GetShaderSource(shader webgl.Shader) string {
+	c.record("GetShaderSource", shader)
+	if s, ok := c.lookup(shader.Object, "Shader", false); ok && s != nil {
+		return s.source
+	}
+	return ""
+}
+
+func (c *Context) CompileShader(shader webgl.Shader) {
+	c.record("CompileShader", shader)
+	s, ok := c.lookup(shader.Object, "Shader", false)
+	if !ok || s == nil {
+		return
+	}
+	s.compiled, s.log = true, ""
+	if c.Compile != nil {
+		if err := c.Compile(s.shaderType, s.source); err != nil {
+			s.compiled, s.log = false, err.Error()
+		}
+	}
+}
+
+func (c *Context) GetShaderParameterb(shader webgl.Shader, pname webgl.Enum) bool {
+	c.record("GetShaderParameterb", shader, pname)
+	s, ok := c.lookup(shader.Object, "Shader", true)
+	if !ok || s == nil {
+		return false
+	}
+	switch pname {
+	case webgl.COMPILE_STATUS:
+		return s.compiled
+	case webgl.DELETE_STATUS:
+		return s.Deleted
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return false
+}
+
+func (c *Context) GetShaderParameteri(shader webgl.Shader, pname webgl.Enum) int {
+	c.record("GetShaderParameteri", shader, pname)
+	s, ok := c.lookup(shader.Object, "Shader", true)
+	if !ok || s == nil {
+		return 0
+	}
+	if pname == webgl.SHADER_TYPE {
+		return int(s.shaderType)
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+func (c *Context) GetShaderInfoLog(shader webgl.Shader) string {
+	c.record("GetShaderInfoLog", shader)
+	if s, ok := c.lookup(shader.Object, "Shader", false); ok && s != nil {
+		return s.log
+	}
+	return ""
+}
+
+func (c *Context) AttachShader(program webgl.Program, shader webgl.Shader) {
+	c.record("AttachShader", program, shader)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok {
+		return
+	}
+	s, ok := c.lookup(shader.Object, "Shader", false)
+	if !ok {
+		return
+	}
+	if p == nil || s == nil {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	for _, attached := range p.shaders {
+		if attached == s || attached.shaderType == s.shaderType {
+			c.fail(webgl.INVALID_OPERATION)
+			return
+		}
+	}
+	p.shaders = append(p.shaders, s)
+}
+
+func (c *Context) DetachShader(program webgl.Program, shader webgl.Shader) {
+	c.record("DetachShader", program, shader)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok {
+		return
+	}
+	s, ok := c.lookup(shader.Object, "Shader", true)
+	if !ok {
+		return
+	}
+	if p == nil || s == nil {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	for i, attached := range p.shaders {
+		if attached == s {
+			p.shaders = append(p.shaders[:i], p.shaders[i+1:]...)
+			return
+		}
+	}
+	c.fail(webgl.INVALID_OPERATION)
+}
+
+func (c *Context) GetAttachedShaders(program webgl.Program) []webgl.Shader {
+	c.record("GetAttachedShaders", program)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok || p == nil {
+		return nil
+	}
+	shaders := make([]webgl.Shader, len(p.shaders))
+	for i, s := range p.shaders {
+		shaders[i] = webgl.Shader{Object: s}
+	}
+	return shaders
+}
+
+func (c *Context) BindAttribLocation(program webgl.Program, index int, name string) {
+	c.record("BindAttribLocation", program, index, name)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok || p == nil {
+		return
+	}
+	if index < 0 || index >= MaxVertexAttribs {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	if strings.HasPrefix(name, "gl_") || strings.HasPrefix(name, "webgl_") {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	p.bindings[name] = index
+}
+
+// Returns the attached shader of the given type.
+func (p *Object) shader(typ webgl.Enum) *Object {
+	for _, s := range p.shaders {
+		if s.shaderType == typ {
+			return s
+		}
+	}
+	return nil
+}
+
+// Returns the number of LinkProgram calls made on a program, which
+// webgl.ProgramInfo.SetUniforms uses to upload its values again after a
+// program is relinked.
+func (c *Context) LinkCount() int {
+	return c.links
+}
+
+func (c *Context) LinkProgram(program webgl.Program) {
+	c.record("LinkProgram", program)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok || p == nil {
+		return
+	}
+	c.links++
+	p.linked, p.log = false, ""
+	p.attribs, p.uniforms = nil, nil
+	p.locations = make(map[string]int)
+	p.uniformAt = make(map[string]*location)
+	p.values = make(map[string]interface{})
+
+	vs, fs := p.shader(webgl.VERTEX_SHADER), p.shader(webgl.FRAGMENT_SHADER)
+	switch {
+	case vs == nil || fs == nil:
+		p.log = "ERROR: program must have a vertex and a fragment shader attached"
+		return
+	case !vs.compiled || !fs.compiled:
+		p.log = "ERROR: attached shaders must be compiled"
+		return
+	}
+	if c.Link != nil {
+		attribs, uniforms, err := c.Link(program, vs.source, fs.source)
+		if err != nil {
+			p.log = err.Error()
+			return
+		}
+		p.attribs, p.uniforms = attribs, uniforms
+	}
+
+	// Assign attribute locations, honoring the bound ones first.
+	used := make(map[int]bool)
+	for _, a := range p.attribs {
+		if loc, ok := p.bindings[a.Name]; ok {
+			p.locations[a.Name] = loc
+			used[loc] = true
+		}
+	}
+	next := 0
+	for _, a := range p.attribs {
+		if _, ok := p.locations[a.Name]; ok {
+			continue
+		}
+		for used[next] {
+			next++
+		}
+		p.locations[a.Name] = next
+		used[next] = true
+	}
+	p.linked = true
+}
+
+func (c *Context) ValidateProgram(program webgl.Program) {
+	c.record("ValidateProgram", program)
+	if p, ok := c.lookup(program.Object, "Program", false); ok && p != nil {
+		p.validated = p.linked
+	}
+}
+
+func (c *Context) GetProgramParameterb(program webgl.Program, pname webgl.Enum) bool {
+	c.record("GetProgramParameterb", program, pname)
+	p, ok := c.lookup(program.Object, "Program", true)
+	if !ok || p == nil {
+		return false
+	}
+	switch pname {
+	case webgl.LINK_STATUS:
+		return p.linked
+	case webgl.DELETE_STATUS:
+		return p.Deleted
+	case webgl.VALIDATE_STATUS:
+		return p.validated
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return false
+}
+
+func (c *Context) GetProgramParameteri(program webgl.Program, pname webgl.Enum) int {
+	c.record("GetProgramParameteri", program, pname)
+	p, ok := c.lookup(program.Object, "Program", true)
+	if !ok || p == nil {
+		return 0
+	}
+	switch pname {
+	case webgl.ATTACHED_SHADERS:
+		return len(p.shaders)
+	case webgl.ACTIVE_ATTRIBUTES:
+		return len(p.attribs)
+	case webgl.ACTIVE_UNIFORMS:
+		return len(p.uniforms)
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+func (c *Context) GetProgramInfoLog(program webgl.Program) string {
+	c.record("GetProgramInfoLog", program)
+	if p, ok := c.lookup(program.Object, "Program", false); ok && p != nil {
+		return p.log
+	}
+	return ""
+}
+
+func (c *Context) GetActiveAttrib(program webgl.Program, index int) webgl.ActiveInfo {
+	c.record("GetActiveAttrib", program, index)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok || p == nil {
+		return webgl.ActiveInfo{}
+	}
+	if index < 0 || index >= len(p.attribs) {
+		c.fail(webgl.INVALID_VALUE)
+		return webgl.ActiveInfo{}
+	}
+	return p.attribs[index]
+}
+
+func (c *Context) GetActiveUniform(program webgl.Program, index int) webgl.ActiveInfo {
+	c.record("GetActiveUniform", program, index)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok || p == nil {
+		return webgl.ActiveInfo{}
+	}
+	if index < 0 || index >= len(p.uniforms) {
+		c.fail(webgl.INVALID_VALUE)
+		return webgl.ActiveInfo{}
+	}
+	return p.uniforms[index]
+}
+
+func (c *Context) GetAttribLocation(program webgl.Program, name string) int {
+	c.record("GetAttribLocation", program, name)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok || p == nil {
+		return -1
+	}
+	if !p.linked {
+		c.fail(webgl.INVALID_OPERATION)
+		return -1
+	}
+	if loc, ok := p.locations[name]; ok {
+		return loc
+	}
+	return -1
+}
+
+// Returns the location of an attribute of a linked program like
+// GetAttribLocation, without recording a call.
+func (c *Context) AttribLocation(program webgl.Program, name string) int {
+	if p := c.object(program.Object, "Program"); p != nil && p.linked {
+		if loc, ok := p.locations[name]; ok {
+			return loc
+		}
+	}
+	return -1
+}
+
+func (c *Context) GetUniformLocation(program webgl.Program, name string) webgl.UniformLocation {
+	c.record("GetUniformLocation", program, name)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok || p == nil {
+		return webgl.UniformLocation{}
+	}
+	if !p.linked {
+		c.fail(webgl.INVALID_OPERATION)
+		return webgl.UniformLocation{}
+	}
+	if _, ok := activeUniform(p.uniforms, name); !ok {
+		return webgl.UniformLocation{}
+	}
+	// The first element of an array shares the location of the array.
+	name = strings.TrimSuffix(name, "[0]")
+	l, ok := p.uniformAt[name]
+	if !ok {
+		l = &location{program: p, name: name}
+		p.uniformAt[name] = l
+	}
+	return webgl.UniformLocation{Object: l}
+}
+
+// Returns the active uniform a location name refers to, matching array
+// elements such as "u_weights[2]" and the array name without a suffix.
+func activeUniform(uniforms []webgl.ActiveInfo, name string) (webgl.ActiveInfo, bool) {
+	for _, u := range uniforms {
+		base := strings.TrimSuffix(u.Name, "[0]")
+		if name == u.Name || name == base {
+			return u, true
+		}
+		if base != u.Name && strings.HasPrefix(name, base+"[") && strings.HasSuffix(name, "]") {
+			var i int
+			if _, err := fmt.Sscanf(name[len(base):], "[%d]", &i); err == nil && i >= 0 && i < u.Size {
+				return u, true
+			}
+		}
+	}
+	return webgl.ActiveInfo{}, false
+}
+
+func (c *Context) UseProgram(program webgl.Program) {
+	c.record("UseProgram", program)
+	p, ok := c.lookup(program.Object, "Program", false)
+	if !ok {
+		return
+	}
+	if p != nil && !p.linked {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	c.state.Program = program
+}
+
+// Returns the values last set for the uniform at the given location name
+// of a program, as a []float32 for float uniforms or a []int32 for the
+// others, or nil if no value has been set.
+func (c *Context) Uniform(program webgl.Program, name string) interface{} {
+	p, _ := program.Object.(*Object)
+	if p == nil || p.values == nil {
+		return nil
+	}
+	return p.values[strings.TrimSuffix(name, "[0]")]
+}
+
+// Returns the names of the uniforms of a program that have been set, in
+// sorted order.
+func (c *Context) UniformNames(program webgl.Program) []string {
+	p, _ := program.Object.(*Object)
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.values))
+	for name := range p.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stores a uniform value for the current program.
+func (c *Context) setUniform(l webgl.UniformLocation, value interface{}) {
+	if l.Object == nil {
+		return
+	}
+	loc, _ := l.Object.(*location)
+	current, _ := c.state.Program.Object.(*Object)
+	if current == nil || loc == nil || loc.program != current {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	current.values[loc.name] = value
+}
+
+func (c *Context) Uniform1f(location webgl.UniformLocation, x float32) {
+	c.record("Uniform1f", location, x)
+	c.setUniform(location, []float32{x})
+}
+
+func (c *Context) Uniform2f(location webgl.UniformLocation, x, y float32) {
+	c.record("Uniform2f", location, x, y)
+	c.setUniform(location, []float32{x, y})
+}
+
+func (c *Context) Uniform3f(location webgl.UniformLocation, x, y, z float32) {
+	c.record("Uniform3f", location, x, y, z)
+	c.setUniform(location, []float32{x, y, z})
+}
+
+func (c *Context) Uniform4f(location webgl.UniformLocation, x, y, z, w float32) {
+	c.record("Uniform4f", location, x, y, z, w)
+	c.setUniform(location, []float32{x, y, z, w})
+}
+
+func (c *Context) Uniform1i(location webgl.UniformLocation, x int) {
+	c.record("Uniform1i", location, x)
+	c.setUniform(location, []int32{int32(x)})
+}
+
+func (c *Context) Uniform2i(location webgl.UniformLocation, x, y int) {
+	c.record("Uniform2i", location, x, y)
+	c.setUniform(location, []int32{int32(x), int32(y)})
+}
+
+func (c *Context) Uniform3i(location webgl.UniformLocation, x, y, z int) {
+	c.record("Uniform3i", location, x, y, z)
+	c.setUniform(location, []int32{int32(x), int32(y), int32(z)})
+}
+
+func (c *Context) Uniform4i(location webgl.UniformLocation, x, y, z, w int) {
+	c.record("Uniform4i", location, x, y, z, w)
+	c.setUniform(location, []int32{int32(x), int32(y), int32(z), int32(w)})
+}
+
+// Stores float uniform values made of n component elements.
+func (c *Context) uniformfv(location webgl.UniformLocation, v []float32, n int) {
+	if len(v) == 0 || len(v)%n != 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.setUniform(location, append([]float32(nil), v...))
+}
+
+// Stores integer uniform values made of n component elements.
+func (c *Context) uniformiv(location webgl.UniformLocation, v []int32, n int) {
+	if len(v) == 0 || len(v)%n != 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.setUniform(location, append([]int32(nil), v...))
+}
+
+func (c *Context) Uniform1fv(location webgl.UniformLocation, v []float32) {
+	c.record("Uniform1fv", location, v)
+	c.uniformfv(location, v, 1)
+}
+
+func (c *Context) Uniform2fv(location webgl.UniformLocation, v []float32) {
+	c.record("Uniform2fv", location, v)
+	c.uniformfv(location, v, 2)
+}
+
+func (c *Context) Uniform3fv(location webgl.UniformLocation, v []float32) {
+	c.record("Uniform3fv", location, v)
+	c.uniformfv(location, v, 3)
+}
+
+func (c *Context) Uniform4fv(location webgl.UniformLocation, v []float32) {
+	c.record("Uniform4fv", location, v)
+	c.uniformfv(location, v, 4)
+}
+
+func (c *Context) Uniform1iv(location webgl.UniformLocation, v []int32) {
+	c.record("Uniform1iv", location, v)
+	c.uniformiv(location, v, 1)
+}
+
+func (c *Context) Uniform2iv(location webgl.UniformLocation, v []int32) {
+	c.record("Uniform2iv", location, v)
+	c.uniformiv(location, v, 2)
+}
+
+func (c *Context) Uniform3iv(location webgl.UniformLocation, v []int32) {
+	c.record("Uniform3iv", location, v)
+	c.uniformiv(location, v, 3)
+}
+
+func (c *Context) Uniform4iv(location webgl.UniformLocation, v []int32) {
+	c.record("Uniform4iv", location, v)
+	c.uniformiv(location, v, 4)
+}
+
+// Stores matrix uniform values. WebGL 1 does not allow transposing.
+func (c *Context) uniformMatrix(location webgl.UniformLocation, transpose bool, v []float32, n int) {
+	if transpose {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.uniformfv(location, v, n)
+}
+
+func (c *Context) UniformMatrix2fv(location webgl.UniformLocation, transpose bool, value []float32) {
+	c.record("UniformMatrix2fv", location, transpose, value)
+	c.uniformMatrix(location, transpose, value, 4)
+}
+
+func (c *Context) UniformMatrix3fv(location webgl.UniformLocation, transpose bool, value []float32) {
+	c.record("UniformMatrix3fv", location, transpose, value)
+	c.uniformMatrix(location, transpose, value, 9)
+}
+
+func (c *Context) UniformMatrix4fv(location webgl.UniformLocation, transpose bool, value []float32) {
+	c.record("UniformMatrix4fv", location, transpose, value)
+	c.uniformMatrix(location, transpose, value, 16)
+}
@@ -0,0 +1,306 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package record implements webgl.RenderingContext in pure Go, without a
+// browser or a GPU. A Context logs every call with its arguments, creates
+// and deletes simulated WebGL objects, tracks bound state and raises the
+// common WebGL errors, so rendering code can be tested with go test by
+// asserting on the calls it makes:
+//
+//	gl := record.New(640, 480, nil)
+//	drawScene(gl)
+//	for _, draw := range gl.Draws() {
+//		if draw.State.Enabled[webgl.BLEND] {
+//			t.Errorf("%v drew with blending enabled", draw)
+//		}
+//	}
+//
+// Nothing is rendered, so ReadPixelsBytes reads zeros. Shaders always
+// compile and programs have no active attributes or uniforms unless the
+// Compile and Link hooks of the Context say otherwise.
+package record
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n2d/webgl"
+)
+
+// Call is a single method call made on a Context.
+type Call struct {
+	// Method is the name of the webgl.RenderingContext method.
+	Method string
+
+	// Args are the arguments the method was called with. Slices are
+	// copied, so later changes by the caller are not seen.
+	Args []interface{}
+
+	// Err is the error the call raised, or NO_ERROR.
+	Err webgl.Enum
+
+	// State is the context state at the time of the call for draw and
+	// clear calls, and nil for other calls.
+	State *State
+}
+
+func (c Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = formatArg(arg)
+	}
+	s := c.Method + "(" + strings.Join(args, ", ") + ")"
+	if c.Err != webgl.NO_ERROR {
+		s += ": " + webgl.Error(c.Err).Error()
+	}
+	return s
+}
+
+// Returns the text used for an argument by Call.String.
+func formatArg(arg interface{}) string {
+	if o, ok := handleObject(arg); ok {
+		if o == nil {
+			return "null"
+		}
+		return fmt.Sprint(o)
+	}
+	switch arg := arg.(type) {
+	case webgl.Enum:
+		return fmt.Sprintf("0x%04X", uint32(arg))
+	case []byte:
+		return fmt.Sprintf("[%d bytes]", len(arg))
+	case string:
+		return fmt.Sprintf("%q", arg)
+	}
+	return fmt.Sprint(arg)
+}
+
+// Returns the object wrapped by a handle, and whether arg is a handle.
+func handleObject(arg interface{}) (webgl.Object, bool) {
+	switch h := arg.(type) {
+	case webgl.Buffer:
+		return h.Object, true
+	case webgl.Texture:
+		return h.Object, true
+	case webgl.Shader:
+		return h.Object, true
+	case webgl.Program:
+		return h.Object, true
+	case webgl.Framebuffer:
+		return h.Object, true
+	case webgl.Renderbuffer:
+		return h.Object, true
+	case webgl.UniformLocation:
+		return h.Object, true
+	}
+	return nil, false
+}
+
+// Context is a recording webgl.RenderingContext. Its methods follow the
+// documentation of webgl.Context. It is not safe for concurrent use.
+type Context struct {
+	// Calls are the calls made on the context, oldest first.
+	Calls []Call
+
+	// Compile, if set, is called by CompileShader with the shader type
+	// and source. A non-nil error fails compilation with the error text
+	// as the info log.
+	Compile func(typ webgl.Enum, src string) error
+
+	// Link, if set, is called by LinkProgram with the program and the
+	// sources of its attached vertex and fragment shader. It returns the
+	// active attributes and uniforms of the program, or an error to fail
+	// linking with the error text as the info log.
+	Link func(program webgl.Program, vs, fs string) (attribs, uniforms []webgl.ActiveInfo, err error)
+
+	attrs  webgl.ContextAttributes
+	width  int
+	height int
+	state  State
+	errors []webgl.Enum
+	ids    int
+	links  int
+}
+
+var _ webgl.RenderingContext = (*Context)(nil)
+
+// Creates a recording context with a drawing buffer of the given size and
+// context attributes. If attrs is nil the WebGL defaults are used.
+func New(width, height int, attrs *webgl.ContextAttributes) *Context {
+	if attrs == nil {
+		attrs = webgl.DefaultAttributes()
+	}
+	c := &Context{attrs: *attrs, width: width, height: height}
+	c.state = defaultState(width, height)
+	return c
+}
+
+// Clears the recorded calls, keeping the context state.
+func (c *Context) Reset() {
+	c.Calls = nil
+}
+
+// Returns the recorded calls to the named methods, in order.
+func (c *Context) Filter(methods ...string) []Call {
+	var calls []Call
+	for _, call := range c.Calls {
+		for _, m := range methods {
+			if call.Method == m {
+				calls = append(calls, call)
+				break
+			}
+		}
+	}
+	return calls
+}
+
+// Returns the number of recorded calls to the named method.
+func (c *Context) Count(method string) int {
+	return len(c.Filter(method))
+}
+
+// Returns the recorded DrawArrays and DrawElements calls, in order.
+func (c *Context) Draws() []Call {
+	return c.Filter("DrawArrays", "DrawElements")
+}
+
+// Returns the recorded calls that raised an error, in order.
+func (c *Context) Errors() []Call {
+	var calls []Call
+	for _, call := range c.Calls {
+		if call.Err != webgl.NO_ERROR {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Returns the current context state. The returned value is shared with
+// the context and changes with later calls.
+func (c *Context) State() *State {
+	return &c.state
+}
+
+// Appends a call to the log and returns it.
+func (c *Context) record(method string, args ...interface{}) *Call {
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case []byte:
+			args[i] = append([]byte(nil), arg...)
+		case []int8:
+			args[i] = append([]int8(nil), arg...)
+		case []int16:
+			args[i] = append([]int16(nil), arg...)
+		case []uint16:
+			args[i] = append([]uint16(nil), arg...)
+		case []int32:
+			args[i] = append([]int32(nil), arg...)
+		case []uint32:
+			args[i] = append([]uint32(nil), arg...)
+		case []float32:
+			args[i] = append([]float32(nil), arg...)
+		}
+	}
+	c.Calls = append(c.Calls, Call{Method: method, Args: args})
+	return &c.Calls[len(c.Calls)-1]
+}
+
+// Records a snapshot of the state on the last call.
+func (c *Context) snapshot() {
+	c.Calls[len(c.Calls)-1].State = c.state.clone()
+}
+
+// Raises an error for the last call. As in WebGL, an error is only
+// recorded once until it is returned by GetError.
+func (c *Context) fail(code webgl.Enum) {
+	if n := len(c.Calls); n > 0 && c.Calls[n-1].Err == webgl.NO_ERROR {
+		c.Calls[n-1].Err = code
+	}
+	for _, e := range c.errors {
+		if e == code {
+			return
+		}
+	}
+	c.errors = append(c.errors, code)
+}
+
+func (c *Context) GetError() webgl.Enum {
+	c.record("GetError")
+	if len(c.errors) == 0 {
+		return webgl.NO_ERROR
+	}
+	e := c.errors[0]
+	c.errors = c.errors[1:]
+	return e
+}
+
+func (c *Context) CheckError() error {
+	if e := c.GetError(); e != webgl.NO_ERROR {
+		return webgl.Error(e)
+	}
+	return nil
+}
+
+func (c *Context) GetContextAttributes() webgl.ContextAttributes {
+	c.record("GetContextAttributes")
+	return c.attrs
+}
+
+func (c *Context) IsContextLost() bool {
+	c.record("IsContextLost")
+	return false
+}
+
+func (c *Context) DrawingBufferWidth() int {
+	c.record("DrawingBufferWidth")
+	return c.width
+}
+
+func (c *Context) DrawingBufferHeight() int {
+	c.record("DrawingBufferHeight")
+	return c.height
+}
+
+func (c *Context) GetSupportedExtensions() []string {
+	c.record("GetSupportedExtensions")
+	return []string{}
+}
+
+func (c *Context) Finish() {
+	c.record("Finish")
+}
+
+func (c *Context) Flush() {
+	c.record("Flush")
+}
+
+func (c *Context) Hint(target, mode webgl.Enum) {
+	c.record("Hint", target, mode)
+	if target != webgl.GENERATE_MIPMAP_HINT {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	switch mode {
+	case webgl.FASTEST, webgl.NICEST, webgl.DONT_CARE:
+		c.state.GenerateMipmapHint = mode
+	default:
+		c.fail(webgl.INVALID_ENUM)
+	}
+}
+
+func (c *Context) GetShaderPrecisionFormat(shaderType, precisionType webgl.Enum) webgl.ShaderPrecisionFormat {
+	c.record("GetShaderPrecisionFormat", shaderType, precisionType)
+	if shaderType != webgl.VERTEX_SHADER && shaderType != webgl.FRAGMENT_SHADER {
+		c.fail(webgl.INVALID_ENUM)
+		return webgl.ShaderPrecisionFormat{}
+	}
+	switch precisionType {
+	case webgl.LOW_FLOAT, webgl.MEDIUM_FLOAT, webgl.HIGH_FLOAT:
+		return webgl.ShaderPrecisionFormat{RangeMin: 127, RangeMax: 127, Precision: 23}
+	case webgl.LOW_INT, webgl.MEDIUM_INT, webgl.HIGH_INT:
+		return webgl.ShaderPrecisionFormat{RangeMin: 31, RangeMax: 30, Precision: 0}
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return webgl.ShaderPrecisionFormat{}
+}
@@ -0,0 +1,161 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/n2d/webgl"
+)
+
+// Links every program with an a_pos attribute and a u_color uniform.
+func link(program webgl.Program, vs, fs string) (attribs, uniforms []webgl.ActiveInfo, err error) {
+	attribs = []webgl.ActiveInfo{{Name: "a_pos", Type: webgl.FLOAT_VEC2, Size: 1}}
+	uniforms = []webgl.ActiveInfo{{Name: "u_color", Type: webgl.FLOAT_VEC4, Size: 1}}
+	return attribs, uniforms, nil
+}
+
+// Draws a triangle and a blended quad through the interface.
+func drawScene(gl webgl.RenderingContext) {
+	vs := gl.CreateShader(webgl.VERTEX_SHADER)
+	gl.ShaderSource(vs, "vertex")
+	gl.CompileShader(vs)
+	fs := gl.CreateShader(webgl.FRAGMENT_SHADER)
+	gl.ShaderSource(fs, "fragment")
+	gl.CompileShader(fs)
+	prog := gl.CreateProgram()
+	gl.AttachShader(prog, vs)
+	gl.AttachShader(prog, fs)
+	gl.LinkProgram(prog)
+	gl.UseProgram(prog)
+	gl.Uniform4f(gl.GetUniformLocation(prog, "u_color"), 1, 0, 0, 1)
+
+	vertices := gl.CreateBuffer()
+	gl.BindBuffer(webgl.ARRAY_BUFFER, vertices)
+	gl.BufferDataFloat32(webgl.ARRAY_BUFFER, []float32{0, 0, 1, 0, 0, 1, 1, 1}, webgl.STATIC_DRAW)
+	gl.BufferSubDataFloat32(webgl.ARRAY_BUFFER, 24, []float32{2, 2})
+	indices := gl.CreateBuffer()
+	gl.BindBuffer(webgl.ELEMENT_ARRAY_BUFFER, indices)
+	gl.BufferDataUint16(webgl.ELEMENT_ARRAY_BUFFER, []uint16{0, 1, 2, 1, 3, 2}, webgl.STATIC_DRAW)
+
+	loc := gl.GetAttribLocation(prog, "a_pos")
+	gl.VertexAttribPointer(loc, 2, webgl.FLOAT, false, 0, 0)
+	gl.EnableVertexAttribArray(loc)
+	gl.DrawArrays(webgl.TRIANGLES, 0, 3)
+	gl.Enable(webgl.BLEND)
+	gl.BlendFunc(webgl.SRC_ALPHA, webgl.ONE_MINUS_SRC_ALPHA)
+	gl.DrawElements(webgl.TRIANGLES, 6, webgl.UNSIGNED_SHORT, 0)
+}
+
+func TestRecord(t *testing.T) {
+	gl := New(64, 64, nil)
+	gl.Link = link
+	drawScene(gl)
+
+	if errs := gl.Errors(); len(errs) > 0 {
+		t.Fatalf("got errors %v", errs)
+	}
+	draws := gl.Draws()
+	if len(draws) != 2 {
+		t.Fatalf("got %d draws, want 2", len(draws))
+	}
+	if draws[0].State.Enabled[webgl.BLEND] || !draws[1].State.Enabled[webgl.BLEND] {
+		t.Errorf("got blending %v and %v, want false and true",
+			draws[0].State.Enabled[webgl.BLEND], draws[1].State.Enabled[webgl.BLEND])
+	}
+
+	want := []string{
+		"BindBuffer(0x8892, Buffer#4)",
+		"BufferDataFloat32(0x8892, [0 0 1 0 0 1 1 1], 0x88E4)",
+		"BufferSubDataFloat32(0x8892, 24, [2 2])",
+	}
+	calls := gl.Filter("BindBuffer", "BufferDataFloat32", "BufferSubDataFloat32")
+	for i, call := range calls[:min(len(calls), len(want))] {
+		if got := call.String(); got != want[i] {
+			t.Errorf("call %d is %s, want %s", i, got, want[i])
+		}
+	}
+	if got := gl.Count("BufferDataUint16"); got != 1 {
+		t.Errorf("got %d BufferDataUint16 calls, want 1", got)
+	}
+}
+
+func TestRecordCopiesArgs(t *testing.T) {
+	gl := New(1, 1, nil)
+	gl.BindBuffer(webgl.ARRAY_BUFFER, gl.CreateBuffer())
+	data := []uint16{1, 2, 3}
+	gl.BufferDataUint16(webgl.ARRAY_BUFFER, data, webgl.STATIC_DRAW)
+	data[0] = 9
+	if got := gl.Calls[len(gl.Calls)-1].Args[1].([]uint16); got[0] != 1 {
+		t.Errorf("recorded data changed to %v", got)
+	}
+	if got := gl.BufferContents(gl.State().ArrayBuffer); !bytes.Equal(got, []byte{1, 0, 2, 0, 3, 0}) {
+		t.Errorf("got buffer contents %v", got)
+	}
+}
+
+// Replays the calls recorded by one context on another, mapping the
+// handles of the first to those created by the second.
+func replay(t *testing.T, calls []Call, gl *Context) {
+	handles := make(map[string]reflect.Value)
+	for _, call := range calls {
+		m := reflect.ValueOf(gl).MethodByName(call.Method)
+		if !m.IsValid() {
+			t.Fatalf("no method %s", call.Method)
+		}
+		args := make([]reflect.Value, len(call.Args))
+		for i, arg := range call.Args {
+			if o, ok := handleObject(arg); ok && o != nil {
+				h, ok := handles[fmt.Sprint(o)]
+				if !ok {
+					t.Fatalf("%v uses unknown handle %v", call, o)
+				}
+				args[i] = h
+			} else if arg == nil {
+				args[i] = reflect.Zero(m.Type().In(i))
+			} else {
+				args[i] = reflect.ValueOf(arg)
+			}
+		}
+		for _, out := range m.Call(args) {
+			if o, ok := handleObject(out.Interface()); ok && o != nil {
+				handles[fmt.Sprint(o)] = out
+			}
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	gl := New(64, 64, nil)
+	gl.Link = link
+	drawScene(gl)
+
+	replayed := New(64, 64, nil)
+	replayed.Link = link
+	replay(t, gl.Calls, replayed)
+
+	if len(replayed.Calls) != len(gl.Calls) {
+		t.Fatalf("replayed %d calls, want %d", len(replayed.Calls), len(gl.Calls))
+	}
+	for i := range gl.Calls {
+		if got, want := replayed.Calls[i].String(), gl.Calls[i].String(); got != want {
+			t.Errorf("call %d replayed as %s, want %s", i, got, want)
+		}
+		if got, want := replayed.Calls[i].State, gl.Calls[i].State; (got == nil) != (want == nil) {
+			t.Errorf("call %d has state %v, want %v", i, got, want)
+		}
+	}
+	for _, b := range []func(*Context) webgl.Buffer{
+		func(c *Context) webgl.Buffer { return c.State().ArrayBuffer },
+		func(c *Context) webgl.Buffer { return c.State().ElementArrayBuffer },
+	} {
+		if got, want := replayed.BufferContents(b(replayed)), gl.BufferContents(b(gl)); !bytes.Equal(got, want) {
+			t.Errorf("replayed buffer holds %v, want %v", got, want)
+		}
+	}
+}
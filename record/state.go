@@ -0,0 +1,736 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"github.com/n2d/webgl"
+)
+
+// Implementation limits reported by GetParameteri.
+const (
+	MaxVertexAttribs          = 16
+	MaxTextureUnits           = 32
+	MaxFragmentTextureUnits   = 16
+	MaxVertexTextureUnits     = 16
+	MaxTextureSize            = 4096
+	MaxCubeMapTextureSize     = 4096
+	MaxRenderbufferSize       = 4096
+	MaxVertexUniformVectors   = 256
+	MaxFragmentUniformVectors = 224
+	MaxVaryingVectors         = 15
+	MaxViewportDims           = 4096
+)
+
+// State is the state of a Context that is changed by binding objects and
+// by the fixed function calls.
+type State struct {
+	// Enabled holds the capabilities toggled by Enable and Disable.
+	Enabled map[webgl.Enum]bool
+
+	ArrayBuffer        webgl.Buffer
+	ElementArrayBuffer webgl.Buffer
+	Framebuffer        webgl.Framebuffer
+	Renderbuffer       webgl.Renderbuffer
+	Program            webgl.Program
+
+	// ActiveTexture is the active texture unit, from TEXTURE0.
+	ActiveTexture webgl.Enum
+
+	// Textures are the textures bound to each texture unit.
+	Textures []TextureUnit
+
+	// Attribs are the vertex attributes.
+	Attribs []VertexAttrib
+
+	Viewport     [4]int
+	Scissor      [4]int
+	DepthRange   [2]float64
+	ClearColor   [4]float32
+	ClearDepth   float64
+	ClearStencil int
+	ColorMask    [4]bool
+	DepthMask    bool
+	DepthFunc    webgl.Enum
+	CullFace     webgl.Enum
+	FrontFace    webgl.Enum
+	LineWidth    float64
+
+	BlendColor         [4]float32
+	BlendEquationRGB   webgl.Enum
+	BlendEquationAlpha webgl.Enum
+	BlendSrcRGB        webgl.Enum
+	BlendDstRGB        webgl.Enum
+	BlendSrcAlpha      webgl.Enum
+	BlendDstAlpha      webgl.Enum
+
+	StencilFront Stencil
+	StencilBack  Stencil
+
+	PolygonOffsetFactor  float64
+	PolygonOffsetUnits   float64
+	SampleCoverageValue  float32
+	SampleCoverageInvert bool
+
+	GenerateMipmapHint webgl.Enum
+
+	// PixelStore holds the values set by PixelStorei.
+	PixelStore map[webgl.Enum]int
+}
+
+// TextureUnit holds the textures bound to a texture unit.
+type TextureUnit struct {
+	Texture2D      webgl.Texture
+	TextureCubeMap webgl.Texture
+}
+
+// VertexAttrib is the state of a vertex attribute.
+type VertexAttrib struct {
+	// Enabled reports whether the attribute reads from Buffer rather
+	// than using Value.
+	Enabled bool
+
+	// Buffer, Size, Type, Normalized, Stride and Offset are the values
+	// given to VertexAttribPointer.
+	Buffer     webgl.Buffer
+	Size       int
+	Type       webgl.Enum
+	Normalized bool
+	Stride     int
+	Offset     int
+
+	// Value is the constant value set by VertexAttrib4f and friends.
+	Value [4]float32
+}
+
+// Stencil is the stencil state for one face.
+type Stencil struct {
+	Func      webgl.Enum
+	Ref       int
+	ValueMask uint32
+	WriteMask uint32
+	Fail      webgl.Enum
+	ZFail     webgl.Enum
+	ZPass     webgl.Enum
+}
+
+// Returns the initial state of a context with a drawing buffer of the
+// given size.
+func defaultState(width, height int) State {
+	stencil := Stencil{
+		Func:      webgl.ALWAYS,
+		ValueMask: 0xFFFFFFFF,
+		WriteMask: 0xFFFFFFFF,
+		Fail:      webgl.KEEP,
+		ZFail:     webgl.KEEP,
+		ZPass:     webgl.KEEP,
+	}
+	s := State{
+		Enabled:             map[webgl.Enum]bool{webgl.DITHER: true},
+		ActiveTexture:       webgl.TEXTURE0,
+		Textures:            make([]TextureUnit, MaxTextureUnits),
+		Attribs:             make([]VertexAttrib, MaxVertexAttribs),
+		Viewport:            [4]int{0, 0, width, height},
+		Scissor:             [4]int{0, 0, width, height},
+		DepthRange:          [2]float64{0, 1},
+		ClearDepth:          1,
+		ColorMask:           [4]bool{true, true, true, true},
+		DepthMask:           true,
+		DepthFunc:           webgl.LESS,
+		CullFace:            webgl.BACK,
+		FrontFace:           webgl.CCW,
+		LineWidth:           1,
+		BlendEquationRGB:    webgl.FUNC_ADD,
+		BlendEquationAlpha:  webgl.FUNC_ADD,
+		BlendSrcRGB:         webgl.ONE,
+		BlendDstRGB:         webgl.ZERO,
+		BlendSrcAlpha:       webgl.ONE,
+		BlendDstAlpha:       webgl.ZERO,
+		StencilFront:        stencil,
+		StencilBack:         stencil,
+		SampleCoverageValue: 1,
+		GenerateMipmapHint:  webgl.DONT_CARE,
+		PixelStore: map[webgl.Enum]int{
+			webgl.PACK_ALIGNMENT:                     4,
+			webgl.UNPACK_ALIGNMENT:                   4,
+			webgl.UNPACK_FLIP_Y_WEBGL:                0,
+			webgl.UNPACK_PREMULTIPLY_ALPHA_WEBGL:     0,
+			webgl.UNPACK_COLORSPACE_CONVERSION_WEBGL: int(webgl.BROWSER_DEFAULT_WEBGL),
+		},
+	}
+	for i := range s.Attribs {
+		s.Attribs[i].Size = 4
+		s.Attribs[i].Type = webgl.FLOAT
+		s.Attribs[i].Value = [4]float32{0, 0, 0, 1}
+	}
+	return s
+}
+
+// Returns a deep copy of the state.
+func (s *State) clone() *State {
+	c := *s
+	c.Enabled = make(map[webgl.Enum]bool, len(s.Enabled))
+	for k, v := range s.Enabled {
+		c.Enabled[k] = v
+	}
+	c.PixelStore = make(map[webgl.Enum]int, len(s.PixelStore))
+	for k, v := range s.PixelStore {
+		c.PixelStore[k] = v
+	}
+	c.Textures = append([]TextureUnit(nil), s.Textures...)
+	c.Attribs = append([]VertexAttrib(nil), s.Attribs...)
+	return &c
+}
+
+// Returns the texture unit selected by ActiveTexture.
+func (s *State) unit() *TextureUnit {
+	return &s.Textures[s.ActiveTexture-webgl.TEXTURE0]
+}
+
+// Returns whether a capability can be passed to Enable and Disable.
+func isCapability(cap webgl.Enum) bool {
+	switch cap {
+	case webgl.BLEND, webgl.CULL_FACE, webgl.DEPTH_TEST, webgl.DITHER,
+		webgl.POLYGON_OFFSET_FILL, webgl.SAMPLE_ALPHA_TO_COVERAGE,
+		webgl.SAMPLE_COVERAGE, webgl.SCISSOR_TEST, webgl.STENCIL_TEST:
+		return true
+	}
+	return false
+}
+
+func (c *Context) Enable(cap webgl.Enum) {
+	c.record("Enable", cap)
+	if !isCapability(cap) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	c.state.Enabled[cap] = true
+}
+
+func (c *Context) Disable(cap webgl.Enum) {
+	c.record("Disable", cap)
+	if !isCapability(cap) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	c.state.Enabled[cap] = false
+}
+
+func (c *Context) IsEnabled(capability webgl.Enum) bool {
+	c.record("IsEnabled", capability)
+	if !isCapability(capability) {
+		c.fail(webgl.INVALID_ENUM)
+		return false
+	}
+	return c.state.Enabled[capability]
+}
+
+func (c *Context) ActiveTexture(texture webgl.Enum) {
+	c.record("ActiveTexture", texture)
+	if texture < webgl.TEXTURE0 || texture >= webgl.TEXTURE0+MaxTextureUnits {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	c.state.ActiveTexture = texture
+}
+
+func (c *Context) Viewport(x, y, width, height int) {
+	c.record("Viewport", x, y, width, height)
+	if width < 0 || height < 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.state.Viewport = [4]int{x, y, width, height}
+}
+
+func (c *Context) Scissor(x, y, width, height int) {
+	c.record("Scissor", x, y, width, height)
+	if width < 0 || height < 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.state.Scissor = [4]int{x, y, width, height}
+}
+
+func (c *Context) DepthRange(zNear, zFar float64) {
+	c.record("DepthRange", zNear, zFar)
+	if zNear > zFar {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	c.state.DepthRange = [2]float64{clamp(zNear), clamp(zFar)}
+}
+
+func (c *Context) ClearColor(r, g, b, a float32) {
+	c.record("ClearColor", r, g, b, a)
+	c.state.ClearColor = [4]float32{r, g, b, a}
+}
+
+func (c *Context) ClearDepth(depth float64) {
+	c.record("ClearDepth", depth)
+	c.state.ClearDepth = clamp(depth)
+}
+
+func (c *Context) ClearStencil(s int) {
+	c.record("ClearStencil", s)
+	c.state.ClearStencil = s
+}
+
+func (c *Context) ColorMask(r, g, b, a bool) {
+	c.record("ColorMask", r, g, b, a)
+	c.state.ColorMask = [4]bool{r, g, b, a}
+}
+
+func (c *Context) DepthMask(flag bool) {
+	c.record("DepthMask", flag)
+	c.state.DepthMask = flag
+}
+
+// Returns whether fun is a depth or stencil comparison function.
+func isCompareFunc(fun webgl.Enum) bool {
+	switch fun {
+	case webgl.NEVER, webgl.LESS, webgl.EQUAL, webgl.LEQUAL,
+		webgl.GREATER, webgl.NOTEQUAL, webgl.GEQUAL, webgl.ALWAYS:
+		return true
+	}
+	return false
+}
+
+func (c *Context) DepthFunc(fun webgl.Enum) {
+	c.record("DepthFunc", fun)
+	if !isCompareFunc(fun) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	c.state.DepthFunc = fun
+}
+
+func (c *Context) CullFace(mode webgl.Enum) {
+	c.record("CullFace", mode)
+	switch mode {
+	case webgl.FRONT, webgl.BACK, webgl.FRONT_AND_BACK:
+		c.state.CullFace = mode
+	default:
+		c.fail(webgl.INVALID_ENUM)
+	}
+}
+
+func (c *Context) FrontFace(mode webgl.Enum) {
+	c.record("FrontFace", mode)
+	switch mode {
+	case webgl.CW, webgl.CCW:
+		c.state.FrontFace = mode
+	default:
+		c.fail(webgl.INVALID_ENUM)
+	}
+}
+
+func (c *Context) LineWidth(width float64) {
+	c.record("LineWidth", width)
+	if width <= 0 {
+		c.fail(webgl.INVALID_VALUE)
+		return
+	}
+	c.state.LineWidth = width
+}
+
+func (c *Context) PolygonOffset(factor, units float64) {
+	c.record("PolygonOffset", factor, units)
+	c.state.PolygonOffsetFactor = factor
+	c.state.PolygonOffsetUnits = units
+}
+
+func (c *Context) SampleCoverage(value float32, invert bool) {
+	c.record("SampleCoverage", value, invert)
+	c.state.SampleCoverageValue = float32(clamp(float64(value)))
+	c.state.SampleCoverageInvert = invert
+}
+
+func (c *Context) BlendColor(r, g, b, a float64) {
+	c.record("BlendColor", r, g, b, a)
+	c.state.BlendColor = [4]float32{float32(clamp(r)), float32(clamp(g)), float32(clamp(b)), float32(clamp(a))}
+}
+
+// Returns whether mode is a blend equation.
+func isBlendEquation(mode webgl.Enum) bool {
+	switch mode {
+	case webgl.FUNC_ADD, webgl.FUNC_SUBTRACT, webgl.FUNC_REVERSE_SUBTRACT:
+		return true
+	}
+	return false
+}
+
+func (c *Context) BlendEquation(mode webgl.Enum) {
+	c.record("BlendEquation", mode)
+	if !isBlendEquation(mode) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	c.state.BlendEquationRGB = mode
+	c.state.BlendEquationAlpha = mode
+}
+
+func (c *Context) BlendEquationSeparate(modeRGB, modeAlpha webgl.Enum) {
+	c.record("BlendEquationSeparate", modeRGB, modeAlpha)
+	if !isBlendEquation(modeRGB) || !isBlendEquation(modeAlpha) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	c.state.BlendEquationRGB = modeRGB
+	c.state.BlendEquationAlpha = modeAlpha
+}
+
+// Returns whether factor is a blend factor.
+func isBlendFactor(factor webgl.Enum) bool {
+	switch factor {
+	case webgl.ZERO, webgl.ONE, webgl.SRC_COLOR, webgl.ONE_MINUS_SRC_COLOR,
+		webgl.DST_COLOR, webgl.ONE_MINUS_DST_COLOR, webgl.SRC_ALPHA,
+		webgl.ONE_MINUS_SRC_ALPHA, webgl.DST_ALPHA, webgl.ONE_MINUS_DST_ALPHA,
+		webgl.CONSTANT_COLOR, webgl.ONE_MINUS_CONSTANT_COLOR,
+		webgl.CONSTANT_ALPHA, webgl.ONE_MINUS_CONSTANT_ALPHA,
+		webgl.SRC_ALPHA_SATURATE:
+		return true
+	}
+	return false
+}
+
+// Returns whether a source and destination factor may be used together.
+// WebGL forbids mixing constant color and constant alpha factors.
+func compatibleBlendFactors(src, dst webgl.Enum) bool {
+	isColor := func(f webgl.Enum) bool { return f == webgl.CONSTANT_COLOR || f == webgl.ONE_MINUS_CONSTANT_COLOR }
+	isAlpha := func(f webgl.Enum) bool { return f == webgl.CONSTANT_ALPHA || f == webgl.ONE_MINUS_CONSTANT_ALPHA }
+	return !(isColor(src) && isAlpha(dst) || isAlpha(src) && isColor(dst))
+}
+
+func (c *Context) BlendFunc(sfactor, dfactor webgl.Enum) {
+	c.record("BlendFunc", sfactor, dfactor)
+	if !isBlendFactor(sfactor) || !isBlendFactor(dfactor) || dfactor == webgl.SRC_ALPHA_SATURATE {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if !compatibleBlendFactors(sfactor, dfactor) {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	c.state.BlendSrcRGB, c.state.BlendSrcAlpha = sfactor, sfactor
+	c.state.BlendDstRGB, c.state.BlendDstAlpha = dfactor, dfactor
+}
+
+func (c *Context) BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha webgl.Enum) {
+	c.record("BlendFuncSeparate", srcRGB, dstRGB, srcAlpha, dstAlpha)
+	for _, f := range []webgl.Enum{srcRGB, dstRGB, srcAlpha, dstAlpha} {
+		if !isBlendFactor(f) {
+			c.fail(webgl.INVALID_ENUM)
+			return
+		}
+	}
+	if dstRGB == webgl.SRC_ALPHA_SATURATE || dstAlpha == webgl.SRC_ALPHA_SATURATE {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	if !compatibleBlendFactors(srcRGB, dstRGB) {
+		c.fail(webgl.INVALID_OPERATION)
+		return
+	}
+	c.state.BlendSrcRGB, c.state.BlendDstRGB = srcRGB, dstRGB
+	c.state.BlendSrcAlpha, c.state.BlendDstAlpha = srcAlpha, dstAlpha
+}
+
+// Returns the stencil states selected by a face argument.
+func (c *Context) stencilFaces(face webgl.Enum) []*Stencil {
+	switch face {
+	case webgl.FRONT:
+		return []*Stencil{&c.state.StencilFront}
+	case webgl.BACK:
+		return []*Stencil{&c.state.StencilBack}
+	case webgl.FRONT_AND_BACK:
+		return []*Stencil{&c.state.StencilFront, &c.state.StencilBack}
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return nil
+}
+
+func (c *Context) StencilFunc(fun webgl.Enum, ref int, mask uint32) {
+	c.record("StencilFunc", fun, ref, mask)
+	c.stencilFunc(webgl.FRONT_AND_BACK, fun, ref, mask)
+}
+
+func (c *Context) StencilFuncSeparate(face, fun webgl.Enum, ref int, mask uint32) {
+	c.record("StencilFuncSeparate", face, fun, ref, mask)
+	c.stencilFunc(face, fun, ref, mask)
+}
+
+func (c *Context) stencilFunc(face, fun webgl.Enum, ref int, mask uint32) {
+	if !isCompareFunc(fun) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	for _, s := range c.stencilFaces(face) {
+		s.Func, s.Ref, s.ValueMask = fun, ref, mask
+	}
+}
+
+func (c *Context) StencilMask(mask uint32) {
+	c.record("StencilMask", mask)
+	c.state.StencilFront.WriteMask = mask
+	c.state.StencilBack.WriteMask = mask
+}
+
+func (c *Context) StencilMaskSeparate(face webgl.Enum, mask uint32) {
+	c.record("StencilMaskSeparate", face, mask)
+	for _, s := range c.stencilFaces(face) {
+		s.WriteMask = mask
+	}
+}
+
+// Returns whether op is a stencil operation.
+func isStencilOp(op webgl.Enum) bool {
+	switch op {
+	case webgl.KEEP, webgl.ZERO, webgl.REPLACE, webgl.INCR, webgl.INCR_WRAP,
+		webgl.DECR, webgl.DECR_WRAP, webgl.INVERT:
+		return true
+	}
+	return false
+}
+
+func (c *Context) StencilOp(fail, zfail, zpass webgl.Enum) {
+	c.record("StencilOp", fail, zfail, zpass)
+	c.stencilOp(webgl.FRONT_AND_BACK, fail, zfail, zpass)
+}
+
+func (c *Context) StencilOpSeparate(face, fail, zfail, zpass webgl.Enum) {
+	c.record("StencilOpSeparate", face, fail, zfail, zpass)
+	c.stencilOp(face, fail, zfail, zpass)
+}
+
+func (c *Context) stencilOp(face, fail, zfail, zpass webgl.Enum) {
+	if !isStencilOp(fail) || !isStencilOp(zfail) || !isStencilOp(zpass) {
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	for _, s := range c.stencilFaces(face) {
+		s.Fail, s.ZFail, s.ZPass = fail, zfail, zpass
+	}
+}
+
+func (c *Context) PixelStorei(pname webgl.Enum, param int) {
+	c.record("PixelStorei", pname, param)
+	switch pname {
+	case webgl.PACK_ALIGNMENT, webgl.UNPACK_ALIGNMENT:
+		switch param {
+		case 1, 2, 4, 8:
+		default:
+			c.fail(webgl.INVALID_VALUE)
+			return
+		}
+	case webgl.UNPACK_FLIP_Y_WEBGL, webgl.UNPACK_PREMULTIPLY_ALPHA_WEBGL:
+		if param != 0 {
+			param = 1
+		}
+	case webgl.UNPACK_COLORSPACE_CONVERSION_WEBGL:
+		if webgl.Enum(param) != webgl.NONE && webgl.Enum(param) != webgl.BROWSER_DEFAULT_WEBGL {
+			c.fail(webgl.INVALID_VALUE)
+			return
+		}
+	default:
+		c.fail(webgl.INVALID_ENUM)
+		return
+	}
+	c.state.PixelStore[pname] = param
+}
+
+func (c *Context) GetParameteri(pname webgl.Enum) int {
+	c.record("GetParameteri", pname)
+	s := &c.state
+	switch pname {
+	case webgl.ACTIVE_TEXTURE:
+		return int(s.ActiveTexture)
+	case webgl.RED_BITS, webgl.GREEN_BITS, webgl.BLUE_BITS:
+		return 8
+	case webgl.ALPHA_BITS:
+		if c.attrs.Alpha {
+			return 8
+		}
+		return 0
+	case webgl.DEPTH_BITS:
+		if c.attrs.Depth {
+			return 24
+		}
+		return 0
+	case webgl.STENCIL_BITS:
+		if c.attrs.Stencil {
+			return 8
+		}
+		return 0
+	case webgl.SUBPIXEL_BITS:
+		return 4
+	case webgl.SAMPLES, webgl.SAMPLE_BUFFERS:
+		return 0
+	case webgl.BLEND_EQUATION_RGB:
+		return int(s.BlendEquationRGB)
+	case webgl.BLEND_EQUATION_ALPHA:
+		return int(s.BlendEquationAlpha)
+	case webgl.BLEND_SRC_RGB:
+		return int(s.BlendSrcRGB)
+	case webgl.BLEND_DST_RGB:
+		return int(s.BlendDstRGB)
+	case webgl.BLEND_SRC_ALPHA:
+		return int(s.BlendSrcAlpha)
+	case webgl.BLEND_DST_ALPHA:
+		return int(s.BlendDstAlpha)
+	case webgl.CULL_FACE_MODE:
+		return int(s.CullFace)
+	case webgl.FRONT_FACE:
+		return int(s.FrontFace)
+	case webgl.DEPTH_FUNC:
+		return int(s.DepthFunc)
+	case webgl.GENERATE_MIPMAP_HINT:
+		return int(s.GenerateMipmapHint)
+	case webgl.STENCIL_CLEAR_VALUE:
+		return s.ClearStencil
+	case webgl.STENCIL_FUNC:
+		return int(s.StencilFront.Func)
+	case webgl.STENCIL_REF:
+		return s.StencilFront.Ref
+	case webgl.STENCIL_VALUE_MASK:
+		return int(s.StencilFront.ValueMask)
+	case webgl.STENCIL_WRITEMASK:
+		return int(s.StencilFront.WriteMask)
+	case webgl.STENCIL_FAIL:
+		return int(s.StencilFront.Fail)
+	case webgl.STENCIL_PASS_DEPTH_FAIL:
+		return int(s.StencilFront.ZFail)
+	case webgl.STENCIL_PASS_DEPTH_PASS:
+		return int(s.StencilFront.ZPass)
+	case webgl.STENCIL_BACK_FUNC:
+		return int(s.StencilBack.Func)
+	case webgl.STENCIL_BACK_REF:
+		return s.StencilBack.Ref
+	case webgl.STENCIL_BACK_VALUE_MASK:
+		return int(s.StencilBack.ValueMask)
+	case webgl.STENCIL_BACK_WRITEMASK:
+		return int(s.StencilBack.WriteMask)
+	case webgl.STENCIL_BACK_FAIL:
+		return int(s.StencilBack.Fail)
+	case webgl.STENCIL_BACK_PASS_DEPTH_FAIL:
+		return int(s.StencilBack.ZFail)
+	case webgl.STENCIL_BACK_PASS_DEPTH_PASS:
+		return int(s.StencilBack.ZPass)
+	case webgl.PACK_ALIGNMENT, webgl.UNPACK_ALIGNMENT, webgl.UNPACK_COLORSPACE_CONVERSION_WEBGL:
+		return s.PixelStore[pname]
+	case webgl.IMPLEMENTATION_COLOR_READ_FORMAT:
+		return int(webgl.RGBA)
+	case webgl.IMPLEMENTATION_COLOR_READ_TYPE:
+		return int(webgl.UNSIGNED_BYTE)
+	case webgl.MAX_VERTEX_ATTRIBS:
+		return MaxVertexAttribs
+	case webgl.MAX_COMBINED_TEXTURE_IMAGE_UNITS:
+		return MaxTextureUnits
+	case webgl.MAX_TEXTURE_IMAGE_UNITS:
+		return MaxFragmentTextureUnits
+	case webgl.MAX_VERTEX_TEXTURE_IMAGE_UNITS:
+		return MaxVertexTextureUnits
+	case webgl.MAX_TEXTURE_SIZE:
+		return MaxTextureSize
+	case webgl.MAX_CUBE_MAP_TEXTURE_SIZE:
+		return MaxCubeMapTextureSize
+	case webgl.MAX_RENDERBUFFER_SIZE:
+		return MaxRenderbufferSize
+	case webgl.MAX_VERTEX_UNIFORM_VECTORS:
+		return MaxVertexUniformVectors
+	case webgl.MAX_FRAGMENT_UNIFORM_VECTORS:
+		return MaxFragmentUniformVectors
+	case webgl.MAX_VARYING_VECTORS:
+		return MaxVaryingVectors
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+func (c *Context) GetParameterb(pname webgl.Enum) bool {
+	c.record("GetParameterb", pname)
+	s := &c.state
+	switch pname {
+	case webgl.DEPTH_WRITEMASK:
+		return s.DepthMask
+	case webgl.SAMPLE_COVERAGE_INVERT:
+		return s.SampleCoverageInvert
+	case webgl.UNPACK_FLIP_Y_WEBGL, webgl.UNPACK_PREMULTIPLY_ALPHA_WEBGL:
+		return s.PixelStore[pname] != 0
+	}
+	if isCapability(pname) {
+		return s.Enabled[pname]
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return false
+}
+
+func (c *Context) GetParameterf(pname webgl.Enum) float32 {
+	c.record("GetParameterf", pname)
+	s := &c.state
+	switch pname {
+	case webgl.LINE_WIDTH:
+		return float32(s.LineWidth)
+	case webgl.DEPTH_CLEAR_VALUE:
+		return float32(s.ClearDepth)
+	case webgl.POLYGON_OFFSET_FACTOR:
+		return float32(s.PolygonOffsetFactor)
+	case webgl.POLYGON_OFFSET_UNITS:
+		return float32(s.PolygonOffsetUnits)
+	case webgl.SAMPLE_COVERAGE_VALUE:
+		return s.SampleCoverageValue
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return 0
+}
+
+func (c *Context) GetParameterString(pname webgl.Enum) string {
+	c.record("GetParameterString", pname)
+	switch pname {
+	case webgl.VENDOR:
+		return "webgl"
+	case webgl.RENDERER:
+		return "webgl/record"
+	case webgl.VERSION:
+		return "WebGL 1.0 (record)"
+	case webgl.SHADING_LANGUAGE_VERSION:
+		return "WebGL GLSL ES 1.0 (record)"
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return ""
+}
+
+func (c *Context) GetParameterObject(pname webgl.Enum) webgl.Object {
+	c.record("GetParameterObject", pname)
+	s := &c.state
+	switch pname {
+	case webgl.ARRAY_BUFFER_BINDING:
+		return s.ArrayBuffer.Object
+	case webgl.ELEMENT_ARRAY_BUFFER_BINDING:
+		return s.ElementArrayBuffer.Object
+	case webgl.FRAMEBUFFER_BINDING:
+		return s.Framebuffer.Object
+	case webgl.RENDERBUFFER_BINDING:
+		return s.Renderbuffer.Object
+	case webgl.CURRENT_PROGRAM:
+		return s.Program.Object
+	case webgl.TEXTURE_BINDING_2D:
+		return s.unit().Texture2D.Object
+	case webgl.TEXTURE_BINDING_CUBE_MAP:
+		return s.unit().TextureCubeMap.Object
+	}
+	c.fail(webgl.INVALID_ENUM)
+	return nil
+}
+
+// Clamps v to [0, 1].
+func clamp(v float64) float64 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 1:
+		return 1
+	}
+	return v
+}
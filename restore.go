@@ -0,0 +1,1109 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Sets a function called when the WebGL context is lost. The loss event's
+// default action is prevented, which tells the browser the page can handle
+// a restore, and every object created through the context from now on is
+// tracked so it can be re-created when the context is restored.
+//
+// Call OnContextLost or OnContextRestored right after creating the context:
+// objects created before the first call are not re-created. f may be nil.
+//
+// The returned function, which is also returned by OnContextRestored,
+// removes the event listeners and their callbacks, and stops tracking
+// objects for restoring them, for when the context is no longer used.
+// Calling it more than once does nothing.
+func (c *Context) OnContextLost(f func()) (stop func()) {
+	r := c.restorable()
+	r.lost = f
+	return r.stopFunc(r.listeners)
+}
+
+// Sets a function called when the WebGL context has been restored after
+// a loss, and enables the tracking described on OnContextLost.
+//
+// Before f is called, the buffers, textures, renderbuffers, shaders,
+// programs and framebuffers created through the context are re-created
+// with the data last uploaded to them, so handles to them, including
+// uniform locations, remain valid. The rest of the context state, such as
+// bindings, enabled capabilities, vertex attributes, uniform values and
+// the drawing buffer contents, is reset by the browser and must be set up
+// again by f. Queries, samplers, transform feedback objects and syncs of
+// the WebGL 2 API are re-created empty, except for sampler parameters, and
+// a sync as a new fence. Textures filled with CopyTexImage2D or
+// CopyTexSubImage2D, vertex array objects and the data uploaded with the
+// WebGL 2 variants of the upload calls are not re-created, and image sources
+// passed to TexImage2D and TexSubImage2D are uploaded again as they are at
+// the time of the restore. Extensions are enabled again by looking them up
+// with GetExtension, which no longer returns the cached objects. The
+// returned function is described on OnContextLost.
+func (c *Context) OnContextRestored(f func()) (stop func()) {
+	r := c.restorable()
+	r.restored = f
+	return r.stopFunc(r.listeners)
+}
+
+// Returns the registry of the context, creating it if needed.
+func (c *Context) registry() *registry {
+	if c.reg == nil {
+		c.reg = &registry{pixelStore: make(map[Enum]int)}
+		c.reg.reset()
+	}
+	return c.reg
+}
+
+// Returns the registry of the context after enabling the restoration of
+// objects, listening for the context loss events of the canvas the first
+// time.
+func (c *Context) restorable() *registry {
+	r := c.registry()
+	if r.restorable {
+		return r
+	}
+	r.restorable = true
+	l := &contextListeners{canvas: c.Get("canvas")}
+	r.listeners = l
+	if !l.canvas.Truthy() {
+		return r
+	}
+	l.lost = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		args[0].Call("preventDefault")
+		if r.lost != nil {
+			r.lost()
+		}
+		return nil
+	})
+	l.restored = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		r.restore(c)
+		if r.restored != nil {
+			r.restored()
+		}
+		return nil
+	})
+	l.canvas.Call("addEventListener", "webglcontextlost", l.lost)
+	l.canvas.Call("addEventListener", "webglcontextrestored", l.restored)
+	return r
+}
+
+// contextListeners are the context loss event listeners added to a canvas.
+type contextListeners struct {
+	canvas         js.Value
+	lost, restored js.Func
+}
+
+// Returns a function that removes the listeners l, if they are still
+// those of the registry, and disables restoring objects.
+func (r *registry) stopFunc(l *contextListeners) func() {
+	return func() {
+		if r.listeners != l {
+			return
+		}
+		r.listeners = nil
+		r.restorable = false
+		r.lost, r.restored = nil, nil
+		if l.canvas.Truthy() {
+			l.canvas.Call("removeEventListener", "webglcontextlost", l.lost)
+			l.canvas.Call("removeEventListener", "webglcontextrestored", l.restored)
+			l.lost.Release()
+			l.restored.Release()
+		}
+	}
+}
+
+// registry remembers how the objects created through a Context were made,
+// so they can be re-created after the context is lost and restored.
+// Handles to tracked objects wrap a resource instead of the JavaScript
+// object, and the resource is given a new JavaScript object on restore.
+//
+// The methods of a nil registry do nothing, so the Context methods call
+// them whether or not tracking is enabled.
+type registry struct {
+	lost, restored func()
+	listeners      *contextListeners
+
+	// restorable is set by OnContextLost and OnContextRestored, and
+	// objects by TrackObjects.
+	restorable, objects bool
+
+	// resources are the live tracked objects in creation order. ids maps
+	// their JavaScript objects to an id, in a WeakMap as js.Value cannot
+	// be a map key, and byID maps the ids back to them.
+	resources []resource
+	restoring bool
+	ids       js.Value
+	byID      map[int]resource
+	lastID    int
+
+	// The bindings and pixel storage modes the tracked calls depend on.
+	activeTexture   Enum
+	buffers         map[Enum]*bufferResource
+	textures        map[textureBinding]*textureResource
+	renderbuffer    *renderbufferResource
+	drawFramebuffer *framebufferResource
+	readFramebuffer *framebufferResource
+	pixelStore      map[Enum]int
+}
+
+// textureBinding is a texture target of a texture unit.
+type textureBinding struct {
+	unit, target Enum
+}
+
+// trackedObject is the Object behind the handles of tracked objects.
+type trackedObject interface {
+	base() *tracked
+}
+
+// resource is a tracked WebGL object that is re-created on restore.
+type resource interface {
+	trackedObject
+	restore(c *Context, r *registry)
+}
+
+// tracked is the state shared by every resource.
+type tracked struct {
+	value   js.Value
+	id      int
+	deleted bool
+
+	// restorable is set if the object is re-created on restore, and its
+	// data kept for it.
+	restorable bool
+
+	// The details reported by LiveObjects.
+	label string
+	stack []uintptr
+	bytes int
+}
+
+func (t *tracked) base() *tracked { return t }
+
+// Returns whether r tracks calls made now.
+func (r *registry) active() bool {
+	return r != nil && !r.restoring
+}
+
+// Clears the bindings and pixel storage modes, as on a new context.
+func (r *registry) reset() {
+	r.activeTexture = TEXTURE0
+	r.buffers = make(map[Enum]*bufferResource)
+	r.textures = make(map[textureBinding]*textureResource)
+	r.renderbuffer = nil
+	r.drawFramebuffer = nil
+	r.readFramebuffer = nil
+	clear(r.pixelStore)
+}
+
+// Returns the Object for a newly created JavaScript object, which is res
+// holding v if tracking is active.
+func (r *registry) track(v js.Value, res resource) Object {
+	if !r.active() {
+		return jsObject(v)
+	}
+	t := res.base()
+	t.value = v
+	t.restorable = r.restorable
+	if r.objects {
+		t.stack = callers()
+	}
+	r.resources = append(r.resources, res)
+	r.index(res)
+	return res
+}
+
+// Maps the JavaScript object of a resource to the resource.
+func (r *registry) index(res resource) {
+	t := res.base()
+	if !t.value.Truthy() {
+		return
+	}
+	if r.byID == nil {
+		r.ids = js.Global().Get("WeakMap").New()
+		r.byID = make(map[int]resource)
+	}
+	if t.id == 0 {
+		r.lastID++
+		t.id = r.lastID
+		r.byID[t.id] = res
+	}
+	r.ids.Call("set", t.value, t.id)
+}
+
+// Returns the Object for a JavaScript object returned by WebGL, which is
+// the resource holding it if it is tracked.
+func (r *registry) object(v js.Value) Object {
+	if r == nil || r.byID == nil || !v.Truthy() {
+		return jsObject(v)
+	}
+	if id := r.ids.Call("get", v); id.Type() == js.TypeNumber {
+		if res, ok := r.byID[id.Int()]; ok {
+			return res
+		}
+	}
+	return v
+}
+
+// Stops tracking a deleted object.
+func (r *registry) untrack(o object) {
+	res, ok := o.object().(resource)
+	if !r.active() || !ok {
+		return
+	}
+	t := res.base()
+	t.deleted = true
+	if i := slices.Index(r.resources, res); i >= 0 {
+		r.resources = slices.Delete(r.resources, i, i+1)
+	}
+	if t.id != 0 {
+		delete(r.byID, t.id)
+		if t.value.Truthy() {
+			r.ids.Call("delete", t.value)
+		}
+	}
+	for target, b := range r.buffers {
+		if resource(b) == res {
+			delete(r.buffers, target)
+		}
+	}
+	for binding, t := range r.textures {
+		if resource(t) == res {
+			delete(r.textures, binding)
+		}
+	}
+	if resource(r.renderbuffer) == res {
+		r.renderbuffer = nil
+	}
+	if resource(r.drawFramebuffer) == res {
+		r.drawFramebuffer = nil
+	}
+	if resource(r.readFramebuffer) == res {
+		r.readFramebuffer = nil
+	}
+}
+
+// Returns the resource of a handle, or nil if it is not tracked.
+func resourceOf[T trackedObject](o object) T {
+	res, _ := o.object().(T)
+	return res
+}
+
+// Re-creates every tracked object on a restored context, then resets the
+// bindings and pixel storage modes the restore changed.
+func (r *registry) restore(c *Context) {
+	r.restoring = true
+	defer func() { r.restoring = false }()
+	r.reset()
+	clear(c.extensions)
+	clear(c.limits)
+	c.vao.reset()
+	c.links++
+	order := slices.Clone(r.resources)
+	slices.SortStableFunc(order, func(a, b resource) int {
+		return restoreRank(a) - restoreRank(b)
+	})
+	for _, res := range order {
+		if res.base().restorable {
+			res.restore(c, r)
+			r.index(res)
+		}
+	}
+
+	for target := range r.buffers {
+		c.BindBuffer(target, Buffer{})
+	}
+	for binding := range r.textures {
+		c.BindTexture(binding.target, Texture{})
+	}
+	if r.renderbuffer != nil {
+		c.BindRenderbuffer(RENDERBUFFER, Renderbuffer{})
+	}
+	if r.drawFramebuffer != nil {
+		c.BindFramebuffer(FRAMEBUFFER, Framebuffer{})
+	}
+	for pname, param := range r.pixelStore {
+		if param != defaultPixelStore(pname) {
+			c.PixelStorei(pname, defaultPixelStore(pname))
+		}
+	}
+	r.reset()
+}
+
+// Returns when a resource is re-created relative to the others, so that
+// the objects it refers to exist first.
+func restoreRank(res resource) int {
+	switch res.(type) {
+	case *bufferResource:
+		return 0
+	case *textureResource:
+		return 1
+	case *renderbufferResource:
+		return 2
+	case *shaderResource:
+		return 3
+	case *programResource:
+		return 4
+	}
+	return 5
+}
+
+// Returns the initial value of a pixel storage mode.
+func defaultPixelStore(pname Enum) int {
+	switch pname {
+	case PACK_ALIGNMENT, UNPACK_ALIGNMENT:
+		return 4
+	case UNPACK_COLORSPACE_CONVERSION_WEBGL:
+		return int(BROWSER_DEFAULT_WEBGL)
+	}
+	return 0
+}
+
+// Sets the pixel storage modes to store while restoring, recording the
+// change so restore can reset them afterwards.
+func (r *registry) setPixelStore(c *Context, store map[Enum]int) {
+	for pname, param := range r.pixelStore {
+		if _, ok := store[pname]; !ok && param != defaultPixelStore(pname) {
+			c.PixelStorei(pname, defaultPixelStore(pname))
+			r.pixelStore[pname] = defaultPixelStore(pname)
+		}
+	}
+	for pname, param := range store {
+		if cur, ok := r.pixelStore[pname]; !ok && param != defaultPixelStore(pname) || ok && cur != param {
+			c.PixelStorei(pname, param)
+			r.pixelStore[pname] = param
+		}
+	}
+}
+
+// Records a change of the active texture unit.
+func (r *registry) setActiveTexture(texture Enum) {
+	if r.active() {
+		r.activeTexture = texture
+	}
+}
+
+// Records a pixel storage mode used by later texture uploads.
+func (r *registry) pixelStorei(pname Enum, param int) {
+	if r.active() {
+		r.pixelStore[pname] = param
+	}
+}
+
+// bufferResource is a tracked WebGLBuffer.
+type bufferResource struct {
+	tracked
+	target Enum
+	usage  Enum
+	data   []byte
+}
+
+func (b *bufferResource) restore(c *Context, r *registry) {
+	b.value = c.call("createBuffer")
+	if b.target == 0 {
+		return
+	}
+	r.buffers[b.target] = b
+	c.BindBuffer(b.target, Buffer{b})
+	if b.data != nil {
+		c.BufferDataBytes(b.target, b.data, b.usage)
+	}
+}
+
+// Records the binding of a buffer.
+func (r *registry) bindBuffer(target Enum, buffer Buffer) {
+	if !r.active() {
+		return
+	}
+	b := resourceOf[*bufferResource](buffer)
+	if b == nil {
+		delete(r.buffers, target)
+		return
+	}
+	if b.target == 0 {
+		b.target = target
+	}
+	r.buffers[target] = b
+}
+
+// Returns the tracked buffer bound to target, or nil.
+func (r *registry) boundBuffer(target Enum) *bufferResource {
+	if !r.active() {
+		return nil
+	}
+	return r.buffers[target]
+}
+
+// Sets the size and usage of the data store of a buffer, and its contents
+// if the buffer is restorable.
+func (b *bufferResource) setData(size int, usage Enum, data func() []byte) {
+	b.bytes, b.usage, b.data = size, usage, nil
+	if b.restorable {
+		b.data = data()
+	}
+}
+
+// Records the data store of the buffer bound to target.
+func (r *registry) bufferData(target Enum, data []byte, usage Enum) {
+	if b := r.boundBuffer(target); b != nil {
+		b.setData(len(data), usage, func() []byte {
+			return append(make([]byte, 0, len(data)), data...)
+		})
+	}
+}
+
+// Records a data store given to BufferData as a size, already converted
+// to an int, or a JavaScript typed array or ArrayBuffer.
+func (r *registry) bufferDataValue(target Enum, data interface{}, usage Enum) {
+	b := r.boundBuffer(target)
+	if b == nil {
+		return
+	}
+	switch data := data.(type) {
+	case int:
+		b.setData(data, usage, func() []byte { return make([]byte, data) })
+	case js.Value:
+		b.setData(data.Get("byteLength").Int(), usage, func() []byte { return valueBytes(data) })
+	}
+}
+
+// Records an update of the data store of the buffer bound to target.
+func (r *registry) bufferSubData(target Enum, offset int, data []byte) {
+	if b := r.boundBuffer(target); b != nil && offset >= 0 && offset+len(data) <= len(b.data) {
+		copy(b.data[offset:], data)
+	}
+}
+
+// Records an update given to BufferSubData as a JavaScript typed array or
+// ArrayBuffer.
+func (r *registry) bufferSubDataValue(target Enum, offset int, data interface{}) {
+	if v, ok := data.(js.Value); ok && r.boundBuffer(target) != nil && r.buffers[target].data != nil {
+		r.bufferSubData(target, offset, valueBytes(v))
+	}
+}
+
+// Returns a copy of the bytes of a typed array or ArrayBuffer.
+func valueBytes(v js.Value) []byte {
+	var arr js.Value
+	if v.Get("byteOffset").Type() == js.TypeNumber {
+		arr = js.Global().Get("Uint8Array").New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	} else {
+		arr = js.Global().Get("Uint8Array").New(v)
+	}
+	b := make([]byte, arr.Length())
+	js.CopyBytesToGo(b, arr)
+	return b
+}
+
+// textureResource is a tracked WebGLTexture.
+type textureResource struct {
+	tracked
+	target Enum
+	ops    []textureOp
+	images map[textureLevel]textureImage
+}
+
+// textureLevel is a mipmap level of a texture image target.
+type textureLevel struct {
+	target Enum
+	level  int
+}
+
+// textureImage is the size of a texture image.
+type textureImage struct {
+	width, height, bytes int
+}
+
+// textureOp is a call that changed the images or parameters of a texture,
+// replayed in order on restore.
+type textureOp struct {
+	kind   textureOpKind
+	target Enum
+	level  int
+	// rect is the region replaced by a sub-image, if known.
+	rect   [4]int
+	pname  Enum
+	store  map[Enum]int
+	data   []byte
+	replay func(c *Context, data []byte)
+}
+
+type textureOpKind int
+
+const (
+	texImage textureOpKind = iota
+	texSubImage
+	texParameter
+	texMipmap
+)
+
+func (t *textureResource) restore(c *Context, r *registry) {
+	t.value = c.call("createTexture")
+	if t.target == 0 {
+		return
+	}
+	r.textures[textureBinding{TEXTURE0, t.target}] = t
+	c.BindTexture(t.target, Texture{t})
+	for _, op := range t.ops {
+		r.setPixelStore(c, op.store)
+		op.replay(c, op.data)
+	}
+}
+
+// Returns the target a texture is bound to for an image target, which
+// differs for the faces of a cube map.
+func textureTarget(target Enum) Enum {
+	if target >= TEXTURE_CUBE_MAP_POSITIVE_X && target <= TEXTURE_CUBE_MAP_NEGATIVE_Z {
+		return TEXTURE_CUBE_MAP
+	}
+	return target
+}
+
+// Records the binding of a texture to the active texture unit.
+func (r *registry) bindTexture(target Enum, texture Texture) {
+	if !r.active() {
+		return
+	}
+	binding := textureBinding{r.activeTexture, target}
+	t := resourceOf[*textureResource](texture)
+	if t == nil {
+		delete(r.textures, binding)
+		return
+	}
+	if t.target == 0 {
+		t.target = target
+	}
+	r.textures[binding] = t
+}
+
+// Returns the tracked texture bound to the target of an image target in
+// the active texture unit, or nil.
+func (r *registry) boundTexture(target Enum) *textureResource {
+	if !r.active() {
+		return nil
+	}
+	return r.textures[textureBinding{r.activeTexture, textureTarget(target)}]
+}
+
+// Records the size of an image of the texture bound to target.
+func (r *registry) textureImage(target Enum, level int, image textureImage) {
+	t := r.boundTexture(target)
+	if t == nil {
+		return
+	}
+	if t.images == nil {
+		t.images = make(map[textureLevel]textureImage)
+	}
+	t.images[textureLevel{target, level}] = image
+	t.updateBytes()
+}
+
+// Adds an operation to the texture bound to the target of op, dropping
+// the earlier operations it overrides. Nothing is kept for textures that
+// are not restorable.
+func (r *registry) textureOp(op textureOp) {
+	t := r.boundTexture(op.target)
+	if t == nil || !t.restorable {
+		return
+	}
+	t.ops = slices.DeleteFunc(t.ops, func(old textureOp) bool {
+		switch op.kind {
+		case texImage:
+			return (old.kind == texImage || old.kind == texSubImage) && old.target == op.target && old.level == op.level
+		case texSubImage:
+			return old.kind == texSubImage && old.target == op.target && old.level == op.level &&
+				op.rect[2] > 0 && old.rect == op.rect
+		case texParameter:
+			return old.kind == texParameter && old.pname == op.pname
+		}
+		return old.kind == texMipmap
+	})
+	if op.kind == texImage || op.kind == texSubImage {
+		op.store = maps.Clone(r.pixelStore)
+	}
+	op.data = bytes.Clone(op.data)
+	t.ops = append(t.ops, op)
+}
+
+// Returns the size of an image source such as an HTMLImageElement,
+// HTMLVideoElement or ImageData, or zero if it is not known.
+func sourceSize(image js.Value) (width, height int) {
+	if !image.Truthy() {
+		return 0, 0
+	}
+	for _, p := range [][2]string{{"videoWidth", "videoHeight"}, {"width", "height"}} {
+		w, h := image.Get(p[0]), image.Get(p[1])
+		if w.Type() == js.TypeNumber && h.Type() == js.TypeNumber {
+			return w.Int(), h.Int()
+		}
+	}
+	return 0, 0
+}
+
+// Records a TexImage2D call.
+func (r *registry) texImage2D(target Enum, level int, internalFormat, format, kind Enum, image js.Value) {
+	if !r.active() {
+		return
+	}
+	w, h := sourceSize(image)
+	r.textureImage(target, level, textureImage{w, h, w * h * pixelBytes(format, kind)})
+	r.textureOp(textureOp{kind: texImage, target: target, level: level, replay: func(c *Context, _ []byte) {
+		c.TexImage2D(target, level, internalFormat, format, kind, image)
+	}})
+}
+
+// Records a TexImage2DPixels call.
+func (r *registry) texImage2DPixels(target Enum, level int, internalFormat Enum, width, height, border int, format, typ Enum, pixels []byte) {
+	if !r.active() {
+		return
+	}
+	r.textureImage(target, level, textureImage{width, height, width * height * pixelBytes(format, typ)})
+	r.textureOp(textureOp{kind: texImage, target: target, level: level, data: pixels, replay: func(c *Context, pixels []byte) {
+		c.TexImage2DPixels(target, level, internalFormat, width, height, border, format, typ, pixels)
+	}})
+}
+
+// Records a TexSubImage2D call.
+func (r *registry) texSubImage2D(target Enum, level, xoffset, yoffset int, format, typ Enum, image js.Value) {
+	if !r.active() {
+		return
+	}
+	w, h := sourceSize(image)
+	r.textureOp(textureOp{kind: texSubImage, target: target, level: level, rect: [4]int{xoffset, yoffset, w, h}, replay: func(c *Context, _ []byte) {
+		c.TexSubImage2D(target, level, xoffset, yoffset, format, typ, image)
+	}})
+}
+
+// Records a TexSubImage2DPixels call.
+func (r *registry) texSubImage2DPixels(target Enum, level, xoffset, yoffset, width, height int, format, typ Enum, pixels []byte) {
+	if !r.active() {
+		return
+	}
+	r.textureOp(textureOp{kind: texSubImage, target: target, level: level, rect: [4]int{xoffset, yoffset, width, height}, data: pixels, replay: func(c *Context, pixels []byte) {
+		c.TexSubImage2DPixels(target, level, xoffset, yoffset, width, height, format, typ, pixels)
+	}})
+}
+
+// Records a CompressedTexImage2D call.
+func (r *registry) compressedTexImage2D(target Enum, level int, internalFormat Enum, width, height, border int, data []byte) {
+	if !r.active() {
+		return
+	}
+	r.textureImage(target, level, textureImage{width, height, len(data)})
+	r.textureOp(textureOp{kind: texImage, target: target, level: level, data: data, replay: func(c *Context, data []byte) {
+		c.CompressedTexImage2D(target, level, internalFormat, width, height, border, data)
+	}})
+}
+
+// Records a CompressedTexSubImage2D call.
+func (r *registry) compressedTexSubImage2D(target Enum, level, xoffset, yoffset, width, height int, format Enum, data []byte) {
+	if !r.active() {
+		return
+	}
+	r.textureOp(textureOp{kind: texSubImage, target: target, level: level, rect: [4]int{xoffset, yoffset, width, height}, data: data, replay: func(c *Context, data []byte) {
+		c.CompressedTexSubImage2D(target, level, xoffset, yoffset, width, height, format, data)
+	}})
+}
+
+// Records the size of the image made by a CopyTexImage2D call, which is
+// not restored.
+func (r *registry) copyTexImage2D(target Enum, level int, internal Enum, w, h int) {
+	r.textureImage(target, level, textureImage{w, h, w * h * pixelBytes(internal, UNSIGNED_BYTE)})
+}
+
+// Records the size of the image made by a TexImage3D call, which is not
+// restored.
+func (r *registry) texImage3D(target Enum, level, width, height, depth int, format, typ Enum) {
+	r.textureImage(target, level, textureImage{width, height, width * height * depth * pixelBytes(format, typ)})
+}
+
+// Records the sizes of the levels allocated by a TexStorage2D or
+// TexStorage3D call, which are not restored. The depth of 2D textures is 1.
+func (r *registry) texStorage(target Enum, levels int, internalFormat Enum, width, height, depth int) {
+	targets := []Enum{target}
+	if target == TEXTURE_CUBE_MAP {
+		targets = targets[:0]
+		for face := TEXTURE_CUBE_MAP_POSITIVE_X; face <= TEXTURE_CUBE_MAP_NEGATIVE_Z; face++ {
+			targets = append(targets, face)
+		}
+	}
+	size := internalFormatBytes(internalFormat)
+	for level := 0; level < levels; level++ {
+		for _, target := range targets {
+			r.textureImage(target, level, textureImage{width, height, width * height * depth * size})
+		}
+		width, height = max(width/2, 1), max(height/2, 1)
+		if target == TEXTURE_3D {
+			depth = max(depth/2, 1)
+		}
+	}
+}
+
+// Records a TexParameterf call.
+func (r *registry) texParameterf(target, pname Enum, param float32) {
+	if !r.active() {
+		return
+	}
+	r.textureOp(textureOp{kind: texParameter, target: target, pname: pname, replay: func(c *Context, _ []byte) {
+		c.TexParameterf(target, pname, param)
+	}})
+}
+
+// Records a TexParameteri call.
+func (r *registry) texParameteri(target, pname, param Enum) {
+	if !r.active() {
+		return
+	}
+	r.textureOp(textureOp{kind: texParameter, target: target, pname: pname, replay: func(c *Context, _ []byte) {
+		c.TexParameteri(target, pname, param)
+	}})
+}
+
+// Records a GenerateMipmap call.
+func (r *registry) generateMipmap(target Enum) {
+	t := r.boundTexture(target)
+	if t == nil {
+		return
+	}
+	t.generateMipmap()
+	r.textureOp(textureOp{kind: texMipmap, target: target, replay: func(c *Context, _ []byte) {
+		c.GenerateMipmap(target)
+	}})
+}
+
+// renderbufferResource is a tracked WebGLRenderbuffer.
+type renderbufferResource struct {
+	tracked
+	internalFormat Enum
+	width, height  int
+}
+
+func (rb *renderbufferResource) restore(c *Context, r *registry) {
+	rb.value = c.call("createRenderbuffer")
+	if rb.internalFormat == 0 {
+		return
+	}
+	r.renderbuffer = rb
+	c.BindRenderbuffer(RENDERBUFFER, Renderbuffer{rb})
+	c.RenderbufferStorage(RENDERBUFFER, rb.internalFormat, rb.width, rb.height)
+}
+
+// Records the binding of a renderbuffer.
+func (r *registry) bindRenderbuffer(renderbuffer Renderbuffer) {
+	if r.active() {
+		r.renderbuffer = resourceOf[*renderbufferResource](renderbuffer)
+	}
+}
+
+// Records the storage of the bound renderbuffer.
+func (r *registry) renderbufferStorage(internalFormat Enum, width, height int) {
+	if !r.active() || r.renderbuffer == nil {
+		return
+	}
+	r.renderbuffer.internalFormat = internalFormat
+	r.renderbuffer.width, r.renderbuffer.height = width, height
+	r.renderbuffer.bytes = width * height * internalFormatBytes(internalFormat)
+}
+
+// framebufferResource is a tracked WebGLFramebuffer.
+type framebufferResource struct {
+	tracked
+	attachments map[Enum]attachedImage
+}
+
+// attachedImage is an image attached to a framebuffer.
+type attachedImage struct {
+	texture      *textureResource
+	textarget    Enum
+	level        int
+	renderbuffer *renderbufferResource
+}
+
+func (f *framebufferResource) restore(c *Context, r *registry) {
+	f.value = c.call("createFramebuffer")
+	if len(f.attachments) == 0 {
+		return
+	}
+	r.drawFramebuffer, r.readFramebuffer = f, f
+	c.BindFramebuffer(FRAMEBUFFER, Framebuffer{f})
+	for _, point := range sortedKeys(f.attachments) {
+		a := f.attachments[point]
+		switch {
+		case a.texture != nil && !a.texture.deleted:
+			c.FramebufferTexture2D(FRAMEBUFFER, point, a.textarget, Texture{a.texture}, a.level)
+		case a.renderbuffer != nil && !a.renderbuffer.deleted:
+			c.FrameBufferRenderBuffer(FRAMEBUFFER, point, RENDERBUFFER, Renderbuffer{a.renderbuffer})
+		}
+	}
+}
+
+// Records the binding of a framebuffer.
+func (r *registry) bindFramebuffer(target Enum, framebuffer Framebuffer) {
+	if !r.active() {
+		return
+	}
+	f := resourceOf[*framebufferResource](framebuffer)
+	if target != READ_FRAMEBUFFER {
+		r.drawFramebuffer = f
+	}
+	if target != DRAW_FRAMEBUFFER {
+		r.readFramebuffer = f
+	}
+}
+
+// Records an attachment of the framebuffer bound to target.
+func (r *registry) framebufferAttachment(target, point Enum, a attachedImage) {
+	if !r.active() {
+		return
+	}
+	f := r.drawFramebuffer
+	if target == READ_FRAMEBUFFER {
+		f = r.readFramebuffer
+	}
+	if f == nil {
+		return
+	}
+	if f.attachments == nil {
+		f.attachments = make(map[Enum]attachedImage)
+	}
+	if a.texture == nil && a.renderbuffer == nil {
+		delete(f.attachments, point)
+		return
+	}
+	f.attachments[point] = a
+}
+
+// shaderResource is a tracked WebGLShader.
+type shaderResource struct {
+	tracked
+	typ    Enum
+	source string
+	// compiled is the source of the last compilation, if any.
+	compiled *string
+}
+
+func (s *shaderResource) restore(c *Context, r *registry) {
+	s.value = c.call("createShader", s.typ)
+	if s.compiled != nil {
+		c.ShaderSource(Shader{s}, *s.compiled)
+		c.CompileShader(Shader{s})
+	}
+	if s.compiled == nil || *s.compiled != s.source {
+		c.ShaderSource(Shader{s}, s.source)
+	}
+}
+
+// Records the source of a shader.
+func (r *registry) shaderSource(shader Shader, source string) {
+	if s := resourceOf[*shaderResource](shader); s != nil && r.active() {
+		s.source = source
+	}
+}
+
+// Records the compilation of a shader.
+func (r *registry) compileShader(shader Shader) {
+	if s := resourceOf[*shaderResource](shader); s != nil && r.active() {
+		source := s.source
+		s.compiled = &source
+	}
+}
+
+// programResource is a tracked WebGLProgram.
+type programResource struct {
+	tracked
+	shaders []*shaderResource
+	attribs map[string]int
+	// linked holds the shaders and attribute bindings of the last link.
+	linked *programLink
+	// locations are the uniform locations returned for the program.
+	locations map[string]*locationResource
+}
+
+type programLink struct {
+	shaders []*shaderResource
+	attribs map[string]int
+}
+
+func (p *programResource) restore(c *Context, r *registry) {
+	p.value = c.call("createProgram")
+	program := Program{p}
+
+	// Deleted shaders are created again for as long as they are needed.
+	var recreated []*shaderResource
+	need := func(s *shaderResource) {
+		if s.deleted && !slices.Contains(recreated, s) {
+			s.restore(c, r)
+			recreated = append(recreated, s)
+		}
+	}
+	if p.linked != nil {
+		for _, s := range p.linked.shaders {
+			need(s)
+			c.AttachShader(program, Shader{s})
+		}
+		for _, name := range sortedKeys(p.linked.attribs) {
+			c.BindAttribLocation(program, p.linked.attribs[name], name)
+		}
+		c.LinkProgram(program)
+		for _, s := range p.linked.shaders {
+			if !slices.Contains(p.shaders, s) {
+				c.DetachShader(program, Shader{s})
+			}
+		}
+	}
+	for _, s := range p.shaders {
+		if p.linked == nil || !slices.Contains(p.linked.shaders, s) {
+			need(s)
+			c.AttachShader(program, Shader{s})
+		}
+	}
+	for _, name := range sortedKeys(p.attribs) {
+		c.BindAttribLocation(program, p.attribs[name], name)
+	}
+	for _, s := range recreated {
+		c.DeleteShader(Shader{s})
+		s.value = js.Null()
+	}
+	if p.linked == nil {
+		return
+	}
+	for name, l := range p.locations {
+		l.value = c.call("getUniformLocation", program, name)
+	}
+}
+
+// Records the attachment of a shader to a program.
+func (r *registry) attachShader(program Program, shader Shader) {
+	p, s := resourceOf[*programResource](program), resourceOf[*shaderResource](shader)
+	if p != nil && s != nil && r.active() && !slices.Contains(p.shaders, s) {
+		p.shaders = append(p.shaders, s)
+	}
+}
+
+// Records the detachment of a shader from a program.
+func (r *registry) detachShader(program Program, shader Shader) {
+	p, s := resourceOf[*programResource](program), resourceOf[*shaderResource](shader)
+	if p != nil && s != nil && r.active() {
+		p.shaders = slices.DeleteFunc(p.shaders, func(old *shaderResource) bool { return old == s })
+	}
+}
+
+// Records the location bound to an attribute of a program.
+func (r *registry) bindAttribLocation(program Program, index int, name string) {
+	if p := resourceOf[*programResource](program); p != nil && r.active() {
+		if p.attribs == nil {
+			p.attribs = make(map[string]int)
+		}
+		p.attribs[name] = index
+	}
+}
+
+// Records the linking of a program.
+func (r *registry) linkProgram(program Program) {
+	if p := resourceOf[*programResource](program); p != nil && r.active() {
+		p.linked = &programLink{shaders: slices.Clone(p.shaders), attribs: maps.Clone(p.attribs)}
+	}
+}
+
+// locationResource is a uniform location of a tracked program, looked up
+// again when the program is restored.
+type locationResource struct {
+	tracked
+}
+
+// Returns the Object for a uniform location of a program, which is
+// cached by name if the program is tracked.
+func (r *registry) location(program Program, name string, v js.Value) Object {
+	p := resourceOf[*programResource](program)
+	if !r.active() || p == nil || !v.Truthy() {
+		return jsObject(v)
+	}
+	l := p.locations[name]
+	if l == nil {
+		if p.locations == nil {
+			p.locations = make(map[string]*locationResource)
+		}
+		l = &locationResource{}
+		p.locations[name] = l
+	}
+	l.value = v
+	return l
+}
+
+// Returns the keys of m in increasing order.
+func sortedKeys[K Enum | string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// queryResource is a tracked WebGLQuery, re-created without a result.
+type queryResource struct {
+	tracked
+}
+
+func (q *queryResource) restore(c *Context, r *registry) {
+	q.value = c.call("createQuery")
+}
+
+// samplerResource is a tracked WebGLSampler.
+type samplerResource struct {
+	tracked
+	// params are the values passed to SamplerParameteri as an Enum or
+	// to SamplerParameterf as a float32.
+	params map[Enum]interface{}
+}
+
+func (s *samplerResource) restore(c *Context, r *registry) {
+	s.value = c.call("createSampler")
+	for _, pname := range sortedKeys(s.params) {
+		switch param := s.params[pname].(type) {
+		case Enum:
+			c.call("samplerParameteri", Sampler{s}, pname, param)
+		case float32:
+			c.call("samplerParameterf", Sampler{s}, pname, param)
+		}
+	}
+}
+
+// Records a sampler parameter.
+func (r *registry) samplerParameter(sampler Sampler, pname Enum, param interface{}) {
+	if s := resourceOf[*samplerResource](sampler); s != nil && r.active() {
+		if s.params == nil {
+			s.params = make(map[Enum]interface{})
+		}
+		s.params[pname] = param
+	}
+}
+
+// syncResource is a tracked WebGLSync, re-created as a new fence that is
+// signaled once the commands issued during the restore have completed.
+type syncResource struct {
+	tracked
+}
+
+func (s *syncResource) restore(c *Context, r *registry) {
+	s.value = c.call("fenceSync", SYNC_GPU_COMMANDS_COMPLETE, 0)
+}
+
+// transformFeedbackResource is a tracked WebGLTransformFeedback,
+// re-created without its buffer bindings.
+type transformFeedbackResource struct {
+	tracked
+}
+
+func (t *transformFeedbackResource) restore(c *Context, r *registry) {
+	t.value = c.call("createTransformFeedback")
+}
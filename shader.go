@@ -0,0 +1,160 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ShaderError is returned when a shader fails to compile or a program
+// fails to link. The info log is parsed into entries that point at the
+// offending lines of the GLSL source.
+type ShaderError struct {
+	// Kind is VERTEX_SHADER or FRAGMENT_SHADER for compile errors,
+	// or NONE for link errors.
+	Kind Enum
+
+	// Log is the info log as returned by WebGL.
+	Log string
+
+	// Entries are the messages parsed from Log.
+	Entries []ShaderLogEntry
+}
+
+// ShaderLogEntry is a single message from a shader or program info log.
+type ShaderLogEntry struct {
+	// Severity is the message kind given by the compiler, such as
+	// "ERROR" or "WARNING".
+	Severity string
+
+	// Line is the 1-based line of the shader source the message refers
+	// to, or 0 if the message does not refer to a line.
+	Line int
+
+	// Source is the text of the source line, if known.
+	Source string
+
+	// Message is the compiler's description of the problem.
+	Message string
+}
+
+func (e *ShaderError) Error() string {
+	var b strings.Builder
+	switch e.Kind {
+	case VERTEX_SHADER:
+		b.WriteString("webgl: vertex shader failed to compile")
+	case FRAGMENT_SHADER:
+		b.WriteString("webgl: fragment shader failed to compile")
+	default:
+		b.WriteString("webgl: program failed to link")
+	}
+	for _, entry := range e.Entries {
+		b.WriteString("\n\t")
+		if entry.Line > 0 {
+			fmt.Fprintf(&b, "%d: ", entry.Line)
+		}
+		if entry.Severity != "" {
+			fmt.Fprintf(&b, "%s: ", strings.ToLower(entry.Severity))
+		}
+		b.WriteString(entry.Message)
+		if entry.Source != "" {
+			fmt.Fprintf(&b, "\n\t\t%s", strings.TrimSpace(entry.Source))
+		}
+	}
+	return b.String()
+}
+
+// Matches ANGLE and Chrome log lines such as
+// "ERROR: 0:12: 'x' : undeclared identifier".
+var shaderLogLine = regexp.MustCompile(`^\s*(ERROR|WARNING|INFO)\s*:\s*(\d+):(\d+)\s*:\s*(.*)$`)
+
+// Matches Mesa log lines such as "0:12(5): error: `x' undeclared".
+var mesaLogLine = regexp.MustCompile(`^\s*(\d+):(\d+)\(\d+\)\s*:\s*(error|warning|info)\s*:\s*(.*)$`)
+
+// Parses an info log, attaching the matching lines of src to the entries.
+func parseShaderLog(log, src string) []ShaderLogEntry {
+	lines := strings.Split(src, "\n")
+	var entries []ShaderLogEntry
+	for _, l := range strings.Split(log, "\n") {
+		l = strings.TrimRight(l, "\r\x00")
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		var entry ShaderLogEntry
+		if m := shaderLogLine.FindStringSubmatch(l); m != nil {
+			entry = ShaderLogEntry{Severity: m[1], Message: m[4]}
+			entry.Line, _ = strconv.Atoi(m[3])
+		} else if m := mesaLogLine.FindStringSubmatch(l); m != nil {
+			entry = ShaderLogEntry{Severity: strings.ToUpper(m[3]), Message: m[4]}
+			entry.Line, _ = strconv.Atoi(m[2])
+		} else {
+			entries = append(entries, ShaderLogEntry{Message: strings.TrimSpace(l)})
+			continue
+		}
+		if entry.Line > 0 && entry.Line <= len(lines) {
+			entry.Source = lines[entry.Line-1]
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Creates and compiles a shader of the given kind, VERTEX_SHADER or
+// FRAGMENT_SHADER, from GLSL source. If compilation fails the shader is
+// deleted and a *ShaderError describing the info log is returned.
+func CompileShader(gl RenderingContext, kind Enum, src string) (Shader, error) {
+	shader := gl.CreateShader(kind)
+	gl.ShaderSource(shader, src)
+	gl.CompileShader(shader)
+	if !gl.GetShaderParameterb(shader, COMPILE_STATUS) {
+		log := gl.GetShaderInfoLog(shader)
+		gl.DeleteShader(shader)
+		return Shader{}, &ShaderError{Kind: kind, Log: log, Entries: parseShaderLog(log, src)}
+	}
+	return shader, nil
+}
+
+// Compiles the vertex and fragment shader sources and links them into a
+// program. Attributes named in attribBindings are bound to the given
+// locations before linking. The shaders are flagged for deletion so they
+// are freed together with the program. Compile and link failures are
+// returned as a *ShaderError.
+func LinkProgram(gl RenderingContext, vs, fs string, attribBindings map[string]int) (Program, error) {
+	vertex, err := CompileShader(gl, VERTEX_SHADER, vs)
+	if err != nil {
+		return Program{}, err
+	}
+	fragment, err := CompileShader(gl, FRAGMENT_SHADER, fs)
+	if err != nil {
+		gl.DeleteShader(vertex)
+		return Program{}, err
+	}
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vertex)
+	gl.AttachShader(program, fragment)
+	names := make([]string, 0, len(attribBindings))
+	for name := range attribBindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		gl.BindAttribLocation(program, attribBindings[name], name)
+	}
+	gl.LinkProgram(program)
+	gl.DeleteShader(vertex)
+	gl.DeleteShader(fragment)
+
+	if !gl.GetProgramParameterb(program, LINK_STATUS) {
+		log := gl.GetProgramInfoLog(program)
+		gl.DeleteProgram(program)
+		return Program{}, &ShaderError{Kind: NONE, Log: log, Entries: parseShaderLog(log, "")}
+	}
+	return program, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+func TestCompileShaderError(t *testing.T) {
+	gl := record.New(64, 64, nil)
+	gl.Compile = func(typ webgl.Enum, src string) error {
+		return errors.New("ERROR: 0:2: 'foo' : undeclared identifier")
+	}
+	src := "void main() {\n\tfoo = 1;\n}"
+	shader, err := webgl.CompileShader(gl, webgl.VERTEX_SHADER, src)
+	var serr *webgl.ShaderError
+	if !errors.As(err, &serr) {
+		t.Fatalf("got error %v, want a *ShaderError", err)
+	}
+	if shader.Valid() {
+		t.Error("returned a shader that failed to compile")
+	}
+	want := []webgl.ShaderLogEntry{{Severity: "ERROR", Line: 2, Source: "\tfoo = 1;", Message: "'foo' : undeclared identifier"}}
+	if serr.Kind != webgl.VERTEX_SHADER || fmt.Sprint(serr.Entries) != fmt.Sprint(want) {
+		t.Errorf("got kind 0x%04X and entries %+v, want a vertex shader error with %+v", uint32(serr.Kind), serr.Entries, want)
+	}
+	wantText := "webgl: vertex shader failed to compile\n\t2: error: 'foo' : undeclared identifier\n\t\tfoo = 1;"
+	if err.Error() != wantText {
+		t.Errorf("got message %q, want %q", err, wantText)
+	}
+	source, deleted := gl.Filter("ShaderSource"), gl.Filter("DeleteShader")
+	if len(deleted) != 1 || deleted[0].Args[0] != source[0].Args[0] {
+		t.Errorf("got deletions %v, want the failed shader deleted", deleted)
+	}
+}
+
+func TestLinkProgramError(t *testing.T) {
+	gl := record.New(64, 64, nil)
+	gl.Link = func(webgl.Program, string, string) ([]webgl.ActiveInfo, []webgl.ActiveInfo, error) {
+		return nil, nil, errors.New("Varying `v` has static-use in the frag shader, but is undeclared in the vert shader.")
+	}
+	program, err := webgl.LinkProgram(gl, "vertex", "fragment", nil)
+	var serr *webgl.ShaderError
+	if !errors.As(err, &serr) {
+		t.Fatalf("got error %v, want a *ShaderError", err)
+	}
+	if program.Valid() || serr.Kind != webgl.NONE || len(serr.Entries) != 1 || serr.Entries[0].Line != 0 {
+		t.Errorf("got program %v and error %+v", program, serr)
+	}
+	if gl.Count("DeleteProgram") != 1 || gl.Count("DeleteShader") != 2 {
+		t.Errorf("deleted %d programs and %d shaders, want 1 and 2", gl.Count("DeleteProgram"), gl.Count("DeleteShader"))
+	}
+}
+
+func TestLinkProgramBindings(t *testing.T) {
+	gl := newRecord([]webgl.ActiveInfo{
+		{Name: "a_pos", Type: webgl.FLOAT_VEC3, Size: 1},
+		{Name: "a_normal", Type: webgl.FLOAT_VEC3, Size: 1},
+		{Name: "a_uv", Type: webgl.FLOAT_VEC2, Size: 1},
+	}, nil)
+	program, err := webgl.LinkProgram(gl, "vertex", "fragment", map[string]int{"a_uv": 0, "a_pos": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := takeCalls(gl, program, "BindAttribLocation", "LinkProgram")
+	want := []string{`BindAttribLocation(p, 2, "a_pos")`, `BindAttribLocation(p, 0, "a_uv")`, `LinkProgram(p)`}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+	for name, want := range map[string]int{"a_pos": 2, "a_uv": 0, "a_normal": 1} {
+		if got := gl.GetAttribLocation(program, name); got != want {
+			t.Errorf("%s is at location %d, want %d", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// Reads the pixels of rect from the currently bound framebuffer into an
+// image. rect is given in framebuffer coordinates with the origin at the
+// bottom left, as for ReadPixels, while the returned image has its origin
+// at (0, 0) and its rows flipped so the top row comes first.
+//
+// The image holds straight alpha colors. When the context uses
+// premultipliedAlpha the colors of the drawing buffer are divided by their
+// alpha while reading. Pixels of a bound framebuffer object are returned
+// as stored.
+func ReadPixelsRGBA(gl RenderingContext, rect image.Rectangle) (*image.NRGBA, error) {
+	if rect.Empty() {
+		return nil, fmt.Errorf("webgl: cannot read pixels of empty rectangle %v", rect)
+	}
+	if status := gl.CheckFramebufferStatus(FRAMEBUFFER); status != FRAMEBUFFER_COMPLETE {
+		return nil, fmt.Errorf("webgl: cannot read pixels of incomplete framebuffer (status 0x%04X)", uint32(status))
+	}
+
+	w, h := rect.Dx(), rect.Dy()
+	pix := make([]byte, 4*w*h)
+	gl.ReadPixelsBytes(rect.Min.X, rect.Min.Y, w, h, RGBA, UNSIGNED_BYTE, pix)
+	if gl.GetParameterObject(FRAMEBUFFER_BINDING) == nil && gl.GetContextAttributes().PremultipliedAlpha {
+		pix = unpremultiply(pix)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	stride := 4 * w
+	for y := 0; y < h; y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+stride], pix[(h-1-y)*stride:(h-y)*stride])
+	}
+	return img, nil
+}
+
+// Reads the pixels of rect from the currently bound framebuffer as
+// ReadPixelsRGBA does and writes them to w as a PNG image.
+func WritePNG(gl RenderingContext, w io.Writer, rect image.Rectangle) error {
+	img, err := ReadPixelsRGBA(gl, rect)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
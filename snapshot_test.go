@@ -0,0 +1,96 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/soft"
+)
+
+// Returns a 2x2 software context cleared to red and green on its bottom
+// row and blue and a translucent gray, premultiplied, on its top row.
+func newPattern(premultipliedAlpha bool) *soft.Context {
+	attrs := webgl.DefaultAttributes()
+	attrs.PremultipliedAlpha = premultipliedAlpha
+	gl := soft.New(2, 2, attrs)
+	gl.Enable(webgl.SCISSOR_TEST)
+	for _, c := range []struct {
+		x, y       int
+		r, g, b, a float32
+	}{
+		{0, 0, 1, 0, 0, 1},
+		{1, 0, 0, 1, 0, 1},
+		{0, 1, 0, 0, 1, 1},
+		{1, 1, 0.2, 0.2, 0.2, 0.4},
+	} {
+		gl.Scissor(c.x, c.y, 1, 1)
+		gl.ClearColor(c.r, c.g, c.b, c.a)
+		gl.Clear(webgl.COLOR_BUFFER_BIT)
+	}
+	gl.Disable(webgl.SCISSOR_TEST)
+	return gl
+}
+
+func TestReadPixelsRGBA(t *testing.T) {
+	red, green, blue := color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 255, 0, 255}, color.NRGBA{0, 0, 255, 255}
+	tests := []struct {
+		name               string
+		premultipliedAlpha bool
+		rect               image.Rectangle
+		want               []color.NRGBA
+	}{
+		{"premultiplied", true, image.Rect(0, 0, 2, 2), []color.NRGBA{blue, {128, 128, 128, 102}, red, green}},
+		{"straight", false, image.Rect(0, 0, 2, 2), []color.NRGBA{blue, {51, 51, 51, 102}, red, green}},
+		{"part", true, image.Rect(1, 0, 2, 2), []color.NRGBA{{128, 128, 128, 102}, green}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl := newPattern(test.premultipliedAlpha)
+			img, err := webgl.ReadPixelsRGBA(gl, test.rect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.Bounds() != image.Rect(0, 0, test.rect.Dx(), test.rect.Dy()) {
+				t.Fatalf("got bounds %v", img.Bounds())
+			}
+			for i, want := range test.want {
+				x, y := i%test.rect.Dx(), i/test.rect.Dx()
+				if got := img.NRGBAAt(x, y); got != want {
+					t.Errorf("pixel %d,%d is %v, want %v", x, y, got, want)
+				}
+			}
+		})
+	}
+
+	if _, err := webgl.ReadPixelsRGBA(newPattern(true), image.Rect(1, 1, 1, 2)); err == nil {
+		t.Error("read an empty rectangle")
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	gl := newPattern(true)
+	var b bytes.Buffer
+	if err := webgl.WritePNG(gl, &b, image.Rect(0, 0, 2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, _ := webgl.ReadPixelsRGBA(gl, image.Rect(0, 0, 2, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if got := color.NRGBAModel.Convert(decoded.At(x, y)); got != img.At(x, y) {
+				t.Errorf("PNG pixel %d,%d is %v, want %v", x, y, got, img.At(x, y))
+			}
+		}
+	}
+}
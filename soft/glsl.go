@@ -0,0 +1,128 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/glsl"
+)
+
+// Compiles a shader source, as the Compile hook of the record.Context.
+func compile(typ webgl.Enum, src string) error {
+	_, err := glsl.Compile(typ, src)
+	return err
+}
+
+// Compiles and links GLSL sources into a Program that interprets them.
+func linkGLSL(vs, fs string) (*Program, error) {
+	v, err := glsl.Compile(webgl.VERTEX_SHADER, vs)
+	if err != nil {
+		return nil, err
+	}
+	f, err := glsl.Compile(webgl.FRAGMENT_SHADER, fs)
+	if err != nil {
+		return nil, err
+	}
+	g, err := glsl.Link(v, f)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{
+		Attribs:  g.Attribs,
+		Uniforms: g.Uniforms,
+		Varyings: g.Varyings,
+		Prepare: func(u *Uniforms) {
+			g.LoadUniforms(func(name string) interface{} {
+				return u.array(name, g.Uniforms)
+			})
+			s := u.ctx.State()
+			g.SetDepthRange(float32(s.DepthRange[0]), float32(s.DepthRange[1]))
+		},
+		Vertex: func(v *Vertex) {
+			v.Position, v.PointSize = g.RunVertex(v.Attribs, v.Varyings, glslTextures{u: v.Uniforms})
+		},
+		Fragment: func(f *Fragment) bool {
+			color, ok := g.RunFragment(f.Coord, f.FrontFacing, f.PointCoord, f.Varyings, glslTextures{u: f.Uniforms, f: f})
+			f.Color = color
+			return ok
+		},
+	}, nil
+}
+
+// Returns the values of an active uniform with all the elements of an
+// array, as set for the whole array or for single elements.
+func (u *Uniforms) array(name string, uniforms []webgl.ActiveInfo) interface{} {
+	var info webgl.ActiveInfo
+	for _, a := range uniforms {
+		if strings.TrimSuffix(a.Name, "[0]") == name {
+			info = a
+		}
+	}
+	if info.Size <= 1 {
+		return u.ctx.Uniform(u.program, name)
+	}
+	n := components(info.Type)
+	switch info.Type {
+	case webgl.FLOAT, webgl.FLOAT_VEC2, webgl.FLOAT_VEC3, webgl.FLOAT_VEC4,
+		webgl.FLOAT_MAT2, webgl.FLOAT_MAT3, webgl.FLOAT_MAT4:
+		v := make([]float32, info.Size*n)
+		for i := 0; i < info.Size; i++ {
+			u.floats(fmt.Sprintf("%s[%d]", name, i), v[i*n:(i+1)*n])
+		}
+		return v
+	}
+	v := make([]int, info.Size*n)
+	for i := 0; i < info.Size; i++ {
+		u.ints(fmt.Sprintf("%s[%d]", name, i), v[i*n:(i+1)*n])
+	}
+	w := make([]int32, len(v))
+	for i, x := range v {
+		w[i] = int32(x)
+	}
+	return w
+}
+
+// Returns the number of components of a uniform type.
+func components(typ webgl.Enum) int {
+	switch typ {
+	case webgl.FLOAT_VEC2, webgl.INT_VEC2, webgl.BOOL_VEC2:
+		return 2
+	case webgl.FLOAT_VEC3, webgl.INT_VEC3, webgl.BOOL_VEC3:
+		return 3
+	case webgl.FLOAT_VEC4, webgl.INT_VEC4, webgl.BOOL_VEC4, webgl.FLOAT_MAT2:
+		return 4
+	case webgl.FLOAT_MAT3:
+		return 9
+	case webgl.FLOAT_MAT4:
+		return 16
+	}
+	return 1
+}
+
+// glslTextures samples the textures of a draw call for an interpreted
+// shader. f is nil in vertex shaders.
+type glslTextures struct {
+	u *Uniforms
+	f *Fragment
+}
+
+func (t glslTextures) Texture2D(unit int, s, tc, bias float32, lod bool) [4]float32 {
+	tex := t.u.unitTexture(unit, webgl.TEXTURE_2D)
+	if lod || t.f == nil {
+		return texture2DLod(tex, s, tc, bias)
+	}
+	return t.f.texture2D(tex, s, tc, bias)
+}
+
+func (t glslTextures) TextureCube(unit int, x, y, z, bias float32, lod bool) [4]float32 {
+	tex := t.u.unitTexture(unit, webgl.TEXTURE_CUBE_MAP)
+	if lod || t.f == nil {
+		return textureCubeLod(tex, x, y, z, bias)
+	}
+	return t.f.textureCube(tex, x, y, z, bias)
+}
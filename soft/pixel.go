@@ -0,0 +1,187 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"math"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+// target is the set of images drawn to by the bound framebuffer.
+type target struct {
+	width   int
+	height  int
+	color   *record.Image
+	depth   *record.Image
+	stencil *record.Image
+
+	// opaque forces alpha to one, for a drawing buffer without alpha.
+	opaque bool
+}
+
+// Returns the images of the bound framebuffer, or of the drawing buffer
+// if no framebuffer object is bound.
+func (c *Context) target() *target {
+	fb := c.State().Framebuffer
+	if fb.Object == nil {
+		t := &target{
+			width:  c.color.Width,
+			height: c.color.Height,
+			color:  c.color,
+			opaque: !c.attrs.Alpha,
+		}
+		if c.attrs.Depth {
+			t.depth = c.depthStencil
+		}
+		if c.attrs.Stencil {
+			t.stencil = c.depthStencil
+		}
+		return t
+	}
+	t := &target{
+		color:   c.AttachedImage(fb, webgl.COLOR_ATTACHMENT0),
+		depth:   c.AttachedImage(fb, webgl.DEPTH_ATTACHMENT),
+		stencil: c.AttachedImage(fb, webgl.STENCIL_ATTACHMENT),
+	}
+	if ds := c.AttachedImage(fb, webgl.DEPTH_STENCIL_ATTACHMENT); ds != nil {
+		t.depth, t.stencil = ds, ds
+	}
+	for _, img := range []*record.Image{t.color, t.depth, t.stencil} {
+		if img != nil {
+			t.width, t.height = img.Width, img.Height
+		}
+	}
+	return t
+}
+
+// Clamps v to [0, 1].
+func clamp01(v float32) float32 {
+	switch {
+	case v < 0:
+		return 0
+	case v > 1:
+		return 1
+	}
+	return v
+}
+
+// Converts a normalized value to an unsigned integer of the given bits.
+func unorm(v float32, bits uint) uint32 {
+	return uint32(math.Round(float64(clamp01(v)) * float64(uint32(1)<<bits-1)))
+}
+
+// Converts an unsigned integer of the given bits to a normalized value.
+func fromUnorm(v uint32, bits uint) float32 {
+	return float32(v) / float32(uint32(1)<<bits-1)
+}
+
+// Returns the color of the i-th pixel of an image, as a texture lookup
+// would: missing color components read as zero and missing alpha as one.
+func loadColor(img *record.Image, i int) [4]float32 {
+	p := img.Pixels
+	switch img.Type {
+	case webgl.UNSIGNED_SHORT_4_4_4_4:
+		v := uint32(p[2*i]) | uint32(p[2*i+1])<<8
+		return [4]float32{fromUnorm(v>>12, 4), fromUnorm(v>>8&15, 4), fromUnorm(v>>4&15, 4), fromUnorm(v&15, 4)}
+	case webgl.UNSIGNED_SHORT_5_5_5_1:
+		v := uint32(p[2*i]) | uint32(p[2*i+1])<<8
+		return [4]float32{fromUnorm(v>>11, 5), fromUnorm(v>>6&31, 5), fromUnorm(v>>1&31, 5), float32(v & 1)}
+	case webgl.UNSIGNED_SHORT_5_6_5:
+		v := uint32(p[2*i]) | uint32(p[2*i+1])<<8
+		return [4]float32{fromUnorm(v>>11, 5), fromUnorm(v>>5&63, 6), fromUnorm(v&31, 5), 1}
+	}
+	switch img.Format {
+	case webgl.ALPHA:
+		return [4]float32{0, 0, 0, fromUnorm(uint32(p[i]), 8)}
+	case webgl.LUMINANCE:
+		l := fromUnorm(uint32(p[i]), 8)
+		return [4]float32{l, l, l, 1}
+	case webgl.LUMINANCE_ALPHA:
+		l := fromUnorm(uint32(p[2*i]), 8)
+		return [4]float32{l, l, l, fromUnorm(uint32(p[2*i+1]), 8)}
+	case webgl.RGB:
+		p = p[3*i:]
+		return [4]float32{fromUnorm(uint32(p[0]), 8), fromUnorm(uint32(p[1]), 8), fromUnorm(uint32(p[2]), 8), 1}
+	}
+	p = p[4*i:]
+	return [4]float32{fromUnorm(uint32(p[0]), 8), fromUnorm(uint32(p[1]), 8), fromUnorm(uint32(p[2]), 8), fromUnorm(uint32(p[3]), 8)}
+}
+
+// Stores a color in the i-th pixel of an image, dropping the components
+// its format does not have.
+func storeColor(img *record.Image, i int, c [4]float32) {
+	p := img.Pixels
+	var v uint32
+	switch img.Type {
+	case webgl.UNSIGNED_SHORT_4_4_4_4:
+		v = unorm(c[0], 4)<<12 | unorm(c[1], 4)<<8 | unorm(c[2], 4)<<4 | unorm(c[3], 4)
+	case webgl.UNSIGNED_SHORT_5_5_5_1:
+		v = unorm(c[0], 5)<<11 | unorm(c[1], 5)<<6 | unorm(c[2], 5)<<1 | unorm(c[3], 1)
+	case webgl.UNSIGNED_SHORT_5_6_5:
+		v = unorm(c[0], 5)<<11 | unorm(c[1], 6)<<5 | unorm(c[2], 5)
+	default:
+		switch img.Format {
+		case webgl.ALPHA:
+			p[i] = byte(unorm(c[3], 8))
+		case webgl.LUMINANCE:
+			p[i] = byte(unorm(c[0], 8))
+		case webgl.LUMINANCE_ALPHA:
+			p[2*i], p[2*i+1] = byte(unorm(c[0], 8)), byte(unorm(c[3], 8))
+		case webgl.RGB:
+			p = p[3*i:]
+			p[0], p[1], p[2] = byte(unorm(c[0], 8)), byte(unorm(c[1], 8)), byte(unorm(c[2], 8))
+		default:
+			p = p[4*i:]
+			p[0], p[1], p[2], p[3] = byte(unorm(c[0], 8)), byte(unorm(c[1], 8)), byte(unorm(c[2], 8)), byte(unorm(c[3], 8))
+		}
+		return
+	}
+	p[2*i], p[2*i+1] = byte(v), byte(v>>8)
+}
+
+// Returns the number of bits of a depth buffer.
+func depthBits(img *record.Image) uint {
+	if img.Format == webgl.DEPTH_COMPONENT16 {
+		return 16
+	}
+	return 24
+}
+
+// Converts a window depth to a fixed point depth value.
+func depthValue(z float64, bits uint) uint32 {
+	return uint32(math.Round(math.Min(math.Max(z, 0), 1) * float64(uint32(1)<<bits-1)))
+}
+
+// Returns the depth value of the i-th pixel of a depth buffer as a fixed
+// point number.
+func loadDepth(img *record.Image, i int) uint32 {
+	p := img.Pixels
+	if img.Format == webgl.DEPTH_COMPONENT16 {
+		return uint32(p[2*i]) | uint32(p[2*i+1])<<8
+	}
+	// DEPTH_STENCIL stores the depth in the upper 24 bits.
+	return uint32(p[4*i+1]) | uint32(p[4*i+2])<<8 | uint32(p[4*i+3])<<16
+}
+
+// Stores a fixed point depth value in the i-th pixel of a depth buffer.
+func storeDepth(img *record.Image, i int, d uint32) {
+	p := img.Pixels
+	if img.Format == webgl.DEPTH_COMPONENT16 {
+		p[2*i], p[2*i+1] = byte(d), byte(d>>8)
+		return
+	}
+	p[4*i+1], p[4*i+2], p[4*i+3] = byte(d), byte(d>>8), byte(d>>16)
+}
+
+// Returns the offset of the stencil value of the i-th pixel of a stencil
+// buffer.
+func stencilOffset(img *record.Image, i int) int {
+	if img.Format == webgl.DEPTH_STENCIL {
+		return 4 * i
+	}
+	return i
+}
@@ -0,0 +1,738 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+// Limits of the rasterizer.
+const (
+	maxPointSize = 64
+	maxLineWidth = 8
+)
+
+// draw holds the state of a draw call.
+type draw struct {
+	ctx      *Context
+	state    *record.State
+	program  *Program
+	uniforms *Uniforms
+	target   *target
+
+	// clip is the rectangle fragments are written to, after the scissor
+	// test.
+	clip [4]int
+
+	// locations are the locations of the program attributes.
+	locations []int
+
+	vertices map[int]*vertex
+	quad     quad
+	lanes    [4]Fragment
+	depths   [4]float64
+}
+
+// vertex is a shaded vertex in clip coordinates.
+type vertex struct {
+	pos      [4]float64
+	size     float64
+	varyings []float64
+}
+
+// window is a vertex in window coordinates, with its varyings divided by
+// w for perspective correct interpolation.
+type window struct {
+	x, y, z  float64
+	invW     float64
+	varyings []float64
+}
+
+// Returns the rectangle of a target that drawing and clearing write to.
+func (c *Context) clipRect(t *target) [4]int {
+	r := [4]int{0, 0, t.width, t.height}
+	s := c.State()
+	if s.Enabled[webgl.SCISSOR_TEST] {
+		r[0], r[1] = max(r[0], s.Scissor[0]), max(r[1], s.Scissor[1])
+		r[2] = min(r[2], s.Scissor[0]+s.Scissor[2])
+		r[3] = min(r[3], s.Scissor[1]+s.Scissor[3])
+	}
+	return r
+}
+
+// Draws primitives of the given mode from a list of vertex indices, after
+// the call has been validated.
+func (c *Context) draw(mode webgl.Enum, indices []int) {
+	s := c.State()
+	p := c.linked[s.Program.Object]
+	t := c.target()
+	if p == nil || t.width == 0 || t.height == 0 {
+		return
+	}
+	d := &draw{
+		ctx:     c,
+		state:   s,
+		program: p,
+		uniforms: &Uniforms{
+			ctx:      c.Context,
+			program:  s.Program,
+			units:    s.Textures,
+			textures: make(map[textureKey]*texture),
+		},
+		target:   t,
+		clip:     c.clipRect(t),
+		vertices: make(map[int]*vertex),
+	}
+	if p.Prepare != nil {
+		p.Prepare(d.uniforms)
+	}
+	for _, a := range p.Attribs {
+		d.locations = append(d.locations, c.AttribLocation(s.Program, a.Name))
+	}
+	for i := range d.lanes {
+		d.lanes[i] = Fragment{
+			Uniforms: d.uniforms,
+			Varyings: make([]float32, p.Varyings),
+			quad:     &d.quad,
+			lane:     i,
+		}
+	}
+
+	v := func(i int) *vertex { return d.vertex(indices[i]) }
+	n := len(indices)
+	switch mode {
+	case webgl.POINTS:
+		for i := 0; i < n; i++ {
+			d.point(v(i))
+		}
+	case webgl.LINES:
+		for i := 0; i+1 < n; i += 2 {
+			d.line(v(i), v(i+1))
+		}
+	case webgl.LINE_STRIP, webgl.LINE_LOOP:
+		for i := 0; i+1 < n; i++ {
+			d.line(v(i), v(i+1))
+		}
+		if mode == webgl.LINE_LOOP && n > 2 {
+			d.line(v(n-1), v(0))
+		}
+	case webgl.TRIANGLES:
+		for i := 0; i+2 < n; i += 3 {
+			d.triangle(v(i), v(i+1), v(i+2))
+		}
+	case webgl.TRIANGLE_STRIP:
+		for i := 0; i+2 < n; i++ {
+			if i%2 == 0 {
+				d.triangle(v(i), v(i+1), v(i+2))
+			} else {
+				d.triangle(v(i+1), v(i), v(i+2))
+			}
+		}
+	case webgl.TRIANGLE_FAN:
+		for i := 1; i+1 < n; i++ {
+			d.triangle(v(0), v(i), v(i+1))
+		}
+	}
+}
+
+// Returns the shaded vertex at an index.
+func (d *draw) vertex(index int) *vertex {
+	if v, ok := d.vertices[index]; ok {
+		return v
+	}
+	in := &Vertex{
+		Uniforms:  d.uniforms,
+		Attribs:   make([][4]float32, len(d.locations)),
+		PointSize: 1,
+		Varyings:  make([]float32, d.program.Varyings),
+		program:   d.program,
+	}
+	for i, loc := range d.locations {
+		in.Attribs[i] = d.attrib(loc, index)
+	}
+	d.program.Vertex(in)
+	v := &vertex{size: float64(in.PointSize), varyings: make([]float64, len(in.Varyings))}
+	for i, x := range in.Position {
+		v.pos[i] = float64(x)
+	}
+	for i, x := range in.Varyings {
+		v.varyings[i] = float64(x)
+	}
+	d.vertices[index] = v
+	return v
+}
+
+// Returns the value of the attribute at a location for a vertex index.
+func (d *draw) attrib(loc, index int) [4]float32 {
+	if loc < 0 || loc >= len(d.state.Attribs) {
+		return [4]float32{0, 0, 0, 1}
+	}
+	a := &d.state.Attribs[loc]
+	if !a.Enabled {
+		return a.Value
+	}
+	n := typeSize(a.Type)
+	stride := a.Stride
+	if stride == 0 {
+		stride = a.Size * n
+	}
+	p := d.ctx.BufferContents(a.Buffer)[a.Offset+index*stride:]
+	v := [4]float32{0, 0, 0, 1}
+	for i := 0; i < a.Size; i++ {
+		v[i] = component(p[i*n:], a.Type, a.Normalized)
+	}
+	return v
+}
+
+// Returns the size in bytes of a vertex attribute component type.
+func typeSize(typ webgl.Enum) int {
+	switch typ {
+	case webgl.BYTE, webgl.UNSIGNED_BYTE:
+		return 1
+	case webgl.SHORT, webgl.UNSIGNED_SHORT:
+		return 2
+	}
+	return 4
+}
+
+// Converts a vertex attribute component to a float.
+func component(p []byte, typ webgl.Enum, normalized bool) float32 {
+	var v, scale float32
+	switch typ {
+	case webgl.BYTE:
+		v, scale = float32(int8(p[0])), 127
+	case webgl.UNSIGNED_BYTE:
+		v, scale = float32(p[0]), 255
+	case webgl.SHORT:
+		v, scale = float32(int16(binary.LittleEndian.Uint16(p))), 32767
+	case webgl.UNSIGNED_SHORT:
+		v, scale = float32(binary.LittleEndian.Uint16(p)), 65535
+	default:
+		return math.Float32frombits(binary.LittleEndian.Uint32(p))
+	}
+	if normalized {
+		return max(v/scale, -1)
+	}
+	return v
+}
+
+// Returns the signed distances of a clip space position to the clip
+// planes, which are negative outside the clip volume.
+func planes(p [4]float64) [7]float64 {
+	const minW = 1e-6
+	return [7]float64{
+		p[3] + p[0], p[3] - p[0],
+		p[3] + p[1], p[3] - p[1],
+		p[3] + p[2], p[3] - p[2],
+		p[3] - minW,
+	}
+}
+
+// Returns the vertex a fraction t of the way from a to b.
+func lerpVertex(a, b *vertex, t float64) *vertex {
+	v := &vertex{size: a.size, varyings: make([]float64, len(a.varyings))}
+	for i := range v.pos {
+		v.pos[i] = a.pos[i] + (b.pos[i]-a.pos[i])*t
+	}
+	for i := range v.varyings {
+		v.varyings[i] = a.varyings[i] + (b.varyings[i]-a.varyings[i])*t
+	}
+	return v
+}
+
+// Returns a vertex in window coordinates.
+func (d *draw) window(v *vertex) window {
+	vp, dr := d.state.Viewport, d.state.DepthRange
+	invW := 1 / v.pos[3]
+	w := window{
+		x:        float64(vp[0]) + (v.pos[0]*invW+1)*float64(vp[2])/2,
+		y:        float64(vp[1]) + (v.pos[1]*invW+1)*float64(vp[3])/2,
+		z:        dr[0] + (dr[1]-dr[0])*(v.pos[2]*invW+1)/2,
+		invW:     invW,
+		varyings: make([]float64, len(v.varyings)),
+	}
+	for i, x := range v.varyings {
+		w.varyings[i] = x * invW
+	}
+	return w
+}
+
+// Sets the varyings of a fragment from the perspective divided varyings
+// of vertices weighted by l, and returns 1/w at the fragment.
+func interpolate(f *Fragment, vs []*window, l []float64) float64 {
+	invW := 0.0
+	for i, v := range vs {
+		invW += l[i] * v.invW
+	}
+	for k := range f.Varyings {
+		x := 0.0
+		for i, v := range vs {
+			x += l[i] * v.varyings[k]
+		}
+		f.Varyings[k] = float32(x / invW)
+	}
+	return invW
+}
+
+// Draws a point.
+func (d *draw) point(v *vertex) {
+	for _, dist := range planes(v.pos) {
+		if dist < 0 {
+			return
+		}
+	}
+	w := d.window(v)
+	size := math.Round(min(max(v.size, 1), maxPointSize))
+	x0, y0 := w.x-size/2, w.y-size/2
+	covered := func(x, y int) bool {
+		px, py := float64(x)+0.5, float64(y)+0.5
+		return px >= x0 && px < x0+size && py >= y0 && py < y0+size
+	}
+	setup := func(f *Fragment, x, y int) float64 {
+		px, py := float64(x)+0.5, float64(y)+0.5
+		for i, x := range v.varyings {
+			f.Varyings[i] = float32(x)
+		}
+		f.Coord = [4]float32{float32(px), float32(py), float32(w.z), float32(w.invW)}
+		f.FrontFacing = true
+		f.PointCoord = [2]float32{float32(0.5 + (px-w.x)/size), float32(0.5 - (py-w.y)/size)}
+		return w.z
+	}
+	d.fill(int(math.Floor(x0)), int(math.Floor(y0)), int(math.Ceil(x0+size)), int(math.Ceil(y0+size)), covered, setup, true)
+}
+
+// Draws a line segment, leaving out its last pixel so that connected
+// segments do not overlap.
+func (d *draw) line(a, b *vertex) {
+	// Clip the segment to the clip volume.
+	t0, t1 := 0.0, 1.0
+	pa, pb := planes(a.pos), planes(b.pos)
+	for i := range pa {
+		switch {
+		case pa[i] < 0 && pb[i] < 0:
+			return
+		case pa[i] < 0:
+			t0 = max(t0, pa[i]/(pa[i]-pb[i]))
+		case pb[i] < 0:
+			t1 = min(t1, pa[i]/(pa[i]-pb[i]))
+		}
+	}
+	if t0 >= t1 {
+		return
+	}
+	if t1 < 1 {
+		b = lerpVertex(a, b, t1)
+	}
+	if t0 > 0 {
+		a = lerpVertex(a, b, t0)
+	}
+	wa, wb := d.window(a), d.window(b)
+	dx, dy := wb.x-wa.x, wb.y-wa.y
+	if dx == 0 && dy == 0 {
+		return
+	}
+	width := math.Round(min(max(d.state.LineWidth, 1), maxLineWidth))
+	xMajor := math.Abs(dx) >= math.Abs(dy)
+
+	// Returns the position of a pixel center along the line and its
+	// distance to the line along the minor axis.
+	param := func(x, y int) (t, dist float64) {
+		px, py := float64(x)+0.5, float64(y)+0.5
+		if xMajor {
+			t = (px - wa.x) / dx
+			return t, py - (wa.y + t*dy)
+		}
+		t = (py - wa.y) / dy
+		return t, px - (wa.x + t*dx)
+	}
+	covered := func(x, y int) bool {
+		t, dist := param(x, y)
+		return t >= 0 && t < 1 && dist >= -width/2 && dist < width/2
+	}
+	vs := []*window{&wa, &wb}
+	setup := func(f *Fragment, x, y int) float64 {
+		t, _ := param(x, y)
+		l := []float64{1 - t, t}
+		invW := interpolate(f, vs, l)
+		z := wa.z + (wb.z-wa.z)*t
+		f.Coord = [4]float32{float32(x) + 0.5, float32(y) + 0.5, float32(z), float32(invW)}
+		f.FrontFacing = true
+		return z
+	}
+	pad := width/2 + 1
+	d.fill(
+		int(math.Floor(min(wa.x, wb.x)-pad)), int(math.Floor(min(wa.y, wb.y)-pad)),
+		int(math.Ceil(max(wa.x, wb.x)+pad)), int(math.Ceil(max(wa.y, wb.y)+pad)),
+		covered, setup, true)
+}
+
+// Clips a polygon in clip coordinates to the clip volume.
+func clipPolygon(poly []*vertex) []*vertex {
+	for plane := 0; plane < 7 && len(poly) > 0; plane++ {
+		var out []*vertex
+		for i, a := range poly {
+			b := poly[(i+1)%len(poly)]
+			da, db := planes(a.pos)[plane], planes(b.pos)[plane]
+			if da >= 0 {
+				out = append(out, a)
+			}
+			if (da >= 0) != (db >= 0) {
+				out = append(out, lerpVertex(a, b, da/(da-db)))
+			}
+		}
+		poly = out
+	}
+	return poly
+}
+
+// Draws a triangle.
+func (d *draw) triangle(a, b, c *vertex) {
+	poly := []*vertex{a, b, c}
+	for _, v := range poly {
+		for _, dist := range planes(v.pos) {
+			if dist < 0 {
+				poly = clipPolygon(poly)
+				goto clipped
+			}
+		}
+	}
+clipped:
+	if len(poly) < 3 {
+		return
+	}
+	ws := make([]window, len(poly))
+	for i, v := range poly {
+		ws[i] = d.window(v)
+	}
+
+	// The signed area of the clipped polygon gives the facing of the
+	// triangle.
+	area := 0.0
+	for i := range ws {
+		j := (i + 1) % len(ws)
+		area += ws[i].x*ws[j].y - ws[j].x*ws[i].y
+	}
+	if area == 0 {
+		return
+	}
+	front := (area > 0) == (d.state.FrontFace == webgl.CCW)
+	if d.state.Enabled[webgl.CULL_FACE] {
+		switch d.state.CullFace {
+		case webgl.FRONT_AND_BACK:
+			return
+		case webgl.FRONT:
+			if front {
+				return
+			}
+		case webgl.BACK:
+			if !front {
+				return
+			}
+		}
+	}
+	for i := 1; i+1 < len(ws); i++ {
+		d.fillTriangle(&ws[0], &ws[i], &ws[i+1], front)
+	}
+}
+
+// Returns the edge function of the directed edge from a to b at a point,
+// which is positive to its left.
+func edge(a, b *window, x, y float64) float64 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// Returns whether a point on the directed edge from a to b of a counter
+// clockwise triangle belongs to the triangle, following the top-left
+// rule.
+func topLeft(a, b *window) bool {
+	dx, dy := b.x-a.x, b.y-a.y
+	return dy < 0 || dy == 0 && dx < 0
+}
+
+// Rasterizes a triangle in window coordinates.
+func (d *draw) fillTriangle(a, b, c *window, front bool) {
+	area := edge(a, b, c.x, c.y)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		b, c = c, b
+		area = -area
+	}
+	vs := []*window{a, b, c}
+
+	// Polygon offset is scaled by the maximum depth slope and the depth
+	// buffer resolution.
+	offset := 0.0
+	if d.state.Enabled[webgl.POLYGON_OFFSET_FILL] {
+		dzdx := ((b.z-a.z)*(c.y-a.y) - (c.z-a.z)*(b.y-a.y)) / area
+		dzdy := ((c.z-a.z)*(b.x-a.x) - (b.z-a.z)*(c.x-a.x)) / area
+		bits := uint(24)
+		if d.target.depth != nil {
+			bits = depthBits(d.target.depth)
+		}
+		offset = d.state.PolygonOffsetFactor*max(math.Abs(dzdx), math.Abs(dzdy)) +
+			d.state.PolygonOffsetUnits/float64(uint32(1)<<bits)
+	}
+
+	inside := func(e float64, a, b *window) bool {
+		return e > 0 || e == 0 && topLeft(a, b)
+	}
+	covered := func(x, y int) bool {
+		px, py := float64(x)+0.5, float64(y)+0.5
+		return inside(edge(b, c, px, py), b, c) && inside(edge(c, a, px, py), c, a) && inside(edge(a, b, px, py), a, b)
+	}
+	var l [3]float64
+	setup := func(f *Fragment, x, y int) float64 {
+		px, py := float64(x)+0.5, float64(y)+0.5
+		l[0], l[1] = edge(b, c, px, py)/area, edge(c, a, px, py)/area
+		l[2] = 1 - l[0] - l[1]
+		invW := interpolate(f, vs, l[:])
+		z := l[0]*a.z + l[1]*b.z + l[2]*c.z
+		f.Coord = [4]float32{float32(px), float32(py), float32(z), float32(invW)}
+		f.FrontFacing = front
+		return z + offset
+	}
+	d.fill(
+		int(math.Floor(min(a.x, b.x, c.x))), int(math.Floor(min(a.y, b.y, c.y))),
+		int(math.Ceil(max(a.x, b.x, c.x))), int(math.Ceil(max(a.y, b.y, c.y))),
+		covered, setup, front)
+}
+
+// Shades and writes the pixels of a bounding box covered by a primitive,
+// in 2x2 quads. setup sets the inputs of a fragment at any pixel and
+// returns its depth.
+func (d *draw) fill(x0, y0, x1, y1 int, covered func(x, y int) bool, setup func(f *Fragment, x, y int) float64, front bool) {
+	x0, y0 = max(x0, d.clip[0]), max(y0, d.clip[1])
+	x1, y1 = min(x1, d.clip[2]), min(y1, d.clip[3])
+	for qy := y0 &^ 1; qy < y1; qy += 2 {
+		for qx := x0 &^ 1; qx < x1; qx += 2 {
+			mask := 0
+			for lane := 0; lane < 4; lane++ {
+				x, y := qx+lane&1, qy+lane>>1
+				if x >= x0 && x < x1 && y >= y0 && y < y1 && covered(x, y) {
+					mask |= 1 << lane
+				}
+			}
+			if mask != 0 {
+				d.shade(qx, qy, mask, setup, front)
+			}
+		}
+	}
+}
+
+// Runs the fragment shader for a quad and writes the fragments of the
+// covered pixels in mask.
+func (d *draw) shade(qx, qy, mask int, setup func(f *Fragment, x, y int) float64, front bool) {
+	var keep [4]bool
+	run := func(probe bool) {
+		d.quad.probe = probe
+		for lane := range d.lanes {
+			f := &d.lanes[lane]
+			f.Color, f.calls = [4]float32{}, 0
+			d.depths[lane] = setup(f, qx+lane&1, qy+lane>>1)
+			keep[lane] = d.program.Fragment(f)
+		}
+	}
+	for lane := range d.quad.log {
+		d.quad.log[lane] = d.quad.log[lane][:0]
+	}
+	run(true)
+	for _, log := range d.quad.log {
+		if len(log) > 0 {
+			run(false)
+			break
+		}
+	}
+	for lane := range d.lanes {
+		if mask&(1<<lane) != 0 && keep[lane] {
+			d.write(qx+lane&1, qy+lane>>1, d.depths[lane], d.lanes[lane].Color, front)
+		}
+	}
+}
+
+// Returns the result of a depth or stencil comparison of an incoming
+// value with a stored one.
+func compare(fun webgl.Enum, in, stored uint32) bool {
+	switch fun {
+	case webgl.NEVER:
+		return false
+	case webgl.LESS:
+		return in < stored
+	case webgl.EQUAL:
+		return in == stored
+	case webgl.LEQUAL:
+		return in <= stored
+	case webgl.GREATER:
+		return in > stored
+	case webgl.NOTEQUAL:
+		return in != stored
+	case webgl.GEQUAL:
+		return in >= stored
+	}
+	return true
+}
+
+// Applies a stencil operation to the stencil value at offset i of a
+// stencil buffer.
+func applyStencil(img *record.Image, i int, op webgl.Enum, s *record.Stencil) {
+	v := uint32(img.Pixels[i])
+	ref := uint32(min(max(s.Ref, 0), 255))
+	var n uint32
+	switch op {
+	case webgl.KEEP:
+		return
+	case webgl.ZERO:
+		n = 0
+	case webgl.REPLACE:
+		n = ref
+	case webgl.INCR:
+		n = min(v+1, 255)
+	case webgl.DECR:
+		n = v - min(v, 1)
+	case webgl.INVERT:
+		n = ^v
+	case webgl.INCR_WRAP:
+		n = v + 1
+	case webgl.DECR_WRAP:
+		n = v - 1
+	}
+	img.Pixels[i] = byte(v&^s.WriteMask | n&s.WriteMask)
+}
+
+// Runs the per-fragment operations for a shaded fragment and writes it
+// to the target.
+func (d *draw) write(x, y int, z float64, color [4]float32, front bool) {
+	s, t := d.state, d.target
+	i := y*t.width + x
+
+	stencil := s.Enabled[webgl.STENCIL_TEST] && t.stencil != nil
+	face := &s.StencilFront
+	if !front {
+		face = &s.StencilBack
+	}
+	var si int
+	if stencil {
+		si = stencilOffset(t.stencil, i)
+		ref := uint32(min(max(face.Ref, 0), 255))
+		if !compare(face.Func, ref&face.ValueMask, uint32(t.stencil.Pixels[si])&face.ValueMask) {
+			applyStencil(t.stencil, si, face.Fail, face)
+			return
+		}
+	}
+	if s.Enabled[webgl.DEPTH_TEST] && t.depth != nil {
+		depth := depthValue(z, depthBits(t.depth))
+		if !compare(s.DepthFunc, depth, loadDepth(t.depth, i)) {
+			if stencil {
+				applyStencil(t.stencil, si, face.ZFail, face)
+			}
+			return
+		}
+		if s.DepthMask {
+			storeDepth(t.depth, i, depth)
+		}
+	}
+	if stencil {
+		applyStencil(t.stencil, si, face.ZPass, face)
+	}
+	if t.color == nil {
+		return
+	}
+	for k := range color {
+		color[k] = clamp01(color[k])
+	}
+	dst := loadColor(t.color, i)
+	if s.Enabled[webgl.BLEND] {
+		color = d.blend(color, dst)
+	}
+	d.ctx.storeMasked(t, i, color, dst)
+}
+
+// Stores a color in a target pixel holding dst, honoring the color mask.
+func (c *Context) storeMasked(t *target, i int, color, dst [4]float32) {
+	mask := c.State().ColorMask
+	for k := range color {
+		if !mask[k] {
+			color[k] = dst[k]
+		}
+	}
+	if t.opaque {
+		color[3] = 1
+	}
+	storeColor(t.color, i, color)
+}
+
+// Returns the blend factor for a source color and destination color.
+func (d *draw) factor(f webgl.Enum, src, dst [4]float32) [4]float32 {
+	one := [4]float32{1, 1, 1, 1}
+	inv := func(c [4]float32) [4]float32 {
+		for i := range c {
+			c[i] = 1 - c[i]
+		}
+		return c
+	}
+	splat := func(v float32) [4]float32 { return [4]float32{v, v, v, v} }
+	k := d.state.BlendColor
+	switch f {
+	case webgl.ZERO:
+		return [4]float32{}
+	case webgl.SRC_COLOR:
+		return src
+	case webgl.ONE_MINUS_SRC_COLOR:
+		return inv(src)
+	case webgl.DST_COLOR:
+		return dst
+	case webgl.ONE_MINUS_DST_COLOR:
+		return inv(dst)
+	case webgl.SRC_ALPHA:
+		return splat(src[3])
+	case webgl.ONE_MINUS_SRC_ALPHA:
+		return splat(1 - src[3])
+	case webgl.DST_ALPHA:
+		return splat(dst[3])
+	case webgl.ONE_MINUS_DST_ALPHA:
+		return splat(1 - dst[3])
+	case webgl.CONSTANT_COLOR:
+		return k
+	case webgl.ONE_MINUS_CONSTANT_COLOR:
+		return inv(k)
+	case webgl.CONSTANT_ALPHA:
+		return splat(k[3])
+	case webgl.ONE_MINUS_CONSTANT_ALPHA:
+		return splat(1 - k[3])
+	case webgl.SRC_ALPHA_SATURATE:
+		v := min(src[3], 1-dst[3])
+		return [4]float32{v, v, v, 1}
+	}
+	return one
+}
+
+// Blends a source color with a destination color.
+func (d *draw) blend(src, dst [4]float32) [4]float32 {
+	s := d.state
+	srcRGB, dstRGB := d.factor(s.BlendSrcRGB, src, dst), d.factor(s.BlendDstRGB, src, dst)
+	srcA, dstA := d.factor(s.BlendSrcAlpha, src, dst), d.factor(s.BlendDstAlpha, src, dst)
+	var out [4]float32
+	for i := range out {
+		sf, df, eq := srcRGB[i], dstRGB[i], s.BlendEquationRGB
+		if i == 3 {
+			sf, df, eq = srcA[i], dstA[i], s.BlendEquationAlpha
+		}
+		switch eq {
+		case webgl.FUNC_SUBTRACT:
+			out[i] = src[i]*sf - dst[i]*df
+		case webgl.FUNC_REVERSE_SUBTRACT:
+			out[i] = dst[i]*df - src[i]*sf
+		default:
+			out[i] = src[i]*sf + dst[i]*df
+		}
+		out[i] = clamp01(out[i])
+	}
+	return out
+}
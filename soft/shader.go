@@ -0,0 +1,364 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+// Program holds the shaders of a program as Go functions.
+type Program struct {
+	// Attribs and Uniforms are the active attributes and uniforms of the
+	// program, as returned by GetActiveAttrib and GetActiveUniform. The
+	// names of uniform arrays end in "[0]".
+	Attribs  []webgl.ActiveInfo
+	Uniforms []webgl.ActiveInfo
+
+	// Varyings is the number of floats passed from Vertex to Fragment.
+	Varyings int
+
+	// Prepare, if set, is called once at the start of each draw call,
+	// before the shaders.
+	Prepare func(u *Uniforms)
+
+	// Vertex is the vertex shader. It is called once for each vertex
+	// used by a draw call and must set Position.
+	Vertex func(v *Vertex)
+
+	// Fragment is the fragment shader. It sets Color and returns false
+	// to discard the fragment. It may be called more than once for the
+	// same fragment, and for helper fragments next to a primitive that
+	// are used to compute texture coordinate derivatives.
+	Fragment func(f *Fragment) bool
+}
+
+// Vertex holds the inputs and outputs of a vertex shader invocation.
+type Vertex struct {
+	// Uniforms holds the uniform values of the program.
+	*Uniforms
+
+	// Attribs are the attribute values, in the order of Program.Attribs.
+	Attribs [][4]float32
+
+	// Position and PointSize are gl_Position and gl_PointSize.
+	Position  [4]float32
+	PointSize float32
+
+	// Varyings are passed to the fragment shader, interpolated across
+	// the primitive.
+	Varyings []float32
+
+	program *Program
+}
+
+// Returns the value of the named attribute, or (0, 0, 0, 1) if the
+// program has no such attribute.
+func (v *Vertex) Attrib(name string) [4]float32 {
+	for i, a := range v.program.Attribs {
+		if a.Name == name {
+			return v.Attribs[i]
+		}
+	}
+	return [4]float32{0, 0, 0, 1}
+}
+
+// Fragment holds the inputs and outputs of a fragment shader invocation.
+type Fragment struct {
+	// Uniforms holds the uniform values of the program.
+	*Uniforms
+
+	// Coord, FrontFacing and PointCoord are gl_FragCoord, gl_FrontFacing
+	// and gl_PointCoord.
+	Coord       [4]float32
+	FrontFacing bool
+	PointCoord  [2]float32
+
+	// Varyings are the interpolated outputs of the vertex shader.
+	Varyings []float32
+
+	// Color is gl_FragColor.
+	Color [4]float32
+
+	quad  *quad
+	lane  int
+	calls int
+}
+
+// quad runs the fragment shader for a 2x2 block of pixels. Texture
+// lookups need the screen space derivatives of their coordinates to
+// select a mipmap level, so if the shader samples textures it runs twice:
+// a probe pass logs the coordinates of each lookup and the final pass
+// takes the differences between neighboring pixels.
+type quad struct {
+	probe bool
+	log   [4][][3]float32
+}
+
+// Returns the derivatives of the coordinates of the k-th texture lookup
+// made by a lane.
+func (q *quad) derivatives(lane, k int) (dx, dy [3]float32) {
+	diff := func(a, b int) (d [3]float32) {
+		if k < len(q.log[a]) && k < len(q.log[b]) {
+			for i := range d {
+				d[i] = q.log[b][k][i] - q.log[a][k][i]
+			}
+		}
+		return d
+	}
+	x, y := lane&1, lane&2
+	return diff(y, y|1), diff(x, x|2)
+}
+
+// Logs the coordinates of a texture lookup in the probe pass, or returns
+// their derivatives in the final pass.
+func (f *Fragment) lookup(coord [3]float32) (dx, dy [3]float32, probe bool) {
+	k := f.calls
+	f.calls++
+	if f.quad.probe {
+		f.quad.log[f.lane] = append(f.quad.log[f.lane], coord)
+		return dx, dy, true
+	}
+	dx, dy = f.quad.derivatives(f.lane, k)
+	return dx, dy, false
+}
+
+// Samples the 2D texture of a sampler uniform, as texture2D does.
+func (f *Fragment) Texture2D(sampler string, s, t float32) [4]float32 {
+	return f.Texture2DBias(sampler, s, t, 0)
+}
+
+// Samples the 2D texture of a sampler uniform with a level of detail
+// bias.
+func (f *Fragment) Texture2DBias(sampler string, s, t, bias float32) [4]float32 {
+	return f.texture2D(f.texture(sampler, webgl.TEXTURE_2D), s, t, bias)
+}
+
+// Samples a 2D texture, which may be nil, with a level of detail bias.
+func (f *Fragment) texture2D(tex *texture, s, t, bias float32) [4]float32 {
+	dx, dy, probe := f.lookup([3]float32{s, t, 0})
+	if tex == nil || probe {
+		return [4]float32{0, 0, 0, 1}
+	}
+	return tex.sample2D(float64(s), float64(t), tex.lambda2D(dx, dy)+float64(bias))
+}
+
+// Samples the cube map texture of a sampler uniform in a direction, as
+// textureCube does.
+func (f *Fragment) TextureCube(sampler string, x, y, z float32) [4]float32 {
+	return f.TextureCubeBias(sampler, x, y, z, 0)
+}
+
+// Samples the cube map texture of a sampler uniform with a level of
+// detail bias.
+func (f *Fragment) TextureCubeBias(sampler string, x, y, z, bias float32) [4]float32 {
+	return f.textureCube(f.texture(sampler, webgl.TEXTURE_CUBE_MAP), x, y, z, bias)
+}
+
+// Samples a cube map texture, which may be nil, with a level of detail
+// bias.
+func (f *Fragment) textureCube(tex *texture, x, y, z, bias float32) [4]float32 {
+	dx, dy, probe := f.lookup([3]float32{x, y, z})
+	if tex == nil || probe {
+		return [4]float32{0, 0, 0, 1}
+	}
+	return tex.sampleCube([3]float32{x, y, z}, dx, dy, float64(bias))
+}
+
+// Uniforms gives shaders access to the uniform values and textures of a
+// program. Uniforms that have not been set read as zero.
+type Uniforms struct {
+	ctx      *record.Context
+	program  webgl.Program
+	units    []record.TextureUnit
+	textures map[textureKey]*texture
+}
+
+// textureKey identifies a sampled texture by its texture unit and target.
+type textureKey struct {
+	unit   int
+	target webgl.Enum
+}
+
+// Returns the value of a uniform, looking up elements of arrays such as
+// "u_weights[2]" in the value set for the array.
+func (u *Uniforms) value(name string, n int) interface{} {
+	if v := u.ctx.Uniform(u.program, name); v != nil {
+		return v
+	}
+	i := strings.IndexByte(name, '[')
+	if i < 0 {
+		return nil
+	}
+	var index int
+	if _, err := fmt.Sscanf(name[i:], "[%d]", &index); err != nil {
+		return nil
+	}
+	switch v := u.ctx.Uniform(u.program, name[:i]).(type) {
+	case []float32:
+		if (index+1)*n <= len(v) {
+			return v[index*n:]
+		}
+	case []int32:
+		if (index+1)*n <= len(v) {
+			return v[index*n:]
+		}
+	}
+	return nil
+}
+
+// Copies the values of a uniform into dst, converting integers and
+// booleans to floats.
+func (u *Uniforms) floats(name string, dst []float32) {
+	switch v := u.value(name, len(dst)).(type) {
+	case []float32:
+		copy(dst, v)
+	case []int32:
+		for i := 0; i < len(dst) && i < len(v); i++ {
+			dst[i] = float32(v[i])
+		}
+	}
+}
+
+// Copies the values of an int, bool or sampler uniform into dst.
+func (u *Uniforms) ints(name string, dst []int) {
+	switch v := u.value(name, len(dst)).(type) {
+	case []int32:
+		for i := 0; i < len(dst) && i < len(v); i++ {
+			dst[i] = int(v[i])
+		}
+	case []float32:
+		for i := 0; i < len(dst) && i < len(v); i++ {
+			dst[i] = int(v[i])
+		}
+	}
+}
+
+// Returns the value of a float uniform.
+func (u *Uniforms) Float(name string) float32 {
+	var v [1]float32
+	u.floats(name, v[:])
+	return v[0]
+}
+
+// Returns the value of a vec2 uniform.
+func (u *Uniforms) Vec2(name string) (v [2]float32) {
+	u.floats(name, v[:])
+	return v
+}
+
+// Returns the value of a vec3 uniform.
+func (u *Uniforms) Vec3(name string) (v [3]float32) {
+	u.floats(name, v[:])
+	return v
+}
+
+// Returns the value of a vec4 uniform.
+func (u *Uniforms) Vec4(name string) (v [4]float32) {
+	u.floats(name, v[:])
+	return v
+}
+
+// Returns the value of a mat2 uniform in column major order.
+func (u *Uniforms) Mat2(name string) (m [4]float32) {
+	u.floats(name, m[:])
+	return m
+}
+
+// Returns the value of a mat3 uniform in column major order.
+func (u *Uniforms) Mat3(name string) (m [9]float32) {
+	u.floats(name, m[:])
+	return m
+}
+
+// Returns the value of a mat4 uniform in column major order.
+func (u *Uniforms) Mat4(name string) (m [16]float32) {
+	u.floats(name, m[:])
+	return m
+}
+
+// Returns the value of an int or sampler uniform.
+func (u *Uniforms) Int(name string) int {
+	var v [1]int
+	u.ints(name, v[:])
+	return v[0]
+}
+
+// Returns the value of a bool uniform.
+func (u *Uniforms) Bool(name string) bool {
+	return u.Int(name) != 0
+}
+
+// Returns the values of an int vector or bool vector uniform with n
+// components.
+func (u *Uniforms) Ints(name string, n int) []int {
+	v := make([]int, n)
+	u.ints(name, v)
+	return v
+}
+
+// Returns the values of a float, vector or matrix uniform with n
+// components, such as a whole array.
+func (u *Uniforms) Floats(name string, n int) []float32 {
+	v := make([]float32, n)
+	u.floats(name, v)
+	return v
+}
+
+// Samples a level of detail of the 2D texture of a sampler uniform, as
+// texture2DLod does.
+func (u *Uniforms) Texture2DLod(sampler string, s, t, lod float32) [4]float32 {
+	return texture2DLod(u.texture(sampler, webgl.TEXTURE_2D), s, t, lod)
+}
+
+// Samples a level of detail of a 2D texture, which may be nil.
+func texture2DLod(tex *texture, s, t, lod float32) [4]float32 {
+	if tex == nil {
+		return [4]float32{0, 0, 0, 1}
+	}
+	return tex.sample2D(float64(s), float64(t), float64(lod))
+}
+
+// Samples a level of detail of the cube map texture of a sampler uniform,
+// as textureCubeLod does.
+func (u *Uniforms) TextureCubeLod(sampler string, x, y, z, lod float32) [4]float32 {
+	return textureCubeLod(u.texture(sampler, webgl.TEXTURE_CUBE_MAP), x, y, z, lod)
+}
+
+// Samples a level of detail of a cube map texture, which may be nil.
+func textureCubeLod(tex *texture, x, y, z, lod float32) [4]float32 {
+	if tex == nil {
+		return [4]float32{0, 0, 0, 1}
+	}
+	return tex.sampleFace(cubeFace([3]float32{x, y, z}), float64(lod))
+}
+
+// Returns the complete texture bound for a sampler uniform, or nil if
+// the texture is missing or incomplete.
+func (u *Uniforms) texture(sampler string, target webgl.Enum) *texture {
+	return u.unitTexture(u.Int(sampler), target)
+}
+
+// Returns the complete texture bound to a texture unit and target, or nil
+// if the texture is missing or incomplete.
+func (u *Uniforms) unitTexture(unit int, target webgl.Enum) *texture {
+	if unit < 0 || unit >= len(u.units) {
+		return nil
+	}
+	key := textureKey{unit, target}
+	if tex, ok := u.textures[key]; ok {
+		return tex
+	}
+	handle := u.units[unit].Texture2D
+	if target == webgl.TEXTURE_CUBE_MAP {
+		handle = u.units[unit].TextureCubeMap
+	}
+	tex := newTexture(u.ctx, handle, target)
+	u.textures[key] = tex
+	return tex
+}
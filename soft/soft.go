@@ -0,0 +1,300 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package soft implements webgl.RenderingContext with a software
+// rasterizer, so reference images can be rendered and read back with
+// ReadPixelsBytes without a browser or a GPU.
+//
+// A Context is a record.Context that also draws: the calls are validated,
+// logged and tracked as package record does, and those that succeed are
+// rendered to the drawing buffer or the bound framebuffer. It supports
+// buffers, textures with NEAREST and LINEAR filtering and mipmaps, cube
+// maps, renderbuffers, framebuffers, the depth, stencil, blend, scissor,
+// cull and polygon offset state, and every primitive mode of DrawArrays
+// and DrawElements. Rendering is not antialiased.
+//
+// Shader sources are compiled as GLSL ES 1.00 by package glsl, so
+// CompileShader and LinkProgram fail with the info logs WebGL would give,
+// and a linked program is interpreted when it draws. Shaders can also be
+// written in Go: a Program holding the vertex and fragment shader
+// functions is registered for a program object and used instead of the
+// sources from its next LinkProgram:
+//
+//	gl := soft.New(64, 64, nil)
+//	prog := gl.NewProgram(&soft.Program{
+//		Attribs: []webgl.ActiveInfo{{Name: "a_pos", Type: webgl.FLOAT_VEC2, Size: 1}},
+//		Vertex: func(v *soft.Vertex) {
+//			p := v.Attribs[0]
+//			v.Position = [4]float32{p[0], p[1], 0, 1}
+//		},
+//		Fragment: func(f *soft.Fragment) bool {
+//			f.Color = [4]float32{1, 0, 0, 1}
+//			return true
+//		},
+//	})
+//	gl.UseProgram(prog)
+package soft
+
+import (
+	"errors"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+// Context is a webgl.RenderingContext that renders in software. The
+// Compile and Link hooks of the embedded record.Context are used by the
+// Context and must not be changed.
+type Context struct {
+	*record.Context
+
+	attrs webgl.ContextAttributes
+
+	// color and depthStencil are the images of the drawing buffer.
+	color        *record.Image
+	depthStencil *record.Image
+
+	// programs are the registered programs, and linked those used by the
+	// last successful link of each program object.
+	programs map[webgl.Object]*Program
+	linked   map[webgl.Object]*Program
+}
+
+var _ webgl.RenderingContext = (*Context)(nil)
+
+// Creates a context with a drawing buffer of the given size and context
+// attributes. If attrs is nil the WebGL defaults are used. The drawing
+// buffer is cleared to transparent black, a depth of 1 and a stencil
+// value of 0.
+func New(width, height int, attrs *webgl.ContextAttributes) *Context {
+	if attrs == nil {
+		attrs = webgl.DefaultAttributes()
+	}
+	c := &Context{
+		Context:  record.New(width, height, attrs),
+		attrs:    *attrs,
+		programs: make(map[webgl.Object]*Program),
+		linked:   make(map[webgl.Object]*Program),
+	}
+	c.Context.Compile = compile
+	c.Context.Link = c.link
+	c.color = &record.Image{
+		Width:  width,
+		Height: height,
+		Format: webgl.RGBA,
+		Type:   webgl.UNSIGNED_BYTE,
+		Pixels: make([]byte, width*height*4),
+	}
+	if !attrs.Alpha {
+		for i := 3; i < len(c.color.Pixels); i += 4 {
+			c.color.Pixels[i] = 0xFF
+		}
+	}
+	if attrs.Depth || attrs.Stencil {
+		c.depthStencil = &record.Image{
+			Width:  width,
+			Height: height,
+			Format: webgl.DEPTH_STENCIL,
+			Type:   webgl.UNSIGNED_INT_24_8,
+			Pixels: make([]byte, width*height*4),
+		}
+		for i := 0; i < width*height; i++ {
+			storeDepth(c.depthStencil, i, 1<<24-1)
+		}
+	}
+	return c
+}
+
+// Registers the Go shaders of a program object. They are used from the
+// next call to LinkProgram for the program.
+func (c *Context) Register(program webgl.Program, p *Program) {
+	c.programs[program.Object] = p
+}
+
+// Creates a program object with placeholder shaders, registers p for it
+// and links it.
+func (c *Context) NewProgram(p *Program) webgl.Program {
+	program := c.CreateProgram()
+	for _, typ := range []webgl.Enum{webgl.VERTEX_SHADER, webgl.FRAGMENT_SHADER} {
+		s := c.CreateShader(typ)
+		c.ShaderSource(s, "void main() {}")
+		c.CompileShader(s)
+		c.AttachShader(program, s)
+		c.DeleteShader(s)
+	}
+	c.Register(program, p)
+	c.LinkProgram(program)
+	return program
+}
+
+// Links a program object with its registered shaders, or with its shader
+// sources if none are registered.
+func (c *Context) link(program webgl.Program, vs, fs string) (attribs, uniforms []webgl.ActiveInfo, err error) {
+	p := c.programs[program.Object]
+	if p == nil {
+		if p, err = linkGLSL(vs, fs); err != nil {
+			return nil, nil, err
+		}
+	}
+	if p.Vertex == nil || p.Fragment == nil {
+		return nil, nil, errors.New("ERROR: the program needs a vertex and a fragment shader function")
+	}
+	c.linked[program.Object] = p
+	return p.Attribs, p.Uniforms, nil
+}
+
+// Returns whether the last call succeeded.
+func (c *Context) ok() bool {
+	n := len(c.Calls)
+	return n > 0 && c.Calls[n-1].Err == webgl.NO_ERROR
+}
+
+func (c *Context) Clear(mask webgl.Enum) {
+	c.Context.Clear(mask)
+	if !c.ok() {
+		return
+	}
+	s, t := c.State(), c.target()
+	r := c.clipRect(t)
+	var color [4]float32
+	for i := range color {
+		color[i] = clamp01(s.ClearColor[i])
+	}
+	var depth uint32
+	if t.depth != nil {
+		depth = depthValue(s.ClearDepth, depthBits(t.depth))
+	}
+	stencil := byte(s.ClearStencil)
+	writeMask := byte(s.StencilFront.WriteMask)
+	for y := r[1]; y < r[3]; y++ {
+		for x := r[0]; x < r[2]; x++ {
+			i := y*t.width + x
+			if mask&webgl.COLOR_BUFFER_BIT != 0 && t.color != nil {
+				c.storeMasked(t, i, color, loadColor(t.color, i))
+			}
+			if mask&webgl.DEPTH_BUFFER_BIT != 0 && t.depth != nil && s.DepthMask {
+				storeDepth(t.depth, i, depth)
+			}
+			if mask&webgl.STENCIL_BUFFER_BIT != 0 && t.stencil != nil {
+				p := &t.stencil.Pixels[stencilOffset(t.stencil, i)]
+				*p = *p&^writeMask | stencil&writeMask
+			}
+		}
+	}
+}
+
+func (c *Context) DrawArrays(mode webgl.Enum, first, count int) {
+	c.Context.DrawArrays(mode, first, count)
+	if !c.ok() {
+		return
+	}
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = first + i
+	}
+	c.draw(mode, indices)
+}
+
+func (c *Context) DrawElements(mode webgl.Enum, count int, typ webgl.Enum, offset int) {
+	c.Context.DrawElements(mode, count, typ, offset)
+	if !c.ok() {
+		return
+	}
+	data := c.BufferContents(c.State().ElementArrayBuffer)[offset:]
+	indices := make([]int, count)
+	for i := range indices {
+		if typ == webgl.UNSIGNED_BYTE {
+			indices[i] = int(data[i])
+		} else {
+			indices[i] = int(data[2*i]) | int(data[2*i+1])<<8
+		}
+	}
+	c.draw(mode, indices)
+}
+
+// Returns the color of a pixel of the read framebuffer, or zero outside
+// of it.
+func (c *Context) readColor(t *target, x, y int) [4]float32 {
+	if t.color == nil || x < 0 || y < 0 || x >= t.width || y >= t.height {
+		return [4]float32{}
+	}
+	return loadColor(t.color, y*t.width+x)
+}
+
+func (c *Context) ReadPixelsBytes(x, y, width, height int, format, typ webgl.Enum, pixels []byte) {
+	c.Context.ReadPixelsBytes(x, y, width, height, format, typ, pixels)
+	if !c.ok() {
+		return
+	}
+	t := c.target()
+	align := c.State().PixelStore[webgl.PACK_ALIGNMENT]
+	row := (width*4 + align - 1) / align * align
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			color := c.readColor(t, x+i, y+j)
+			p := pixels[j*row+i*4:]
+			for k := range color {
+				p[k] = byte(unorm(color[k], 8))
+			}
+		}
+	}
+}
+
+// Returns the texture bound to the active texture unit for a target,
+// which may be a cube map face.
+func (c *Context) boundTexture(target webgl.Enum) webgl.Texture {
+	s := c.State()
+	unit := s.Textures[s.ActiveTexture-webgl.TEXTURE0]
+	if target == webgl.TEXTURE_2D {
+		return unit.Texture2D
+	}
+	return unit.TextureCubeMap
+}
+
+// Copies a rectangle of the read framebuffer into a texture image.
+func (c *Context) copyTexture(img *record.Image, xoffset, yoffset, x, y, w, h int) {
+	t := c.target()
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			storeColor(img, (yoffset+j)*img.Width+xoffset+i, c.readColor(t, x+i, y+j))
+		}
+	}
+}
+
+func (c *Context) CopyTexImage2D(target webgl.Enum, level int, internal webgl.Enum, x, y, w, h, border int) {
+	c.Context.CopyTexImage2D(target, level, internal, x, y, w, h, border)
+	if c.ok() {
+		c.copyTexture(c.TextureImage(c.boundTexture(target), target, level), 0, 0, x, y, w, h)
+	}
+}
+
+func (c *Context) CopyTexSubImage2D(target webgl.Enum, level, xoffset, yoffset, x, y, w, h int) {
+	c.Context.CopyTexSubImage2D(target, level, xoffset, yoffset, x, y, w, h)
+	if c.ok() {
+		c.copyTexture(c.TextureImage(c.boundTexture(target), target, level), xoffset, yoffset, x, y, w, h)
+	}
+}
+
+func (c *Context) GenerateMipmap(target webgl.Enum) {
+	c.Context.GenerateMipmap(target)
+	if !c.ok() {
+		return
+	}
+	texture := c.boundTexture(target)
+	faces := []webgl.Enum{webgl.TEXTURE_2D}
+	if target == webgl.TEXTURE_CUBE_MAP {
+		faces = cubeFaces
+	}
+	for _, face := range faces {
+		var levels []*record.Image
+		for level := 0; ; level++ {
+			img := c.TextureImage(texture, face, level)
+			if img == nil {
+				break
+			}
+			levels = append(levels, img)
+		}
+		generateMipmaps(levels)
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/n2d/webgl"
+)
+
+var (
+	red   = [4]byte{255, 0, 0, 255}
+	green = [4]byte{0, 255, 0, 255}
+	blue  = [4]byte{0, 0, 255, 255}
+	white = [4]byte{255, 255, 255, 255}
+)
+
+// Creates a context with a depth buffer and a program drawing its a_pos
+// attribute, a clip space position, in the color of its u_color uniform.
+func newColorContext(t *testing.T, width, height int) *Context {
+	gl := New(width, height, &webgl.ContextAttributes{Alpha: true, Depth: true})
+	prog := gl.NewProgram(&Program{
+		Attribs:  []webgl.ActiveInfo{{Name: "a_pos", Type: webgl.FLOAT_VEC4, Size: 1}},
+		Uniforms: []webgl.ActiveInfo{{Name: "u_color", Type: webgl.FLOAT_VEC4, Size: 1}},
+		Vertex: func(v *Vertex) {
+			v.Position = v.Attrib("a_pos")
+		},
+		Fragment: func(f *Fragment) bool {
+			f.Color = f.Vec4("u_color")
+			return true
+		},
+	})
+	gl.UseProgram(prog)
+	gl.BindBuffer(webgl.ARRAY_BUFFER, gl.CreateBuffer())
+	gl.VertexAttribPointer(0, 3, webgl.FLOAT, false, 0, 0)
+	gl.EnableVertexAttribArray(0)
+	if err := gl.CheckError(); err != nil {
+		t.Fatal(err)
+	}
+	return gl
+}
+
+// Draws the vertices, given as x, y and z, as a triangle strip in color.
+func drawColor(gl *Context, color [4]float32, vertices ...float32) {
+	prog := gl.State().Program
+	gl.Uniform4f(gl.GetUniformLocation(prog, "u_color"), color[0], color[1], color[2], color[3])
+	gl.BufferDataFloat32(webgl.ARRAY_BUFFER, vertices, webgl.STREAM_DRAW)
+	gl.DrawArrays(webgl.TRIANGLE_STRIP, 0, len(vertices)/3)
+}
+
+// Returns the pixel at x, y, counted from the bottom left.
+func pixel(gl *Context, x, y int) [4]byte {
+	var p [4]byte
+	gl.ReadPixelsBytes(x, y, 1, 1, webgl.RGBA, webgl.UNSIGNED_BYTE, p[:])
+	return p
+}
+
+// Returns the rows of the drawing buffer from the top, with # for pixels
+// that are not transparent black.
+func coverage(gl *Context) string {
+	var rows []string
+	for y := gl.DrawingBufferHeight() - 1; y >= 0; y-- {
+		row := ""
+		for x := 0; x < gl.DrawingBufferWidth(); x++ {
+			if pixel(gl, x, y) != [4]byte{} {
+				row += "#"
+			} else {
+				row += "."
+			}
+		}
+		rows = append(rows, row)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// fullscreen covers the whole viewport at a depth of 0.
+var fullscreen = []float32{-1, -1, 0, 1, -1, 0, -1, 1, 0, 1, 1, 0}
+
+func TestCoverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(gl *Context)
+		vertices []float32
+		want     []string
+	}{{
+		name:     "triangle",
+		vertices: []float32{-1, -1, 0, 1, -1, 0, -1, 1, 0},
+		want:     []string{"....", "#...", "##..", "###."},
+	}, {
+		name:     "outside",
+		vertices: []float32{-1, -1, 0, 1, -1, 0, -1, -3, 0},
+		want:     []string{"....", "....", "....", "...."},
+	}, {
+		name:     "clipped by the far plane",
+		vertices: []float32{-1, -1, 0, 1, -1, 0, -1, 1, 3, 1, 1, 3},
+		want:     []string{"....", "....", "....", "####"},
+	}, {
+		name:     "clipped by the sides",
+		vertices: []float32{-3, -3, 0, 5, -3, 0, -3, 5, 0},
+		want:     []string{"####", "####", "####", "####"},
+	}, {
+		name: "scissor",
+		setup: func(gl *Context) {
+			gl.Enable(webgl.SCISSOR_TEST)
+			gl.Scissor(1, 1, 2, 2)
+		},
+		vertices: fullscreen,
+		want:     []string{"....", ".##.", ".##.", "...."},
+	}, {
+		name: "viewport",
+		setup: func(gl *Context) {
+			gl.Viewport(0, 0, 2, 2)
+		},
+		vertices: fullscreen,
+		want:     []string{"....", "....", "##..", "##.."},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl := newColorContext(t, 4, 4)
+			if test.setup != nil {
+				test.setup(gl)
+			}
+			drawColor(gl, [4]float32{1, 0, 0, 1}, test.vertices...)
+			if errs := gl.Errors(); len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if got, want := coverage(gl), strings.Join(test.want, "\n"); got != want {
+				t.Errorf("got coverage\n%s\nwant\n%s", got, want)
+			}
+			if p := pixel(gl, 0, 0); test.want[3][0] == '#' && p != red {
+				t.Errorf("got color %v, want %v", p, red)
+			}
+		})
+	}
+}
+
+func TestDepth(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(gl *Context)
+		want  [4]byte
+	}{
+		{"disabled", func(gl *Context) { gl.Disable(webgl.DEPTH_TEST) }, red},
+		{"less", func(gl *Context) {}, green},
+		{"greater", func(gl *Context) { gl.DepthFunc(webgl.GREATER) }, red},
+		{"always", func(gl *Context) { gl.DepthFunc(webgl.ALWAYS) }, red},
+		{"never", func(gl *Context) { gl.DepthFunc(webgl.NEVER) }, [4]byte{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl := newColorContext(t, 2, 2)
+			gl.Enable(webgl.DEPTH_TEST)
+			gl.Clear(webgl.DEPTH_BUFFER_BIT)
+			// The green quad is nearer than the red one drawn after it.
+			drawColor(gl, [4]float32{0, 1, 0, 1}, -1, -1, 0, 1, -1, 0, -1, 1, 0, 1, 1, 0)
+			test.setup(gl)
+			if test.name == "never" {
+				gl.Clear(webgl.COLOR_BUFFER_BIT)
+			}
+			drawColor(gl, [4]float32{1, 0, 0, 1}, -1, -1, 0.5, 1, -1, 0.5, -1, 1, 0.5, 1, 1, 0.5)
+			if got := pixel(gl, 1, 1); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDepthMask(t *testing.T) {
+	gl := newColorContext(t, 2, 2)
+	gl.Enable(webgl.DEPTH_TEST)
+	gl.Clear(webgl.DEPTH_BUFFER_BIT)
+	gl.DepthMask(false)
+	drawColor(gl, [4]float32{0, 1, 0, 1}, fullscreen...)
+	drawColor(gl, [4]float32{1, 0, 0, 1}, -1, -1, 0.5, 1, -1, 0.5, -1, 1, 0.5, 1, 1, 0.5)
+	if got := pixel(gl, 0, 0); got != red {
+		t.Errorf("got %v, want %v drawn over a quad without depth writes", got, red)
+	}
+}
+
+func TestBlend(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(gl *Context)
+		dst, src [4]float32
+		want     [4]byte
+	}{{
+		name:  "alpha",
+		setup: func(gl *Context) { gl.BlendFunc(webgl.SRC_ALPHA, webgl.ONE_MINUS_SRC_ALPHA) },
+		dst:   [4]float32{0, 1, 0, 1},
+		src:   [4]float32{1, 0, 0, 0.5},
+		want:  [4]byte{128, 128, 0, 191},
+	}, {
+		name:  "additive",
+		setup: func(gl *Context) { gl.BlendFunc(webgl.ONE, webgl.ONE) },
+		dst:   [4]float32{0, 1, 1, 1},
+		src:   [4]float32{0.25, 0.5, 0, 0.5},
+		want:  [4]byte{64, 255, 255, 255},
+	}, {
+		name: "reverse subtract",
+		setup: func(gl *Context) {
+			gl.BlendFunc(webgl.ONE, webgl.ONE)
+			gl.BlendEquation(webgl.FUNC_REVERSE_SUBTRACT)
+		},
+		dst:  [4]float32{1, 1, 0, 1},
+		src:  [4]float32{0.25, 0.5, 0, 0},
+		want: [4]byte{191, 128, 0, 255},
+	}, {
+		name:  "multiply",
+		setup: func(gl *Context) { gl.BlendFunc(webgl.ZERO, webgl.SRC_COLOR) },
+		dst:   [4]float32{1, 1, 0, 1},
+		src:   [4]float32{0.5, 0.25, 1, 1},
+		want:  [4]byte{128, 64, 0, 255},
+	}, {
+		name: "constant",
+		setup: func(gl *Context) {
+			gl.BlendColor(0.5, 0.5, 0.5, 0.5)
+			gl.BlendFunc(webgl.CONSTANT_COLOR, webgl.ZERO)
+		},
+		src:  [4]float32{1, 1, 1, 1},
+		want: [4]byte{128, 128, 128, 128},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl := newColorContext(t, 2, 2)
+			gl.ClearColor(test.dst[0], test.dst[1], test.dst[2], test.dst[3])
+			gl.Clear(webgl.COLOR_BUFFER_BIT)
+			gl.Enable(webgl.BLEND)
+			test.setup(gl)
+			drawColor(gl, test.src, fullscreen...)
+			if got := pixel(gl, 0, 0); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTextureSampling(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter webgl.Enum
+		want   [4][4][4]byte // rows from the bottom
+	}{{
+		name:   "nearest",
+		filter: webgl.NEAREST,
+		want: [4][4][4]byte{
+			{red, red, green, green},
+			{red, red, green, green},
+			{blue, blue, white, white},
+			{blue, blue, white, white},
+		},
+	}, {
+		name:   "linear",
+		filter: webgl.LINEAR,
+		want: [4][4][4]byte{
+			{red, {191, 64, 0, 255}, {64, 191, 0, 255}, green},
+			{{191, 0, 64, 255}, {159, 64, 64, 255}, {96, 191, 64, 255}, {64, 255, 64, 255}},
+			{{64, 0, 191, 255}, {96, 64, 191, 255}, {159, 191, 191, 255}, {191, 255, 191, 255}},
+			{blue, {64, 64, 255, 255}, {191, 191, 255, 255}, white},
+		},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl := New(4, 4, nil)
+			prog := gl.NewProgram(&Program{
+				Attribs:  []webgl.ActiveInfo{{Name: "a_pos", Type: webgl.FLOAT_VEC2, Size: 1}},
+				Uniforms: []webgl.ActiveInfo{{Name: "u_tex", Type: webgl.SAMPLER_2D, Size: 1}},
+				Varyings: 2,
+				Vertex: func(v *Vertex) {
+					p := v.Attrib("a_pos")
+					v.Position = [4]float32{p[0], p[1], 0, 1}
+					v.Varyings[0], v.Varyings[1] = (p[0]+1)/2, (p[1]+1)/2
+				},
+				Fragment: func(f *Fragment) bool {
+					f.Color = f.Texture2D("u_tex", f.Varyings[0], f.Varyings[1])
+					return true
+				},
+			})
+			gl.UseProgram(prog)
+			gl.BindBuffer(webgl.ARRAY_BUFFER, gl.CreateBuffer())
+			gl.BufferDataFloat32(webgl.ARRAY_BUFFER, []float32{-1, -1, 1, -1, -1, 1, 1, 1}, webgl.STATIC_DRAW)
+			gl.VertexAttribPointer(0, 2, webgl.FLOAT, false, 0, 0)
+			gl.EnableVertexAttribArray(0)
+
+			gl.BindTexture(webgl.TEXTURE_2D, gl.CreateTexture())
+			texels := append(append(append(red[:], green[:]...), blue[:]...), white[:]...)
+			gl.TexImage2DPixels(webgl.TEXTURE_2D, 0, webgl.RGBA, 2, 2, 0, webgl.RGBA, webgl.UNSIGNED_BYTE, texels)
+			gl.TexParameteri(webgl.TEXTURE_2D, webgl.TEXTURE_MIN_FILTER, test.filter)
+			gl.TexParameteri(webgl.TEXTURE_2D, webgl.TEXTURE_MAG_FILTER, test.filter)
+			gl.TexParameteri(webgl.TEXTURE_2D, webgl.TEXTURE_WRAP_S, webgl.CLAMP_TO_EDGE)
+			gl.TexParameteri(webgl.TEXTURE_2D, webgl.TEXTURE_WRAP_T, webgl.CLAMP_TO_EDGE)
+			gl.DrawArrays(webgl.TRIANGLE_STRIP, 0, 4)
+			if errs := gl.Errors(); len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			for y, row := range test.want {
+				for x, want := range row {
+					if got := pixel(gl, x, y); got != want {
+						t.Errorf("pixel %d, %d is %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIncompleteTexture(t *testing.T) {
+	gl := New(1, 1, nil)
+	prog := gl.NewProgram(&Program{
+		Attribs:  []webgl.ActiveInfo{{Name: "a_pos", Type: webgl.FLOAT_VEC2, Size: 1}},
+		Uniforms: []webgl.ActiveInfo{{Name: "u_tex", Type: webgl.SAMPLER_2D, Size: 1}},
+		Vertex: func(v *Vertex) {
+			p := v.Attrib("a_pos")
+			v.Position = [4]float32{p[0], p[1], 0, 1}
+		},
+		Fragment: func(f *Fragment) bool {
+			f.Color = f.Texture2D("u_tex", 0.5, 0.5)
+			return true
+		},
+	})
+	gl.UseProgram(prog)
+	gl.BindBuffer(webgl.ARRAY_BUFFER, gl.CreateBuffer())
+	gl.BufferDataFloat32(webgl.ARRAY_BUFFER, []float32{-1, -1, 3, -1, -1, 3}, webgl.STATIC_DRAW)
+	gl.VertexAttribPointer(0, 2, webgl.FLOAT, false, 0, 0)
+	gl.EnableVertexAttribArray(0)
+	gl.BindTexture(webgl.TEXTURE_2D, gl.CreateTexture())
+	// The default minification filter needs mipmaps, which are missing.
+	gl.TexImage2DPixels(webgl.TEXTURE_2D, 0, webgl.RGBA, 2, 2, 0, webgl.RGBA, webgl.UNSIGNED_BYTE, make([]byte, 16))
+	gl.ClearColor(1, 1, 1, 1)
+	gl.Clear(webgl.COLOR_BUFFER_BIT)
+	gl.DrawArrays(webgl.TRIANGLES, 0, 3)
+	if got, want := pixel(gl, 0, 0), [4]byte{0, 0, 0, 255}; got != want {
+		t.Errorf("got %v, want %v from an incomplete texture", got, want)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"math"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+// cubeFaces are the faces of a cube map, in the order of their targets.
+var cubeFaces = []webgl.Enum{
+	webgl.TEXTURE_CUBE_MAP_POSITIVE_X, webgl.TEXTURE_CUBE_MAP_NEGATIVE_X,
+	webgl.TEXTURE_CUBE_MAP_POSITIVE_Y, webgl.TEXTURE_CUBE_MAP_NEGATIVE_Y,
+	webgl.TEXTURE_CUBE_MAP_POSITIVE_Z, webgl.TEXTURE_CUBE_MAP_NEGATIVE_Z,
+}
+
+// texture is a complete texture being sampled by a draw call.
+type texture struct {
+	// faces holds the mipmap levels of each face, with a single face for
+	// 2D textures.
+	faces [][]*record.Image
+
+	minFilter webgl.Enum
+	magFilter webgl.Enum
+	wrapS     webgl.Enum
+	wrapT     webgl.Enum
+}
+
+// Returns whether n is a power of two.
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// Returns whether a minification filter uses mipmaps.
+func mipmapped(filter webgl.Enum) bool {
+	return filter != webgl.NEAREST && filter != webgl.LINEAR
+}
+
+// Returns the texture of a handle for sampling, or nil if it is missing
+// or incomplete. Incomplete textures sample as opaque black.
+func newTexture(ctx *record.Context, handle webgl.Texture, target webgl.Enum) *texture {
+	if handle.Object == nil {
+		return nil
+	}
+	t := &texture{
+		minFilter: webgl.Enum(ctx.TextureParameter(handle, webgl.TEXTURE_MIN_FILTER)),
+		magFilter: webgl.Enum(ctx.TextureParameter(handle, webgl.TEXTURE_MAG_FILTER)),
+		wrapS:     webgl.Enum(ctx.TextureParameter(handle, webgl.TEXTURE_WRAP_S)),
+		wrapT:     webgl.Enum(ctx.TextureParameter(handle, webgl.TEXTURE_WRAP_T)),
+	}
+	targets := []webgl.Enum{webgl.TEXTURE_2D}
+	if target == webgl.TEXTURE_CUBE_MAP {
+		targets = cubeFaces
+	}
+	base := ctx.TextureImage(handle, targets[0], 0)
+	if base == nil || base.Width == 0 || base.Height == 0 {
+		return nil
+	}
+	w, h := base.Width, base.Height
+	// WebGL 1 cannot repeat or mipmap textures whose size is not a power
+	// of two.
+	if !isPowerOfTwo(w) || !isPowerOfTwo(h) {
+		if mipmapped(t.minFilter) || t.wrapS != webgl.CLAMP_TO_EDGE || t.wrapT != webgl.CLAMP_TO_EDGE {
+			return nil
+		}
+	}
+	levels := 1
+	if mipmapped(t.minFilter) {
+		for n := max(w, h); n > 1; n /= 2 {
+			levels++
+		}
+	}
+	for _, target := range targets {
+		var face []*record.Image
+		for level := 0; level < levels; level++ {
+			img := ctx.TextureImage(handle, target, level)
+			if img == nil || img.Width != max(w>>level, 1) || img.Height != max(h>>level, 1) ||
+				img.Format != base.Format || img.Type != base.Type {
+				return nil
+			}
+			face = append(face, img)
+		}
+		t.faces = append(t.faces, face)
+	}
+	return t
+}
+
+// Returns the level of detail for derivatives of 2D texture coordinates.
+func (t *texture) lambda2D(dx, dy [3]float32) float64 {
+	base := t.faces[0][0]
+	w, h := float64(base.Width), float64(base.Height)
+	rho := math.Max(
+		math.Hypot(float64(dx[0])*w, float64(dx[1])*h),
+		math.Hypot(float64(dy[0])*w, float64(dy[1])*h))
+	return math.Log2(rho)
+}
+
+// Samples the 2D texture at a level of detail.
+func (t *texture) sample2D(s, tc, lambda float64) [4]float32 {
+	return t.sampleFace(face{0, s, tc}, lambda)
+}
+
+// face is a location on a face of a cube map.
+type face struct {
+	index int
+	s, t  float64
+}
+
+// Returns the face of a cube map and the location on it a direction
+// points at, following the table of the OpenGL ES 2.0 specification.
+func cubeFace(dir [3]float32) face {
+	x, y, z := float64(dir[0]), float64(dir[1]), float64(dir[2])
+	ax, ay, az := math.Abs(x), math.Abs(y), math.Abs(z)
+	switch {
+	case ax >= ay && ax >= az:
+		if x >= 0 {
+			return faceAt(0, -z, -y, ax)
+		}
+		return faceAt(1, z, -y, ax)
+	case ay >= az:
+		if y >= 0 {
+			return faceAt(2, x, z, ay)
+		}
+		return faceAt(3, x, -z, ay)
+	}
+	if z >= 0 {
+		return faceAt(4, x, -y, az)
+	}
+	return faceAt(5, -x, -y, az)
+}
+
+// Returns the location on a face for the coordinates sc and tc along the
+// face and the major axis coordinate ma.
+func faceAt(index int, sc, tc, ma float64) face {
+	if ma == 0 {
+		return face{index, 0.5, 0.5}
+	}
+	return face{index, (sc/ma + 1) / 2, (tc/ma + 1) / 2}
+}
+
+// Returns the location a direction points at on a given face, which need
+// not be the face it points to.
+func onFace(index int, dir [3]float32) face {
+	x, y, z := float64(dir[0]), float64(dir[1]), float64(dir[2])
+	switch index {
+	case 0:
+		return faceAt(0, -z, -y, math.Abs(x))
+	case 1:
+		return faceAt(1, z, -y, math.Abs(x))
+	case 2:
+		return faceAt(2, x, z, math.Abs(y))
+	case 3:
+		return faceAt(3, x, -z, math.Abs(y))
+	case 4:
+		return faceAt(4, x, -y, math.Abs(z))
+	}
+	return faceAt(5, -x, -y, math.Abs(z))
+}
+
+// Samples the cube map in a direction, selecting the level of detail
+// from the derivatives of the direction.
+func (t *texture) sampleCube(dir, dx, dy [3]float32, bias float64) [4]float32 {
+	f := cubeFace(dir)
+	at := func(d [3]float32) face {
+		for i := range d {
+			d[i] += dir[i]
+		}
+		return onFace(f.index, d)
+	}
+	fx, fy := at(dx), at(dy)
+	size := float64(t.faces[f.index][0].Width)
+	rho := math.Max(
+		math.Hypot((fx.s-f.s)*size, (fx.t-f.t)*size),
+		math.Hypot((fy.s-f.s)*size, (fy.t-f.t)*size))
+	return t.sampleFace(f, math.Log2(rho)+bias)
+}
+
+// Samples a face at a level of detail, following the filter parameters.
+func (t *texture) sampleFace(f face, lambda float64) [4]float32 {
+	levels := t.faces[f.index]
+	c := 0.0
+	if t.magFilter == webgl.LINEAR && (t.minFilter == webgl.NEAREST_MIPMAP_NEAREST || t.minFilter == webgl.NEAREST_MIPMAP_LINEAR) {
+		c = 0.5
+	}
+	if lambda <= c || math.IsNaN(lambda) {
+		return t.sampleLevel(levels[0], f, t.magFilter)
+	}
+	q := float64(len(levels) - 1)
+	switch t.minFilter {
+	case webgl.NEAREST, webgl.LINEAR:
+		return t.sampleLevel(levels[0], f, t.minFilter)
+	case webgl.NEAREST_MIPMAP_NEAREST, webgl.LINEAR_MIPMAP_NEAREST:
+		filter := webgl.NEAREST
+		if t.minFilter == webgl.LINEAR_MIPMAP_NEAREST {
+			filter = webgl.LINEAR
+		}
+		d := 0.0
+		if lambda > 0.5 {
+			d = math.Min(math.Ceil(lambda+0.5)-1, q)
+		}
+		return t.sampleLevel(levels[int(d)], f, filter)
+	}
+	filter := webgl.NEAREST
+	if t.minFilter == webgl.LINEAR_MIPMAP_LINEAR {
+		filter = webgl.LINEAR
+	}
+	if lambda >= q {
+		return t.sampleLevel(levels[int(q)], f, filter)
+	}
+	d := math.Floor(lambda)
+	a := t.sampleLevel(levels[int(d)], f, filter)
+	b := t.sampleLevel(levels[int(d)+1], f, filter)
+	return mix(a, b, float32(lambda-d))
+}
+
+// Returns the linear interpolation of two colors.
+func mix(a, b [4]float32, t float32) [4]float32 {
+	for i := range a {
+		a[i] += (b[i] - a[i]) * t
+	}
+	return a
+}
+
+// Returns a texel index wrapped into [0, size) by a wrap mode.
+func wrap(i, size int, mode webgl.Enum) int {
+	switch mode {
+	case webgl.REPEAT:
+		i %= size
+		if i < 0 {
+			i += size
+		}
+		return i
+	case webgl.MIRRORED_REPEAT:
+		i %= 2 * size
+		if i < 0 {
+			i += 2 * size
+		}
+		if i >= size {
+			i = 2*size - 1 - i
+		}
+		return i
+	}
+	return min(max(i, 0), size-1)
+}
+
+// Samples an image of the texture with the NEAREST or LINEAR filter.
+func (t *texture) sampleLevel(img *record.Image, f face, filter webgl.Enum) [4]float32 {
+	wrapS, wrapT := t.wrapS, t.wrapT
+	if len(t.faces) > 1 {
+		// Cube maps are sampled as if they were seamless.
+		wrapS, wrapT = webgl.CLAMP_TO_EDGE, webgl.CLAMP_TO_EDGE
+	}
+	w, h := img.Width, img.Height
+	texel := func(i, j int) [4]float32 {
+		return loadColor(img, wrap(j, h, wrapT)*w+wrap(i, w, wrapS))
+	}
+	u, v := f.s*float64(w), f.t*float64(h)
+	if filter == webgl.NEAREST {
+		return texel(int(math.Floor(u)), int(math.Floor(v)))
+	}
+	u, v = u-0.5, v-0.5
+	i, j := math.Floor(u), math.Floor(v)
+	a, b := float32(u-i), float32(v-j)
+	x, y := int(i), int(j)
+	return mix(mix(texel(x, y), texel(x+1, y), a), mix(texel(x, y+1), texel(x+1, y+1), a), b)
+}
+
+// Fills the mipmap levels of an image chain from level 0 by averaging
+// each 2x2 block of texels of the level above.
+func generateMipmaps(levels []*record.Image) {
+	for l := 1; l < len(levels); l++ {
+		src, dst := levels[l-1], levels[l]
+		for y := 0; y < dst.Height; y++ {
+			for x := 0; x < dst.Width; x++ {
+				var sum [4]float32
+				for _, p := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+					sx, sy := min(2*x+p[0], src.Width-1), min(2*y+p[1], src.Height-1)
+					c := loadColor(src, sy*src.Width+sx)
+					for i := range sum {
+						sum[i] += c[i] / 4
+					}
+				}
+				storeColor(dst, y*dst.Width+x, sum)
+			}
+		}
+	}
+}
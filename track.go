@@ -0,0 +1,262 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// ObjectInfo describes a live object created through a Context that
+// tracks objects.
+type ObjectInfo struct {
+	// Object is the Object of the handles to the object, so
+	// info.Object == texture.Object finds the info of a Texture.
+	Object Object
+
+	// Kind is the name of the handle type, such as "Texture".
+	Kind string
+
+	// Label is the label given with SetLabel, if any.
+	Label string
+
+	// Bytes estimates the GPU memory used by the object, from the sizes
+	// passed to BufferData, the dimensions and formats passed to the
+	// texture image calls and the renderbuffer storage. Mipmap levels
+	// made by GenerateMipmap are included.
+	Bytes int
+
+	// Stack is the call stack of the call that created the object,
+	// starting at the first caller outside of this package.
+	Stack []runtime.Frame
+}
+
+// Starts tracking the objects created through the context, so leaked
+// objects can be found with LiveObjects or WriteObjectReport, for example
+// after unloading a scene. Objects created before the call are not
+// tracked.
+//
+// Tracking records the call stack of every create call and keeps the
+// sizes of the data uploaded to objects, but not the data itself.
+func (c *Context) TrackObjects() {
+	c.registry().objects = true
+}
+
+// Sets the label of a tracked object, given as a handle such as a Texture
+// or as the Object of one, to name it in LiveObjects and object reports.
+func (c *Context) SetLabel(o Object, label string) {
+	if h, ok := o.(object); ok {
+		o = h.object()
+	}
+	if t, ok := o.(trackedObject); ok {
+		t.base().label = label
+	}
+}
+
+// Returns the live tracked objects in creation order. Objects are live
+// from their creation until they are deleted. Objects tracked for
+// OnContextRestored are included, without a stack unless TrackObjects
+// was called before they were created.
+func (c *Context) LiveObjects() []ObjectInfo {
+	if c.reg == nil {
+		return nil
+	}
+	infos := make([]ObjectInfo, 0, len(c.reg.resources))
+	for _, res := range c.reg.resources {
+		t := res.base()
+		infos = append(infos, ObjectInfo{
+			Object: res,
+			Kind:   kind(res),
+			Label:  t.label,
+			Bytes:  t.bytes,
+			Stack:  frames(t.stack),
+		})
+	}
+	return infos
+}
+
+// Returns the name of the handle type of a resource.
+func kind(res resource) string {
+	switch res.(type) {
+	case *bufferResource:
+		return "Buffer"
+	case *textureResource:
+		return "Texture"
+	case *renderbufferResource:
+		return "Renderbuffer"
+	case *framebufferResource:
+		return "Framebuffer"
+	case *shaderResource:
+		return "Shader"
+	case *queryResource:
+		return "Query"
+	case *samplerResource:
+		return "Sampler"
+	case *syncResource:
+		return "Sync"
+	case *transformFeedbackResource:
+		return "TransformFeedback"
+	}
+	return "Program"
+}
+
+// Returns the estimated GPU memory used by the live tracked objects, in
+// bytes.
+func (c *Context) MemoryEstimate() int {
+	n := 0
+	for _, info := range c.LiveObjects() {
+		n += info.Bytes
+	}
+	return n
+}
+
+// Writes a report of the live tracked objects to w, with the number of
+// objects and bytes of each kind followed by every object, largest first,
+// with its creation stack.
+func (c *Context) WriteObjectReport(w io.Writer) error {
+	infos := c.LiveObjects()
+	type total struct{ count, bytes int }
+	totals := make(map[string]*total)
+	var kinds []string
+	sum := 0
+	for _, info := range infos {
+		t := totals[info.Kind]
+		if t == nil {
+			t = &total{}
+			totals[info.Kind] = t
+			kinds = append(kinds, info.Kind)
+		}
+		t.count++
+		t.bytes += info.Bytes
+		sum += info.Bytes
+	}
+	sort.Strings(kinds)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d live objects, %s\n", len(infos), formatBytes(sum))
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "  %-12s %5d %12s\n", kind, totals[kind].count, formatBytes(totals[kind].bytes))
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Bytes > infos[j].Bytes })
+	for _, info := range infos {
+		fmt.Fprintf(&b, "\n%s %s", info.Kind, formatBytes(info.Bytes))
+		if info.Label != "" {
+			fmt.Fprintf(&b, " %q", info.Label)
+		}
+		b.WriteString("\n")
+		for _, frame := range info.Stack {
+			fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Returns a byte count in the largest unit keeping it above 1.
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// Returns the program counters of the calling goroutine's stack.
+func callers() []uintptr {
+	pcs := make([]uintptr, 32)
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// Returns the frames of a stack from the first one outside of this package.
+func frames(pcs []uintptr) []runtime.Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	pkg := reflect.TypeOf(Context{}).PkgPath() + "."
+	var stack []runtime.Frame
+	frames := runtime.CallersFrames(pcs)
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		if len(stack) > 0 || !strings.HasPrefix(frame.Function, pkg) {
+			stack = append(stack, frame)
+		}
+	}
+	return stack
+}
+
+// Updates the estimated size of a texture from the sizes of its images.
+func (t *textureResource) updateBytes() {
+	t.bytes = 0
+	for _, image := range t.images {
+		t.bytes += image.bytes
+	}
+}
+
+// Adds the sizes of the mipmap levels GenerateMipmap makes from the base
+// images of a texture.
+func (t *textureResource) generateMipmap() {
+	for level, base := range t.images {
+		if level.level != 0 || base.width <= 0 || base.height <= 0 {
+			continue
+		}
+		w, h := base.width, base.height
+		size := base.bytes / (w * h)
+		for i := 1; w > 1 || h > 1; i++ {
+			w, h = max(w/2, 1), max(h/2, 1)
+			t.images[textureLevel{level.target, i}] = textureImage{w, h, w * h * size}
+		}
+	}
+	t.updateBytes()
+}
+
+// Returns the number of bytes of a pixel of the given format and type.
+func pixelBytes(format, typ Enum) int {
+	switch typ {
+	case UNSIGNED_SHORT_4_4_4_4, UNSIGNED_SHORT_5_5_5_1, UNSIGNED_SHORT_5_6_5:
+		return 2
+	case UNSIGNED_INT_24_8, UNSIGNED_INT_2_10_10_10_REV, UNSIGNED_INT_10F_11F_11F_REV, UNSIGNED_INT_5_9_9_9_REV:
+		return 4
+	}
+	_, size := pixelArrayType(typ)
+	switch format {
+	case ALPHA, LUMINANCE, RED, RED_INTEGER, DEPTH_COMPONENT:
+		return size
+	case LUMINANCE_ALPHA, RG, RG_INTEGER, DEPTH_STENCIL:
+		return 2 * size
+	case RGB, RGB_INTEGER:
+		return 3 * size
+	}
+	return 4 * size
+}
+
+// Returns the number of bytes of a pixel of a sized internal format of a
+// renderbuffer or texture.
+func internalFormatBytes(internalFormat Enum) int {
+	switch internalFormat {
+	case STENCIL_INDEX8, R8, R8I, R8UI, R8_SNORM:
+		return 1
+	case RGBA4, RGB5_A1, RGB565, DEPTH_COMPONENT16, RG8, RG8I, RG8UI, RG8_SNORM,
+		R16F, R16I, R16UI:
+		return 2
+	case RGB8, SRGB8, RGB8I, RGB8UI, RGB8_SNORM:
+		return 3
+	case RGB16F, RGB16I, RGB16UI:
+		return 6
+	case RGBA16F, RGBA16I, RGBA16UI, RG32F, RG32I, RG32UI, DEPTH32F_STENCIL8:
+		return 8
+	case RGB32F, RGB32I, RGB32UI:
+		return 12
+	case RGBA32F, RGBA32I, RGBA32UI:
+		return 16
+	}
+	return 4
+}
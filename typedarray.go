@@ -0,0 +1,276 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/n2d/webgl/internal/js"
+)
+
+// scratch is a reusable JavaScript ArrayBuffer that Go slices are copied
+// into before being handed to WebGL. The buffer only grows, so uploading
+// data of a similar size every frame does not allocate a new buffer.
+type scratch struct {
+	buffer js.Value
+	bytes  js.Value
+	size   int
+	views  map[string]js.Value
+}
+
+// Returns a view of the first n bytes of the scratch buffer using the named
+// typed array constructor with elements of elemSize bytes, growing the
+// buffer if needed.
+func (s *scratch) view(ctor string, n, elemSize int) js.Value {
+	if n > s.size {
+		size := 2 * s.size
+		if size < 1024 {
+			size = 1024
+		}
+		for size < n {
+			size *= 2
+		}
+		s.buffer = js.Global().Get("ArrayBuffer").New(size)
+		s.bytes = js.Global().Get("Uint8Array").New(s.buffer)
+		s.size = size
+		s.views = make(map[string]js.Value)
+	}
+	if s.size == 0 {
+		return js.Global().Get(ctor).New(0)
+	}
+	v, ok := s.views[ctor]
+	if !ok {
+		v = js.Global().Get(ctor).New(s.buffer)
+		s.views[ctor] = v
+	}
+	return v.Call("subarray", 0, n/elemSize)
+}
+
+// Copies b into the scratch buffer and returns a view of the copied bytes.
+func (s *scratch) upload(ctor string, b []byte, elemSize int) js.Value {
+	v := s.view(ctor, len(b), elemSize)
+	if len(b) > 0 {
+		js.CopyBytesToJS(s.bytes, b)
+	}
+	return v
+}
+
+// Returns the memory backing a slice of fixed size numbers without copying.
+func sliceBytes[T int8 | uint8 | int16 | uint16 | int32 | uint32 | float32](s []T) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+// Returns a Uint8Array holding a copy of data.
+func (c *Context) bytesArray(data []byte) js.Value {
+	return c.scratch.upload("Uint8Array", data, 1)
+}
+
+// Returns a Float32Array holding a copy of data.
+func (c *Context) float32Array(data []float32) js.Value {
+	return c.scratch.upload("Float32Array", sliceBytes(data), 4)
+}
+
+// Returns an Int32Array holding a copy of data.
+func (c *Context) int32Array(data []int32) js.Value {
+	return c.scratch.upload("Int32Array", sliceBytes(data), 4)
+}
+
+// Returns a Uint32Array holding a copy of data.
+func (c *Context) uint32Array(data []uint32) js.Value {
+	return c.scratch.upload("Uint32Array", sliceBytes(data), 4)
+}
+
+// Returns a typed array holding a copy of pixel data of the given type,
+// using the array type WebGL requires for it, or null if pixels is nil.
+func (c *Context) pixelArray(typ Enum, pixels []byte) interface{} {
+	if pixels == nil {
+		return nil
+	}
+	ctor, size := pixelArrayType(typ)
+	return c.scratch.upload(ctor, pixels, size)
+}
+
+// Returns the typed array constructor and element size matching a pixel type.
+func pixelArrayType(typ Enum) (string, int) {
+	switch typ {
+	case BYTE:
+		return "Int8Array", 1
+	case SHORT:
+		return "Int16Array", 2
+	case UNSIGNED_SHORT, UNSIGNED_SHORT_4_4_4_4, UNSIGNED_SHORT_5_5_5_1, UNSIGNED_SHORT_5_6_5, HALF_FLOAT:
+		return "Uint16Array", 2
+	case INT:
+		return "Int32Array", 4
+	case UNSIGNED_INT, UNSIGNED_INT_24_8, UNSIGNED_INT_2_10_10_10_REV,
+		UNSIGNED_INT_10F_11F_11F_REV, UNSIGNED_INT_5_9_9_9_REV:
+		return "Uint32Array", 4
+	case FLOAT:
+		return "Float32Array", 4
+	}
+	return "Uint8Array", 1
+}
+
+// Returns a Uint8Array view of the scratch buffer for JavaScript to write
+// n bytes into, to be copied back to Go with js.CopyBytesToGo.
+func (c *Context) readArray(n int) js.Value {
+	return c.scratch.view("Uint8Array", n, 1)
+}
+
+// Returns a JavaScript Array of the given Go values.
+func jsArray[T any](values []T, conv func(T) interface{}) js.Value {
+	arr := make([]interface{}, len(values))
+	for i, v := range values {
+		arr[i] = conv(v)
+	}
+	return js.ValueOf(arr)
+}
+
+// Returns the byte contents of a Go slice passed to BufferData or
+// BufferSubData, or false if data is not a slice of numbers. []float64,
+// []int and []uint are converted to float32, int32 and uint32, as WebGL
+// has no wider vertex or index types.
+func bufferBytes(data interface{}) ([]byte, bool) {
+	switch data := data.(type) {
+	case []byte:
+		return data, true
+	case []int8:
+		return sliceBytes(data), true
+	case []int16:
+		return sliceBytes(data), true
+	case []uint16:
+		return sliceBytes(data), true
+	case []int32:
+		return sliceBytes(data), true
+	case []uint32:
+		return sliceBytes(data), true
+	case []float32:
+		return sliceBytes(data), true
+	case []float64:
+		return sliceBytes(convertSlice[float32](data)), true
+	case []int:
+		return sliceBytes(convertSlice[int32](data)), true
+	case []uint:
+		return sliceBytes(convertSlice[uint32](data)), true
+	}
+	return nil, false
+}
+
+// Returns a copy of s with its elements converted to T.
+func convertSlice[T int32 | uint32 | float32, S int | uint | float64](s []S) []T {
+	t := make([]T, len(s))
+	for i, v := range s {
+		t[i] = T(v)
+	}
+	return t
+}
+
+// Returns a buffer size given as any Go integer type as an int.
+func bufferSize(data interface{}) (int, bool) {
+	switch data := data.(type) {
+	case int:
+		return data, true
+	case int32:
+		return int(data), true
+	case int64:
+		return int(data), true
+	case uint:
+		return int(data), true
+	case uint32:
+		return int(data), true
+	case uint64:
+		return int(data), true
+	}
+	return 0, false
+}
+
+// Panics with a descriptive error if data, passed to method, is neither a
+// JavaScript value nor a size, which syscall/js would otherwise fail to
+// convert or WebGL reject with a TypeError.
+func checkBufferValue(method string, data interface{}, size bool) {
+	if _, ok := data.(js.Value); ok {
+		return
+	}
+	if _, ok := bufferSize(data); ok && size {
+		return
+	}
+	panic(fmt.Sprintf("webgl: %s cannot upload data of type %T", method, data))
+}
+
+// Creates a buffer in memory and initializes it with the bytes of data.
+func (c *Context) BufferDataBytes(target Enum, data []byte, usage Enum) {
+	c.call("bufferData", target, c.bytesArray(data), usage)
+	c.reg.bufferData(target, data, usage)
+}
+
+// Creates a buffer in memory and initializes it with int8 data.
+func (c *Context) BufferDataInt8(target Enum, data []int8, usage Enum) {
+	c.BufferDataBytes(target, sliceBytes(data), usage)
+}
+
+// Creates a buffer in memory and initializes it with int16 data.
+func (c *Context) BufferDataInt16(target Enum, data []int16, usage Enum) {
+	c.BufferDataBytes(target, sliceBytes(data), usage)
+}
+
+// Creates a buffer in memory and initializes it with uint16 data,
+// typically indices for DrawElements with UNSIGNED_SHORT.
+func (c *Context) BufferDataUint16(target Enum, data []uint16, usage Enum) {
+	c.BufferDataBytes(target, sliceBytes(data), usage)
+}
+
+// Creates a buffer in memory and initializes it with int32 data.
+func (c *Context) BufferDataInt32(target Enum, data []int32, usage Enum) {
+	c.BufferDataBytes(target, sliceBytes(data), usage)
+}
+
+// Creates a buffer in memory and initializes it with uint32 data.
+func (c *Context) BufferDataUint32(target Enum, data []uint32, usage Enum) {
+	c.BufferDataBytes(target, sliceBytes(data), usage)
+}
+
+// Creates a buffer in memory and initializes it with float32 data.
+func (c *Context) BufferDataFloat32(target Enum, data []float32, usage Enum) {
+	c.BufferDataBytes(target, sliceBytes(data), usage)
+}
+
+// Updates the data store of the bound buffer at offset with the bytes of data.
+func (c *Context) BufferSubDataBytes(target Enum, offset int, data []byte) {
+	c.call("bufferSubData", target, offset, c.bytesArray(data))
+	c.reg.bufferSubData(target, offset, data)
+}
+
+// Updates the data store of the bound buffer at offset with int8 data.
+func (c *Context) BufferSubDataInt8(target Enum, offset int, data []int8) {
+	c.BufferSubDataBytes(target, offset, sliceBytes(data))
+}
+
+// Updates the data store of the bound buffer at offset with int16 data.
+func (c *Context) BufferSubDataInt16(target Enum, offset int, data []int16) {
+	c.BufferSubDataBytes(target, offset, sliceBytes(data))
+}
+
+// Updates the data store of the bound buffer at offset with uint16 data.
+func (c *Context) BufferSubDataUint16(target Enum, offset int, data []uint16) {
+	c.BufferSubDataBytes(target, offset, sliceBytes(data))
+}
+
+// Updates the data store of the bound buffer at offset with int32 data.
+func (c *Context) BufferSubDataInt32(target Enum, offset int, data []int32) {
+	c.BufferSubDataBytes(target, offset, sliceBytes(data))
+}
+
+// Updates the data store of the bound buffer at offset with uint32 data.
+func (c *Context) BufferSubDataUint32(target Enum, offset int, data []uint32) {
+	c.BufferSubDataBytes(target, offset, sliceBytes(data))
+}
+
+// Updates the data store of the bound buffer at offset with float32 data.
+func (c *Context) BufferSubDataFloat32(target Enum, offset int, data []float32) {
+	c.BufferSubDataBytes(target, offset, sliceBytes(data))
+}
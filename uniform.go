@@ -0,0 +1,422 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// uniformField maps a tagged struct field to an active uniform.
+type uniformField struct {
+	index    []int
+	info     ActiveInfo
+	location UniformLocation
+	float    bool
+}
+
+// sentUniform is the last value uploaded for a uniform by SetUniforms.
+type sentUniform struct {
+	floats []float32
+	ints   []int32
+}
+
+// Sets the uniforms of the program from the fields of the struct pointed
+// to by v. Fields are matched to uniforms by their "gl" tag:
+//
+//	type Material struct {
+//		Color [4]float32  `gl:"u_color"`
+//		MVP   [16]float32 `gl:"u_mvp"`
+//		Tex   int         `gl:"u_tex"`
+//	}
+//
+// Float uniforms take float32 fields, int, bool and sampler uniforms take
+// integer or bool fields, and vectors, matrices and uniform arrays take
+// arrays or slices of those holding the flattened components. The field
+// types are checked against the GLSL types the first time a struct type is
+// used. Fields tagged with uniforms that are not active in the program are
+// ignored.
+//
+// Every field is checked before any value is uploaded, so an error leaves
+// the uniforms unchanged. Integer values must fit in an int32.
+//
+// Only values that differ from the ones last set through SetUniforms are
+// uploaded, so values set on the program by other means may be missed.
+// The values are uploaded again after a program is linked or the context
+// restored, if gl has a LinkCount method as *Context does. The program
+// must be in use.
+func (p *ProgramInfo) SetUniforms(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("webgl: SetUniforms needs a struct, got %T", v)
+	}
+	fields, err := p.uniformFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	if n, ok := linkCount(p.gl); ok && n != p.links {
+		clear(p.sent)
+		p.links = n
+	}
+
+	// Gather the values of every field into p.floats and p.ints, ending
+	// at p.ends, before uploading any of them.
+	p.floats, p.ints, p.ends = p.floats[:0], p.ints[:0], p.ends[:0]
+	for i := range fields {
+		f := &fields[i]
+		fv := rv.FieldByIndex(f.index)
+		comps := uniformComponents(f.info.Type)
+		start := len(p.floats)
+		if f.float {
+			p.floats = appendUniformFloats(p.floats, fv)
+			p.ends = append(p.ends, len(p.floats))
+		} else {
+			start = len(p.ints)
+			if p.ints, err = appendUniformInts(p.ints, fv, f.info.Name); err != nil {
+				return err
+			}
+			p.ends = append(p.ends, len(p.ints))
+		}
+		if err := checkUniformLength(f.info, comps, p.ends[i]-start); err != nil {
+			return err
+		}
+	}
+
+	floatStart, intStart := 0, 0
+	for i := range fields {
+		f := &fields[i]
+		sent := p.sent[f.info.Name]
+		if sent == nil {
+			sent = new(sentUniform)
+			p.sent[f.info.Name] = sent
+		}
+		if f.float {
+			floats := p.floats[floatStart:p.ends[i]]
+			floatStart = p.ends[i]
+			if sent.floats != nil && equalFloats(sent.floats, floats) {
+				continue
+			}
+			sent.floats = append(sent.floats[:0], floats...)
+			p.uniformfv(f.info.Type, f.location, floats)
+		} else {
+			ints := p.ints[intStart:p.ends[i]]
+			intStart = p.ends[i]
+			if sent.ints != nil && equalInts(sent.ints, ints) {
+				continue
+			}
+			sent.ints = append(sent.ints[:0], ints...)
+			p.uniformiv(f.info.Type, f.location, ints)
+		}
+	}
+	return nil
+}
+
+// Returns the link count of gl if it has a LinkCount method, as *Context
+// and the record backend do, so cached uniform values can be dropped when
+// it changes.
+func linkCount(gl RenderingContext) (int, bool) {
+	if c, ok := gl.(interface{ LinkCount() int }); ok {
+		return c.LinkCount(), true
+	}
+	return 0, false
+}
+
+// Returns the uniform fields of a struct type, building and validating
+// them the first time the type is seen.
+func (p *ProgramInfo) uniformFields(t reflect.Type) ([]uniformField, error) {
+	if fields, ok := p.plans[t]; ok {
+		return fields, nil
+	}
+	var fields []uniformField
+	for _, sf := range reflect.VisibleFields(t) {
+		name, ok := sf.Tag.Lookup("gl")
+		if !ok || name == "-" {
+			continue
+		}
+		info, ok := p.Uniform(name)
+		if !ok {
+			continue
+		}
+		comps := uniformComponents(info.Type)
+		if comps == 0 {
+			return nil, fmt.Errorf("webgl: uniform %s has unsupported type %s", name, TypeName(info.Type))
+		}
+		kind, count := uniformLeaf(sf.Type)
+		float := kind == reflect.Float32
+		switch {
+		case kind == reflect.Invalid,
+			float && !uniformIsFloat(info.Type),
+			!float && uniformIsFloat(info.Type):
+			return nil, fmt.Errorf("webgl: field %s of type %s cannot set uniform %s of type %s",
+				sf.Name, sf.Type, name, TypeName(info.Type))
+		}
+		if count >= 0 {
+			if err := checkUniformLength(info, comps, count); err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, uniformField{
+			index:    sf.Index,
+			info:     info,
+			location: p.UniformLocation(name),
+			float:    float,
+		})
+	}
+	p.plans[t] = fields
+	return fields, nil
+}
+
+// Returns the scalar kind held by a field type, Float32 for float data and
+// Int32 for integer and bool data, along with the number of scalars for
+// fixed size types or -1 if the field contains a slice.
+func uniformLeaf(t reflect.Type) (reflect.Kind, int) {
+	switch t.Kind() {
+	case reflect.Float32:
+		return reflect.Float32, 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Bool:
+		return reflect.Int32, 1
+	case reflect.Array:
+		kind, n := uniformLeaf(t.Elem())
+		if n < 0 {
+			return kind, -1
+		}
+		return kind, n * t.Len()
+	case reflect.Slice:
+		kind, _ := uniformLeaf(t.Elem())
+		return kind, -1
+	}
+	return reflect.Invalid, 0
+}
+
+// Checks that n scalars fill a whole number of elements of a uniform
+// without overflowing it.
+func checkUniformLength(info ActiveInfo, comps, n int) error {
+	if n == 0 || n%comps != 0 || n > comps*info.Size {
+		return fmt.Errorf("webgl: %d values cannot set uniform %s of type %s and size %d",
+			n, info.Name, TypeName(info.Type), info.Size)
+	}
+	return nil
+}
+
+func appendUniformFloats(dst []float32, v reflect.Value) []float32 {
+	switch v.Kind() {
+	case reflect.Float32:
+		return append(dst, float32(v.Float()))
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			dst = appendUniformFloats(dst, v.Index(i))
+		}
+	}
+	return dst
+}
+
+// Appends the integer values of a field to dst, failing for values that
+// do not fit in the int32 of a GLSL int.
+func appendUniformInts(dst []int32, v reflect.Value, name string) ([]int32, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n < math.MinInt32 || n > math.MaxInt32 {
+			return dst, fmt.Errorf("webgl: value %d of uniform %s overflows int32", n, name)
+		}
+		return append(dst, int32(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		if n := v.Uint(); n > math.MaxInt32 {
+			return dst, fmt.Errorf("webgl: value %d of uniform %s overflows int32", n, name)
+		}
+		return append(dst, int32(v.Uint())), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return append(dst, 1), nil
+		}
+		return append(dst, 0), nil
+	case reflect.Array, reflect.Slice:
+		var err error
+		for i := 0; i < v.Len() && err == nil; i++ {
+			dst, err = appendUniformInts(dst, v.Index(i), name)
+		}
+		return dst, err
+	}
+	return dst, nil
+}
+
+func equalFloats(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalInts(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Uploads float values to a uniform of the given GLSL type.
+func (p *ProgramInfo) uniformfv(typ Enum, location UniformLocation, v []float32) {
+	switch typ {
+	case FLOAT:
+		p.gl.Uniform1fv(location, v)
+	case FLOAT_VEC2:
+		p.gl.Uniform2fv(location, v)
+	case FLOAT_VEC3:
+		p.gl.Uniform3fv(location, v)
+	case FLOAT_VEC4:
+		p.gl.Uniform4fv(location, v)
+	case FLOAT_MAT2:
+		p.gl.UniformMatrix2fv(location, false, v)
+	case FLOAT_MAT3:
+		p.gl.UniformMatrix3fv(location, false, v)
+	case FLOAT_MAT4:
+		p.gl.UniformMatrix4fv(location, false, v)
+	}
+}
+
+// Uploads integer values to a uniform of the given GLSL type.
+func (p *ProgramInfo) uniformiv(typ Enum, location UniformLocation, v []int32) {
+	switch uniformComponents(typ) {
+	case 1:
+		p.gl.Uniform1iv(location, v)
+	case 2:
+		p.gl.Uniform2iv(location, v)
+	case 3:
+		p.gl.Uniform3iv(location, v)
+	case 4:
+		p.gl.Uniform4iv(location, v)
+	}
+}
+
+// Returns whether a GLSL type is set with float values.
+func uniformIsFloat(typ Enum) bool {
+	switch typ {
+	case FLOAT, FLOAT_VEC2, FLOAT_VEC3, FLOAT_VEC4, FLOAT_MAT2, FLOAT_MAT3, FLOAT_MAT4:
+		return true
+	}
+	return false
+}
+
+// Returns the number of scalar components of a WebGL 1 GLSL type,
+// or 0 if the type is not supported by SetUniforms.
+func uniformComponents(typ Enum) int {
+	switch typ {
+	case FLOAT, INT, BOOL, SAMPLER_2D, SAMPLER_CUBE:
+		return 1
+	case FLOAT_VEC2, INT_VEC2, BOOL_VEC2:
+		return 2
+	case FLOAT_VEC3, INT_VEC3, BOOL_VEC3:
+		return 3
+	case FLOAT_VEC4, INT_VEC4, BOOL_VEC4, FLOAT_MAT2:
+		return 4
+	case FLOAT_MAT3:
+		return 9
+	case FLOAT_MAT4:
+		return 16
+	}
+	return 0
+}
+
+// Returns the GLSL name of a uniform or attribute type, such as "vec4"
+// for FLOAT_VEC4, or the hexadecimal value for unknown types.
+func TypeName(typ Enum) string {
+	switch typ {
+	case FLOAT:
+		return "float"
+	case FLOAT_VEC2:
+		return "vec2"
+	case FLOAT_VEC3:
+		return "vec3"
+	case FLOAT_VEC4:
+		return "vec4"
+	case INT:
+		return "int"
+	case INT_VEC2:
+		return "ivec2"
+	case INT_VEC3:
+		return "ivec3"
+	case INT_VEC4:
+		return "ivec4"
+	case UNSIGNED_INT:
+		return "uint"
+	case UNSIGNED_INT_VEC2:
+		return "uvec2"
+	case UNSIGNED_INT_VEC3:
+		return "uvec3"
+	case UNSIGNED_INT_VEC4:
+		return "uvec4"
+	case BOOL:
+		return "bool"
+	case BOOL_VEC2:
+		return "bvec2"
+	case BOOL_VEC3:
+		return "bvec3"
+	case BOOL_VEC4:
+		return "bvec4"
+	case FLOAT_MAT2:
+		return "mat2"
+	case FLOAT_MAT3:
+		return "mat3"
+	case FLOAT_MAT4:
+		return "mat4"
+	case FLOAT_MAT2x3:
+		return "mat2x3"
+	case FLOAT_MAT2x4:
+		return "mat2x4"
+	case FLOAT_MAT3x2:
+		return "mat3x2"
+	case FLOAT_MAT3x4:
+		return "mat3x4"
+	case FLOAT_MAT4x2:
+		return "mat4x2"
+	case FLOAT_MAT4x3:
+		return "mat4x3"
+	case SAMPLER_2D:
+		return "sampler2D"
+	case SAMPLER_CUBE:
+		return "samplerCube"
+	case SAMPLER_3D:
+		return "sampler3D"
+	case SAMPLER_2D_SHADOW:
+		return "sampler2DShadow"
+	case SAMPLER_2D_ARRAY:
+		return "sampler2DArray"
+	case SAMPLER_2D_ARRAY_SHADOW:
+		return "sampler2DArrayShadow"
+	case SAMPLER_CUBE_SHADOW:
+		return "samplerCubeShadow"
+	case INT_SAMPLER_2D:
+		return "isampler2D"
+	case INT_SAMPLER_3D:
+		return "isampler3D"
+	case INT_SAMPLER_CUBE:
+		return "isamplerCube"
+	case INT_SAMPLER_2D_ARRAY:
+		return "isampler2DArray"
+	case UNSIGNED_INT_SAMPLER_2D:
+		return "usampler2D"
+	case UNSIGNED_INT_SAMPLER_3D:
+		return "usampler3D"
+	case UNSIGNED_INT_SAMPLER_CUBE:
+		return "usamplerCube"
+	case UNSIGNED_INT_SAMPLER_2D_ARRAY:
+		return "usampler2DArray"
+	}
+	return fmt.Sprintf("0x%04X", uint32(typ))
+}
@@ -0,0 +1,145 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/n2d/webgl"
+	"github.com/n2d/webgl/record"
+)
+
+// Returns a recording context whose programs have the given active
+// attributes and uniforms.
+func newRecord(attribs, uniforms []webgl.ActiveInfo) *record.Context {
+	gl := record.New(64, 64, nil)
+	gl.Link = func(webgl.Program, string, string) ([]webgl.ActiveInfo, []webgl.ActiveInfo, error) {
+		return attribs, uniforms, nil
+	}
+	return gl
+}
+
+// Links a program on gl and makes it current, failing the test on errors.
+func useProgram(t *testing.T, gl *record.Context) webgl.Program {
+	t.Helper()
+	program, err := webgl.LinkProgram(gl, "vertex", "fragment", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gl.UseProgram(program)
+	return program
+}
+
+// Returns the recorded calls to the named methods as strings, with the
+// program shown as p, and clears the log.
+func takeCalls(gl *record.Context, program webgl.Program, methods ...string) []string {
+	var calls []string
+	for _, call := range gl.Filter(methods...) {
+		calls = append(calls, strings.ReplaceAll(call.String(), fmt.Sprint(program.Object), "p"))
+	}
+	gl.Reset()
+	return calls
+}
+
+var uniformMethods = []string{"Uniform1iv", "Uniform4fv"}
+
+type material struct {
+	Color [4]float32 `gl:"u_color"`
+	Count int64      `gl:"u_count"`
+	Tex   int        `gl:"u_tex"`
+}
+
+func TestSetUniforms(t *testing.T) {
+	gl := newRecord(nil, []webgl.ActiveInfo{
+		{Name: "u_color", Type: webgl.FLOAT_VEC4, Size: 1},
+		{Name: "u_count", Type: webgl.INT, Size: 1},
+		{Name: "u_tex", Type: webgl.SAMPLER_2D, Size: 1},
+	})
+	program := useProgram(t, gl)
+	info := webgl.GetProgramInfo(gl, program)
+	gl.Reset()
+
+	m := material{Color: [4]float32{1, 0, 0, 1}, Count: 3, Tex: 1}
+	steps := []struct {
+		name  string
+		set   func() error
+		err   string
+		calls []string
+	}{{
+		name: "first upload",
+		set:  func() error { return info.SetUniforms(&m) },
+		calls: []string{
+			`Uniform4fv(p["u_color"], [1 0 0 1])`,
+			`Uniform1iv(p["u_count"], [3])`,
+			`Uniform1iv(p["u_tex"], [1])`,
+		},
+	}, {
+		name: "unchanged",
+		set:  func() error { return info.SetUniforms(&m) },
+	}, {
+		name: "one field changed",
+		set: func() error {
+			m.Count = 4
+			return info.SetUniforms(m)
+		},
+		calls: []string{`Uniform1iv(p["u_count"], [4])`},
+	}, {
+		name: "field of the wrong type",
+		set: func() error {
+			return info.SetUniforms(&struct {
+				Color [4]int `gl:"u_color"`
+			}{})
+		},
+		err: "field Color of type [4]int cannot set uniform u_color of type vec4",
+	}, {
+		name: "invalid field after a changed one",
+		set: func() error {
+			return info.SetUniforms(&struct {
+				Color [4]float32 `gl:"u_color"`
+				Count []int32    `gl:"u_count"`
+			}{[4]float32{0, 1, 0, 1}, []int32{1, 2}})
+		},
+		err: "2 values cannot set uniform u_count of type int and size 1",
+	}, {
+		name: "int32 overflow",
+		set: func() error {
+			m.Color[0], m.Count = 0.5, math.MaxInt32+1
+			return info.SetUniforms(&m)
+		},
+		err: "value 2147483648 of uniform u_count overflows int32",
+	}, {
+		name: "relink",
+		set: func() error {
+			m.Color[0], m.Count = 1, 4
+			gl.LinkProgram(program)
+			gl.Reset()
+			return info.SetUniforms(&m)
+		},
+		calls: []string{
+			`Uniform4fv(p["u_color"], [1 0 0 1])`,
+			`Uniform1iv(p["u_count"], [4])`,
+			`Uniform1iv(p["u_tex"], [1])`,
+		},
+	}}
+	for _, step := range steps {
+		err := step.set()
+		switch {
+		case step.err == "" && err != nil:
+			t.Errorf("%s: %v", step.name, err)
+		case step.err != "" && (err == nil || err.Error() != "webgl: "+step.err):
+			t.Errorf("%s: got error %v, want %q", step.name, err, step.err)
+		}
+		calls := takeCalls(gl, program, uniformMethods...)
+		if strings.Join(calls, "\n") != strings.Join(step.calls, "\n") {
+			t.Errorf("%s: got calls %q, want %q", step.name, calls, step.calls)
+		}
+	}
+	if errs := gl.Errors(); len(errs) > 0 {
+		t.Errorf("got WebGL errors %v", errs)
+	}
+}
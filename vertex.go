@@ -0,0 +1,208 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// VertexLayout describes how the fields of a vertex struct are laid out
+// in a vertex buffer, so they can be bound to program attributes with
+// VertexAttribPointer without computing sizes, strides and offsets by hand.
+type VertexLayout struct {
+	// Type is the vertex struct type the layout was built from.
+	Type reflect.Type
+
+	// Stride is the size of a vertex in bytes.
+	Stride int
+
+	// Attributes are the tagged fields of the vertex struct.
+	Attributes []VertexAttrib
+}
+
+// VertexAttrib describes a single attribute of a VertexLayout.
+type VertexAttrib struct {
+	// Name is the name of the attribute in the program.
+	Name string
+
+	// Size is the number of components, 1 to 4.
+	Size int
+
+	// Type is the component type, such as FLOAT or UNSIGNED_BYTE.
+	Type Enum
+
+	// Normalized reports whether integer components are mapped to
+	// [0, 1] or [-1, 1] rather than converted directly to floats.
+	Normalized bool
+
+	// Offset is the byte offset of the attribute within a vertex.
+	Offset int
+}
+
+// Builds the layout of a vertex struct from its "gl" field tags. The tag
+// gives the attribute name followed by an optional ",normalized" flag:
+//
+//	type Vertex struct {
+//		Pos    [3]float32 `gl:"a_position"`
+//		Normal [3]float32 `gl:"a_normal"`
+//		UV     [2]float32 `gl:"a_uv"`
+//		Color  [4]uint8   `gl:"a_color,normalized"`
+//	}
+//
+// Tagged fields must be a float32, int8, uint8, int16 or uint16, or an
+// array of 1 to 4 of them. Untagged fields are skipped but still count
+// towards the stride, which may be at most 255 bytes. Fields promoted
+// through embedded pointers cannot be tagged. vertex may be a struct value
+// or its reflect.Type.
+func NewVertexLayout(vertex interface{}) (*VertexLayout, error) {
+	t, ok := vertex.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(vertex)
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("webgl: vertex layout needs a struct type, got %v", t)
+	}
+
+	l := &VertexLayout{Type: t, Stride: int(t.Size())}
+	if l.Stride > 255 {
+		return nil, fmt.Errorf("webgl: vertex %v of %d bytes is larger than the maximum stride of 255", t, l.Stride)
+	}
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, ok := sf.Tag.Lookup("gl")
+		if !ok || tag == "-" {
+			continue
+		}
+		offset, ok := fieldOffset(t, sf.Index)
+		if !ok {
+			return nil, fmt.Errorf("webgl: field %s is promoted through a pointer and cannot be a vertex attribute", sf.Name)
+		}
+		opts := strings.Split(tag, ",")
+		attr := VertexAttrib{Name: opts[0], Size: 1, Offset: offset}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "normalized":
+				attr.Normalized = true
+			default:
+				return nil, fmt.Errorf("webgl: unknown option %q in tag of field %s", opt, sf.Name)
+			}
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Array {
+			attr.Size = ft.Len()
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Float32:
+			attr.Type = FLOAT
+		case reflect.Int8:
+			attr.Type = BYTE
+		case reflect.Uint8:
+			attr.Type = UNSIGNED_BYTE
+		case reflect.Int16:
+			attr.Type = SHORT
+		case reflect.Uint16:
+			attr.Type = UNSIGNED_SHORT
+		}
+		if attr.Type == 0 || attr.Size < 1 || attr.Size > 4 {
+			return nil, fmt.Errorf("webgl: field %s of type %s cannot be a vertex attribute", sf.Name, sf.Type)
+		}
+		l.Attributes = append(l.Attributes, attr)
+	}
+	return l, nil
+}
+
+// Returns the byte offset of a possibly promoted field within t, or false
+// if it is promoted through an embedded pointer and so not stored in t.
+func fieldOffset(t reflect.Type, index []int) (int, bool) {
+	offset := 0
+	for _, i := range index {
+		if t.Kind() != reflect.Struct {
+			return 0, false
+		}
+		f := t.Field(i)
+		offset += int(f.Offset)
+		t = f.Type
+	}
+	return offset, true
+}
+
+// Returns the bytes of a slice of vertices, checking that its elements
+// are of the layout's vertex type.
+func (l *VertexLayout) bytes(vertices interface{}) ([]byte, error) {
+	v := reflect.ValueOf(vertices)
+	if v.Kind() != reflect.Slice || v.Type().Elem() != l.Type {
+		return nil, fmt.Errorf("webgl: vertices must be a []%v, got %T", l.Type, vertices)
+	}
+	if v.Len() == 0 {
+		return nil, nil
+	}
+	return unsafe.Slice((*byte)(v.UnsafePointer()), v.Len()*l.Stride), nil
+}
+
+// Uploads a slice of vertices of the layout's type to the buffer bound
+// to target, replacing its contents as BufferData does.
+func (l *VertexLayout) BufferData(gl RenderingContext, target Enum, vertices interface{}, usage Enum) error {
+	b, err := l.bytes(vertices)
+	if err != nil {
+		return err
+	}
+	gl.BufferDataBytes(target, b, usage)
+	return nil
+}
+
+// Uploads a slice of vertices of the layout's type into the buffer bound
+// to target, starting at the vertex with the given index.
+func (l *VertexLayout) BufferSubData(gl RenderingContext, target Enum, index int, vertices interface{}) error {
+	b, err := l.bytes(vertices)
+	if err != nil {
+		return err
+	}
+	gl.BufferSubDataBytes(target, index*l.Stride, b)
+	return nil
+}
+
+// Points the program's attributes at the fields of the vertices stored in
+// the buffer bound to ARRAY_BUFFER and enables them. Every active attribute
+// of the program must be provided by the layout. Layout attributes the
+// program does not use are skipped.
+func (l *VertexLayout) Bind(gl RenderingContext, info *ProgramInfo) error {
+	for _, active := range info.Attributes {
+		if strings.HasPrefix(active.Name, "gl_") {
+			continue
+		}
+		if !l.has(active.Name) {
+			return fmt.Errorf("webgl: vertex %v has no field for attribute %s", l.Type, active.Name)
+		}
+		switch active.Type {
+		case FLOAT, FLOAT_VEC2, FLOAT_VEC3, FLOAT_VEC4:
+		default:
+			return fmt.Errorf("webgl: attribute %s of type %s cannot be bound to a vertex field",
+				active.Name, TypeName(active.Type))
+		}
+	}
+	for _, attr := range l.Attributes {
+		location := info.AttribLocation(attr.Name)
+		if location < 0 {
+			continue
+		}
+		gl.VertexAttribPointer(location, attr.Size, attr.Type, attr.Normalized, l.Stride, attr.Offset)
+		gl.EnableVertexAttribArray(location)
+	}
+	return nil
+}
+
+// Returns whether the layout has an attribute with the given name.
+func (l *VertexLayout) has(name string) bool {
+	for _, attr := range l.Attributes {
+		if attr.Name == name {
+			return true
+		}
+	}
+	return false
+}
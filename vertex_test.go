@@ -0,0 +1,185 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/n2d/webgl"
+)
+
+type Vertex struct {
+	Pos    [3]float32 `gl:"a_position"`
+	Normal [3]float32 `gl:"a_normal"`
+	UV     [2]float32 `gl:"a_uv"`
+	Color  [4]uint8   `gl:"a_color,normalized"`
+}
+
+// Returns the bytes of float32 and uint8 values in native byte order.
+func vertexBytes(values ...interface{}) []byte {
+	var b []byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case float32:
+			b = binary.NativeEndian.AppendUint32(b, math.Float32bits(v))
+		case uint8:
+			b = append(b, v)
+		}
+	}
+	return b
+}
+
+func TestVertexLayout(t *testing.T) {
+	l, err := webgl.NewVertexLayout(Vertex{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []webgl.VertexAttrib{
+		{Name: "a_position", Size: 3, Type: webgl.FLOAT, Offset: 0},
+		{Name: "a_normal", Size: 3, Type: webgl.FLOAT, Offset: 12},
+		{Name: "a_uv", Size: 2, Type: webgl.FLOAT, Offset: 24},
+		{Name: "a_color", Size: 4, Type: webgl.UNSIGNED_BYTE, Normalized: true, Offset: 32},
+	}
+	if l.Stride != 36 || l.Type != reflect.TypeOf(Vertex{}) || !reflect.DeepEqual(l.Attributes, want) {
+		t.Errorf("got layout %+v, want stride 36 and attributes %+v", l, want)
+	}
+
+	gl := newRecord([]webgl.ActiveInfo{
+		{Name: "a_position", Type: webgl.FLOAT_VEC3, Size: 1},
+		{Name: "a_color", Type: webgl.FLOAT_VEC4, Size: 1},
+		{Name: "a_normal", Type: webgl.FLOAT_VEC3, Size: 1},
+		{Name: "gl_VertexID", Type: webgl.INT, Size: 1},
+	}, nil)
+	program := useProgram(t, gl)
+	info := webgl.GetProgramInfo(gl, program)
+	buffer := gl.CreateBuffer()
+	gl.BindBuffer(webgl.ARRAY_BUFFER, buffer)
+	gl.Reset()
+
+	if err := l.Bind(gl, info); err != nil {
+		t.Fatal(err)
+	}
+	calls := takeCalls(gl, program, "VertexAttribPointer", "EnableVertexAttribArray")
+	wantCalls := []string{
+		"VertexAttribPointer(0, 3, 0x1406, false, 36, 0)",
+		"EnableVertexAttribArray(0)",
+		"VertexAttribPointer(2, 3, 0x1406, false, 36, 12)",
+		"EnableVertexAttribArray(2)",
+		"VertexAttribPointer(1, 4, 0x1401, true, 36, 32)",
+		"EnableVertexAttribArray(1)",
+	}
+	if strings.Join(calls, "\n") != strings.Join(wantCalls, "\n") {
+		t.Errorf("got calls\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(wantCalls, "\n"))
+	}
+
+	vertices := []Vertex{
+		{Pos: [3]float32{1, 2, 3}, Normal: [3]float32{0, 0, 1}, UV: [2]float32{0.5, 1}, Color: [4]uint8{255, 0, 0, 255}},
+		{Pos: [3]float32{4, 5, 6}, Normal: [3]float32{0, 1, 0}, UV: [2]float32{1, 0}, Color: [4]uint8{0, 255, 0, 128}},
+	}
+	first := vertexBytes(float32(1), float32(2), float32(3), float32(0), float32(0), float32(1),
+		float32(0.5), float32(1), uint8(255), uint8(0), uint8(0), uint8(255))
+	second := vertexBytes(float32(4), float32(5), float32(6), float32(0), float32(1), float32(0),
+		float32(1), float32(0), uint8(0), uint8(255), uint8(0), uint8(128))
+	if err := l.BufferData(gl, webgl.ARRAY_BUFFER, vertices, webgl.STATIC_DRAW); err != nil {
+		t.Fatal(err)
+	}
+	if got := gl.BufferContents(buffer); !bytes.Equal(got, append(append([]byte{}, first...), second...)) {
+		t.Errorf("BufferData uploaded %v", got)
+	}
+	if err := l.BufferSubData(gl, webgl.ARRAY_BUFFER, 1, vertices[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := gl.BufferContents(buffer); !bytes.Equal(got, append(append([]byte{}, first...), first...)) {
+		t.Errorf("BufferSubData left %v", got)
+	}
+	if err := l.BufferData(gl, webgl.ARRAY_BUFFER, []float32{1}, webgl.STATIC_DRAW); err == nil {
+		t.Error("BufferData accepted a slice of another type")
+	}
+	if errs := gl.Errors(); len(errs) > 0 {
+		t.Errorf("got WebGL errors %v", errs)
+	}
+}
+
+type Inner struct {
+	Pos [2]float32 `gl:"a_position"`
+}
+
+func TestVertexLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		vertex interface{}
+		want   string
+	}{
+		{"not a struct", [3]float32{}, "needs a struct type"},
+		{"promoted through a pointer", struct{ *Inner }{}, "field Pos is promoted through a pointer"},
+		{"stride", struct {
+			Pos  [3]float32 `gl:"a_position"`
+			Data [64]float32
+		}{}, "268 bytes is larger than the maximum stride of 255"},
+		{"unknown option", struct {
+			Pos [3]float32 `gl:"a_position,packed"`
+		}{}, `unknown option "packed"`},
+		{"component type", struct {
+			Pos [3]float64 `gl:"a_position"`
+		}{}, "field Pos of type [3]float64 cannot be a vertex attribute"},
+		{"component count", struct {
+			Pos [5]float32 `gl:"a_position"`
+		}{}, "field Pos of type [5]float32 cannot be a vertex attribute"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := webgl.NewVertexLayout(test.vertex)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+
+	// Fields promoted through embedded structs are laid out in place.
+	l, err := webgl.NewVertexLayout(struct {
+		Color [4]uint8 `gl:"a_color"`
+		Inner
+	}{})
+	if err != nil || fmt.Sprint(l.Attributes) != "[{a_color 4 5121 false 0} {a_position 2 5126 false 4}]" {
+		t.Errorf("got attributes %v and error %v for an embedded struct", l.Attributes, err)
+	}
+}
+
+func TestVertexLayoutBindErrors(t *testing.T) {
+	l, err := webgl.NewVertexLayout(Vertex{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		attribs []webgl.ActiveInfo
+		want    string
+	}{
+		{"missing field", []webgl.ActiveInfo{{Name: "a_tangent", Type: webgl.FLOAT_VEC3, Size: 1}},
+			"has no field for attribute a_tangent"},
+		{"matrix attribute", []webgl.ActiveInfo{{Name: "a_uv", Type: webgl.FLOAT_MAT2, Size: 1}},
+			"attribute a_uv of type mat2 cannot be bound"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl := newRecord(test.attribs, nil)
+			info := webgl.GetProgramInfo(gl, useProgram(t, gl))
+			gl.Reset()
+			err := l.Bind(gl, info)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want one containing %q", err, test.want)
+			}
+			if len(gl.Calls) > 0 {
+				t.Errorf("made calls %v", gl.Calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"sort"
+
+	"github.com/n2d/webgl/internal/js"
+)
+
+// vertexArrays emulates vertex array objects on WebGL 1 contexts without
+// the OES_vertex_array_object extension. While it exists the context
+// records the vertex attribute state set through it into the bound
+// emulated vertex array, starting with the default one.
+type vertexArrays struct {
+	current     *emulatedVertexArray
+	null        emulatedVertexArray
+	arrayBuffer Buffer
+}
+
+// emulatedVertexArray is the Object of an emulated VertexArray.
+type emulatedVertexArray struct {
+	elementBuffer Buffer
+	attribs       map[int]vertexAttrib
+	deleted       bool
+}
+
+// vertexAttrib is the state of a vertex attribute recorded in an emulated
+// vertex array.
+type vertexAttrib struct {
+	enabled    bool
+	pointer    bool
+	buffer     Buffer
+	size       int
+	typ        Enum
+	normalized bool
+	stride     int
+	offset     int
+	divisor    int
+}
+
+// Returns the object the vertex array methods are called on and the
+// suffix of their names: the context itself on WebGL 2, otherwise the
+// OES_vertex_array_object extension. ok is false if vertex arrays are
+// emulated.
+func (c *Context) vertexArrayTarget() (target js.Value, suffix string, ok bool) {
+	if c.webglVersion() == 2 {
+		return c.Value, "", true
+	}
+	if ext := c.GetExtension("OES_vertex_array_object"); ext.Truthy() {
+		return ext, "OES", true
+	}
+	return js.Value{}, "", false
+}
+
+// Creates a vertex array object, which holds the vertex attribute
+// pointers, the enabled attributes, their divisors and the
+// ELEMENT_ARRAY_BUFFER binding, so they can be set again with a single
+// BindVertexArray call.
+//
+// It uses WebGL 2 or the OES_vertex_array_object extension. When neither
+// is available the context emulates vertex array objects instead: it
+// records the state set through it while one is bound and replays the
+// calls on BindVertexArray. The emulation only knows about state set
+// through the context after the first CreateVertexArray call, so the
+// default vertex array should be set up after it. Divisors are recorded
+// only when VertexAttribDivisor succeeds: without instanced drawing it
+// returns an *UnsupportedError and the emulated vertex arrays keep a
+// divisor of 0.
+func (c *Context) CreateVertexArray() VertexArray {
+	if target, suffix, ok := c.vertexArrayTarget(); ok {
+		return VertexArray{jsObject(c.Invoke(target, "createVertexArray"+suffix))}
+	}
+	if c.vao == nil {
+		c.vao = &vertexArrays{
+			null:        emulatedVertexArray{elementBuffer: Buffer{c.GetParameterObject(ELEMENT_ARRAY_BUFFER_BINDING)}},
+			arrayBuffer: Buffer{c.GetParameterObject(ARRAY_BUFFER_BINDING)},
+		}
+		c.vao.null.attribs = make(map[int]vertexAttrib)
+		c.vao.current = &c.vao.null
+	}
+	return VertexArray{&emulatedVertexArray{attribs: make(map[int]vertexAttrib)}}
+}
+
+// Binds a vertex array object, restoring the vertex attribute state
+// recorded in it. Binding a null VertexArray restores the default state.
+func (c *Context) BindVertexArray(vertexArray VertexArray) {
+	if target, suffix, ok := c.vertexArrayTarget(); ok {
+		c.Invoke(target, "bindVertexArray"+suffix, vertexArray)
+		return
+	}
+	if c.vao == nil {
+		return
+	}
+	va, _ := vertexArray.Object.(*emulatedVertexArray)
+	if va == nil || va.deleted {
+		va = &c.vao.null
+	}
+	c.vao.bind(c, va)
+}
+
+// Deletes a vertex array object. If it is bound the default vertex array
+// is bound instead.
+func (c *Context) DeleteVertexArray(vertexArray VertexArray) {
+	if target, suffix, ok := c.vertexArrayTarget(); ok {
+		c.Invoke(target, "deleteVertexArray"+suffix, vertexArray)
+		return
+	}
+	if c.vao == nil {
+		return
+	}
+	va, _ := vertexArray.Object.(*emulatedVertexArray)
+	if va == nil || va.deleted {
+		return
+	}
+	va.deleted = true
+	if c.vao.current == va {
+		c.vao.bind(c, &c.vao.null)
+	}
+}
+
+// Returns true if vertexArray is a valid vertex array object.
+func (c *Context) IsVertexArray(vertexArray VertexArray) bool {
+	if target, suffix, ok := c.vertexArrayTarget(); ok {
+		return c.Invoke(target, "isVertexArray"+suffix, vertexArray).Bool()
+	}
+	va, _ := vertexArray.Object.(*emulatedVertexArray)
+	return va != nil && !va.deleted
+}
+
+// Replays the state recorded in va, disabling the attributes enabled by
+// the bound vertex array that va does not use, and makes it the bound one.
+// The ARRAY_BUFFER binding is kept, as it is not vertex array state.
+func (v *vertexArrays) bind(c *Context, va *emulatedVertexArray) {
+	prev := v.current
+	v.current = va
+	arrayBuffer := v.arrayBuffer
+
+	indices := make([]int, 0, len(prev.attribs)+len(va.attribs))
+	for index := range va.attribs {
+		indices = append(indices, index)
+	}
+	for index := range prev.attribs {
+		if _, ok := va.attribs[index]; !ok {
+			indices = append(indices, index)
+		}
+	}
+	sort.Ints(indices)
+
+	c.BindBuffer(ELEMENT_ARRAY_BUFFER, va.elementBuffer)
+	rebound := false
+	for _, index := range indices {
+		a := va.attribs[index]
+		if a.pointer {
+			c.BindBuffer(ARRAY_BUFFER, a.buffer)
+			c.VertexAttribPointer(index, a.size, a.typ, a.normalized, a.stride, a.offset)
+			rebound = true
+		}
+		if a.enabled {
+			c.EnableVertexAttribArray(index)
+		} else {
+			c.DisableVertexAttribArray(index)
+		}
+		if a.divisor != prev.attribs[index].divisor {
+			// A recorded divisor means instanced drawing was available,
+			// so this cannot fail with an *UnsupportedError.
+			_ = c.VertexAttribDivisor(index, a.divisor)
+		}
+	}
+	if rebound {
+		c.BindBuffer(ARRAY_BUFFER, arrayBuffer)
+	}
+}
+
+// Records the binding of a buffer.
+func (v *vertexArrays) bindBuffer(target Enum, buffer Buffer) {
+	if v == nil {
+		return
+	}
+	switch target {
+	case ARRAY_BUFFER:
+		v.arrayBuffer = buffer
+	case ELEMENT_ARRAY_BUFFER:
+		v.current.elementBuffer = buffer
+	}
+}
+
+// Records a vertex attribute pointer into the buffer bound to ARRAY_BUFFER.
+func (v *vertexArrays) vertexAttribPointer(index, size int, typ Enum, normalized bool, stride, offset int) {
+	if v == nil {
+		return
+	}
+	a := v.current.attribs[index]
+	a.pointer, a.buffer = true, v.arrayBuffer
+	a.size, a.typ, a.normalized, a.stride, a.offset = size, typ, normalized, stride, offset
+	v.current.attribs[index] = a
+}
+
+// Records whether a vertex attribute array is enabled.
+func (v *vertexArrays) enableVertexAttribArray(index int, enabled bool) {
+	if v == nil {
+		return
+	}
+	a := v.current.attribs[index]
+	a.enabled = enabled
+	v.current.attribs[index] = a
+}
+
+// Records the divisor of a vertex attribute.
+func (v *vertexArrays) vertexAttribDivisor(index, divisor int) {
+	if v == nil {
+		return
+	}
+	a := v.current.attribs[index]
+	a.divisor = divisor
+	v.current.attribs[index] = a
+}
+
+// Forgets the state of the default vertex array and binds it, after the
+// context has been restored with its state reset.
+func (v *vertexArrays) reset() {
+	if v == nil {
+		return
+	}
+	v.null = emulatedVertexArray{attribs: make(map[int]vertexAttrib)}
+	v.current = &v.null
+	v.arrayBuffer = Buffer{}
+}
@@ -0,0 +1,632 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgl
+
+import (
+	"math"
+
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Context2 is a WebGL 2 rendering context. It embeds the WebGL 1 Context,
+// so every WebGL 1 method is available alongside the WebGL 2 additions.
+type Context2 struct {
+	*Context
+}
+
+// NotSupportedError is returned by NewContext2 when the browser is unable
+// to create a WebGL 2 context. If WebGL1 is true a WebGL 1 context can still
+// be created for the canvas with NewContext.
+type NotSupportedError struct {
+	WebGL1 bool
+}
+
+func (e *NotSupportedError) Error() string {
+	if e.WebGL1 {
+		return "webgl2 is not supported, only webgl is available."
+	}
+	return "webgl2 is not supported."
+}
+
+// Creates a WebGL 2 context for the canvas using the browser's default
+// context attributes.
+func NewContext2(canvas js.Value) (*Context2, error) {
+	return NewContext2WithAttributes(canvas, nil)
+}
+
+// Creates a WebGL 2 context for the canvas, requesting the given context
+// attributes. If attrs is nil the browser's defaults are used. When WebGL 2
+// is unavailable a *NotSupportedError is returned and the canvas is left
+// untouched, so the caller can fall back to NewContextWithAttributes.
+func NewContext2WithAttributes(canvas js.Value, attrs *ContextAttributes) (*Context2, error) {
+	webgl1 := !js.Global().Get("WebGLRenderingContext").IsUndefined()
+	if js.Global().Get("WebGL2RenderingContext").IsUndefined() {
+		return nil, &NotSupportedError{WebGL1: webgl1}
+	}
+
+	gl := getContext(canvas, attrs, "webgl2")
+	if gl.IsNull() {
+		return nil, &NotSupportedError{WebGL1: webgl1}
+	}
+	return &Context2{newContext(gl, 2)}, nil
+}
+
+// Buffer objects
+
+// Copies part of the data store of the buffer bound to readTarget into
+// the buffer bound to writeTarget.
+func (c *Context2) CopyBufferSubData(readTarget, writeTarget Enum, readOffset, writeOffset, size int) {
+	c.call("copyBufferSubData", readTarget, writeTarget, readOffset, writeOffset, size)
+}
+
+// Reads data from the buffer bound to target, starting at srcByteOffset,
+// into dst.
+func (c *Context2) GetBufferSubData(target Enum, srcByteOffset int, dst []byte) {
+	arr := c.readArray(len(dst))
+	c.call("getBufferSubData", target, srcByteOffset, arr)
+	js.CopyBytesToGo(dst, arr)
+}
+
+// Framebuffer objects
+
+// Transfers a block of pixels from the read framebuffer to the draw framebuffer.
+func (c *Context2) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int, mask, filter Enum) {
+	c.call("blitFramebuffer", srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+// Attaches a single layer of a 3D or 2D array texture to a framebuffer.
+func (c *Context2) FramebufferTextureLayer(target, attachment Enum, texture Texture, level, layer int) {
+	c.call("framebufferTextureLayer", target, attachment, texture, level, layer)
+}
+
+// Invalidates the contents of attachments in the framebuffer bound to target.
+func (c *Context2) InvalidateFramebuffer(target Enum, attachments []Enum) {
+	c.call("invalidateFramebuffer", target, enumArray(attachments))
+}
+
+// Invalidates a region of the contents of attachments in the framebuffer
+// bound to target.
+func (c *Context2) InvalidateSubFramebuffer(target Enum, attachments []Enum, x, y, width, height int) {
+	c.call("invalidateSubFramebuffer", target, enumArray(attachments), x, y, width, height)
+}
+
+// Selects the color buffer used as the source for pixel reads.
+func (c *Context2) ReadBuffer(src Enum) {
+	c.call("readBuffer", src)
+}
+
+// Renderbuffer objects
+
+// Returns the sample counts supported for internalFormat, in descending order.
+func (c *Context2) GetInternalformatSamples(target, internalFormat Enum) []int {
+	arr := c.call("getInternalformatParameter", target, internalFormat, SAMPLES)
+	samples := make([]int, arr.Length())
+	for i := range samples {
+		samples[i] = arr.Index(i).Int()
+	}
+	return samples
+}
+
+// Creates or replaces the data store of the bound renderbuffer with a
+// multisampled image.
+func (c *Context2) RenderbufferStorageMultisample(target Enum, samples int, internalFormat Enum, width, height int) {
+	c.call("renderbufferStorageMultisample", target, samples, internalFormat, width, height)
+}
+
+// Texture objects
+
+// Allocates immutable storage for all levels of a 2D texture.
+func (c *Context2) TexStorage2D(target Enum, levels int, internalFormat Enum, width, height int) {
+	c.call("texStorage2D", target, levels, internalFormat, width, height)
+	c.reg.texStorage(target, levels, internalFormat, width, height, 1)
+}
+
+// Allocates immutable storage for all levels of a 3D or 2D array texture.
+func (c *Context2) TexStorage3D(target Enum, levels int, internalFormat Enum, width, height, depth int) {
+	c.call("texStorage3D", target, levels, internalFormat, width, height, depth)
+	c.reg.texStorage(target, levels, internalFormat, width, height, depth)
+}
+
+// Specifies a 3D or 2D array texture image. If pixels is nil the texture
+// image is allocated without being initialized.
+func (c *Context2) TexImage3D(target Enum, level int, internalFormat Enum, width, height, depth, border int, format, typ Enum, pixels []byte) {
+	c.call("texImage3D", target, level, internalFormat, width, height, depth, border, format, typ, c.pixelArray(typ, pixels))
+	c.reg.texImage3D(target, level, width, height, depth, format, typ)
+}
+
+// Replaces a region of a 3D or 2D array texture image.
+func (c *Context2) TexSubImage3D(target Enum, level, xoffset, yoffset, zoffset, width, height, depth int, format, typ Enum, pixels []byte) {
+	c.call("texSubImage3D", target, level, xoffset, yoffset, zoffset, width, height, depth, format, typ, c.pixelArray(typ, pixels))
+}
+
+// Copies pixels from the current read framebuffer into a region of a
+// 3D or 2D array texture image.
+func (c *Context2) CopyTexSubImage3D(target Enum, level, xoffset, yoffset, zoffset, x, y, width, height int) {
+	c.call("copyTexSubImage3D", target, level, xoffset, yoffset, zoffset, x, y, width, height)
+}
+
+// Specifies a 3D or 2D array texture image in a compressed format.
+func (c *Context2) CompressedTexImage3D(target Enum, level int, internalFormat Enum, width, height, depth, border int, data []byte) {
+	c.call("compressedTexImage3D", target, level, internalFormat, width, height, depth, border, c.bytesArray(data))
+}
+
+// Replaces a region of a compressed 3D or 2D array texture image.
+func (c *Context2) CompressedTexSubImage3D(target Enum, level, xoffset, yoffset, zoffset, width, height, depth int, format Enum, data []byte) {
+	c.call("compressedTexSubImage3D", target, level, xoffset, yoffset, zoffset, width, height, depth, format, c.bytesArray(data))
+}
+
+// Programs and shaders
+
+// Returns the binding of color numbers to a user-defined varying out variable.
+func (c *Context2) GetFragDataLocation(program Program, name string) int {
+	return c.call("getFragDataLocation", program, name).Int()
+}
+
+// Uniforms
+
+// Assigns an unsigned integer value to a uniform variable for the current program object.
+func (c *Context2) Uniform1ui(location UniformLocation, x uint32) {
+	c.call("uniform1ui", location, x)
+}
+
+// Assigns 2 unsigned integer values to a uniform variable for the current program object.
+func (c *Context2) Uniform2ui(location UniformLocation, x, y uint32) {
+	c.call("uniform2ui", location, x, y)
+}
+
+// Assigns 3 unsigned integer values to a uniform variable for the current program object.
+func (c *Context2) Uniform3ui(location UniformLocation, x, y, z uint32) {
+	c.call("uniform3ui", location, x, y, z)
+}
+
+// Assigns 4 unsigned integer values to a uniform variable for the current program object.
+func (c *Context2) Uniform4ui(location UniformLocation, x, y, z, w uint32) {
+	c.call("uniform4ui", location, x, y, z, w)
+}
+
+// Assigns a slice of unsigned integer values to a uint uniform
+// variable or array for the current program object.
+func (c *Context2) Uniform1uiv(location UniformLocation, v []uint32) {
+	c.call("uniform1uiv", location, c.uint32Array(v))
+}
+
+// Assigns a slice of unsigned integer values to a uvec2 uniform
+// variable or array for the current program object.
+func (c *Context2) Uniform2uiv(location UniformLocation, v []uint32) {
+	c.call("uniform2uiv", location, c.uint32Array(v))
+}
+
+// Assigns a slice of unsigned integer values to a uvec3 uniform
+// variable or array for the current program object.
+func (c *Context2) Uniform3uiv(location UniformLocation, v []uint32) {
+	c.call("uniform3uiv", location, c.uint32Array(v))
+}
+
+// Assigns a slice of unsigned integer values to a uvec4 uniform
+// variable or array for the current program object.
+func (c *Context2) Uniform4uiv(location UniformLocation, v []uint32) {
+	c.call("uniform4uiv", location, c.uint32Array(v))
+}
+
+// Sets values for a 2x3 floating point matrix into a
+// uniform location as a matrix or a matrix array.
+func (c *Context2) UniformMatrix2x3fv(location UniformLocation, transpose bool, value []float32) {
+	c.call("uniformMatrix2x3fv", location, transpose, c.float32Array(value))
+}
+
+// Sets values for a 3x2 floating point matrix into a
+// uniform location as a matrix or a matrix array.
+func (c *Context2) UniformMatrix3x2fv(location UniformLocation, transpose bool, value []float32) {
+	c.call("uniformMatrix3x2fv", location, transpose, c.float32Array(value))
+}
+
+// Sets values for a 2x4 floating point matrix into a
+// uniform location as a matrix or a matrix array.
+func (c *Context2) UniformMatrix2x4fv(location UniformLocation, transpose bool, value []float32) {
+	c.call("uniformMatrix2x4fv", location, transpose, c.float32Array(value))
+}
+
+// Sets values for a 4x2 floating point matrix into a
+// uniform location as a matrix or a matrix array.
+func (c *Context2) UniformMatrix4x2fv(location UniformLocation, transpose bool, value []float32) {
+	c.call("uniformMatrix4x2fv", location, transpose, c.float32Array(value))
+}
+
+// Sets values for a 3x4 floating point matrix into a
+// uniform location as a matrix or a matrix array.
+func (c *Context2) UniformMatrix3x4fv(location UniformLocation, transpose bool, value []float32) {
+	c.call("uniformMatrix3x4fv", location, transpose, c.float32Array(value))
+}
+
+// Sets values for a 4x3 floating point matrix into a
+// uniform location as a matrix or a matrix array.
+func (c *Context2) UniformMatrix4x3fv(location UniformLocation, transpose bool, value []float32) {
+	c.call("uniformMatrix4x3fv", location, transpose, c.float32Array(value))
+}
+
+// Vertex attributes
+
+// Sets 4 constant integer values for a disabled generic vertex attribute.
+func (c *Context2) VertexAttribI4i(index int, x, y, z, w int32) {
+	c.call("vertexAttribI4i", index, x, y, z, w)
+}
+
+// Sets 4 constant integer values for a disabled generic vertex
+// attribute from the first elements of values.
+func (c *Context2) VertexAttribI4iv(index int, values []int32) {
+	c.call("vertexAttribI4iv", index, c.int32Array(values))
+}
+
+// Sets 4 constant unsigned integer values for a disabled generic vertex attribute.
+func (c *Context2) VertexAttribI4ui(index int, x, y, z, w uint32) {
+	c.call("vertexAttribI4ui", index, x, y, z, w)
+}
+
+// Sets 4 constant unsigned integer values for a disabled generic vertex
+// attribute from the first elements of values.
+func (c *Context2) VertexAttribI4uiv(index int, values []uint32) {
+	c.call("vertexAttribI4uiv", index, c.uint32Array(values))
+}
+
+// Specifies the layout of an integer vertex attribute in the bound
+// ARRAY_BUFFER. Unlike VertexAttribPointer the values are not converted
+// to floating point.
+func (c *Context2) VertexAttribIPointer(index, size int, typ Enum, stride, offset int) {
+	c.call("vertexAttribIPointer", index, size, typ, stride, offset)
+}
+
+// Writing to the drawing buffer
+
+// Sets the rate at which a generic vertex attribute advances when drawing
+// multiple instances. A divisor of 0 advances once per vertex.
+func (c *Context2) VertexAttribDivisor(index, divisor int) {
+	c.call("vertexAttribDivisor", index, divisor)
+}
+
+// Renders instanceCount instances of primitives from array data.
+func (c *Context2) DrawArraysInstanced(mode Enum, first, count, instanceCount int) {
+	c.call("drawArraysInstanced", mode, first, count, instanceCount)
+}
+
+// Renders instanceCount instances of primitives indexed by element array data.
+func (c *Context2) DrawElementsInstanced(mode Enum, count int, typ Enum, offset, instanceCount int) {
+	c.call("drawElementsInstanced", mode, count, typ, offset, instanceCount)
+}
+
+// Renders primitives indexed by element array data whose indices all
+// lie within [start, end].
+func (c *Context2) DrawRangeElements(mode Enum, start, end, count int, typ Enum, offset int) {
+	c.call("drawRangeElements", mode, start, end, count, typ, offset)
+}
+
+// Multiple render targets
+
+// Clears a floating point color buffer of the current draw framebuffer.
+func (c *Context2) ClearBufferfv(buffer Enum, drawBuffer int, values []float32) {
+	c.call("clearBufferfv", buffer, drawBuffer, c.float32Array(values))
+}
+
+// Clears a signed integer color buffer or the stencil buffer of the
+// current draw framebuffer.
+func (c *Context2) ClearBufferiv(buffer Enum, drawBuffer int, values []int32) {
+	c.call("clearBufferiv", buffer, drawBuffer, c.int32Array(values))
+}
+
+// Clears an unsigned integer color buffer of the current draw framebuffer.
+func (c *Context2) ClearBufferuiv(buffer Enum, drawBuffer int, values []uint32) {
+	c.call("clearBufferuiv", buffer, drawBuffer, c.uint32Array(values))
+}
+
+// Clears both the depth and stencil buffers of the current draw framebuffer.
+func (c *Context2) ClearBufferfi(buffer Enum, drawBuffer int, depth float32, stencil int) {
+	c.call("clearBufferfi", buffer, drawBuffer, depth, stencil)
+}
+
+// Query objects
+
+// Creates a WebGLQuery object.
+func (c *Context2) CreateQuery() Query {
+	return Query{c.reg.track(c.call("createQuery"), &queryResource{})}
+}
+
+// Deletes a WebGLQuery object.
+func (c *Context2) DeleteQuery(query Query) {
+	c.call("deleteQuery", query)
+	c.reg.untrack(query)
+}
+
+// Returns true if query is a valid WebGLQuery object.
+func (c *Context2) IsQuery(query Query) bool {
+	return c.call("isQuery", query).Bool()
+}
+
+// Starts an asynchronous query of the given target.
+func (c *Context2) BeginQuery(target Enum, query Query) {
+	c.call("beginQuery", target, query)
+}
+
+// Ends the active asynchronous query of the given target.
+func (c *Context2) EndQuery(target Enum) {
+	c.call("endQuery", target)
+}
+
+// Returns the currently active query for target, or null.
+func (c *Context2) GetQuery(target Enum) Query {
+	return Query{c.reg.object(c.call("getQuery", target, CURRENT_QUERY))}
+}
+
+// Returns a query parameter which is interpreted as an int, such as QUERY_RESULT.
+func (c *Context2) GetQueryParameteri(query Query, pname Enum) int {
+	return c.call("getQueryParameter", query, pname).Int()
+}
+
+// Returns a query parameter which is interpreted as a bool, such as
+// QUERY_RESULT_AVAILABLE.
+func (c *Context2) GetQueryParameterb(query Query, pname Enum) bool {
+	return c.call("getQueryParameter", query, pname).Bool()
+}
+
+// Sampler objects
+
+// Creates a WebGLSampler object.
+func (c *Context2) CreateSampler() Sampler {
+	return Sampler{c.reg.track(c.call("createSampler"), &samplerResource{})}
+}
+
+// Deletes a WebGLSampler object.
+func (c *Context2) DeleteSampler(sampler Sampler) {
+	c.call("deleteSampler", sampler)
+	c.reg.untrack(sampler)
+}
+
+// Returns true if sampler is a valid WebGLSampler object.
+func (c *Context2) IsSampler(sampler Sampler) bool {
+	return c.call("isSampler", sampler).Bool()
+}
+
+// Binds a sampler to a texture unit, overriding the sampling parameters
+// of the texture bound to that unit.
+func (c *Context2) BindSampler(unit int, sampler Sampler) {
+	c.call("bindSampler", unit, sampler)
+}
+
+// Sets sampling parameters of a sampler object.
+func (c *Context2) SamplerParameteri(sampler Sampler, pname, param Enum) {
+	c.call("samplerParameteri", sampler, pname, param)
+	c.reg.samplerParameter(sampler, pname, param)
+}
+
+// Sets floating point sampling parameters of a sampler object.
+func (c *Context2) SamplerParameterf(sampler Sampler, pname Enum, param float32) {
+	c.call("samplerParameterf", sampler, pname, param)
+	c.reg.samplerParameter(sampler, pname, param)
+}
+
+// Returns a sampling parameter of a sampler object.
+func (c *Context2) GetSamplerParameter(sampler Sampler, pname Enum) js.Value {
+	return c.call("getSamplerParameter", sampler, pname)
+}
+
+// Returns a sampling parameter of a sampler object which is interpreted
+// as an int, such as TEXTURE_MIN_FILTER or TEXTURE_COMPARE_MODE.
+func (c *Context2) GetSamplerParameteri(sampler Sampler, pname Enum) int {
+	return c.call("getSamplerParameter", sampler, pname).Int()
+}
+
+// Returns a sampling parameter of a sampler object which is interpreted
+// as a float, such as TEXTURE_MIN_LOD.
+func (c *Context2) GetSamplerParameterf(sampler Sampler, pname Enum) float32 {
+	return float32(c.call("getSamplerParameter", sampler, pname).Float())
+}
+
+// Sync objects
+
+// Creates a fence sync object that becomes signaled once all previous
+// commands have completed.
+func (c *Context2) FenceSync(condition, flags Enum) Sync {
+	return Sync{c.reg.track(c.call("fenceSync", condition, flags), &syncResource{})}
+}
+
+// Returns true if sync is a valid WebGLSync object.
+func (c *Context2) IsSync(sync Sync) bool {
+	return c.call("isSync", sync).Bool()
+}
+
+// Deletes a WebGLSync object.
+func (c *Context2) DeleteSync(sync Sync) {
+	c.call("deleteSync", sync)
+	c.reg.untrack(sync)
+}
+
+// Blocks until sync is signaled or timeout nanoseconds have passed, and
+// returns one of ALREADY_SIGNALED, TIMEOUT_EXPIRED, CONDITION_SATISFIED
+// or WAIT_FAILED.
+func (c *Context2) ClientWaitSync(sync Sync, flags Enum, timeout int64) Enum {
+	return Enum(c.call("clientWaitSync", sync, flags, timeout).Int())
+}
+
+// Makes the GL server wait until sync is signaled.
+func (c *Context2) WaitSync(sync Sync, flags Enum, timeout int64) {
+	c.call("waitSync", sync, flags, timeout)
+}
+
+// Returns a parameter of a sync object, such as SYNC_STATUS.
+func (c *Context2) GetSyncParameter(sync Sync, pname Enum) Enum {
+	return Enum(c.call("getSyncParameter", sync, pname).Int())
+}
+
+// Transform feedback
+
+// Creates a WebGLTransformFeedback object.
+func (c *Context2) CreateTransformFeedback() TransformFeedback {
+	return TransformFeedback{c.reg.track(c.call("createTransformFeedback"), &transformFeedbackResource{})}
+}
+
+// Deletes a WebGLTransformFeedback object.
+func (c *Context2) DeleteTransformFeedback(tf TransformFeedback) {
+	c.call("deleteTransformFeedback", tf)
+	c.reg.untrack(tf)
+}
+
+// Returns true if tf is a valid WebGLTransformFeedback object.
+func (c *Context2) IsTransformFeedback(tf TransformFeedback) bool {
+	return c.call("isTransformFeedback", tf).Bool()
+}
+
+// Binds a transform feedback object to target.
+func (c *Context2) BindTransformFeedback(target Enum, tf TransformFeedback) {
+	c.call("bindTransformFeedback", target, tf)
+}
+
+// Starts a transform feedback operation capturing primitiveMode primitives.
+func (c *Context2) BeginTransformFeedback(primitiveMode Enum) {
+	c.call("beginTransformFeedback", primitiveMode)
+}
+
+// Ends the active transform feedback operation.
+func (c *Context2) EndTransformFeedback() {
+	c.call("endTransformFeedback")
+}
+
+// Pauses the active transform feedback operation.
+func (c *Context2) PauseTransformFeedback() {
+	c.call("pauseTransformFeedback")
+}
+
+// Resumes a paused transform feedback operation.
+func (c *Context2) ResumeTransformFeedback() {
+	c.call("resumeTransformFeedback")
+}
+
+// Specifies the varyings to record in transform feedback buffers. It must
+// be called before LinkProgram.
+func (c *Context2) TransformFeedbackVaryings(program Program, varyings []string, bufferMode Enum) {
+	c.call("transformFeedbackVaryings", program, stringArray(varyings), bufferMode)
+}
+
+// Returns the size, type, and name of the varying at index recorded
+// by transform feedback.
+func (c *Context2) GetTransformFeedbackVarying(program Program, index int) ActiveInfo {
+	return activeInfo(c.call("getTransformFeedbackVarying", program, index))
+}
+
+// Uniform buffer objects
+
+// Binds a buffer to the indexed binding point of target.
+func (c *Context2) BindBufferBase(target Enum, index int, buffer Buffer) {
+	c.call("bindBufferBase", target, index, buffer)
+}
+
+// Binds a range of a buffer to the indexed binding point of target.
+func (c *Context2) BindBufferRange(target Enum, index int, buffer Buffer, offset, size int) {
+	c.call("bindBufferRange", target, index, buffer, offset, size)
+}
+
+// Returns the value of an indexed parameter, such as UNIFORM_BUFFER_BINDING.
+func (c *Context2) GetIndexedParameter(target Enum, index int) js.Value {
+	return c.call("getIndexedParameter", target, index)
+}
+
+// Returns the value of an indexed parameter which is interpreted as an
+// int, such as UNIFORM_BUFFER_START or UNIFORM_BUFFER_SIZE.
+func (c *Context2) GetIndexedParameteri(target Enum, index int) int {
+	return c.call("getIndexedParameter", target, index).Int()
+}
+
+// Returns the buffer bound to an indexed binding point, such as
+// UNIFORM_BUFFER_BINDING or TRANSFORM_FEEDBACK_BUFFER_BINDING, or nil if
+// none is bound. The result can be wrapped in a Buffer.
+func (c *Context2) GetIndexedParameterObject(target Enum, index int) Object {
+	return c.reg.object(c.call("getIndexedParameter", target, index))
+}
+
+// INVALID_INDEX is the index of a uniform or uniform block that is not
+// active. It is passed to WebGL as the GLuint 0xFFFFFFFF.
+const INVALID_INDEX = -1
+
+// Returns the indices of the named uniforms in program. Uniforms that
+// are not active get INVALID_INDEX.
+func (c *Context2) GetUniformIndices(program Program, names []string) []int {
+	return indexSlice(c.call("getUniformIndices", program, stringArray(names)))
+}
+
+// Returns a parameter for each of the uniforms at indices in program.
+// Boolean parameters such as UNIFORM_IS_ROW_MAJOR are returned as 0 or 1.
+func (c *Context2) GetActiveUniforms(program Program, indices []int, pname Enum) []int {
+	arr := c.call("getActiveUniforms", program, jsArray(indices, func(i int) interface{} { return i }), pname)
+	values := make([]int, arr.Length())
+	for i := range values {
+		v := arr.Index(i)
+		if v.Type() == js.TypeBoolean {
+			if v.Bool() {
+				values[i] = 1
+			}
+			continue
+		}
+		values[i] = v.Int()
+	}
+	return values
+}
+
+// Returns the index of the named uniform block in program, or INVALID_INDEX.
+func (c *Context2) GetUniformBlockIndex(program Program, name string) int {
+	return uniformIndex(c.call("getUniformBlockIndex", program, name))
+}
+
+// Returns a uniform block parameter which is interpreted as an int,
+// such as UNIFORM_BLOCK_DATA_SIZE.
+func (c *Context2) GetActiveUniformBlockParameteri(program Program, index int, pname Enum) int {
+	return c.call("getActiveUniformBlockParameter", program, index, pname).Int()
+}
+
+// Returns a uniform block parameter which is interpreted as a bool,
+// such as UNIFORM_BLOCK_REFERENCED_BY_VERTEX_SHADER.
+func (c *Context2) GetActiveUniformBlockParameterb(program Program, index int, pname Enum) bool {
+	return c.call("getActiveUniformBlockParameter", program, index, pname).Bool()
+}
+
+// Returns the indices of the active uniforms of a uniform block.
+func (c *Context2) GetActiveUniformBlockUniformIndices(program Program, index int) []int {
+	return indexSlice(c.call("getActiveUniformBlockParameter", program, index, UNIFORM_BLOCK_ACTIVE_UNIFORM_INDICES))
+}
+
+// Returns the name of the uniform block at index in program.
+func (c *Context2) GetActiveUniformBlockName(program Program, index int) string {
+	return c.call("getActiveUniformBlockName", program, index).String()
+}
+
+// Assigns the uniform block at blockIndex in program to a uniform buffer
+// binding point.
+func (c *Context2) UniformBlockBinding(program Program, blockIndex, blockBinding int) {
+	c.call("uniformBlockBinding", program, blockIndex, blockBinding)
+}
+
+// Returns a JavaScript Array of the given enums.
+func enumArray(values []Enum) js.Value {
+	return jsArray(values, func(e Enum) interface{} { return uint32(e) })
+}
+
+// Returns a uniform or uniform block index returned by WebGL, with the
+// GLuint 0xFFFFFFFF as INVALID_INDEX.
+func uniformIndex(v js.Value) int {
+	if v.Float() == math.MaxUint32 {
+		return INVALID_INDEX
+	}
+	return v.Int()
+}
+
+// Returns the elements of a JavaScript array of indices.
+func indexSlice(arr js.Value) []int {
+	values := make([]int, arr.Length())
+	for i := range values {
+		values[i] = uniformIndex(arr.Index(i))
+	}
+	return values
+}
+
+// Returns a JavaScript Array of the given strings.
+func stringArray(values []string) js.Value {
+	return jsArray(values, func(s string) interface{} { return s })
+}
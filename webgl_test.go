@@ -0,0 +1,710 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !(js && wasm)
+
+package webgl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/n2d/webgl/internal/js"
+)
+
+// Creates a context over a fake WebGL 1 context.
+func newFakeContext(t *testing.T) (*Context, *js.WebGL, *js.Canvas) {
+	t.Helper()
+	fake := js.NewWebGL(1)
+	canvas := js.NewCanvas(fake)
+	gl, err := NewContext(canvas.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gl, fake, canvas
+}
+
+// Returns the last call made to a method of the fake, failing the test if
+// there is none.
+func lastCall(t *testing.T, fake *js.WebGL, method string) js.Call {
+	t.Helper()
+	calls := fake.CallsTo(method)
+	if len(calls) == 0 {
+		t.Fatalf("%s was not called", method)
+	}
+	return calls[len(calls)-1]
+}
+
+// Returns the contents of a Uint8Array.
+func arrayBytes(v js.Value) []byte {
+	b := make([]byte, v.Length())
+	for i := range b {
+		b[i] = byte(v.Index(i).Int())
+	}
+	return b
+}
+
+// Returns the calls made to the given methods of the fake, formatted as
+// method(args) with number, boolean and null arguments, and clears them.
+func takeCalls(fake *js.WebGL, methods ...string) []string {
+	var calls []string
+	for _, call := range fake.Calls {
+		for _, m := range methods {
+			if call.Method != m {
+				continue
+			}
+			args := make([]string, len(call.Args))
+			for i, arg := range call.Args {
+				switch arg.Type() {
+				case js.TypeNumber:
+					args[i] = fmt.Sprint(arg.Float())
+				case js.TypeBoolean:
+					args[i] = fmt.Sprint(arg.Bool())
+				case js.TypeNull:
+					args[i] = "null"
+				default:
+					args[i] = arg.Type().String()
+				}
+			}
+			calls = append(calls, call.Method+"("+strings.Join(args, ", ")+")")
+		}
+	}
+	fake.Calls = nil
+	return calls
+}
+
+func TestNewContextWithAttributes(t *testing.T) {
+	fake := js.NewWebGL(1)
+	canvas := js.NewCanvas(fake)
+	attrs := DefaultAttributes()
+	attrs.Stencil = true
+	attrs.Antialias = false
+	attrs.PowerPreference = PowerPreferenceHighPerformance
+	gl, err := NewContextWithAttributes(canvas.Value, attrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gl.Value.Equal(fake.Value) {
+		t.Fatal("got a different context than the canvas returned")
+	}
+	requested := canvas.Attributes
+	for name, want := range map[string]interface{}{
+		"alpha":           true,
+		"stencil":         true,
+		"antialias":       false,
+		"powerPreference": "high-performance",
+	} {
+		if got := requested.Get(name); !got.Equal(js.ValueOf(want)) {
+			t.Errorf("requested %s %v, want %v", name, got, want)
+		}
+	}
+
+	fake.Attributes["stencil"] = true
+	fake.Attributes["antialias"] = false
+	fake.Attributes["powerPreference"] = "low-power"
+	fake.Attributes["desynchronized"] = true
+	got := gl.GetContextAttributes()
+	want := *DefaultAttributes()
+	want.Stencil = true
+	want.Antialias = false
+	want.PowerPreference = PowerPreferenceLowPower
+	want.Desynchronized = true
+	if got != want {
+		t.Errorf("got attributes %+v, want %+v", got, want)
+	}
+}
+
+func TestNewContextDefaults(t *testing.T) {
+	canvas := js.NewCanvas(js.NewWebGL(1))
+	if _, err := NewContext(canvas.Value); err != nil {
+		t.Fatal(err)
+	}
+	if !canvas.Attributes.IsUndefined() {
+		t.Errorf("requested attributes %v, want none", canvas.Attributes)
+	}
+	if _, err := NewContext(js.NewCanvas().Value); err == nil {
+		t.Error("created a context on a canvas without one")
+	}
+}
+
+func TestFrameBufferRenderBuffer(t *testing.T) {
+	gl, fake, _ := newFakeContext(t)
+	fb := gl.CreateFramebuffer()
+	rb := gl.CreateRenderbuffer()
+	gl.BindFramebuffer(FRAMEBUFFER, fb)
+	gl.FrameBufferRenderBuffer(FRAMEBUFFER, DEPTH_ATTACHMENT, RENDERBUFFER, rb)
+	call := lastCall(t, fake, "framebufferRenderbuffer")
+	for i, want := range []Enum{FRAMEBUFFER, DEPTH_ATTACHMENT, RENDERBUFFER} {
+		if got := Enum(call.Args[i].Int()); got != want {
+			t.Errorf("argument %d is 0x%04X, want 0x%04X", i, uint32(got), uint32(want))
+		}
+	}
+	if !call.Args[3].Equal(rb.Object.(js.Value)) {
+		t.Errorf("got renderbuffer %v, want the created one", call.Args[3])
+	}
+
+	gl.FrameBufferRenderBuffer(FRAMEBUFFER, DEPTH_ATTACHMENT, RENDERBUFFER, Renderbuffer{})
+	if call := lastCall(t, fake, "framebufferRenderbuffer"); !call.Args[3].IsNull() {
+		t.Errorf("got renderbuffer %v for a nil handle, want null", call.Args[3])
+	}
+}
+
+func TestGetSupportedExtensions(t *testing.T) {
+	gl, fake, _ := newFakeContext(t)
+	if got := gl.GetSupportedExtensions(); len(got) != 0 {
+		t.Errorf("got extensions %v, want none", got)
+	}
+	fake.Extensions = []string{"OES_texture_float", "WEBGL_lose_context"}
+	got := gl.GetSupportedExtensions()
+	if strings.Join(got, " ") != "OES_texture_float WEBGL_lose_context" {
+		t.Errorf("got extensions %v, want %v", got, fake.Extensions)
+	}
+}
+
+// Returns the bytes of a slice of fixed size numbers, as the typed
+// uploads send them.
+func testBytes[T int8 | uint8 | int16 | uint16 | int32 | uint32 | float32](s ...T) []byte {
+	return append([]byte(nil), unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))...)
+}
+
+func TestBufferDataTyped(t *testing.T) {
+	tests := []struct {
+		name   string
+		upload func(gl *Context)
+		want   []byte
+	}{
+		{"Bytes", func(gl *Context) { gl.BufferDataBytes(ARRAY_BUFFER, []byte{1, 2, 3}, STATIC_DRAW) }, []byte{1, 2, 3}},
+		{"Int8", func(gl *Context) { gl.BufferDataInt8(ARRAY_BUFFER, []int8{-1, 2}, STATIC_DRAW) }, testBytes[int8](-1, 2)},
+		{"Int16", func(gl *Context) { gl.BufferDataInt16(ARRAY_BUFFER, []int16{-300, 2}, STATIC_DRAW) }, testBytes[int16](-300, 2)},
+		{"Uint16", func(gl *Context) { gl.BufferDataUint16(ARRAY_BUFFER, []uint16{0, 1, 65535}, STATIC_DRAW) }, testBytes[uint16](0, 1, 65535)},
+		{"Int32", func(gl *Context) { gl.BufferDataInt32(ARRAY_BUFFER, []int32{-70000}, STATIC_DRAW) }, testBytes[int32](-70000)},
+		{"Uint32", func(gl *Context) { gl.BufferDataUint32(ARRAY_BUFFER, []uint32{1 << 31}, STATIC_DRAW) }, testBytes[uint32](1 << 31)},
+		{"Float32", func(gl *Context) { gl.BufferDataFloat32(ARRAY_BUFFER, []float32{0.5, -2}, STATIC_DRAW) }, testBytes[float32](0.5, -2)},
+		{"slice", func(gl *Context) { gl.BufferData(ARRAY_BUFFER, []uint16{7, 8}, STATIC_DRAW) }, testBytes[uint16](7, 8)},
+		{"float64", func(gl *Context) { gl.BufferData(ARRAY_BUFFER, []float64{0.5, -2}, STATIC_DRAW) }, testBytes[float32](0.5, -2)},
+		{"int", func(gl *Context) { gl.BufferData(ARRAY_BUFFER, []int{-1, 2}, STATIC_DRAW) }, testBytes[int32](-1, 2)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gl, fake, _ := newFakeContext(t)
+			gl.BindBuffer(ARRAY_BUFFER, gl.CreateBuffer())
+			test.upload(gl)
+			call := lastCall(t, fake, "bufferData")
+			if got := Enum(call.Args[2].Int()); got != STATIC_DRAW {
+				t.Errorf("got usage 0x%04X", uint32(got))
+			}
+			if got := arrayBytes(call.Args[1]); !bytes.Equal(got, test.want) {
+				t.Errorf("uploaded %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBufferSubDataTyped(t *testing.T) {
+	gl, fake, _ := newFakeContext(t)
+	gl.BindBuffer(ARRAY_BUFFER, gl.CreateBuffer())
+	gl.BufferData(ARRAY_BUFFER, 16, DYNAMIC_DRAW)
+	if got := lastCall(t, fake, "bufferData").Args[1]; got.Int() != 16 {
+		t.Errorf("got size %v, want 16", got)
+	}
+	gl.BufferSubDataFloat32(ARRAY_BUFFER, 4, []float32{1.5})
+	call := lastCall(t, fake, "bufferSubData")
+	if call.Args[1].Int() != 4 || !bytes.Equal(arrayBytes(call.Args[2]), testBytes[float32](1.5)) {
+		t.Errorf("got %v", call)
+	}
+}
+
+func TestBufferDataUnsupported(t *testing.T) {
+	gl, _, _ := newFakeContext(t)
+	defer func() {
+		want := "webgl: BufferData cannot upload data of type []string"
+		if r := recover(); fmt.Sprint(r) != want {
+			t.Errorf("got panic %v, want %q", r, want)
+		}
+	}()
+	gl.BufferData(ARRAY_BUFFER, []string{"a"}, STATIC_DRAW)
+}
+
+func TestContextLostRestored(t *testing.T) {
+	gl, fake, canvas := newFakeContext(t)
+	var events []string
+	gl.OnContextLost(func() { events = append(events, "lost") })
+	gl.OnContextRestored(func() { events = append(events, "restored") })
+
+	buf := gl.CreateBuffer()
+	gl.BindBuffer(ARRAY_BUFFER, buf)
+	gl.BufferDataUint16(ARRAY_BUFFER, []uint16{1, 2}, STATIC_DRAW)
+	old := objectValue(buf)
+
+	if !canvas.Lose() {
+		t.Error("the loss event was not prevented")
+	}
+	if gl.IsBuffer(buf) {
+		t.Error("buffer is valid on a lost context")
+	}
+	fake.Calls = nil
+	canvas.Restore()
+	if strings.Join(events, " ") != "lost restored" {
+		t.Errorf("got events %v", events)
+	}
+	if v := objectValue(buf); v.Equal(old) || !gl.IsBuffer(buf) {
+		t.Error("buffer was not re-created")
+	}
+	if got := arrayBytes(lastCall(t, fake, "bufferData").Args[1]); !bytes.Equal(got, testBytes[uint16](1, 2)) {
+		t.Errorf("restored buffer data %v", got)
+	}
+}
+
+func TestBufferSizeRestored(t *testing.T) {
+	for _, size := range []interface{}{int(3), int32(3), int64(3), uint(3), uint32(3), uint64(3)} {
+		gl, fake, canvas := newFakeContext(t)
+		gl.OnContextLost(nil)
+		gl.BindBuffer(ARRAY_BUFFER, gl.CreateBuffer())
+		gl.BufferData(ARRAY_BUFFER, size, STATIC_DRAW)
+		if arg := lastCall(t, fake, "bufferData").Args[1]; arg.Int() != 3 {
+			t.Errorf("%T size was passed as %v", size, arg)
+		}
+		canvas.Lose()
+		fake.Calls = nil
+		canvas.Restore()
+		if got := arrayBytes(lastCall(t, fake, "bufferData").Args[1]); !bytes.Equal(got, make([]byte, 3)) {
+			t.Errorf("buffer of %T size restored with data %v", size, got)
+		}
+	}
+}
+
+func TestDrawBuffersLimits(t *testing.T) {
+	fake := js.NewWebGL(2)
+	canvas := js.NewCanvas(fake)
+	gl, err := NewContext2(canvas.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gl.OnContextLost(nil)
+	fake.Parameters[uint32(MAX_DRAW_BUFFERS)] = 4
+	fake.Parameters[uint32(MAX_COLOR_ATTACHMENTS)] = 4
+	for i := 0; i < 3; i++ {
+		if err := gl.DrawBuffers([]Enum{COLOR_ATTACHMENT0, COLOR_ATTACHMENT1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gl.MaxDrawBuffers() != 4 || gl.MaxColorAttachments() != 4 {
+		t.Errorf("got limits %d and %d, want 4", gl.MaxDrawBuffers(), gl.MaxColorAttachments())
+	}
+	if n := len(fake.CallsTo("getParameter")); n != 2 {
+		t.Errorf("read the limits %d times, want 2", n)
+	}
+
+	canvas.Lose()
+	fake.Parameters[uint32(MAX_DRAW_BUFFERS)] = 8
+	canvas.Restore()
+	if got := gl.MaxDrawBuffers(); got != 8 {
+		t.Errorf("got %d draw buffers after a restore, want 8", got)
+	}
+}
+
+func TestDebugSharesState(t *testing.T) {
+	gl, _, _ := newFakeContext(t)
+	var errs []*CallError
+	dbg := gl.Debug(func(err *CallError) { errs = append(errs, err) })
+	va := gl.CreateVertexArray()
+	dbg.BindVertexArray(va)
+	dbg.DeleteVertexArray(va)
+	if dbg.IsVertexArray(va) || gl.IsVertexArray(va) {
+		t.Error("vertex array deleted through a debug copy is still valid")
+	}
+	if len(errs) > 0 {
+		t.Errorf("got errors %v", errs)
+	}
+}
+
+func TestDeleteVertexArrayWithoutEmulation(t *testing.T) {
+	gl, _, _ := newFakeContext(t)
+	other, _, _ := newFakeContext(t)
+	va := gl.CreateVertexArray()
+	other.DeleteVertexArray(va)
+	if !gl.IsVertexArray(va) {
+		t.Error("vertex array deleted through a context that did not create it")
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	gl, fake, _ := newFakeContext(t)
+	fake.Extensions = []string{"OES_vertex_array_object"}
+	gl.OnContextLost(nil)
+	oes := gl.GetExtension("OES_vertex_array_object")
+	buffer := gl.CreateBuffer()
+	var errs []*CallError
+	dbg := gl.Debug(func(err *CallError) { errs = append(errs, err) })
+	fake.Errors = []uint32{uint32(INVALID_OPERATION)}
+	dbg.Invoke(oes, "bindVertexArrayOES", buffer)
+	if got := lastCall(t, fake, "bindVertexArrayOES").Args[0]; !got.Equal(objectValue(buffer)) {
+		t.Errorf("passed %v for a tracked object", got)
+	}
+	if len(errs) != 1 || errs[0].Err != ErrInvalidOperation || errs[0].Method != "bindVertexArrayOES" {
+		t.Errorf("got errors %v, want an invalid operation in bindVertexArrayOES", errs)
+	}
+}
+
+func TestContextLossStop(t *testing.T) {
+	gl, _, canvas := newFakeContext(t)
+	lost := 0
+	stop := gl.OnContextLost(func() { lost++ })
+	if stop2 := gl.OnContextRestored(nil); stop2 == nil {
+		t.Fatal("OnContextRestored returned no stop function")
+	}
+	canvas.Lose()
+	canvas.Restore()
+	stop()
+	stop()
+	if canvas.Lose() {
+		t.Error("the loss event was prevented after stop")
+	}
+	if lost != 1 {
+		t.Errorf("lost called %d times, want 1", lost)
+	}
+
+	// Registering again adds new listeners, which an old stop function
+	// leaves alone.
+	gl.OnContextLost(func() { lost++ })
+	stop()
+	if !canvas.Dispatch("webglcontextlost") || lost != 2 {
+		t.Errorf("got prevented false or %d losses after registering again", lost)
+	}
+}
+
+func TestTrackedObjectLookup(t *testing.T) {
+	gl, fake, _ := newFakeContext(t)
+	gl.OnContextLost(nil)
+	buffers := []Buffer{gl.CreateBuffer(), gl.CreateBuffer(), gl.CreateBuffer()}
+	for _, b := range buffers {
+		fake.Parameters[uint32(ARRAY_BUFFER_BINDING)] = objectValue(b)
+		if got := gl.GetParameterObject(ARRAY_BUFFER_BINDING); got != b.Object {
+			t.Errorf("got %v for tracked buffer %v", got, b.Object)
+		}
+	}
+	v := objectValue(buffers[1])
+	gl.DeleteBuffer(buffers[1])
+	fake.Parameters[uint32(ARRAY_BUFFER_BINDING)] = v
+	if got, ok := gl.GetParameterObject(ARRAY_BUFFER_BINDING).(js.Value); !ok || !got.Equal(v) {
+		t.Errorf("got %v for a deleted buffer, want its JavaScript object", got)
+	}
+}
+
+func TestTextureMemory(t *testing.T) {
+	fake := js.NewWebGL(2)
+	gl, err := NewContext2(js.NewCanvas(fake).Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gl.TrackObjects()
+	tests := []struct {
+		name   string
+		target Enum
+		load   func()
+		want   int
+	}{
+		{"TexImage2DPixels RG", TEXTURE_2D, func() {
+			gl.TexImage2DPixels(TEXTURE_2D, 0, RG8, 4, 4, 0, RG, UNSIGNED_BYTE, nil)
+		}, 4 * 4 * 2},
+		{"TexImage3D", TEXTURE_3D, func() {
+			gl.TexImage3D(TEXTURE_3D, 0, RGBA8, 4, 4, 2, 0, RGBA, UNSIGNED_BYTE, nil)
+		}, 4 * 4 * 2 * 4},
+		{"TexStorage2D", TEXTURE_2D, func() {
+			gl.TexStorage2D(TEXTURE_2D, 3, RG16F, 4, 2)
+		}, (4*2 + 2*1 + 1*1) * 4},
+		{"TexStorage2D cube", TEXTURE_CUBE_MAP, func() {
+			gl.TexStorage2D(TEXTURE_CUBE_MAP, 2, RGBA8, 2, 2)
+		}, 6 * (2*2 + 1) * 4},
+		{"TexStorage3D", TEXTURE_3D, func() {
+			gl.TexStorage3D(TEXTURE_3D, 2, R8, 4, 4, 4)
+		}, 4*4*4 + 2*2*2},
+		{"TexStorage3D array", TEXTURE_2D_ARRAY, func() {
+			gl.TexStorage3D(TEXTURE_2D_ARRAY, 2, RGB8, 2, 2, 3)
+		}, (2*2*3 + 1*1*3) * 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			texture := gl.CreateTexture()
+			defer gl.DeleteTexture(texture)
+			gl.BindTexture(test.target, texture)
+			test.load()
+			if got := gl.MemoryEstimate(); got != test.want {
+				t.Errorf("got an estimate of %d bytes, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUniformIndices(t *testing.T) {
+	fake := js.NewWebGL(2)
+	gl, err := NewContext2(js.NewCanvas(fake).Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.Results["getUniformIndices"] = func([]js.Value) interface{} {
+		return []interface{}{2, 0xFFFFFFFF}
+	}
+	fake.Results["getUniformBlockIndex"] = func([]js.Value) interface{} { return 0xFFFFFFFF }
+	fake.Results["getActiveUniforms"] = func([]js.Value) interface{} { return []interface{}{16} }
+	p := gl.CreateProgram()
+	indices := gl.GetUniformIndices(p, []string{"u_a", "u_missing"})
+	if len(indices) != 2 || indices[0] != 2 || indices[1] != INVALID_INDEX {
+		t.Errorf("got indices %v, want [2 %d]", indices, INVALID_INDEX)
+	}
+	if got := gl.GetUniformBlockIndex(p, "Missing"); got != INVALID_INDEX {
+		t.Errorf("got block index %d, want INVALID_INDEX", got)
+	}
+	if got := gl.GetActiveUniforms(p, indices[:1], UNIFORM_OFFSET); len(got) != 1 || got[0] != 16 {
+		t.Errorf("got offsets %v, want [16]", got)
+	}
+	if got := lastCall(t, fake, "getActiveUniforms").Args[1]; got.Length() != 1 || got.Index(0).Int() != 2 {
+		t.Errorf("passed indices %v", got)
+	}
+}
+
+func TestParseShaderLog(t *testing.T) {
+	src := "void main() {\n\tfoo = 1;\n\tgl_Position = vec4(0.0);\n}"
+	tests := []struct {
+		name string
+		log  string
+		want []ShaderLogEntry
+	}{{
+		name: "ANGLE",
+		log:  "ERROR: 0:2: 'foo' : undeclared identifier\nWARNING: 0:3: 'x' : unused\x00",
+		want: []ShaderLogEntry{
+			{Severity: "ERROR", Line: 2, Source: "\tfoo = 1;", Message: "'foo' : undeclared identifier"},
+			{Severity: "WARNING", Line: 3, Source: "\tgl_Position = vec4(0.0);", Message: "'x' : unused"},
+		},
+	}, {
+		name: "Mesa",
+		log:  "0:2(2): error: `foo' undeclared\r\n",
+		want: []ShaderLogEntry{
+			{Severity: "ERROR", Line: 2, Source: "\tfoo = 1;", Message: "`foo' undeclared"},
+		},
+	}, {
+		name: "line out of range",
+		log:  "ERROR: 0:9: '' : syntax error",
+		want: []ShaderLogEntry{{Severity: "ERROR", Line: 9, Message: "'' : syntax error"}},
+	}, {
+		name: "unparseable",
+		log:  "  Compilation failed.  \n\nout of memory",
+		want: []ShaderLogEntry{{Message: "Compilation failed."}, {Message: "out of memory"}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := parseShaderLog(test.log, src)
+			if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInstancedRouting(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    int
+		extensions []string
+		suffix     string
+	}{
+		{"WebGL 2", 2, nil, ""},
+		{"ANGLE", 1, []string{"ANGLE_instanced_arrays"}, "ANGLE"},
+		{"unsupported", 1, nil, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := js.NewWebGL(test.version)
+			fake.Extensions = test.extensions
+			var gl *Context
+			var err error
+			if test.version == 2 {
+				var gl2 *Context2
+				gl2, err = NewContext2(js.NewCanvas(fake).Value)
+				if gl2 != nil {
+					gl = gl2.Context
+				}
+			} else {
+				gl, err = NewContext(js.NewCanvas(fake).Value)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			fake.Calls = nil
+			errs := []error{
+				gl.VertexAttribDivisor(1, 2),
+				gl.DrawArraysInstanced(TRIANGLES, 0, 3, 4),
+				gl.DrawElementsInstanced(TRIANGLES, 6, UNSIGNED_SHORT, 0, 4),
+			}
+			calls := takeCalls(fake, "vertexAttribDivisor", "drawArraysInstanced", "drawElementsInstanced",
+				"vertexAttribDivisorANGLE", "drawArraysInstancedANGLE", "drawElementsInstancedANGLE")
+
+			if test.name == "unsupported" {
+				for _, err := range errs {
+					var unsupported *UnsupportedError
+					if !errors.As(err, &unsupported) || unsupported.Extension != "ANGLE_instanced_arrays" {
+						t.Errorf("got error %v, want an *UnsupportedError for ANGLE_instanced_arrays", err)
+					}
+				}
+				if len(calls) > 0 {
+					t.Errorf("made calls %v", calls)
+				}
+				return
+			}
+			for _, err := range errs {
+				if err != nil {
+					t.Error(err)
+				}
+			}
+			want := []string{
+				"vertexAttribDivisor" + test.suffix + "(1, 2)",
+				"drawArraysInstanced" + test.suffix + "(4, 0, 3, 4)",
+				"drawElementsInstanced" + test.suffix + "(4, 6, 5123, 0, 4)",
+			}
+			if strings.Join(calls, " ") != strings.Join(want, " ") {
+				t.Errorf("got calls %v, want %v", calls, want)
+			}
+		})
+	}
+}
+
+func TestBindInstances(t *testing.T) {
+	gl, fake, _ := newFakeContext(t)
+	fake.Extensions = []string{"ANGLE_instanced_arrays"}
+	attribs := []map[string]interface{}{
+		{"name": "a_offset", "type": uint32(FLOAT_VEC2), "size": 1},
+		{"name": "a_color", "type": uint32(FLOAT_VEC4), "size": 1},
+		{"name": "a_position", "type": uint32(FLOAT_VEC3), "size": 1},
+	}
+	locations := map[string]int{"a_offset": 3, "a_color": 5, "a_position": 0}
+	fake.Results["getProgramParameter"] = func(args []js.Value) interface{} {
+		if Enum(args[1].Int()) == ACTIVE_ATTRIBUTES {
+			return len(attribs)
+		}
+		return 0
+	}
+	fake.Results["getActiveAttrib"] = func(args []js.Value) interface{} { return attribs[args[1].Int()] }
+	fake.Results["getAttribLocation"] = func(args []js.Value) interface{} { return locations[args[1].String()] }
+	info := GetProgramInfo(gl, gl.CreateProgram())
+
+	type instance struct {
+		Offset [2]float32 `gl:"a_offset"`
+		Color  [4]uint8   `gl:"a_color,normalized"`
+	}
+	l, err := NewVertexLayout(instance{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gl.BindBuffer(ARRAY_BUFFER, gl.CreateBuffer())
+	fake.Calls = nil
+	instances := []instance{{[2]float32{1, 2}, [4]uint8{255, 0, 0, 255}}, {[2]float32{3, 4}, [4]uint8{0, 0, 255, 128}}}
+	if err := l.BindInstances(gl, info, instances, DYNAMIC_DRAW); err != nil {
+		t.Fatal(err)
+	}
+
+	want := append(testBytes[float32](1, 2), 255, 0, 0, 255)
+	want = append(append(want, testBytes[float32](3, 4)...), 0, 0, 255, 128)
+	if got := arrayBytes(lastCall(t, fake, "bufferData").Args[1]); !bytes.Equal(got, want) {
+		t.Errorf("uploaded %v, want %v", got, want)
+	}
+	calls := takeCalls(fake, "vertexAttribPointer", "enableVertexAttribArray", "vertexAttribDivisorANGLE")
+	wantCalls := []string{
+		"vertexAttribPointer(3, 2, 5126, false, 12, 0)",
+		"enableVertexAttribArray(3)",
+		"vertexAttribDivisorANGLE(3, 1)",
+		"vertexAttribPointer(5, 4, 5121, true, 12, 8)",
+		"enableVertexAttribArray(5)",
+		"vertexAttribDivisorANGLE(5, 1)",
+	}
+	if strings.Join(calls, "\n") != strings.Join(wantCalls, "\n") {
+		t.Errorf("got calls\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(wantCalls, "\n"))
+	}
+}
+
+func TestEmulatedVertexArrays(t *testing.T) {
+	gl, fake, _ := newFakeContext(t)
+	a, b := gl.CreateVertexArray(), gl.CreateVertexArray()
+	positions, uvs, current, indices := gl.CreateBuffer(), gl.CreateBuffer(), gl.CreateBuffer(), gl.CreateBuffer()
+
+	gl.BindVertexArray(a)
+	gl.BindBuffer(ARRAY_BUFFER, positions)
+	gl.VertexAttribPointer(0, 3, FLOAT, false, 12, 0)
+	gl.EnableVertexAttribArray(0)
+	gl.BindBuffer(ELEMENT_ARRAY_BUFFER, indices)
+	var unsupported *UnsupportedError
+	if err := gl.VertexAttribDivisor(0, 1); !errors.As(err, &unsupported) {
+		t.Errorf("got error %v setting a divisor without instancing", err)
+	}
+	gl.BindVertexArray(b)
+	gl.BindBuffer(ARRAY_BUFFER, uvs)
+	gl.VertexAttribPointer(1, 2, FLOAT, true, 8, 4)
+	gl.EnableVertexAttribArray(1)
+	gl.BindBuffer(ARRAY_BUFFER, current)
+
+	methods := []string{"bindBuffer", "vertexAttribPointer", "enableVertexAttribArray", "disableVertexAttribArray",
+		"vertexAttribDivisor", "vertexAttribDivisorANGLE"}
+	steps := []struct {
+		name    string
+		array   VertexArray
+		calls   []string
+		buffers []Buffer
+	}{{
+		name:  "a",
+		array: a,
+		calls: []string{
+			"bindBuffer(34963, object)",
+			"bindBuffer(34962, object)",
+			"vertexAttribPointer(0, 3, 5126, false, 12, 0)",
+			"enableVertexAttribArray(0)",
+			"disableVertexAttribArray(1)",
+			"bindBuffer(34962, object)",
+		},
+		buffers: []Buffer{indices, positions, current},
+	}, {
+		name:  "b",
+		array: b,
+		calls: []string{
+			"bindBuffer(34963, null)",
+			"disableVertexAttribArray(0)",
+			"bindBuffer(34962, object)",
+			"vertexAttribPointer(1, 2, 5126, true, 8, 4)",
+			"enableVertexAttribArray(1)",
+			"bindBuffer(34962, object)",
+		},
+		buffers: []Buffer{{}, uvs, current},
+	}, {
+		name: "default",
+		calls: []string{
+			"bindBuffer(34963, null)",
+			"disableVertexAttribArray(0)",
+			"disableVertexAttribArray(1)",
+		},
+		buffers: []Buffer{{}},
+	}}
+	fake.Calls = nil
+	for _, step := range steps {
+		gl.BindVertexArray(step.array)
+		var bound []js.Value
+		for _, call := range fake.CallsTo("bindBuffer") {
+			bound = append(bound, call.Args[1])
+		}
+		calls := takeCalls(fake, methods...)
+		if strings.Join(calls, "\n") != strings.Join(step.calls, "\n") {
+			t.Errorf("binding %s made calls\n%s\nwant\n%s", step.name, strings.Join(calls, "\n"), strings.Join(step.calls, "\n"))
+			continue
+		}
+		for i, buffer := range step.buffers {
+			if want := objectValue(buffer); !bound[i].Equal(want) {
+				t.Errorf("binding %s bound buffer %d to %v, want %v", step.name, i, bound[i], want)
+			}
+		}
+	}
+	if got := objectValue(gl.vao.arrayBuffer); !got.Equal(objectValue(current)) {
+		t.Errorf("recorded ARRAY_BUFFER binding %v after the replays, want the bound buffer", got)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package webgltest compares rendered images against golden PNG files,
+// for testing rendering code with go test.
+//
+// Check renders a scene into an offscreen framebuffer of any
+// webgl.RenderingContext, such as one from soft.New, and compares it with
+// testdata/<name>.png:
+//
+//	func TestScene(t *testing.T) {
+//		gl := soft.New(64, 64, nil)
+//		webgltest.Check(t, gl, "scene", 64, 64, nil, func() {
+//			drawScene(gl)
+//		})
+//	}
+//
+// Colors may differ by a per-channel tolerance, and a ratio of the pixels
+// may differ by more. When the images do not match, the rendered image
+// and a heat map of the differences are written next to the golden file
+// as <name>.actual.png and <name>.diff.png. Running go test with the
+// -update flag writes the rendered images as the new golden files.
+package webgltest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/n2d/webgl"
+)
+
+var update = flag.Bool("update", false, "write rendered images as the new golden files")
+
+// Options control how images are compared. The zero value requires
+// identical images.
+type Options struct {
+	// Tolerance is the largest difference between two color or alpha
+	// values that is not counted as a difference.
+	Tolerance uint8
+
+	// MaxDiffRatio is the largest ratio of differing pixels, between 0
+	// and 1, for the images to match.
+	MaxDiffRatio float64
+
+	// Dir is the directory of the golden files. It defaults to
+	// "testdata".
+	Dir string
+}
+
+func (o *Options) dir() string {
+	if o == nil || o.Dir == "" {
+		return "testdata"
+	}
+	return o.Dir
+}
+
+// Diff is the result of comparing two images of the same size.
+type Diff struct {
+	// Pixels is the number of pixels compared, and Differing the number
+	// of them with a channel that differs by more than the tolerance.
+	Pixels    int
+	Differing int
+
+	// MaxDelta is the largest difference of a channel.
+	MaxDelta uint8
+
+	// Image is a heat map of the differences. Matching pixels are a
+	// darkened gray of the expected image, pixels within the tolerance
+	// are blue, and the others go from yellow to red as the difference
+	// grows.
+	Image *image.NRGBA
+}
+
+// Returns the ratio of differing pixels.
+func (d *Diff) Ratio() float64 {
+	if d.Pixels == 0 {
+		return 0
+	}
+	return float64(d.Differing) / float64(d.Pixels)
+}
+
+// Renders a scene into an offscreen framebuffer of the given size with a
+// depth buffer, and returns its pixels. draw is called with the
+// framebuffer bound and the viewport covering it. The previous
+// framebuffer binding is restored afterwards, but not the viewport.
+//
+// The pixels are returned as stored by draw, without alpha
+// premultiplication.
+func Render(gl webgl.RenderingContext, width, height int, draw func()) (*image.NRGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("webgltest: cannot render %dx%d image", width, height)
+	}
+	previous := webgl.Framebuffer{Object: gl.GetParameterObject(webgl.FRAMEBUFFER_BINDING)}
+
+	tex := gl.CreateTexture()
+	gl.BindTexture(webgl.TEXTURE_2D, tex)
+	gl.TexParameteri(webgl.TEXTURE_2D, webgl.TEXTURE_MIN_FILTER, webgl.NEAREST)
+	gl.TexParameteri(webgl.TEXTURE_2D, webgl.TEXTURE_MAG_FILTER, webgl.NEAREST)
+	gl.TexImage2DPixels(webgl.TEXTURE_2D, 0, webgl.RGBA, width, height, 0, webgl.RGBA, webgl.UNSIGNED_BYTE, nil)
+	gl.BindTexture(webgl.TEXTURE_2D, webgl.Texture{})
+	depth := gl.CreateRenderbuffer()
+	gl.BindRenderbuffer(webgl.RENDERBUFFER, depth)
+	gl.RenderbufferStorage(webgl.RENDERBUFFER, webgl.DEPTH_COMPONENT16, width, height)
+	gl.BindRenderbuffer(webgl.RENDERBUFFER, webgl.Renderbuffer{})
+	fb := gl.CreateFramebuffer()
+	gl.BindFramebuffer(webgl.FRAMEBUFFER, fb)
+	gl.FramebufferTexture2D(webgl.FRAMEBUFFER, webgl.COLOR_ATTACHMENT0, webgl.TEXTURE_2D, tex, 0)
+	gl.FrameBufferRenderBuffer(webgl.FRAMEBUFFER, webgl.DEPTH_ATTACHMENT, webgl.RENDERBUFFER, depth)
+	defer func() {
+		gl.BindFramebuffer(webgl.FRAMEBUFFER, previous)
+		gl.DeleteFramebuffer(fb)
+		gl.DeleteRenderbuffer(depth)
+		gl.DeleteTexture(tex)
+	}()
+	if err := gl.CheckError(); err != nil {
+		return nil, fmt.Errorf("webgltest: cannot create framebuffer: %v", err)
+	}
+	if status := gl.CheckFramebufferStatus(webgl.FRAMEBUFFER); status != webgl.FRAMEBUFFER_COMPLETE {
+		return nil, fmt.Errorf("webgltest: incomplete framebuffer (status 0x%04X)", uint32(status))
+	}
+
+	gl.Viewport(0, 0, width, height)
+	draw()
+	if err := gl.CheckError(); err != nil {
+		return nil, fmt.Errorf("webgltest: rendering failed: %v", err)
+	}
+
+	pix := make([]byte, 4*width*height)
+	gl.ReadPixelsBytes(0, 0, width, height, webgl.RGBA, webgl.UNSIGNED_BYTE, pix)
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	stride := 4 * width
+	for y := 0; y < height; y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+stride], pix[(height-1-y)*stride:(height-y)*stride])
+	}
+	return img, nil
+}
+
+// Returns an image as a non-premultiplied image with its origin at (0, 0).
+func toNRGBA(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) {
+		return n
+	}
+	b := img.Bounds()
+	n := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(n, n.Rect, img, b.Min, draw.Src)
+	return n
+}
+
+// Compares an image with the expected one. It returns an error if their
+// sizes differ.
+func Compare(want, got image.Image, opts *Options) (*Diff, error) {
+	w, g := toNRGBA(want), toNRGBA(got)
+	if w.Rect != g.Rect {
+		return nil, fmt.Errorf("webgltest: image size is %dx%d, want %dx%d", g.Rect.Dx(), g.Rect.Dy(), w.Rect.Dx(), w.Rect.Dy())
+	}
+	var tolerance uint8
+	if opts != nil {
+		tolerance = opts.Tolerance
+	}
+	d := &Diff{Pixels: w.Rect.Dx() * w.Rect.Dy(), Image: image.NewNRGBA(w.Rect)}
+	for y := 0; y < w.Rect.Dy(); y++ {
+		for x := 0; x < w.Rect.Dx(); x++ {
+			i := w.PixOffset(x, y)
+			var delta uint8
+			for c := 0; c < 4; c++ {
+				a, b := w.Pix[i+c], g.Pix[i+c]
+				delta = max(delta, max(a, b)-min(a, b))
+			}
+			d.MaxDelta = max(d.MaxDelta, delta)
+			if delta > tolerance {
+				d.Differing++
+			}
+			d.Image.SetNRGBA(x, y, heat(w.Pix[i:i+4], delta, tolerance))
+		}
+	}
+	return d, nil
+}
+
+// Returns the heat map color of a pixel.
+func heat(want []byte, delta, tolerance uint8) color.NRGBA {
+	switch {
+	case delta == 0:
+		y := (299*uint32(want[0]) + 587*uint32(want[1]) + 114*uint32(want[2])) / 1000
+		y = y * uint32(want[3]) / 255 / 4
+		return color.NRGBA{uint8(y), uint8(y), uint8(y), 255}
+	case delta <= tolerance:
+		return color.NRGBA{0, 0, 255, 255}
+	}
+	return color.NRGBA{255, 255 - delta, 0, 255}
+}
+
+// Compares an image with the golden file testdata/<name>.png, failing the
+// test if they do not match. With the -update flag the image is written
+// as the golden file instead.
+func Golden(t testing.TB, name string, img image.Image, opts *Options) {
+	t.Helper()
+	path := filepath.Join(opts.dir(), name+".png")
+	if *update {
+		if err := writePNG(path, img); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("webgltest: wrote %s", path)
+		return
+	}
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("webgltest: %v (run go test -update to create it)", err)
+	}
+
+	actual := filepath.Join(opts.dir(), name+".actual.png")
+	d, err := Compare(want, img, opts)
+	if err != nil {
+		writePNG(actual, img)
+		t.Fatalf("%s: %v; wrote %s", path, err, actual)
+	}
+	var maxRatio float64
+	if opts != nil {
+		maxRatio = opts.MaxDiffRatio
+	}
+	if d.Ratio() <= maxRatio {
+		return
+	}
+	diff := filepath.Join(opts.dir(), name+".diff.png")
+	writePNG(actual, img)
+	writePNG(diff, d.Image)
+	t.Errorf("%s: %d of %d pixels differ (%.2f%%, max %.2f%%), largest difference %d; wrote %s and %s",
+		path, d.Differing, d.Pixels, 100*d.Ratio(), 100*maxRatio, d.MaxDelta, actual, diff)
+}
+
+// Renders a scene as Render does and compares it with the golden file
+// testdata/<name>.png as Golden does.
+func Check(t testing.TB, gl webgl.RenderingContext, name string, width, height int, opts *Options, draw func()) {
+	t.Helper()
+	img, err := Render(gl, width, height, draw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Golden(t, name, img, opts)
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
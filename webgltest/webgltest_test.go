@@ -0,0 +1,239 @@
+// Copyright 2014 Joseph Hager. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package webgltest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Returns a 1-pixel high image of the given colors.
+func row(colors ...color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(colors), 1))
+	for x, c := range colors {
+		img.SetNRGBA(x, 0, c)
+	}
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	want := row(color.NRGBA{100, 100, 100, 255}, color.NRGBA{0, 0, 0, 255}, color.NRGBA{200, 0, 0, 255})
+	tests := []struct {
+		name      string
+		got       *image.NRGBA
+		tolerance uint8
+		differing int
+		maxDelta  uint8
+		heat      []color.NRGBA
+	}{{
+		name:      "identical",
+		got:       want,
+		differing: 0,
+		heat:      []color.NRGBA{{25, 25, 25, 255}, {0, 0, 0, 255}, {14, 14, 14, 255}},
+	}, {
+		name:      "within tolerance",
+		got:       row(color.NRGBA{102, 99, 100, 255}, color.NRGBA{0, 0, 0, 255}, color.NRGBA{200, 0, 0, 253}),
+		tolerance: 2,
+		differing: 0,
+		maxDelta:  2,
+		heat:      []color.NRGBA{{0, 0, 255, 255}, {0, 0, 0, 255}, {0, 0, 255, 255}},
+	}, {
+		name:      "beyond tolerance",
+		got:       row(color.NRGBA{103, 100, 100, 255}, color.NRGBA{0, 255, 0, 255}, color.NRGBA{200, 0, 0, 255}),
+		tolerance: 2,
+		differing: 2,
+		maxDelta:  255,
+		heat:      []color.NRGBA{{255, 252, 0, 255}, {255, 0, 0, 255}, {14, 14, 14, 255}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := Compare(want, test.got, &Options{Tolerance: test.tolerance})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Pixels != 3 || d.Differing != test.differing || d.MaxDelta != test.maxDelta {
+				t.Errorf("got %d of %d pixels differing by up to %d, want %d of 3 by up to %d",
+					d.Differing, d.Pixels, d.MaxDelta, test.differing, test.maxDelta)
+			}
+			if got := float64(test.differing) / 3; d.Ratio() != got {
+				t.Errorf("got ratio %v, want %v", d.Ratio(), got)
+			}
+			for x, c := range test.heat {
+				if got := d.Image.NRGBAAt(x, 0); got != c {
+					t.Errorf("heat map pixel %d is %v, want %v", x, got, c)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareSize(t *testing.T) {
+	if _, err := Compare(row(color.NRGBA{}), row(color.NRGBA{}, color.NRGBA{}), nil); err == nil {
+		t.Error("compared images of different sizes")
+	}
+}
+
+// recorder is a testing.TB that records failures. Fatal calls end the
+// goroutine, so the function under test must run in run.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatal(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+	r.fatal = true
+	runtime.Goexit()
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Fatal(fmt.Sprintf(format, args...))
+}
+
+// Runs f on its own goroutine and returns the failures it recorded.
+func (r *recorder) run(f func(t testing.TB)) *recorder {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(r)
+	}()
+	<-done
+	return r
+}
+
+// Sets the -update flag for the rest of the test.
+func setUpdate(t *testing.T, v bool) {
+	old := *update
+	*update = v
+	t.Cleanup(func() { *update = old })
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	dir := t.TempDir()
+	opts := &Options{Dir: dir}
+	img := row(color.NRGBA{1, 2, 3, 255}, color.NRGBA{4, 5, 6, 128})
+
+	setUpdate(t, true)
+	r := (&recorder{TB: t}).run(func(t testing.TB) { Golden(t, "scene", img, opts) })
+	if len(r.errors) > 0 {
+		t.Fatal(r.errors)
+	}
+	written, err := readPNG(filepath.Join(dir, "scene.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := Compare(img, written, nil); err != nil || d.Differing > 0 {
+		t.Fatalf("golden file differs from the image: %v, %+v", err, d)
+	}
+
+	setUpdate(t, false)
+	r = (&recorder{TB: t}).run(func(t testing.TB) { Golden(t, "scene", img, opts) })
+	if len(r.errors) > 0 {
+		t.Errorf("got failures %v comparing with the updated golden file", r.errors)
+	}
+	for _, name := range []string{"scene.actual.png", "scene.diff.png"} {
+		if exists(filepath.Join(dir, name)) {
+			t.Errorf("wrote %s for matching images", name)
+		}
+	}
+}
+
+func TestGoldenMissing(t *testing.T) {
+	setUpdate(t, false)
+	opts := &Options{Dir: t.TempDir()}
+	r := (&recorder{TB: t}).run(func(t testing.TB) { Golden(t, "missing", row(color.NRGBA{}), opts) })
+	if !r.fatal || len(r.errors) != 1 || !strings.Contains(r.errors[0], "-update") {
+		t.Errorf("got failures %q, want a fatal one suggesting -update", r.errors)
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	setUpdate(t, false)
+	black, white := color.NRGBA{0, 0, 0, 255}, color.NRGBA{255, 255, 255, 255}
+	golden := row(black, black, black, black)
+
+	tests := []struct {
+		name   string
+		img    *image.NRGBA
+		opts   Options
+		failed bool
+	}{
+		{"within ratio", row(black, black, black, white), Options{MaxDiffRatio: 0.25}, false},
+		{"beyond ratio", row(black, black, white, white), Options{MaxDiffRatio: 0.25}, true},
+		{"within tolerance", row(black, color.NRGBA{3, 3, 3, 255}, black, black), Options{Tolerance: 3}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			test.opts.Dir = dir
+			if err := writePNG(filepath.Join(dir, "scene.png"), golden); err != nil {
+				t.Fatal(err)
+			}
+			r := (&recorder{TB: t}).run(func(t testing.TB) { Golden(t, "scene", test.img, &test.opts) })
+			if failed := len(r.errors) > 0; failed != test.failed || r.fatal {
+				t.Fatalf("got failures %q, want failed %v", r.errors, test.failed)
+			}
+			actual, diff := filepath.Join(dir, "scene.actual.png"), filepath.Join(dir, "scene.diff.png")
+			if !test.failed {
+				if exists(actual) || exists(diff) {
+					t.Error("wrote the actual image or diff for matching images")
+				}
+				return
+			}
+
+			got, err := readPNG(actual)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d, _ := Compare(test.img, got, nil); d.Differing > 0 {
+				t.Error("actual image differs from the rendered one")
+			}
+			heat, err := readPNG(diff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []color.NRGBA{black, black, {255, 0, 0, 255}, {255, 0, 0, 255}}
+			for x, c := range want {
+				if got := color.NRGBAModel.Convert(heat.At(x, 0)); got != c {
+					t.Errorf("heat map pixel %d is %v, want %v", x, got, c)
+				}
+			}
+		})
+	}
+}
+
+func TestGoldenSize(t *testing.T) {
+	setUpdate(t, false)
+	dir := t.TempDir()
+	if err := writePNG(filepath.Join(dir, "scene.png"), row(color.NRGBA{})); err != nil {
+		t.Fatal(err)
+	}
+	img := row(color.NRGBA{}, color.NRGBA{})
+	r := (&recorder{TB: t}).run(func(t testing.TB) { Golden(t, "scene", img, &Options{Dir: dir}) })
+	if !r.fatal {
+		t.Errorf("got failures %q, want a fatal one", r.errors)
+	}
+	if !exists(filepath.Join(dir, "scene.actual.png")) {
+		t.Error("did not write the actual image")
+	}
+}